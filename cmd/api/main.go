@@ -1,105 +1,296 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"math/rand"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
-	"github.com/starkbank/sdk-go/starkbank"
-	"github.com/starkinfra/core-go/starkcore/user/project"
-
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/calendar"
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/handler"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/middleware"
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
 )
 
 func main() {
 	// Inicializar seed para números aleatórios
 	rand.Seed(time.Now().UnixNano())
 
+	// Carregar configurações: padrão, arquivo (-config), ambiente e flags
+	loader, err := config.NewLoader(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fatal("erro ao ler argumentos", err)
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		fatal("erro ao carregar configurações", err)
+	}
+	if loader.PrintConfig {
+		cfg.WriteRedacted(os.Stdout)
+		return
+	}
+	var current atomic.Pointer[config.Config]
+	current.Store(cfg)
+
 	// Banner
 	printBanner()
 
-	// Carregar configurações
-	log.Println("⚙️  Carregando configurações...")
-	cfg, err := config.Load()
+	// Configurar logging estruturado (nível alterável por SIGHUP)
+	level, err := logging.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		fatal("erro ao configurar logging", err)
+	}
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(level)
+	slog.SetDefault(logging.New(logLevel, os.Stdout))
+	if loader.File != "" {
+		slog.Info("arquivo de configuração carregado", "file", loader.File)
+	}
+
+	// Configurar tracing (OpenTelemetry)
+	shutdownTracing, err := tracing.Setup(cfg.Tracing)
 	if err != nil {
-		log.Fatalf("❌ Erro ao carregar configurações: %v\n", err)
+		fatal("erro ao configurar tracing", err)
 	}
 
-	// Inicializar SDK da StarkBank
-	log.Println("🔐 Inicializando SDK da StarkBank...")
-	starkbank.User = project.Project{
-		Environment: cfg.StarkBank.Environment,
-		Id:          cfg.StarkBank.ProjectID,
-		PrivateKey:  cfg.StarkBank.PrivateKey,
+	// Inicializar repositórios compartilhados entre os tenants
+	apiKeyRepo, err := repository.NewFileAPIKeyRepository(cfg.Storage.DataDir)
+	if err != nil {
+		fatal("erro ao abrir repositório de chaves de API", err)
+	}
+	auditRepo, err := repository.NewFileAuditRepository(cfg.Storage.DataDir)
+	if err != nil {
+		fatal("erro ao abrir trilha de auditoria", err)
 	}
-	log.Println("✅ SDK inicializado com sucesso!")
+	defer auditRepo.Close()
 
-	// Inicializar repositórios
-	invoiceRepo := repository.NewStarkBankInvoiceRepository()
-	transferRepo := repository.NewStarkBankTransferRepository()
+	// Inicializar serviços compartilhados
+	auditService := service.NewAuditService(auditRepo)
+	auditService.RecordConfig(domain.ContextWithActor(context.Background(), domain.ActorStartup), cfg.AuditSummary())
+	authService := service.NewAuthService(apiKeyRepo, cfg.Auth.Keys, auditService)
+	if !authService.HasKeys() {
+		slog.Warn("nenhuma chave de API configurada: rotas administrativas recusarão todas as requisições (defina API_KEYS)")
+	}
 
-	// Inicializar serviços
-	invoiceService := service.NewInvoiceService(invoiceRepo)
-	transferService := service.NewTransferService(transferRepo, cfg.Destination)
-	webhookService := service.NewWebhookService(transferService)
-	schedulerService := service.NewSchedulerService(invoiceService)
+	// Inicializar tenants (um por projeto da StarkBank)
+	tenants := make([]*tenant, 0, len(cfg.Tenants))
+	checks := []service.HealthCheckFunc{service.StorageCheck(cfg.Storage.DataDir)}
+	webhookRouter := handler.NewWebhookRouter()
+	destinationUsage := service.NewDestinationUsage()
+	businessDays, err := calendar.Load(cfg.Calendar.HolidaysFile, cfg.Calendar.Cutoff, cfg.Calendar.Timezone)
+	if err != nil {
+		fatal("erro ao carregar calendário de dias úteis", err)
+	}
+	for _, tc := range cfg.Tenants {
+		t, err := newTenant(tc, cfg, auditService, destinationUsage, businessDays)
+		if err != nil {
+			fatal("erro ao inicializar tenant", err, "tenant", tc.ID)
+		}
+		tenants = append(tenants, t)
+		webhookRouter.Add(tc.ID, tc.WorkspaceID, t.webhookHandler)
+		for _, check := range t.checks {
+			if len(cfg.Tenants) > 1 {
+				check = service.ForTenant(tc.ID, check)
+			}
+			checks = append(checks, check)
+		}
+	}
+	healthService := service.NewHealthService(checks...)
 
 	// Inicializar handlers
-	webhookHandler := handler.NewWebhookHandler(webhookService)
-	healthHandler := handler.NewHealthHandler()
-	balanceHandler := handler.NewBalanceHandler()
+	healthHandler := handler.NewHealthHandler(healthService)
+	apiKeyHandler := handler.NewAPIKeyHandler(authService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	calendarHandler := handler.NewCalendarHandler(businessDays)
 
-	// Configurar rotas
+	// Configurar rotas (cada rota com métricas, limite de corpo e tempo limite).
+	// Os limites são lidos da configuração vigente a cada requisição.
 	mux := http.NewServeMux()
-	mux.HandleFunc("/webhook", webhookHandler.Handle)
-	mux.HandleFunc("/health", healthHandler.Handle)
-	mux.HandleFunc("/balance", balanceHandler.Handle)
+	routeLimits := func() middleware.RouteLimits { return middleware.DefaultLimits(current.Load().Server) }
+	webhookLimits := func() middleware.RouteLimits { return middleware.WebhookLimits(current.Load().Server) }
+	transferLimits := func() middleware.RouteLimits { return middleware.TransferLimits(current.Load().Server) }
+	handleWith := func(pattern string, limits middleware.LimitsFunc, h http.Handler, extra ...middleware.Middleware) {
+		chain := append(middleware.Route(pattern, limits), extra...)
+		mux.Handle(pattern, middleware.Chain(h, chain...))
+	}
+	handle := func(pattern string, h http.Handler) {
+		handleWith(pattern, routeLimits, h)
+	}
+	// Rotas públicas têm limite de taxa por IP (e, no webhook, lista de IPs permitidos)
+	clientIP := middleware.NewClientIP(cfg.RateLimit.TrustedProxies)
+	limiter := middleware.NewRateLimiter(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)
+	anyOrigin := func() []netip.Prefix { return nil }
+	webhookOrigins := func() []netip.Prefix { return current.Load().RateLimit.WebhookAllowlist }
+	public := func(pattern string, limits middleware.LimitsFunc, allowlist func() []netip.Prefix, h http.HandlerFunc) {
+		handleWith(pattern, limits, h, middleware.Public(pattern, limiter, clientIP, allowlist)...)
+	}
+	if len(cfg.RateLimit.WebhookAllowlist) == 0 {
+		slog.Warn("WEBHOOK_ALLOWED_IPS vazio: /webhook aceita requisições de qualquer origem (permitido apenas no sandbox)")
+	}
+	// Rotas administrativas exigem chave de API com o papel mínimo indicado
+	protect := func(pattern, role string, h http.HandlerFunc) {
+		handle(pattern, middleware.RequireRole(authService, role, h))
+	}
+	// Rotas de um tenant identificam-no no contexto antes da autenticação
+	protectTenant := func(t *tenant, pattern, role string, h http.HandlerFunc) {
+		handleWith(pattern, routeLimits, middleware.RequireRole(authService, role, h), middleware.Tenant(t.cfg.ID))
+	}
+	// Rotas que movimentam dinheiro não têm tempo limite (ver TransferLimits)
+	protectTransfer := func(t *tenant, pattern, role string, h http.HandlerFunc) {
+		handleWith(pattern, transferLimits, middleware.RequireRole(authService, role, h), middleware.Tenant(t.cfg.ID))
+	}
+	tenantRoutes := func(t *tenant, prefix string) {
+		// Leitura
+		protectTenant(t, prefix+"/balance", domain.RoleReadOnly, t.balanceHandler.Handle)
+		protectTenant(t, prefix+"/balance/history", domain.RoleReadOnly, t.balanceHandler.History)
+		protectTenant(t, prefix+"/balance/alerts", domain.RoleReadOnly, t.balanceHandler.Alerts)
+		protectTenant(t, prefix+"/reports/reversals", domain.RoleReadOnly, t.reversalHandler.Report)
+		protectTenant(t, prefix+"/ledger", domain.RoleReadOnly, t.ledgerHandler.Entries)
+		protectTenant(t, prefix+"/ledger/verify", domain.RoleReadOnly, t.ledgerHandler.Verify)
+		protectTenant(t, prefix+"/transfers/held", domain.RoleReadOnly, t.holdQueueHandler.Handle)
+		protectTenant(t, prefix+"/invoices", domain.RoleReadOnly, t.invoiceHandler.List)
+		protectTenant(t, prefix+"/transfers", domain.RoleReadOnly, t.transferHandler.List)
+		protectTenant(t, prefix+"/transfers/scheduled", domain.RoleReadOnly, t.scheduleHandler.List)
+		protectTenant(t, prefix+"/events", domain.RoleReadOnly, t.eventHandler.List)
+		protectTenant(t, prefix+"/jobs", domain.RoleReadOnly, t.jobHandler.List)
+		protectTenant(t, prefix+"/forwarding", domain.RoleReadOnly, t.forwardingHandler.Status)
+		protectTenant(t, prefix+"/forwarding/decisions", domain.RoleReadOnly, t.forwardingHandler.Decisions)
+		protectTenant(t, prefix+"/forwarding/destination", domain.RoleReadOnly, t.forwardingHandler.Destination)
+		protectTenant(t, prefix+"/approvals", domain.RoleReadOnly, t.forwardingHandler.Approvals)
+
+		// Operação
+		protectTransfer(t, prefix+"/reversals/resolve", domain.RoleOperator, t.reversalHandler.Resolve)
+		protectTransfer(t, prefix+"/invoices/create", domain.RoleOperator, t.invoiceHandler.Create)
+		protectTransfer(t, prefix+"/events/replay", domain.RoleOperator, t.eventHandler.Replay)
+		protectTransfer(t, prefix+"/jobs/run", domain.RoleOperator, t.jobHandler.Run)
+		protectTransfer(t, prefix+"/approvals/approve", domain.RoleOperator, t.forwardingHandler.Approve)
+		protectTenant(t, prefix+"/approvals/reject", domain.RoleOperator, t.forwardingHandler.Reject)
+		protectTransfer(t, prefix+"/transfers/scheduled/cancel", domain.RoleOperator, t.scheduleHandler.Cancel)
+
+		// Administração: transferências agendadas para qualquer conta
+		protectTransfer(t, prefix+"/transfers/schedule", domain.RoleAdmin, t.scheduleHandler.Create)
+
+		// Administração: rotação da chave privada do projeto
+		protectTenant(t, prefix+"/admin/key-rotation", domain.RoleAdmin, t.keyHandler.Rotation)
+		protectTenant(t, prefix+"/admin/key-rotation/confirm", domain.RoleAdmin, t.keyHandler.Confirm)
+		protectTenant(t, prefix+"/admin/key-rotation/rollback", domain.RoleAdmin, t.keyHandler.Rollback)
+		protectTenant(t, prefix+"/admin/key-rotation/retire", domain.RoleAdmin, t.keyHandler.Retire)
+		protectTenant(t, prefix+"/admin/key-rotation/cancel", domain.RoleAdmin, t.keyHandler.Cancel)
+	}
+
+	// Públicas: o webhook é autenticado pela assinatura Digital-Signature,
+	// verificada com a chave pública da StarkBank antes de processar. Em
+	// /webhook o tenant vem do workspaceId do evento já verificado; em
+	// /webhook/<id>, do caminho
+	public("/webhook", webhookLimits, webhookOrigins, webhookRouter.Handle)
+	for _, t := range tenants {
+		public("/webhook/"+t.cfg.ID, webhookLimits, webhookOrigins, webhookRouter.Tenant(t.cfg.ID))
+	}
+	public("/health", routeLimits, anyOrigin, healthHandler.Live) // compatibilidade: equivalente a /livez
+	public("/livez", routeLimits, anyOrigin, healthHandler.Live)
+	public("/readyz", routeLimits, anyOrigin, healthHandler.Ready)
+
+	// Rotas de cada tenant em /tenants/<id>/...; as rotas sem prefixo
+	// continuam atendendo o primeiro tenant
+	for _, t := range tenants {
+		tenantRoutes(t, "/tenants/"+t.cfg.ID)
+	}
+	tenantRoutes(tenants[0], "")
+	protect("/metrics", domain.RoleReadOnly, metrics.Default.Handler().ServeHTTP)
+	protect("/calendar", domain.RoleReadOnly, calendarHandler.Holidays)
+	protect("/calendar/business-day", domain.RoleReadOnly, calendarHandler.BusinessDay)
 
-	// Aplicar middlewares
-	handlerWithMiddleware := middleware.Recovery(middleware.Logger(mux))
+	// Administração
+	protect("/admin/keys", domain.RoleAdmin, apiKeyHandler.Keys)
+	protect("/admin/keys/revoke", domain.RoleAdmin, apiKeyHandler.Revoke)
+	protect("/audit", domain.RoleAdmin, auditHandler.Entries)
+	protect("/audit/verify", domain.RoleAdmin, auditHandler.Verify)
 
-	// Iniciar scheduler em background
-	go schedulerService.StartInvoiceGeneration()
+	// Aplicar middlewares globais
+	handlerWithMiddleware := middleware.Chain(mux, middleware.Server(cfg.Server)...)
+
+	// Iniciar jobs em background
+	for _, t := range tenants {
+		t.start()
+	}
 
 	// Configurar servidor HTTP
 	server := &http.Server{
 		Addr:         cfg.Server.Host + ":" + cfg.Server.Port,
 		Handler:      handlerWithMiddleware,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	// Canal para capturar sinais de interrupção
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	// SIGHUP recarrega os campos recarregáveis da configuração
+	reload := &reloader{
+		loader:   loader,
+		current:  &current,
+		logLevel: logLevel,
+		limiter:  limiter,
+		tenants:  tenants,
+		audit:    auditService,
+	}
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			slog.Info("SIGHUP recebido, recarregando configuração")
+			reload.reload()
+		}
+	}()
+
 	// Iniciar servidor em goroutine
 	go func() {
-		log.Printf("🌐 Servidor HTTP iniciado em %s\n", server.Addr)
-		log.Printf("📡 Endpoint webhook: http://localhost:%s/webhook\n", cfg.Server.Port)
-		log.Printf("❤️  Endpoint health: http://localhost:%s/health\n", cfg.Server.Port)
-		log.Printf("💰 Endpoint balance: http://localhost:%s/balance\n", cfg.Server.Port)
-		log.Println("💡 Dica: Use ngrok para expor localmente: ngrok http", cfg.Server.Port)
+		slog.Info("servidor HTTP iniciado", "addr", server.Addr, "log_level", logLevel.Level().String())
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("❌ Erro ao iniciar servidor: %v\n", err)
+			fatal("erro ao iniciar servidor", err)
 		}
 	}()
 
 	// Aguardar sinal de interrupção
 	<-sigChan
-	log.Println("\n🛑 Recebido sinal de interrupção. Encerrando aplicação...")
-	schedulerService.Stop()
-	log.Println("👋 Aplicação encerrada!")
+	slog.Info("sinal de interrupção recebido, encerrando aplicação")
+	for _, t := range tenants {
+		t.stop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("erro ao encerrar tracing", "error", err)
+	}
+	slog.Info("aplicação encerrada")
+}
+
+// fatal registra o erro e encerra a aplicação
+func fatal(msg string, err error, attrs ...interface{}) {
+	slog.Error(msg, append([]interface{}{"error", err}, attrs...)...)
+	os.Exit(1)
 }
 
 func printBanner() {
@@ -129,5 +320,5 @@ func printBanner() {
   ✓ Clean Code Principles
 
 `
-	log.Println(banner)
+	fmt.Fprintln(os.Stderr, banner)
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/middleware"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// reloader reaplica a configuração ao receber SIGHUP
+//
+// Apenas os campos marcados como recarregáveis no schema têm efeito; os
+// demais são registrados em log e passam a valer no próximo reinício.
+type reloader struct {
+	loader   *config.Loader
+	current  *atomic.Pointer[config.Config]
+	logLevel *slog.LevelVar
+	limiter  *middleware.RateLimiter
+	tenants  []*tenant
+	audit    *service.AuditService
+}
+
+func (r *reloader) reload() {
+	ctx := domain.ContextWithActor(context.Background(), domain.ActorReload)
+
+	next, err := r.loader.Load()
+	if err != nil {
+		slog.ErrorContext(ctx, "configuração recarregada é inválida, mantendo a atual", "error", err)
+		return
+	}
+
+	merged, applied, ignored := config.Reload(r.current.Load(), next)
+	if err := merged.Validate(); err != nil {
+		slog.ErrorContext(ctx, "configuração recarregada é inválida, mantendo a atual", "error", err)
+		return
+	}
+	if len(ignored) > 0 {
+		slog.WarnContext(ctx, "campos alterados exigem reinício e foram ignorados", "fields", ignored)
+	}
+	if len(applied) == 0 {
+		slog.InfoContext(ctx, "configuração recarregada sem alterações aplicáveis")
+		return
+	}
+
+	level, _ := logging.ParseLevel(merged.Log.Level)
+	r.logLevel.Set(level)
+	r.limiter.SetLimits(merged.RateLimit.RequestsPerSecond, merged.RateLimit.Burst)
+	for _, t := range r.tenants {
+		t.reversal.SetAction(merged.Reversal.Action)
+		t.balance.SetRules(merged.Balance.LowThreshold, merged.Balance.DivergenceTolerance)
+		if tc, ok := merged.Tenant(t.cfg.ID); ok {
+			t.forwarding.SetRules(tc.Forwarding, tc.Approval)
+		}
+	}
+	r.current.Store(merged)
+
+	r.audit.RecordConfig(ctx, merged.AuditSummary())
+	slog.InfoContext(ctx, "configuração recarregada", "fields", applied)
+}
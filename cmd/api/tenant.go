@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/calendar"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/handler"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/secrets"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// tenant reúne os serviços e handlers de um projeto da StarkBank
+//
+// Cada tenant tem credenciais, repositórios locais e jobs próprios; a
+// trilha de auditoria e as chaves de API são compartilhadas.
+type tenant struct {
+	cfg config.TenantConfig
+	ctx context.Context // identifica o tenant nos logs e na auditoria dos jobs
+
+	ledgerRepo *repository.FileLedgerRepository
+
+	balance    *service.BalanceService
+	holdQueue  *service.HoldQueueService
+	reversal   *service.ReversalService
+	scheduler  *service.SchedulerService
+	webhook    *service.WebhookServiceImpl
+	polling    *service.EventPollingService
+	forwarding *service.ForwardingService
+	schedules  *service.TransferScheduleService
+	checks     []service.HealthCheckFunc
+
+	webhookHandler    *handler.WebhookHandler
+	balanceHandler    *handler.BalanceHandler
+	reversalHandler   *handler.ReversalHandler
+	ledgerHandler     *handler.LedgerHandler
+	holdQueueHandler  *handler.HoldQueueHandler
+	keyHandler        *handler.KeyRotationHandler
+	invoiceHandler    *handler.InvoiceHandler
+	transferHandler   *handler.TransferHandler
+	eventHandler      *handler.EventHandler
+	jobHandler        *handler.JobHandler
+	forwardingHandler *handler.ForwardingHandler
+	scheduleHandler   *handler.TransferScheduleHandler
+}
+
+// pixKeyCacheTTL é por quanto tempo a conta de uma chave Pix consultada no
+// DICT é reutilizada nos repasses
+const pixKeyCacheTTL = 10 * time.Minute
+
+// newTenant carrega as credenciais do tenant e monta seus serviços; usage é
+// compartilhado para o limite diário por conta de destino, e cal define os
+// dias úteis
+func newTenant(tc config.TenantConfig, cfg *config.Config, auditService *service.AuditService, usage *service.DestinationUsage, cal *calendar.Calendar) (*tenant, error) {
+	ctx := logging.WithTenant(context.Background(), tc.ID)
+
+	// Carregar e validar a chave privada antes de qualquer chamada ao SDK
+	keyProvider, err := secrets.NewProvider(tc.StarkBank)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao configurar origem da chave privada: %w", err)
+	}
+	privateKey, err := keyProvider.PrivateKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar chave privada (%s): %w", keyProvider.Name(), err)
+	}
+
+	// Cada tenant assina as chamadas com o próprio chaveiro, que permite
+	// trocar a chave sem reiniciar (rotação)
+	keys, err := secrets.NewKeyRing(tc.StarkBank.ProjectID, tc.StarkBank.Environment, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("chave privada inválida (%s): %w", keyProvider.Name(), err)
+	}
+	if err := os.MkdirAll(tc.DataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de dados: %w", err)
+	}
+	rotationRepo, err := repository.NewFileKeyRotationRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir repositório de rotações de chave: %w", err)
+	}
+	keyStore, _ := keyProvider.(secrets.Store)
+	verifyKey := func(ctx context.Context, fingerprint string) error {
+		candidate, err := keys.User(fingerprint)
+		if err != nil {
+			return err
+		}
+		return service.CheckCredentials(ctx, repository.NewStarkBankBalanceRepository(candidate))
+	}
+	keyRotationService := service.NewKeyRotationService(rotationRepo, keys, keyStore, verifyKey, auditService)
+	if err := keyRotationService.Restore(ctx); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "SDK da StarkBank inicializado",
+		"project_id", tc.StarkBank.ProjectID,
+		"environment", tc.StarkBank.Environment,
+		"key_source", keyProvider.Name(),
+		"key_fingerprint", keys.Active(),
+	)
+
+	// Inicializar repositórios
+	invoiceRepo := repository.NewStarkBankInvoiceRepository(keys)
+	transferRepo := repository.NewStarkBankTransferRepository(keys)
+	pixKeyRepo := repository.NewCachedPixKeyRepository(repository.NewStarkBankPixKeyRepository(keys), pixKeyCacheTTL)
+	brcodePaymentRepo := repository.NewStarkBankBRCodePaymentRepository(keys)
+	balanceRepo := repository.NewStarkBankBalanceRepository(keys)
+	eventRepo := repository.NewStarkBankEventRepository(keys)
+	invoiceLogRepo := repository.NewStarkBankInvoiceLogRepository(keys)
+	if tc.StarkBank.VerifyCredentials {
+		if err := service.VerifyCredentials(ctx, balanceRepo); err != nil {
+			return nil, fmt.Errorf("chave privada não pertence ao projeto %s: %w", tc.StarkBank.ProjectID, err)
+		}
+	}
+
+	// Inicializar repositórios locais
+	forwardRepo, err := repository.NewFileForwardRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir repositório de repasses: %w", err)
+	}
+	reversalRepo, err := repository.NewFileReversalRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir repositório de estornos: %w", err)
+	}
+	payerHoldRepo, err := repository.NewFilePayerHoldRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir repositório de bloqueios: %w", err)
+	}
+	refundRepo, err := repository.NewFileRefundRequestRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir repositório de devoluções: %w", err)
+	}
+	ledgerRepo, err := repository.NewFileLedgerRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir razão: %w", err)
+	}
+	snapshotRepo, err := repository.NewFileBalanceSnapshotRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir histórico de saldos: %w", err)
+	}
+	balanceAlertRepo, err := repository.NewFileBalanceAlertRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir repositório de alertas: %w", err)
+	}
+	holdQueueRepo, err := repository.NewFileHoldQueueRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir fila de retenção: %w", err)
+	}
+	pendingCreditRepo, err := repository.NewFilePendingCreditRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir fila de créditos pendentes: %w", err)
+	}
+	forwardDecisionRepo, err := repository.NewFileForwardDecisionRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir decisões de repasse: %w", err)
+	}
+	transferApprovalRepo, err := repository.NewFileTransferApprovalRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir solicitações de aprovação: %w", err)
+	}
+	scheduledTransferRepo, err := repository.NewFileScheduledTransferRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir transferências agendadas: %w", err)
+	}
+	eventCursorRepo, err := repository.NewFileEventCursorRepository(tc.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir cursor de eventos: %w", err)
+	}
+	cachedBalanceRepo := repository.NewCachedBalanceRepository(balanceRepo, cfg.Transfer.BalanceCacheTTL)
+
+	// Inicializar serviços
+	invoiceService := service.NewInvoiceService(invoiceRepo, cal, cfg.Calendar.InvoiceDueDays, auditService)
+	forwardTarget, err := service.NewForwardTarget(tc.Forwarding)
+	if err != nil {
+		return nil, err
+	}
+	transferService := service.NewTransferService(transferRepo, tc.Destination, forwardTarget, pixKeyRepo, brcodePaymentRepo,
+		cachedBalanceRepo, domain.BRL(cfg.Transfer.Fee), auditService)
+	holdQueueService := service.NewHoldQueueService(holdQueueRepo, cachedBalanceRepo, cfg.Transfer.HoldReleaseInterval, auditService)
+	ledgerService := service.NewLedgerService(ledgerRepo)
+	forwardingService := service.NewForwardingService(tc.Forwarding, tc.Approval, cfg.Destination,
+		transferService, ledgerService, forwardRepo, pendingCreditRepo, forwardDecisionRepo, transferApprovalRepo, usage, cal.Location(), auditService)
+	reversalService := service.NewReversalService(reversalRepo, forwardRepo, payerHoldRepo, refundRepo, ledgerService, forwardingService, cfg.Reversal.Action, auditService)
+	scheduleService := service.NewTransferScheduleService(scheduledTransferRepo, transferService, ledgerService,
+		[]service.ReservedBalance{forwardingService, holdQueueService}, cal, cfg.Transfer.ScheduleInterval, auditService)
+	webhookService := service.NewWebhookService(forwardingService, reversalService, ledgerService, holdQueueService, eventRepo)
+	schedulerService := service.NewSchedulerService(invoiceService, tc.Scheduler)
+	balanceService := service.NewBalanceService(balanceRepo, snapshotRepo, balanceAlertRepo, ledgerRepo, cfg.Balance, domain.BRL(cfg.Transfer.Fee))
+	eventService := service.NewEventService(eventRepo, webhookService, auditService)
+	pollingService := service.NewEventPollingService(tc.Polling, eventRepo, invoiceLogRepo, eventCursorRepo, webhookService)
+
+	// Jobs em background que o operador pode disparar (POST /jobs/run)
+	jobs := []service.Job{
+		{Name: "invoices", Description: "gera um lote de invoices aleatórios", Run: func(ctx context.Context) (string, error) {
+			n, err := schedulerService.RunOnce(ctx)
+			return fmt.Sprintf("%d invoices criados", n), err
+		}},
+		{Name: "balance-snapshot", Description: "registra um snapshot do saldo", Run: func(ctx context.Context) (string, error) {
+			return "snapshot registrado", balanceService.Snapshot(ctx)
+		}},
+		{Name: "hold-release", Description: "tenta liberar a fila de repasses retidos", Run: func(ctx context.Context) (string, error) {
+			return fmt.Sprintf("%d repasses liberados", holdQueueService.Release(ctx, webhookService.Forward)), nil
+		}},
+		{Name: "forward-sweep", Description: "repassa os créditos pendentes e encerra as aprovações vencidas", Run: func(ctx context.Context) (string, error) {
+			decision, err := forwardingService.Sweep(ctx)
+			if err != nil || decision == nil {
+				return "nenhum crédito pendente", err
+			}
+			return decision.Reason, nil
+		}},
+		{Name: "transfer-schedules", Description: "executa as transferências recorrentes vencidas", Run: func(ctx context.Context) (string, error) {
+			n, err := scheduleService.Run(ctx)
+			return fmt.Sprintf("%d transferências criadas", n), err
+		}},
+	}
+	checks := []service.HealthCheckFunc{
+		service.StarkBankCheck(balanceRepo, cfg.Health.StarkBankCacheTTL),
+		service.HoldQueueCheck(holdQueueService, int(cfg.Health.MaxHoldBacklog)),
+		service.SchedulerCheck(schedulerService),
+	}
+	if pollingService.Enabled() {
+		jobs = append(jobs, service.Job{Name: "event-poll", Description: "consulta os eventos pendentes na StarkBank", Run: func(ctx context.Context) (string, error) {
+			n, err := pollingService.Poll(ctx)
+			return fmt.Sprintf("%d eventos processados", n), err
+		}})
+		checks = append(checks, service.EventPollingCheck(pollingService))
+	}
+	jobService := service.NewJobService(auditService, jobs...)
+
+	return &tenant{
+		cfg:               tc,
+		ctx:               ctx,
+		ledgerRepo:        ledgerRepo,
+		balance:           balanceService,
+		holdQueue:         holdQueueService,
+		reversal:          reversalService,
+		scheduler:         schedulerService,
+		webhook:           webhookService,
+		polling:           pollingService,
+		forwarding:        forwardingService,
+		schedules:         scheduleService,
+		checks:            checks,
+		webhookHandler:    handler.NewWebhookHandler(webhookService),
+		balanceHandler:    handler.NewBalanceHandler(balanceService),
+		reversalHandler:   handler.NewReversalHandler(reversalService),
+		ledgerHandler:     handler.NewLedgerHandler(ledgerService, cal),
+		holdQueueHandler:  handler.NewHoldQueueHandler(holdQueueService),
+		keyHandler:        handler.NewKeyRotationHandler(keyRotationService),
+		invoiceHandler:    handler.NewInvoiceHandler(invoiceService),
+		transferHandler:   handler.NewTransferHandler(transferService),
+		eventHandler:      handler.NewEventHandler(eventService),
+		jobHandler:        handler.NewJobHandler(jobService),
+		forwardingHandler: handler.NewForwardingHandler(forwardingService),
+		scheduleHandler:   handler.NewTransferScheduleHandler(scheduleService),
+	}, nil
+}
+
+// start inicia os jobs em background do tenant
+func (t *tenant) start() {
+	go t.scheduler.StartInvoiceGeneration(t.ctx)
+	go t.balance.StartSnapshots(t.ctx)
+	go t.holdQueue.StartRelease(t.ctx, t.webhook.Forward)
+	go t.polling.Start(t.ctx)
+	go t.forwarding.StartSweeps(t.ctx)
+	go t.schedules.Start(t.ctx)
+}
+
+// stop encerra os jobs e fecha o razão
+func (t *tenant) stop() {
+	t.scheduler.Stop()
+	t.balance.Stop()
+	t.holdQueue.Stop()
+	t.polling.Stop()
+	t.forwarding.Stop()
+	t.schedules.Stop()
+	t.ledgerRepo.Close()
+}
@@ -0,0 +1,41 @@
+// Comando audit-verify confere offline a trilha de auditoria
+//
+// Uso:
+//
+//	go run ./cmd/audit-verify -file data/audit.jsonl
+//
+// Termina com código 1 se a cadeia estiver violada e 2 se o arquivo não
+// puder ser lido.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+func main() {
+	file := flag.String("file", filepath.Join("data", repository.AuditFileName), "arquivo da trilha de auditoria")
+	flag.Parse()
+
+	entries, err := repository.ReadAuditFile(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(2)
+	}
+
+	result := service.VerifyAudit(entries)
+	if !result.Valid {
+		fmt.Printf("❌ %s (%d entradas lidas)\n", result.Error, result.Entries)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ trilha íntegra: %d entradas\n", result.Entries)
+	if result.Head != "" {
+		fmt.Printf("   último hash: %s\n", result.Head)
+	}
+}
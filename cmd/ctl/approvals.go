@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+var approvalCommands = map[string]command{
+	"list":    {summary: "lista as solicitações de aprovação de repasse (requer -server)", run: approvalsList},
+	"approve": {summary: "aprova um repasse com a chave de API usada (requer -server)", run: approvalsApprove},
+	"reject":  {summary: "rejeita um repasse; os créditos saem da fila (requer -server)", run: approvalsReject},
+}
+
+func approvalTable(env *ctlEnv, approvals []domain.TransferApproval) table {
+	t := table{header: []string{"ID", "STATUS", "VALOR", "INVOICES", "APROVAÇÕES", "APROVADORES", "EXPIRA", "TRANSFERÊNCIA", "MOTIVO"}}
+	for _, a := range approvals {
+		reason := a.Reason
+		if a.LastError != "" {
+			reason = "erro: " + a.LastError
+		}
+		t.rows = append(t.rows, []string{
+			a.ID, a.Status, env.money(a.Amount), strconv.Itoa(len(a.InvoiceIDs)),
+			fmt.Sprintf("%d/%d", len(a.Approvals), a.Required), strings.Join(a.Approvers(), ","),
+			env.time(&a.Expires), a.TransferID, reason,
+		})
+	}
+	return t
+}
+
+func approvalsList(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("approvals list", flag.ContinueOnError)
+	status := fs.String("status", domain.ApprovalStatusPending, "pending_approval, approved, rejected, expired ou all")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	approvals, err := env.backend.Approvals(env.ctx, *status)
+	if err != nil {
+		return err
+	}
+	return env.print(approvals, approvalTable(env, approvals))
+}
+
+func approvalsApprove(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("approvals approve", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "não pede confirmação")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("uso: ctl approvals approve [-yes] <id>")
+	}
+	id := fs.Arg(0)
+
+	// A última aprovação necessária cria a transferência
+	if !confirm(fmt.Sprintf("Aprovar o repasse %s?", id), *yes) {
+		env.note("nada alterado")
+		return nil
+	}
+	approval, err := env.backend.Approve(env.ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := env.print(approval, approvalTable(env, []domain.TransferApproval{*approval})); err != nil {
+		return err
+	}
+	switch {
+	case approval.LastError != "":
+		return errors.New("aprovação registrada, mas a transferência falhou: " + approval.LastError)
+	case approval.Status == domain.ApprovalStatusApproved:
+		env.note("✅ repasse aprovado: transferência %s", approval.TransferID)
+	default:
+		env.note("aprovação registrada (%d/%d)", len(approval.Approvals), approval.Required)
+	}
+	return nil
+}
+
+func approvalsReject(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("approvals reject", flag.ContinueOnError)
+	note := fs.String("note", "", "justificativa da rejeição")
+	yes := fs.Bool("yes", false, "não pede confirmação")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("uso: ctl approvals reject [-note texto] [-yes] <id>")
+	}
+	id := fs.Arg(0)
+
+	if !confirm(fmt.Sprintf("Rejeitar o repasse %s? Os créditos saem da fila de repasse.", id), *yes) {
+		env.note("nada alterado")
+		return nil
+	}
+	approval, err := env.backend.Reject(env.ctx, id, *note)
+	if err != nil {
+		return err
+	}
+	env.note("repasse %s rejeitado", approval.ID)
+	return env.print(approval, approvalTable(env, []domain.TransferApproval{*approval}))
+}
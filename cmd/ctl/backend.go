@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/calendar"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// backend executa as operações dos comandos, diretamente na StarkBank ou
+// pela API do servidor em execução
+type backend interface {
+	Invoices(ctx context.Context, limit int) ([]domain.Invoice, error)
+	Invoice(ctx context.Context, id string) (*domain.Invoice, error)
+	CreateInvoices(ctx context.Context, invoices []domain.Invoice) ([]domain.Invoice, error)
+	RandomInvoices(ctx context.Context, count int) ([]domain.Invoice, error)
+	Transfers(ctx context.Context, limit int) ([]domain.Transfer, error)
+	Transfer(ctx context.Context, id string) (*domain.Transfer, error)
+	Balance(ctx context.Context) (*domain.Balance, error)
+	Events(ctx context.Context, filter domain.EventFilter) ([]domain.Event, error)
+	ReplayEvent(ctx context.Context, id string) (*domain.Event, error)
+	Jobs(ctx context.Context) ([]service.JobStatus, error)
+	RunJob(ctx context.Context, name string) (*service.JobRun, error)
+	Approvals(ctx context.Context, status string) ([]domain.TransferApproval, error)
+	Approve(ctx context.Context, id string) (*domain.TransferApproval, error)
+	Reject(ctx context.Context, id, note string) (*domain.TransferApproval, error)
+	ScheduledTransfers(ctx context.Context, status string) ([]domain.ScheduledTransfer, error)
+	ScheduleTransfer(ctx context.Context, schedule domain.ScheduledTransfer) (*domain.ScheduledTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, id string) (*domain.ScheduledTransfer, error)
+}
+
+// errRequiresServer indica uma operação que depende do estado do servidor
+// (razão, fila de retenção, jobs, aprovações e agendamentos) e não pode ser feita diretamente
+var errRequiresServer = errors.New("operação disponível apenas pelo servidor: use -server (ou CTL_SERVER)")
+
+// directBackend chama a StarkBank com a chave do tenant. Operações diretas
+// não entram na trilha de auditoria, que pertence ao processo da API.
+type directBackend struct {
+	env *ctlEnv
+}
+
+// open carrega a chave do tenant e o identifica no contexto dos logs
+func (b *directBackend) open(ctx context.Context) (context.Context, error) {
+	if err := b.env.load(); err != nil {
+		return nil, err
+	}
+	return logging.WithTenant(ctx, b.env.tenant.ID), nil
+}
+
+func (b *directBackend) invoices(ctx context.Context) (context.Context, *service.InvoiceService, error) {
+	ctx, err := b.open(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	cal, err := calendar.Load(b.env.cfg.Calendar.HolidaysFile, b.env.cfg.Calendar.Cutoff, b.env.cfg.Calendar.Timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+	invoices := service.NewInvoiceService(repository.NewStarkBankInvoiceRepository(b.env.keys), cal, b.env.cfg.Calendar.InvoiceDueDays, service.NopAuditor)
+	return ctx, invoices, nil
+}
+
+func (b *directBackend) transfers(ctx context.Context) (context.Context, *repository.StarkBankTransferRepository, error) {
+	ctx, err := b.open(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, repository.NewStarkBankTransferRepository(b.env.keys), nil
+}
+
+func (b *directBackend) Invoices(ctx context.Context, limit int) ([]domain.Invoice, error) {
+	ctx, svc, err := b.invoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.List(ctx, limit)
+}
+
+func (b *directBackend) Invoice(ctx context.Context, id string) (*domain.Invoice, error) {
+	ctx, svc, err := b.invoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetByID(ctx, id)
+}
+
+func (b *directBackend) CreateInvoices(ctx context.Context, invoices []domain.Invoice) ([]domain.Invoice, error) {
+	ctx, svc, err := b.invoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.Create(ctx, invoices)
+}
+
+func (b *directBackend) RandomInvoices(ctx context.Context, count int) ([]domain.Invoice, error) {
+	ctx, svc, err := b.invoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GenerateRandomInvoices(ctx, count)
+}
+
+func (b *directBackend) Transfers(ctx context.Context, limit int) ([]domain.Transfer, error) {
+	ctx, repo, err := b.transfers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.List(ctx, limit)
+}
+
+func (b *directBackend) Transfer(ctx context.Context, id string) (*domain.Transfer, error) {
+	ctx, repo, err := b.transfers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(ctx, id)
+}
+
+func (b *directBackend) Balance(ctx context.Context) (*domain.Balance, error) {
+	ctx, err := b.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewStarkBankBalanceRepository(b.env.keys).Get(ctx)
+}
+
+func (b *directBackend) Events(ctx context.Context, filter domain.EventFilter) ([]domain.Event, error) {
+	ctx, err := b.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewStarkBankEventRepository(b.env.keys).List(ctx, filter)
+}
+
+func (b *directBackend) ReplayEvent(ctx context.Context, id string) (*domain.Event, error) {
+	return nil, errRequiresServer
+}
+
+func (b *directBackend) Jobs(ctx context.Context) ([]service.JobStatus, error) {
+	return nil, errRequiresServer
+}
+
+func (b *directBackend) RunJob(ctx context.Context, name string) (*service.JobRun, error) {
+	return nil, errRequiresServer
+}
+
+func (b *directBackend) Approvals(ctx context.Context, status string) ([]domain.TransferApproval, error) {
+	return nil, errRequiresServer
+}
+
+func (b *directBackend) Approve(ctx context.Context, id string) (*domain.TransferApproval, error) {
+	return nil, errRequiresServer
+}
+
+func (b *directBackend) Reject(ctx context.Context, id, note string) (*domain.TransferApproval, error) {
+	return nil, errRequiresServer
+}
+
+func (b *directBackend) ScheduledTransfers(ctx context.Context, status string) ([]domain.ScheduledTransfer, error) {
+	return nil, errRequiresServer
+}
+
+func (b *directBackend) ScheduleTransfer(ctx context.Context, schedule domain.ScheduledTransfer) (*domain.ScheduledTransfer, error) {
+	return nil, errRequiresServer
+}
+
+func (b *directBackend) CancelScheduledTransfer(ctx context.Context, id string) (*domain.ScheduledTransfer, error) {
+	return nil, errRequiresServer
+}
+
+// apiBackend usa a API administrativa do servidor; as operações entram na
+// auditoria do servidor com a chave de API usada
+type apiBackend struct {
+	baseURL string // URL do servidor com o prefixo do tenant, se houver
+	apiKey  string
+	client  *http.Client
+}
+
+func newAPIBackend(server, apiKey, tenantID string) *apiBackend {
+	base := strings.TrimRight(server, "/")
+	if tenantID != "" {
+		base += "/tenants/" + url.PathEscape(tenantID)
+	}
+	return &apiBackend{
+		baseURL: base,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// do envia a requisição e decodifica a resposta em out; statuses lista
+// códigos de erro cujo corpo ainda é o resultado esperado
+func (b *apiBackend) do(ctx context.Context, method, path string, body, out interface{}, statuses ...int) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if b.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+b.apiKey)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao chamar o servidor: %w", err)
+	}
+	defer resp.Body.Close()
+
+	accepted := resp.StatusCode < 300
+	for _, status := range statuses {
+		accepted = accepted || resp.StatusCode == status
+	}
+	if !accepted {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("servidor respondeu %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (b *apiBackend) Invoices(ctx context.Context, limit int) ([]domain.Invoice, error) {
+	var invoices []domain.Invoice
+	err := b.do(ctx, http.MethodGet, "/invoices?limit="+strconv.Itoa(limit), nil, &invoices)
+	return invoices, err
+}
+
+func (b *apiBackend) Invoice(ctx context.Context, id string) (*domain.Invoice, error) {
+	var invoice domain.Invoice
+	if err := b.do(ctx, http.MethodGet, "/invoices?id="+url.QueryEscape(id), nil, &invoice); err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (b *apiBackend) CreateInvoices(ctx context.Context, invoices []domain.Invoice) ([]domain.Invoice, error) {
+	var created []domain.Invoice
+	err := b.do(ctx, http.MethodPost, "/invoices/create", map[string]interface{}{"invoices": invoices}, &created)
+	return created, err
+}
+
+func (b *apiBackend) RandomInvoices(ctx context.Context, count int) ([]domain.Invoice, error) {
+	var created []domain.Invoice
+	err := b.do(ctx, http.MethodPost, "/invoices/create", map[string]interface{}{"count": count}, &created)
+	return created, err
+}
+
+func (b *apiBackend) Transfers(ctx context.Context, limit int) ([]domain.Transfer, error) {
+	var transfers []domain.Transfer
+	err := b.do(ctx, http.MethodGet, "/transfers?limit="+strconv.Itoa(limit), nil, &transfers)
+	return transfers, err
+}
+
+func (b *apiBackend) Transfer(ctx context.Context, id string) (*domain.Transfer, error) {
+	var transfer domain.Transfer
+	if err := b.do(ctx, http.MethodGet, "/transfers?id="+url.QueryEscape(id), nil, &transfer); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (b *apiBackend) Balance(ctx context.Context) (*domain.Balance, error) {
+	var resp struct {
+		Amount   int64      `json:"amount"`
+		Currency string     `json:"currency"`
+		Updated  *time.Time `json:"updated"`
+	}
+	if err := b.do(ctx, http.MethodGet, "/balance", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &domain.Balance{Amount: domain.NewMoney(resp.Amount, resp.Currency), Updated: resp.Updated}, nil
+}
+
+func (b *apiBackend) Events(ctx context.Context, filter domain.EventFilter) ([]domain.Event, error) {
+	query := url.Values{"limit": {strconv.Itoa(filter.Limit)}}
+	if filter.After != nil {
+		query.Set("after", filter.After.Format("2006-01-02"))
+	}
+	if filter.Before != nil {
+		query.Set("before", filter.Before.Format("2006-01-02"))
+	}
+	if filter.Undelivered {
+		query.Set("undelivered", "true")
+	}
+	var events []domain.Event
+	err := b.do(ctx, http.MethodGet, "/events?"+query.Encode(), nil, &events)
+	return events, err
+}
+
+func (b *apiBackend) ReplayEvent(ctx context.Context, id string) (*domain.Event, error) {
+	var event domain.Event
+	if err := b.do(ctx, http.MethodPost, "/events/replay", map[string]string{"id": id}, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (b *apiBackend) Jobs(ctx context.Context) ([]service.JobStatus, error) {
+	var jobs []service.JobStatus
+	err := b.do(ctx, http.MethodGet, "/jobs", nil, &jobs)
+	return jobs, err
+}
+
+func (b *apiBackend) RunJob(ctx context.Context, name string) (*service.JobRun, error) {
+	var run service.JobRun
+	// 502 traz o resultado de um job que falhou
+	if err := b.do(ctx, http.MethodPost, "/jobs/run", map[string]string{"job": name}, &run, http.StatusBadGateway); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (b *apiBackend) Approvals(ctx context.Context, status string) ([]domain.TransferApproval, error) {
+	var approvals []domain.TransferApproval
+	err := b.do(ctx, http.MethodGet, "/approvals?status="+url.QueryEscape(status), nil, &approvals)
+	return approvals, err
+}
+
+func (b *apiBackend) Approve(ctx context.Context, id string) (*domain.TransferApproval, error) {
+	var approval domain.TransferApproval
+	// 502 traz a solicitação aprovada cuja transferência falhou
+	if err := b.do(ctx, http.MethodPost, "/approvals/approve", map[string]string{"id": id}, &approval, http.StatusBadGateway); err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+func (b *apiBackend) Reject(ctx context.Context, id, note string) (*domain.TransferApproval, error) {
+	var approval domain.TransferApproval
+	if err := b.do(ctx, http.MethodPost, "/approvals/reject", map[string]string{"id": id, "note": note}, &approval); err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+func (b *apiBackend) ScheduledTransfers(ctx context.Context, status string) ([]domain.ScheduledTransfer, error) {
+	var schedules []domain.ScheduledTransfer
+	err := b.do(ctx, http.MethodGet, "/transfers/scheduled?status="+url.QueryEscape(status), nil, &schedules)
+	return schedules, err
+}
+
+func (b *apiBackend) ScheduleTransfer(ctx context.Context, schedule domain.ScheduledTransfer) (*domain.ScheduledTransfer, error) {
+	req := map[string]interface{}{
+		"description": schedule.Description,
+		"amount":      schedule.Amount.Cents(),
+		"days":        schedule.Days,
+		"at":          schedule.At,
+		"destination": schedule.Destination,
+	}
+	if schedule.Next != nil {
+		req["date"] = schedule.Next.Format(time.RFC3339)
+	}
+	if schedule.KeepBalance != nil {
+		req["keep_balance"] = schedule.KeepBalance.Cents()
+	}
+	var created domain.ScheduledTransfer
+	if err := b.do(ctx, http.MethodPost, "/transfers/schedule", req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (b *apiBackend) CancelScheduledTransfer(ctx context.Context, id string) (*domain.ScheduledTransfer, error) {
+	var schedule domain.ScheduledTransfer
+	if err := b.do(ctx, http.MethodPost, "/transfers/scheduled/cancel", map[string]string{"id": id}, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import "flag"
+
+var balanceCommands = map[string]command{
+	"show": {summary: "mostra o saldo da conta", run: balanceShow},
+}
+
+func balanceShow(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("balance show", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	balance, err := env.backend.Balance(env.ctx)
+	if err != nil {
+		return err
+	}
+	view := map[string]interface{}{"amount": balance.Amount, "updated": balance.Updated}
+	return env.print(view, table{
+		header: []string{"SALDO", "MOEDA", "ATUALIZADO"},
+		rows:   [][]string{{env.money(balance.Amount), balance.Amount.Currency(), env.time(balance.Updated)}},
+	})
+}
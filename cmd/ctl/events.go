@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+var eventCommands = map[string]command{
+	"list":   {summary: "lista os eventos da StarkBank", run: eventsList},
+	"replay": {summary: "reprocessa um evento no servidor (requer -server)", run: eventsReplay},
+}
+
+func eventTable(env *ctlEnv, events []domain.Event) table {
+	t := table{header: []string{"ID", "SUBSCRIPTION", "TIPO", "RECURSO", "ENTREGUE", "CRIADO"}}
+	for _, e := range events {
+		t.rows = append(t.rows, []string{
+			e.ID, e.Subscription, e.Type, e.ResourceID, strconv.FormatBool(e.Delivered), env.time(e.Created),
+		})
+	}
+	return t
+}
+
+func eventsList(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("events list", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "quantidade de eventos (máximo 100)")
+	after := fs.String("after", "", "criados a partir de (AAAA-MM-DD)")
+	before := fs.String("before", "", "criados até (AAAA-MM-DD)")
+	undelivered := fs.Bool("undelivered", false, "apenas eventos não entregues pelo webhook")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	filter := domain.EventFilter{Limit: *limit, Undelivered: *undelivered}
+	for name, value := range map[string]string{"after": *after, "before": *before} {
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return fmt.Errorf("-%s inválido: %q (use AAAA-MM-DD)", name, value)
+		}
+		if name == "after" {
+			filter.After = &t
+		} else {
+			filter.Before = &t
+		}
+	}
+
+	events, err := env.backend.Events(env.ctx, filter)
+	if err != nil {
+		return err
+	}
+	return env.print(events, eventTable(env, events))
+}
+
+func eventsReplay(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("events replay", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "não pede confirmação")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("uso: ctl events replay [-yes] <id>")
+	}
+	id := fs.Arg(0)
+
+	// Um invoice creditado e ainda não repassado gera a transferência
+	if !confirm(fmt.Sprintf("Reprocessar o evento %s?", id), *yes) {
+		env.note("nada alterado")
+		return nil
+	}
+	event, err := env.backend.ReplayEvent(env.ctx, id)
+	if err != nil {
+		return err
+	}
+	env.note("✅ evento %s reprocessado", event.ID)
+	return env.print(event, eventTable(env, []domain.Event{*event}))
+}
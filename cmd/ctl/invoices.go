@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+var invoiceCommands = map[string]command{
+	"list":   {summary: "lista os invoices mais recentes", run: invoicesList},
+	"get":    {summary: "mostra um invoice pelo ID", run: invoicesGet},
+	"create": {summary: "emite um invoice (ou um lote aleatório com -random)", run: invoicesCreate},
+}
+
+func invoiceTable(env *ctlEnv, invoices []domain.Invoice) table {
+	t := table{header: []string{"ID", "STATUS", "VALOR", "TAXA", "NOME", "CPF/CNPJ", "CRIADO"}}
+	for _, inv := range invoices {
+		t.rows = append(t.rows, []string{
+			inv.ID, inv.Status, env.money(inv.Amount), env.money(inv.Fee), inv.Name, inv.TaxID, env.time(inv.Created),
+		})
+	}
+	return t
+}
+
+func invoicesList(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("invoices list", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "quantidade de invoices (máximo 100)")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	invoices, err := env.backend.Invoices(env.ctx, *limit)
+	if err != nil {
+		return err
+	}
+	return env.print(invoices, invoiceTable(env, invoices))
+}
+
+func invoicesGet(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("invoices get", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("uso: ctl invoices get <id>")
+	}
+
+	invoice, err := env.backend.Invoice(env.ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return env.print(invoice, invoiceTable(env, []domain.Invoice{*invoice}))
+}
+
+func invoicesCreate(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("invoices create", flag.ContinueOnError)
+	amount := fs.String("amount", "", "valor em reais (ex: 150.00)")
+	name := fs.String("name", "", "nome do pagador")
+	taxID := fs.String("tax-id", "", "CPF ou CNPJ do pagador")
+	random := fs.Int("random", 0, "emite um lote de N invoices aleatórios em vez de um invoice")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	var created []domain.Invoice
+	var err error
+	if *random > 0 {
+		if *amount != "" || *name != "" || *taxID != "" {
+			return errors.New("use -random ou -amount/-name/-tax-id, não ambos")
+		}
+		created, err = env.backend.RandomInvoices(env.ctx, *random)
+	} else {
+		if *amount == "" || strings.TrimSpace(*name) == "" || strings.TrimSpace(*taxID) == "" {
+			return errors.New("uso: ctl invoices create -amount <valor> -name <nome> -tax-id <cpf/cnpj> | -random <n>")
+		}
+		value, parseErr := parseAmount(*amount)
+		if parseErr != nil {
+			return parseErr
+		}
+		created, err = env.backend.CreateInvoices(env.ctx, []domain.Invoice{{Amount: value, Name: *name, TaxID: *taxID}})
+	}
+	if err != nil {
+		return err
+	}
+
+	env.note("✅ %d invoice(s) emitido(s)", len(created))
+	return env.print(created, invoiceTable(env, created))
+}
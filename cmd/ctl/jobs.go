@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+)
+
+var jobCommands = map[string]command{
+	"list": {summary: "lista os jobs do servidor (requer -server)", run: jobsList},
+	"run":  {summary: "executa um job no servidor e aguarda o resultado (requer -server)", run: jobsRun},
+}
+
+func jobsList(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("jobs list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	jobs, err := env.backend.Jobs(env.ctx)
+	if err != nil {
+		return err
+	}
+	t := table{header: []string{"JOB", "DESCRIÇÃO", "EM EXECUÇÃO", "ÚLTIMA EXECUÇÃO", "RESULTADO"}}
+	for _, j := range jobs {
+		last, result := "", ""
+		if j.LastRun != nil {
+			last = env.time(&j.LastRun.Started)
+			result = j.LastRun.Result
+			if j.LastRun.Error != "" {
+				result = "erro: " + j.LastRun.Error
+			}
+		}
+		t.rows = append(t.rows, []string{j.Name, j.Description, strconv.FormatBool(j.Running), last, result})
+	}
+	return env.print(jobs, t)
+}
+
+func jobsRun(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("jobs run", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("uso: ctl jobs run <job> (veja ctl jobs list)")
+	}
+
+	run, err := env.backend.RunJob(env.ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := env.print(run, table{
+		header: []string{"JOB", "INÍCIO", "DURAÇÃO", "RESULTADO", "ERRO"},
+		rows:   [][]string{{run.Job, env.time(&run.Started), run.Duration, run.Result, run.Error}},
+	}); err != nil {
+		return err
+	}
+	if run.Error != "" {
+		return errors.New("o job falhou: " + run.Error)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+// Comando ctl administra os recursos da StarkBank de um tenant
+//
+// Uso:
+//
+//	go run ./cmd/ctl [flags de configuração] [-tenant id] [-o table|json|csv] <grupo> <comando> [opções]
+//	go run ./cmd/ctl webhooks sync -dry-run
+//	go run ./cmd/ctl -o csv transfers list -limit 50
+//	go run ./cmd/ctl -server http://localhost:8080 jobs run hold-release
+//
+// Sem -server, lê a mesma configuração da API (-config ou CONFIG_FILE,
+// ambiente e flags) e chama a StarkBank diretamente com a chave do tenant.
+// Com -server, usa a API administrativa do servidor em execução, que é
+// obrigatória para reprocessar eventos e disparar jobs.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/secrets"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// command é um subcomando de um grupo (ex: webhooks list)
+type command struct {
+	summary string
+	run     func(env *ctlEnv, args []string) error
+}
+
+// groups são os grupos de comandos disponíveis
+var groups = map[string]map[string]command{
+	"webhooks":  webhookCommands,
+	"invoices":  invoiceCommands,
+	"transfers": transferCommands,
+	"balance":   balanceCommands,
+	"events":    eventCommands,
+	"jobs":      jobCommands,
+	"approvals": approvalCommands,
+}
+
+// ctlEnv é o contexto compartilhado pelos comandos
+//
+// A configuração e a chave do tenant só são carregadas quando um comando
+// chama a StarkBank diretamente (load).
+type ctlEnv struct {
+	ctx      context.Context
+	loader   *config.Loader
+	tenantID string
+	output   string
+	backend  backend
+	stdout   io.Writer // resultados e mensagens do formato table
+	stderr   io.Writer // mensagens fora do formato table
+
+	cfg    *config.Config
+	tenant config.TenantConfig
+	keys   *secrets.KeyRing
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executa a linha de comando args e retorna o código de saída: 0 em
+// sucesso, 1 quando o comando falha e 2 em erros de uso
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { usage(fs) }
+	loader := config.BindFlags(fs)
+	tenantID := fs.String("tenant", "", "tenant a administrar (obrigatório com mais de um tenant)")
+	server := fs.String("server", os.Getenv("CTL_SERVER"), "URL do servidor em execução; vazio chama a StarkBank diretamente (env CTL_SERVER)")
+	apiKey := fs.String("api-key", os.Getenv("CTL_API_KEY"), "chave de API usada com -server (env CTL_API_KEY)")
+	output := fs.String("o", "table", "formato da saída: table, json ou csv")
+	verbose := fs.Bool("v", false, "exibe os logs da aplicação")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *output != "table" && *output != "json" && *output != "csv" {
+		fmt.Fprintf(stderr, "formato de saída inválido: %q (use table, json ou csv)\n", *output)
+		return 2
+	}
+
+	args = fs.Args()
+	if len(args) < 2 {
+		usage(fs)
+		return 2
+	}
+	cmd, ok := groups[args[0]][args[1]]
+	if !ok {
+		fmt.Fprintf(stderr, "comando desconhecido: %s\n\n", strings.Join(args[:2], " "))
+		usage(fs)
+		return 2
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(logging.New(level, stderr))
+
+	env := &ctlEnv{
+		ctx:      context.Background(),
+		loader:   loader,
+		tenantID: *tenantID,
+		output:   *output,
+		stdout:   stdout,
+		stderr:   stderr,
+	}
+	if *server != "" {
+		env.backend = newAPIBackend(*server, *apiKey, *tenantID)
+	} else {
+		env.backend = &directBackend{env: env}
+	}
+	if err := cmd.run(env, args[2:]); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// load carrega a configuração e a chave do tenant (uma única vez)
+func (e *ctlEnv) load() error {
+	if e.keys != nil {
+		return nil
+	}
+	cfg, err := e.loader.Load()
+	if err != nil {
+		return err
+	}
+
+	tenant := cfg.Tenants[0]
+	if e.tenantID != "" {
+		t, ok := cfg.Tenant(e.tenantID)
+		if !ok {
+			return fmt.Errorf("tenant %q não configurado", e.tenantID)
+		}
+		tenant = t
+	} else if len(cfg.Tenants) > 1 {
+		ids := make([]string, len(cfg.Tenants))
+		for i, t := range cfg.Tenants {
+			ids[i] = t.ID
+		}
+		return fmt.Errorf("informe -tenant (%s)", strings.Join(ids, ", "))
+	}
+
+	ctx := logging.WithTenant(context.Background(), tenant.ID)
+	provider, err := secrets.NewProvider(tenant.StarkBank)
+	if err != nil {
+		return err
+	}
+	privateKey, err := provider.PrivateKey(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao carregar chave privada (%s): %w", provider.Name(), err)
+	}
+	keys, err := secrets.NewKeyRing(tenant.StarkBank.ProjectID, tenant.StarkBank.Environment, privateKey)
+	if err != nil {
+		return fmt.Errorf("chave privada inválida (%s): %w", provider.Name(), err)
+	}
+
+	// Uma rotação em andamento pode ter trocado a chave ativa sem atualizar a origem
+	rotations, err := repository.NewFileKeyRotationRepository(tenant.DataDir)
+	if err != nil {
+		return err
+	}
+	if err := service.NewKeyRotationService(rotations, keys, nil, nil, service.NopAuditor).Restore(ctx); err != nil {
+		return err
+	}
+
+	e.ctx, e.cfg, e.tenant, e.keys = ctx, cfg, tenant, keys
+	return nil
+}
+
+// confirm pergunta ao operador antes de uma alteração; yes dispensa a pergunta
+func confirm(question string, yes bool) bool {
+	if yes {
+		return true
+	}
+	fmt.Fprintf(os.Stderr, "%s [s/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "s" || answer == "sim" || answer == "y" || answer == "yes"
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Uso: ctl [-config arquivo] [-tenant id] [-server url] [-o table|json|csv] [-v] <grupo> <comando> [opções]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Comandos:")
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, group := range names {
+		cmds := make([]string, 0, len(groups[group]))
+		for name := range groups[group] {
+			cmds = append(cmds, name)
+		}
+		sort.Strings(cmds)
+		for _, name := range cmds {
+			fmt.Fprintf(w, "  %-22s %s\n", group+" "+name, groups[group][name].summary)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Use ctl <grupo> <comando> -h para as opções de cada comando.")
+	fmt.Fprintln(w, "Sem -server, as flags de configuração são as mesmas da API (ex: -starkbank.project_id); veja go run ./cmd/api -h.")
+	fmt.Fprintln(w, "Com -server (ou CTL_SERVER), os comandos usam a API do servidor com a chave CTL_API_KEY.")
+}
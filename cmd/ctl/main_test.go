@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServer simula a API administrativa: "admin-key" tem papel admin,
+// "read-key" só consulta e as demais chaves são recusadas
+type fakeServer struct {
+	mu       sync.Mutex
+	requests []string // método, caminho e corpo de cada requisição
+	auth     []string
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	f.requests = append(f.requests, strings.TrimSpace(r.Method+" "+r.URL.RequestURI()+" "+string(body)))
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	f.mu.Unlock()
+
+	role := map[string]string{"Bearer admin-key": "admin", "Bearer read-key": "read"}[r.Header.Get("Authorization")]
+	if role == "" {
+		http.Error(w, "chave de API inválida", http.StatusUnauthorized)
+		return
+	}
+	if r.Method == http.MethodPost && role != "admin" {
+		http.Error(w, "permissão insuficiente", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/balance":
+		io.WriteString(w, `{"amount": 123456, "currency": "BRL"}`)
+	case "/tenants/loja-b/balance":
+		io.WriteString(w, `{"amount": 500, "currency": "BRL"}`)
+	case "/jobs":
+		io.WriteString(w, `[{"name": "hold-release", "description": "libera a fila de retenção", "running": false}]`)
+	case "/jobs/run":
+		var req struct{ Job string }
+		json.Unmarshal(body, &req)
+		if req.Job == "forward-sweep" {
+			// Job que falhou: 502 com o resultado
+			w.WriteHeader(http.StatusBadGateway)
+			io.WriteString(w, `{"job": "forward-sweep", "duration": "1s", "error": "saldo insuficiente"}`)
+			return
+		}
+		io.WriteString(w, `{"job": "`+req.Job+`", "duration": "12ms", "result": "3 liberados"}`)
+	case "/approvals/approve":
+		io.WriteString(w, `{"ID": "apr-1", "Status": "approved", "Amount": 150000, "Required": 1,
+			"Approvals": [{"Actor": "key:admin"}], "TransferID": "tr-9", "InvoiceIDs": ["inv-1"]}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv("CTL_SERVER", "")
+	t.Setenv("CTL_API_KEY", "")
+
+	tests := []struct {
+		name    string
+		args    []string // depois de -server
+		code    int
+		stdout  []string
+		stderr  []string
+		request string // última requisição recebida pelo servidor
+		auth    string
+	}{
+		{
+			name:    "tabela",
+			args:    []string{"-api-key", "read-key", "balance", "show"},
+			stdout:  []string{"SALDO", "R$ 1.234,56", "BRL"},
+			request: "GET /balance",
+			auth:    "Bearer read-key",
+		},
+		{
+			name:    "json",
+			args:    []string{"-api-key", "read-key", "-o", "json", "balance", "show"},
+			stdout:  []string{`"amount": {`, `"amount": 123456`},
+			request: "GET /balance",
+		},
+		{
+			name:    "csv",
+			args:    []string{"-api-key", "read-key", "-o", "csv", "balance", "show"},
+			stdout:  []string{"SALDO,MOEDA,ATUALIZADO\n1234.56,BRL,"},
+			request: "GET /balance",
+		},
+		{
+			name:    "prefixo do tenant",
+			args:    []string{"-api-key", "read-key", "-tenant", "loja-b", "balance", "show"},
+			stdout:  []string{"R$ 5,00"},
+			request: "GET /tenants/loja-b/balance",
+		},
+		{
+			name:    "opções do comando",
+			args:    []string{"-api-key", "admin-key", "jobs", "run", "hold-release"},
+			stdout:  []string{"hold-release", "3 liberados"},
+			request: `POST /jobs/run {"job":"hold-release"}`,
+			auth:    "Bearer admin-key",
+		},
+		{
+			name:    "job que falhou",
+			args:    []string{"-api-key", "admin-key", "-o", "json", "jobs", "run", "forward-sweep"},
+			code:    1,
+			stdout:  []string{`"error": "saldo insuficiente"`},
+			stderr:  []string{"o job falhou: saldo insuficiente"},
+			request: `POST /jobs/run {"job":"forward-sweep"}`,
+		},
+		{
+			name:    "papel sem permissão",
+			args:    []string{"-api-key", "read-key", "jobs", "run", "hold-release"},
+			code:    1,
+			stderr:  []string{"403 Forbidden", "permissão insuficiente"},
+			request: `POST /jobs/run {"job":"hold-release"}`,
+		},
+		{
+			name:    "sem chave de API",
+			args:    []string{"jobs", "list"},
+			code:    1,
+			stderr:  []string{"401 Unauthorized"},
+			request: "GET /jobs",
+		},
+		{
+			name:    "mensagens fora da tabela vão para stderr",
+			args:    []string{"-api-key", "admin-key", "-o", "json", "approvals", "approve", "-yes", "apr-1"},
+			stdout:  []string{`"TransferID": "tr-9"`},
+			stderr:  []string{"repasse aprovado: transferência tr-9"},
+			request: `POST /approvals/approve {"id":"apr-1"}`,
+		},
+		{
+			name:   "argumento obrigatório",
+			args:   []string{"-api-key", "admin-key", "jobs", "run"},
+			code:   1,
+			stderr: []string{"uso: ctl jobs run <job>"},
+		},
+		{
+			name:   "comando desconhecido",
+			args:   []string{"jobs", "stop"},
+			code:   2,
+			stderr: []string{"comando desconhecido: jobs stop", "Comandos:"},
+		},
+		{
+			name:   "grupo sem comando",
+			args:   []string{"jobs"},
+			code:   2,
+			stderr: []string{"Uso: ctl"},
+		},
+		{
+			name:   "formato inválido",
+			args:   []string{"-o", "xml", "balance", "show"},
+			code:   2,
+			stderr: []string{`formato de saída inválido: "xml"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeServer{}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			var stdout, stderr strings.Builder
+			code := run(append([]string{"-server", server.URL}, tt.args...), &stdout, &stderr)
+			if code != tt.code {
+				t.Fatalf("código de saída %d, esperado %d\nstdout: %s\nstderr: %s", code, tt.code, stdout.String(), stderr.String())
+			}
+			for _, want := range tt.stdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout sem %q:\n%s", want, stdout.String())
+				}
+			}
+			for _, want := range tt.stderr {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("stderr sem %q:\n%s", want, stderr.String())
+				}
+			}
+
+			if tt.request == "" {
+				if len(fake.requests) != 0 {
+					t.Errorf("nenhuma requisição esperada, obtido %v", fake.requests)
+				}
+				return
+			}
+			if len(fake.requests) == 0 {
+				t.Fatal("nenhuma requisição recebida pelo servidor")
+			}
+			last := len(fake.requests) - 1
+			if fake.requests[last] != tt.request {
+				t.Errorf("requisição %q, esperada %q", fake.requests[last], tt.request)
+			}
+			if tt.auth != "" && fake.auth[last] != tt.auth {
+				t.Errorf("Authorization %q, esperado %q", fake.auth[last], tt.auth)
+			}
+		})
+	}
+}
+
+func TestRunWithoutServer(t *testing.T) {
+	t.Setenv("CTL_SERVER", "")
+
+	// Jobs dependem do estado do servidor e não são chamados na StarkBank
+	var stdout, stderr strings.Builder
+	if code := run([]string{"jobs", "list"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("código de saída %d, esperado 1", code)
+	}
+	if !strings.Contains(stderr.String(), errRequiresServer.Error()) {
+		t.Errorf("stderr sem a indicação de -server:\n%s", stderr.String())
+	}
+}
+
+func TestRunServerFromEnvironment(t *testing.T) {
+	fake := &fakeServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	t.Setenv("CTL_SERVER", server.URL)
+	t.Setenv("CTL_API_KEY", "read-key")
+
+	var stdout, stderr strings.Builder
+	if code := run([]string{"balance", "show"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("código de saída %d: %s", code, stderr.String())
+	}
+	if len(fake.auth) != 1 || fake.auth[0] != "Bearer read-key" {
+		t.Errorf("chave de CTL_API_KEY não enviada: %v", fake.auth)
+	}
+}
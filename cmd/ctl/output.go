@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// table é a representação tabular de um resultado (formatos table e csv)
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// print escreve o resultado no formato escolhido por -o: v como JSON ou t
+// como tabela alinhada ou CSV
+func (e *ctlEnv) print(v interface{}, t table) error {
+	switch e.output {
+	case "json":
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "csv":
+		w := csv.NewWriter(e.stdout)
+		w.Write(t.header)
+		w.WriteAll(t.rows)
+		return w.Error()
+	}
+
+	if len(t.rows) == 0 {
+		fmt.Fprintln(e.stdout, "nenhum resultado")
+		return nil
+	}
+	w := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// note escreve uma mensagem para o operador; fora do formato table vai para
+// stderr, para não misturar com o JSON ou CSV
+func (e *ctlEnv) note(format string, args ...interface{}) {
+	out := e.stdout
+	if e.output != "table" {
+		out = e.stderr
+	}
+	fmt.Fprintf(out, format+"\n", args...)
+}
+
+// money formata um valor: R$ 1.234,56 na tabela, 1234.56 no CSV
+func (e *ctlEnv) money(m domain.Money) string {
+	if e.output == "table" {
+		return m.String()
+	}
+	cents := m.Cents()
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// time formata uma data: local e legível na tabela, RFC 3339 no CSV
+func (e *ctlEnv) time(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	if e.output == "table" {
+		return t.Local().Format("2006-01-02 15:04")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// parseAmount lê um valor em reais (ex: 150, 150.5 ou 150,50) em centavos
+func parseAmount(value string) (domain.Money, error) {
+	value = strings.Replace(strings.TrimSpace(value), ",", ".", 1)
+	units, frac, _ := strings.Cut(value, ".")
+	if len(frac) > 2 {
+		return domain.Money{}, fmt.Errorf("valor inválido: %q (no máximo 2 casas decimais)", value)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil || u < 0 {
+		return domain.Money{}, fmt.Errorf("valor inválido: %q", value)
+	}
+	f, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("valor inválido: %q", value)
+	}
+	return domain.BRL(u*100 + f), nil
+}
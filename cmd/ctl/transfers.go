@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+var transferCommands = map[string]command{
+	"list":      {summary: "lista as transferências mais recentes", run: transfersList},
+	"get":       {summary: "mostra uma transferência pelo ID", run: transfersGet},
+	"scheduled": {summary: "lista as transferências agendadas e recorrentes (requer -server)", run: transfersScheduled},
+	"schedule":  {summary: "agenda uma transferência única ou recorrente (requer -server)", run: transfersSchedule},
+	"cancel":    {summary: "cancela um agendamento antes da execução (requer -server)", run: transfersCancel},
+}
+
+func transferTable(env *ctlEnv, transfers []domain.Transfer) table {
+	t := table{header: []string{"ID", "STATUS", "VALOR", "TAXA", "DESTINO", "CONTA", "DESCRIÇÃO", "CRIADA"}}
+	for _, tr := range transfers {
+		t.rows = append(t.rows, []string{
+			tr.ID, tr.Status, env.money(tr.Amount), env.money(tr.Fee), tr.Name,
+			tr.BankCode + " " + tr.BranchCode + "/" + tr.AccountNumber, tr.Description, env.time(tr.Created),
+		})
+	}
+	return t
+}
+
+func transfersList(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("transfers list", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "quantidade de transferências (máximo 100)")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	transfers, err := env.backend.Transfers(env.ctx, *limit)
+	if err != nil {
+		return err
+	}
+	return env.print(transfers, transferTable(env, transfers))
+}
+
+func transfersGet(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("transfers get", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("uso: ctl transfers get <id>")
+	}
+
+	transfer, err := env.backend.Transfer(env.ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return env.print(transfer, transferTable(env, []domain.Transfer{*transfer}))
+}
+
+func scheduleTable(env *ctlEnv, schedules []domain.ScheduledTransfer) table {
+	t := table{header: []string{"ID", "TIPO", "STATUS", "VALOR", "QUANDO", "PRÓXIMA", "DESTINO", "ÚLTIMO RESULTADO"}}
+	for _, s := range schedules {
+		value := env.money(s.Amount)
+		if s.KeepBalance != nil {
+			value = "acima de " + env.money(*s.KeepBalance)
+		}
+		when := ""
+		if s.Kind == domain.ScheduleRecurring {
+			when = strings.Join(s.Days, ",") + " " + s.At
+		}
+		destination := "padrão do tenant"
+		if d := s.Destination; d != nil {
+			destination = d.Name + " " + d.BankCode + " " + d.BranchCode + "/" + d.AccountNumber
+		}
+		t.rows = append(t.rows, []string{
+			s.ID, s.Kind, s.Status, value, when, env.time(s.Next), destination, s.LastResult,
+		})
+	}
+	return t
+}
+
+func transfersScheduled(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("transfers scheduled", flag.ContinueOnError)
+	status := fs.String("status", domain.ScheduleStatusActive, "active, done, canceled ou all")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	schedules, err := env.backend.ScheduledTransfers(env.ctx, *status)
+	if err != nil {
+		return err
+	}
+	return env.print(schedules, scheduleTable(env, schedules))
+}
+
+func transfersSchedule(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("transfers schedule", flag.ContinueOnError)
+	date := fs.String("date", "", "transferência única: data (AAAA-MM-DD ou RFC3339)")
+	days := fs.String("days", "", "recorrente: dias separados por vírgula (mon...sun, weekdays ou daily)")
+	at := fs.String("at", "", "recorrente: horário HH:MM")
+	amount := fs.String("amount", "", "valor fixo em reais (ex: 500.00)")
+	keep := fs.String("keep", "", "recorrente: transfere o saldo acima deste valor em reais")
+	description := fs.String("description", "", "descrição da transferência")
+	bankCode := fs.String("bank-code", "", "conta de destino (padrão: a do tenant)")
+	branch := fs.String("branch", "", "agência da conta de destino")
+	account := fs.String("account", "", "número da conta de destino")
+	name := fs.String("name", "", "titular da conta de destino")
+	taxID := fs.String("tax-id", "", "CPF/CNPJ do titular")
+	accountType := fs.String("account-type", "", "tipo da conta (checking, savings...)")
+	yes := fs.Bool("yes", false, "não pede confirmação")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	schedule := domain.ScheduledTransfer{Kind: domain.ScheduleRecurring, Description: *description, At: *at}
+	if *days != "" {
+		schedule.Days = strings.Split(*days, ",")
+	}
+	if *date != "" {
+		t, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, *date); err != nil {
+				return fmt.Errorf("-date inválido: %q (use AAAA-MM-DD ou RFC3339)", *date)
+			}
+		}
+		schedule.Kind = domain.ScheduleOnce
+		schedule.Next = &t
+	}
+	if *amount != "" {
+		value, err := parseAmount(*amount)
+		if err != nil {
+			return err
+		}
+		schedule.Amount = value
+	}
+	if *keep != "" {
+		value, err := parseAmount(*keep)
+		if err != nil {
+			return err
+		}
+		schedule.KeepBalance = &value
+	}
+	if *account != "" {
+		schedule.Destination = &domain.BankAccount{
+			BankCode: *bankCode, BranchCode: *branch, AccountNumber: *account,
+			Name: *name, TaxID: *taxID, AccountType: *accountType,
+		}
+	}
+	if *date == "" && (*days == "" || *at == "") {
+		return errors.New("uso: ctl transfers schedule -date <data> -amount <valor> | -days <dias> -at <HH:MM> (-amount <valor> | -keep <valor>)")
+	}
+
+	if !confirm("Agendar a transferência?", *yes) {
+		env.note("nada alterado")
+		return nil
+	}
+	created, err := env.backend.ScheduleTransfer(env.ctx, schedule)
+	if err != nil {
+		return err
+	}
+	env.note("✅ agendamento %s criado", created.ID)
+	return env.print(created, scheduleTable(env, []domain.ScheduledTransfer{*created}))
+}
+
+func transfersCancel(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("transfers cancel", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "não pede confirmação")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("uso: ctl transfers cancel [-yes] <id do agendamento>")
+	}
+	id := fs.Arg(0)
+
+	if !confirm(fmt.Sprintf("Cancelar o agendamento %s?", id), *yes) {
+		env.note("nada alterado")
+		return nil
+	}
+	schedule, err := env.backend.CancelScheduledTransfer(env.ctx, id)
+	if err != nil {
+		return err
+	}
+	env.note("agendamento %s cancelado", schedule.ID)
+	return env.print(schedule, scheduleTable(env, []domain.ScheduledTransfer{*schedule}))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// Os comandos de webhooks sempre chamam a StarkBank diretamente, com a
+// configuração do tenant (webhook.url e webhook.subscriptions)
+var webhookCommands = map[string]command{
+	"list":   {summary: "lista os webhooks cadastrados no projeto", run: webhooksList},
+	"create": {summary: "cadastra um webhook", run: webhooksCreate},
+	"delete": {summary: "remove um webhook pelo ID", run: webhooksDelete},
+	"sync":   {summary: "reconcilia os webhooks com webhook.url e webhook.subscriptions", run: webhooksSync},
+}
+
+func (e *ctlEnv) webhooks() (*service.WebhookSubscriptionService, error) {
+	if err := e.load(); err != nil {
+		return nil, err
+	}
+	return service.NewWebhookSubscriptionService(repository.NewStarkBankWebhookRepository(e.keys)), nil
+}
+
+func webhooksList(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("webhooks list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	svc, err := env.webhooks()
+	if err != nil {
+		return err
+	}
+	webhooks, err := svc.List(env.ctx)
+	if err != nil {
+		return err
+	}
+	return env.print(webhooks, webhookTable(webhooks))
+}
+
+func webhookTable(webhooks []domain.WebhookSubscription) table {
+	t := table{header: []string{"ID", "URL", "EVENTOS"}}
+	for _, wh := range webhooks {
+		t.rows = append(t.rows, []string{wh.ID, wh.URL, strings.Join(wh.Subscriptions, ",")})
+	}
+	return t
+}
+
+func webhooksCreate(env *ctlEnv, args []string) error {
+	svc, err := env.webhooks()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("webhooks create", flag.ContinueOnError)
+	url := fs.String("url", env.tenant.Webhook.URL, "URL pública do webhook")
+	subscriptions := fs.String("subscriptions", strings.Join(env.tenant.Webhook.Subscriptions, ","),
+		"eventos separados por vírgula ("+strings.Join(config.WebhookSubscriptions, ", ")+")")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	created, err := svc.Create(env.ctx, *url, strings.Split(*subscriptions, ","))
+	if err != nil {
+		return err
+	}
+	env.note("✅ webhook %s criado", created.ID)
+	return env.print(created, webhookTable([]domain.WebhookSubscription{*created}))
+}
+
+func webhooksDelete(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("webhooks delete", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "não pede confirmação")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("uso: ctl webhooks delete [-yes] <id>")
+	}
+	id := fs.Arg(0)
+
+	svc, err := env.webhooks()
+	if err != nil {
+		return err
+	}
+	if !confirm(fmt.Sprintf("Remover o webhook %s do projeto %s?", id, env.tenant.StarkBank.ProjectID), *yes) {
+		env.note("nada alterado")
+		return nil
+	}
+	if err := svc.Delete(env.ctx, id); err != nil {
+		return err
+	}
+	env.note("✅ webhook %s removido", id)
+	return nil
+}
+
+func webhooksSync(env *ctlEnv, args []string) error {
+	fs := flag.NewFlagSet("webhooks sync", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "apenas mostra as diferenças")
+	yes := fs.Bool("yes", false, "aplica sem pedir confirmação")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	svc, err := env.webhooks()
+	if err != nil {
+		return err
+	}
+	plan, err := svc.Plan(env.ctx, env.tenant.Webhook)
+	if err != nil {
+		return err
+	}
+
+	symbols := map[string]string{
+		service.WebhookActionKeep:   "=",
+		service.WebhookActionCreate: "+",
+		service.WebhookActionDelete: "-",
+	}
+	diff := table{header: []string{"", "ID", "URL", "EVENTOS", "MOTIVO"}}
+	for _, c := range plan.Changes {
+		id := c.Webhook.ID
+		if id == "" {
+			id = "(novo)"
+		}
+		diff.rows = append(diff.rows, []string{symbols[c.Action], id, c.Webhook.URL, strings.Join(c.Webhook.Subscriptions, ","), c.Reason})
+	}
+	if err := env.print(plan, diff); err != nil {
+		return err
+	}
+
+	pending := plan.Pending()
+	switch {
+	case pending == 0:
+		env.note("✅ webhooks já estão sincronizados")
+		return nil
+	case *dryRun:
+		env.note("%d alterações pendentes (dry-run: nada alterado)", pending)
+		return nil
+	case !confirm(fmt.Sprintf("Aplicar %d alterações no projeto %s?", pending, env.tenant.StarkBank.ProjectID), *yes):
+		env.note("nada alterado")
+		return nil
+	}
+
+	if err := svc.Apply(env.ctx, plan); err != nil {
+		return err
+	}
+	env.note("✅ %d alterações aplicadas", pending)
+	return nil
+}
+
+// ignoreHelp trata -h em um subcomando como saída normal
+func ignoreHelp(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	return err
+}
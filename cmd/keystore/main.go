@@ -0,0 +1,77 @@
+// Comando keystore cifra a chave privada da StarkBank com uma senha
+//
+// Uso:
+//
+//	KEYSTORE_PASSPHRASE=... go run ./cmd/keystore -in privateKeyChallenge.pem -out keystore.json
+//	KEYSTORE_PASSPHRASE=... go run ./cmd/keystore -check -out keystore.json
+//
+// A senha vem de KEYSTORE_PASSPHRASE ou do arquivo indicado em
+// -passphrase-file. A chave é validada antes de ser cifrada; o arquivo
+// original pode ser removido depois de conferir o keystore com -check.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/secrets"
+)
+
+func main() {
+	in := flag.String("in", "privateKeyChallenge.pem", "chave privada em PEM")
+	out := flag.String("out", "keystore.json", "keystore a gerar (ou conferir com -check)")
+	passphraseFile := flag.String("passphrase-file", os.Getenv("KEYSTORE_PASSPHRASE_FILE"), "arquivo com a senha (alternativa a KEYSTORE_PASSPHRASE)")
+	check := flag.Bool("check", false, "apenas decifra e valida o keystore existente")
+	flag.Parse()
+
+	passphrase := func() (string, error) {
+		if value := os.Getenv("KEYSTORE_PASSPHRASE"); value != "" {
+			return value, nil
+		}
+		if *passphraseFile == "" {
+			return "", fmt.Errorf("defina KEYSTORE_PASSPHRASE ou -passphrase-file")
+		}
+		return secrets.ReadFile(*passphraseFile)
+	}
+
+	if *check {
+		key, err := secrets.NewKeystoreProvider(*out, passphrase).PrivateKey(context.Background())
+		if err != nil {
+			fail(err)
+		}
+		fingerprint, err := secrets.ValidatePrivateKey(key)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Printf("✅ keystore válido: %s (chave pública %s)\n", *out, fingerprint)
+		return
+	}
+
+	key, err := secrets.NewFileProvider(*in).PrivateKey(context.Background())
+	if err != nil {
+		fail(err)
+	}
+	fingerprint, err := secrets.ValidatePrivateKey(key)
+	if err != nil {
+		fail(err)
+	}
+	secret, err := passphrase()
+	if err != nil {
+		fail(err)
+	}
+	ks, err := secrets.Seal(key, secret)
+	if err != nil {
+		fail(err)
+	}
+	if err := secrets.WriteKeystore(*out, ks); err != nil {
+		fail(err)
+	}
+	fmt.Printf("✅ keystore gerado: %s (chave pública %s)\n", *out, fingerprint)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	os.Exit(1)
+}
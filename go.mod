@@ -3,13 +3,21 @@ module github.com/jpdsbarbosa/challenge-joao-barbosa
 go 1.22.2
 
 require (
+	github.com/starkbank/ecdsa-go/v2 v2.0.0
 	github.com/starkbank/sdk-go v1.5.0
 	github.com/starkinfra/core-go v1.0.0
+	go.opentelemetry.io/otel v1.31.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.31.0
+	go.opentelemetry.io/otel/sdk v1.31.0
+	go.opentelemetry.io/otel/trace v1.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/Nhanderu/brdoc v1.1.2 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/iancoleman/strcase v0.2.0 // indirect
-	github.com/klassmann/cpfcnpj v0.0.0-20200907140233-a595c5fd8de1 // indirect
-	github.com/starkbank/ecdsa-go/v2 v2.0.0 // indirect
+	go.opentelemetry.io/otel/metric v1.31.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
 )
@@ -0,0 +1,286 @@
+// Package calendar calcula dias úteis bancários no Brasil
+//
+// Não são dias úteis os sábados, domingos, feriados nacionais (inclusive os
+// móveis: Carnaval, Sexta-feira Santa e Corpus Christi, sem expediente
+// bancário) e os feriados de um arquivo próprio (ex: municipais).
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	// Base de fusos embutida: imagens mínimas não trazem /usr/share/zoneinfo
+	_ "time/tzdata"
+)
+
+// Origem de um feriado
+const (
+	SourceNational = "national"
+	SourceCustom   = "custom"
+)
+
+// Holiday é um feriado em uma data
+type Holiday struct {
+	Date   string `json:"date"` // AAAA-MM-DD
+	Name   string `json:"name"`
+	Source string `json:"source"` // national ou custom
+}
+
+// Calendar identifica os dias úteis bancários e o dia útil a que pertence
+// cada movimentação, conforme o horário de corte
+type Calendar struct {
+	cutoff   time.Duration     // horário de corte desde a meia-noite; 24h sem corte
+	location *time.Location    // fuso das datas de agendamento
+	dates    map[string]string // feriados próprios em uma data (AAAA-MM-DD)
+	annual   map[string]string // feriados próprios todo ano (MM-DD)
+}
+
+// New cria o calendário com os feriados nacionais, sem horário de corte e
+// no fuso do processo
+func New() *Calendar {
+	return &Calendar{
+		cutoff:   24 * time.Hour,
+		location: time.Local,
+		dates:    map[string]string{},
+		annual:   map[string]string{},
+	}
+}
+
+// Load cria o calendário com os feriados nacionais, os do arquivo
+// holidaysFile (vazio dispensa), o horário de corte cutoff (HH:MM; vazio
+// fecha o dia à meia-noite) e o fuso timezone (vazio usa o do processo)
+//
+// O arquivo tem um feriado por linha, com a data e o nome: AAAA-MM-DD para
+// uma data ou MM-DD para todo ano. Linhas vazias e iniciadas por # são
+// ignoradas:
+//
+//	# feriados municipais de São Paulo
+//	01-25 Aniversário de São Paulo
+//	2024-12-24 Véspera de Natal (ponto facultativo)
+func Load(holidaysFile, cutoff, timezone string) (*Calendar, error) {
+	c := New()
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("fuso inválido: %q: %w", timezone, err)
+		}
+		c.location = location
+	}
+	if cutoff != "" {
+		t, err := time.Parse("15:04", cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("horário de corte inválido: %q (use HH:MM)", cutoff)
+		}
+		c.cutoff = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if holidaysFile == "" {
+		return c, nil
+	}
+
+	f, err := os.Open(holidaysFile)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo de feriados: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		date, name, _ := strings.Cut(line, " ")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = "Feriado"
+		}
+		if _, err := time.Parse("2006-01-02", date); err == nil {
+			c.dates[date] = name
+		} else if _, err := time.Parse("01-02", date); err == nil {
+			c.annual[date] = name
+		} else {
+			return nil, fmt.Errorf("%s:%d: data inválida %q (use AAAA-MM-DD ou MM-DD)", holidaysFile, n, date)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de feriados: %w", err)
+	}
+	return c, nil
+}
+
+// Cutoff retorna o horário de corte (HH:MM), ou vazio sem corte
+func (c *Calendar) Cutoff() string {
+	if c.cutoff >= 24*time.Hour {
+		return ""
+	}
+	return time.Time{}.Add(c.cutoff).Format("15:04")
+}
+
+// Location retorna o fuso do calendário
+func (c *Calendar) Location() *time.Location {
+	return c.location
+}
+
+// Now retorna o horário atual no fuso do calendário
+func (c *Calendar) Now() time.Time {
+	return time.Now().In(c.location)
+}
+
+// Holiday retorna o nome do feriado na data de t
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	if name, ok := c.dates[t.Format("2006-01-02")]; ok {
+		return name, true
+	}
+	if name, ok := c.annual[t.Format("01-02")]; ok {
+		return name, true
+	}
+	name, ok := national(t.Year())[t.Format("01-02")]
+	return name, ok
+}
+
+// Holidays lista os feriados do ano em ordem de data
+func (c *Calendar) Holidays(year int) []Holiday {
+	byDate := map[string]Holiday{}
+	for day, name := range national(year) {
+		date := fmt.Sprintf("%d-%s", year, day)
+		byDate[date] = Holiday{Date: date, Name: name, Source: SourceNational}
+	}
+	for day, name := range c.annual {
+		date := fmt.Sprintf("%d-%s", year, day)
+		byDate[date] = Holiday{Date: date, Name: name, Source: SourceCustom}
+	}
+	prefix := fmt.Sprintf("%d-", year)
+	for date, name := range c.dates {
+		if strings.HasPrefix(date, prefix) {
+			byDate[date] = Holiday{Date: date, Name: name, Source: SourceCustom}
+		}
+	}
+
+	holidays := make([]Holiday, 0, len(byDate))
+	for _, h := range byDate {
+		holidays = append(holidays, h)
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+	return holidays
+}
+
+// IsBusinessDay indica se a data de t é um dia útil
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	switch t.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	_, holiday := c.Holiday(t)
+	return !holiday
+}
+
+// NextBusinessDay retorna t se for dia útil, ou o mesmo horário no próximo
+// dia útil
+func (c *Calendar) NextBusinessDay(t time.Time) time.Time {
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// PreviousBusinessDay retorna t se for dia útil, ou o mesmo horário no dia
+// útil anterior
+func (c *Calendar) PreviousBusinessDay(t time.Time) time.Time {
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// AddBusinessDays avança n dias úteis a partir de t (D+n), mantendo o
+// horário; n negativo recua. Com n zero, retorna o próximo dia útil.
+func (c *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for ; n > 0; n-- {
+		t = t.AddDate(0, 0, step)
+		for !c.IsBusinessDay(t) {
+			t = t.AddDate(0, 0, step)
+		}
+	}
+	return c.NextBusinessDay(t)
+}
+
+// BusinessDate retorna o dia útil (meia-noite) a que pertence uma
+// movimentação em t: depois do horário de corte, ou fora de dia útil, ela
+// conta para o próximo dia útil
+func (c *Calendar) BusinessDate(t time.Time) time.Time {
+	day := startOfDay(t)
+	if c.IsBusinessDay(day) && t.Before(day.Add(c.cutoff)) {
+		return day
+	}
+	return c.AddBusinessDays(day, 1)
+}
+
+// Window retorna o período [from, to) das movimentações do dia útil de
+// date: do corte do dia útil anterior ao corte de date
+func (c *Calendar) Window(date time.Time) (from, to time.Time) {
+	day := startOfDay(c.NextBusinessDay(date))
+	previous := startOfDay(c.AddBusinessDays(day, -1))
+	return previous.Add(c.cutoff), day.Add(c.cutoff)
+}
+
+// national retorna os feriados nacionais do ano com expediente bancário
+// suspenso, por MM-DD
+func national(year int) map[string]string {
+	holidays := map[string]string{
+		"01-01": "Confraternização Universal",
+		"04-21": "Tiradentes",
+		"05-01": "Dia do Trabalho",
+		"09-07": "Independência do Brasil",
+		"10-12": "Nossa Senhora Aparecida",
+		"11-02": "Finados",
+		"11-15": "Proclamação da República",
+		"12-25": "Natal",
+	}
+	if year >= 2024 {
+		holidays["11-20"] = "Dia Nacional de Zumbi e da Consciência Negra"
+	}
+
+	easter := easter(year)
+	for _, h := range []struct {
+		offset int
+		name   string
+	}{
+		{-48, "Carnaval"},
+		{-47, "Carnaval"},
+		{-2, "Sexta-feira Santa"},
+		{60, "Corpus Christi"},
+	} {
+		holidays[easter.AddDate(0, 0, h.offset).Format("01-02")] = h.name
+	}
+	return holidays
+}
+
+// easter calcula o domingo de Páscoa do ano (algoritmo de Meeus/Jones/Butcher)
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// startOfDay retorna a meia-noite do dia de t, no fuso de t
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestMoveableHolidays(t *testing.T) {
+	c := New()
+	for _, tc := range []struct {
+		day  time.Time
+		name string
+	}{
+		{date(2024, time.February, 12), "Carnaval"},
+		{date(2024, time.February, 13), "Carnaval"},
+		{date(2024, time.March, 29), "Sexta-feira Santa"},
+		{date(2024, time.May, 30), "Corpus Christi"},
+		{date(2025, time.March, 4), "Carnaval"},
+		{date(2025, time.June, 19), "Corpus Christi"},
+		{date(2024, time.November, 20), "Dia Nacional de Zumbi e da Consciência Negra"},
+	} {
+		if name, ok := c.Holiday(tc.day); !ok || name != tc.name {
+			t.Errorf("%s: feriado = %q (%t), esperado %q", tc.day.Format("2006-01-02"), name, ok, tc.name)
+		}
+	}
+	if _, ok := c.Holiday(date(2023, time.November, 20)); ok {
+		t.Errorf("20/11 só é feriado nacional a partir de 2024")
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	c := New()
+	friday := date(2024, time.February, 9) // véspera de Carnaval
+
+	if got := c.AddBusinessDays(friday, 1); !got.Equal(date(2024, time.February, 14)) {
+		t.Fatalf("D+1 = %s, esperado quarta-feira de cinzas", got.Format("2006-01-02"))
+	}
+	if got := c.AddBusinessDays(date(2024, time.February, 14), -1); !got.Equal(friday) {
+		t.Fatalf("D-1 = %s, esperado %s", got.Format("2006-01-02"), friday.Format("2006-01-02"))
+	}
+	if got := c.AddBusinessDays(date(2024, time.February, 10), 0); !got.Equal(date(2024, time.February, 14)) {
+		t.Fatalf("D+0 no sábado = %s, esperado o próximo dia útil", got.Format("2006-01-02"))
+	}
+}
+
+func TestLoadCustomHolidaysAndCutoff(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "holidays.txt")
+	content := "# municipais\n01-25 Aniversário de São Paulo\n2024-12-24 Véspera de Natal\n\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(file, "17:00", "")
+	if err != nil {
+		t.Fatalf("erro ao carregar: %v", err)
+	}
+
+	if !c.IsBusinessDay(date(2025, time.January, 27)) {
+		t.Fatalf("segunda-feira comum deveria ser dia útil")
+	}
+	for _, day := range []time.Time{date(2024, time.January, 25), date(2030, time.January, 25), date(2024, time.December, 24)} {
+		if c.IsBusinessDay(day) {
+			t.Errorf("%s deveria ser feriado próprio", day.Format("2006-01-02"))
+		}
+	}
+	if _, ok := c.Holiday(date(2025, time.December, 24)); ok {
+		t.Errorf("feriado com ano não se repete")
+	}
+
+	// Depois do corte, a movimentação conta para o próximo dia útil
+	friday := date(2024, time.February, 9)
+	if got := c.BusinessDate(friday.Add(16 * time.Hour)); !got.Equal(friday) {
+		t.Fatalf("antes do corte = %s, esperado %s", got, friday)
+	}
+	if got := c.BusinessDate(friday.Add(18 * time.Hour)); !got.Equal(date(2024, time.February, 14)) {
+		t.Fatalf("depois do corte = %s, esperado quarta-feira de cinzas", got)
+	}
+	from, to := c.Window(date(2024, time.February, 14))
+	if !from.Equal(friday.Add(17*time.Hour)) || !to.Equal(date(2024, time.February, 14).Add(17*time.Hour)) {
+		t.Fatalf("janela = [%s, %s)", from, to)
+	}
+
+	if err := os.WriteFile(file, []byte("25/01 inválido\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(file, "", ""); err == nil {
+		t.Fatalf("esperado erro para data inválida")
+	}
+}
+
+func TestLoadTimezone(t *testing.T) {
+	c, err := Load("", "", "America/Sao_Paulo")
+	if err != nil {
+		t.Fatalf("erro ao carregar: %v", err)
+	}
+	if c.Location().String() != "America/Sao_Paulo" || c.Now().Location() != c.Location() {
+		t.Fatalf("fuso = %s, esperado America/Sao_Paulo", c.Location())
+	}
+	if _, err := Load("", "", "America/Atlantida"); err == nil {
+		t.Fatalf("esperado erro para fuso inválido")
+	}
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"os"
+	"net/netip"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 // Config armazena todas as configurações da aplicação
@@ -10,19 +15,67 @@ type Config struct {
 	Server      ServerConfig
 	StarkBank   StarkBankConfig
 	Destination DestinationAccount
+	Scheduler   SchedulerConfig
+	Webhook     WebhookConfig
+	Polling     PollingConfig
+	Forwarding  ForwardingConfig
+	Approval    ApprovalConfig
+	Storage     StorageConfig
+	Reversal    ReversalConfig
+	Balance     BalanceConfig
+	Transfer    TransferConfig
+	Calendar    CalendarConfig
+	Log         LogConfig
+	Tracing     TracingConfig
+	Health      HealthConfig
+	Auth        AuthConfig
+	RateLimit   RateLimitConfig
+
+	// Tenants são os projetos da StarkBank atendidos pelo processo. Sem a
+	// seção tenants no arquivo, há um único tenant "default" formado pelas
+	// seções starkbank, destination e scheduler.
+	Tenants []TenantConfig
 }
 
 // ServerConfig configurações do servidor HTTP
 type ServerConfig struct {
 	Port string
 	Host string
+
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration // deve ser maior que os tempos limite por rota
+	IdleTimeout  time.Duration
+
+	// Limites aplicados às rotas; o webhook tem limite de corpo próprio e
+	// nenhum tempo limite
+	RequestTimeout      time.Duration
+	MaxBodyBytes        int64
+	WebhookMaxBodyBytes int64
+
+	// HSTS envia Strict-Transport-Security (use apenas atrás de HTTPS)
+	HSTS bool
 }
 
 // StarkBankConfig configurações da StarkBank
 type StarkBankConfig struct {
 	ProjectID   string
-	PrivateKey  string
 	Environment string
+
+	// KeySource escolhe de onde a chave privada é lida (ver internal/secrets):
+	// auto (PrivateKey se informada, senão PrivateKeyFile), env, file, secret
+	// (SecretsDir/SecretName, montado pelo Docker ou Kubernetes) ou keystore
+	KeySource              string
+	PrivateKey             string
+	PrivateKeyFile         string
+	SecretsDir             string
+	SecretName             string
+	KeystoreFile           string
+	KeystorePassphrase     string
+	KeystorePassphraseFile string // alternativa a KeystorePassphrase, ex: outro secret montado
+
+	// VerifyCredentials consulta a API na inicialização para confirmar que a
+	// chave está registrada no projeto
+	VerifyCredentials bool
 }
 
 // DestinationAccount conta de destino para transferências
@@ -35,62 +88,307 @@ type DestinationAccount struct {
 	AccountType   string
 }
 
-// Load carrega as configurações da aplicação
+// SchedulerConfig política do gerador de invoices
+type SchedulerConfig struct {
+	Enabled  bool
+	Interval time.Duration // intervalo entre lotes
+	Duration time.Duration // por quanto tempo gerar lotes após o início
+	MinBatch int
+	MaxBatch int
+}
+
+// WebhookConfig assinatura de webhook desejada na StarkBank, reconciliada
+// por ctl webhooks sync
+type WebhookConfig struct {
+	URL           string   // URL pública (ex: https://exemplo.com/webhook)
+	Subscriptions []string // eventos assinados (invoice, transfer...)
+}
+
+// Origens de eventos do modo de consulta periódica (polling)
+const (
+	PollingOff         = "off"          // eventos chegam apenas pelo webhook
+	PollingEvents      = "events"       // Event.Query: eventos não entregues, marcados como entregues
+	PollingInvoiceLogs = "invoice_logs" // Invoice.Log: dispensa webhook cadastrado
+)
+
+// PollingConfig consulta periódica de eventos, alternativa ao webhook para
+// instalações sem URL pública
+type PollingConfig struct {
+	Source   string        // off, events ou invoice_logs
+	Interval time.Duration // intervalo entre consultas
+	Lookback time.Duration // janela da primeira consulta, sem cursor salvo
+}
+
+// Modos de repasse dos invoices creditados
+const (
+	ForwardingImmediate = "immediate" // uma transferência por invoice, assim que creditado
+	ForwardingSweep     = "sweep"     // créditos acumulados e repassados juntos a cada varredura
+)
+
+// Destinos dos repasses
+const (
+	ForwardToAccount = "account" // transferência à conta da seção destination
+	ForwardToPixKey  = "pix_key" // transferência Pix à conta da chave, consultada no DICT
+	ForwardToBRCode  = "brcode"  // pagamento de um BR Code (QR Code Pix)
+)
+
+// ForwardingConfig política de repasse dos invoices creditados
+//
+// Valores em centavos; zero desativa o mínimo ou o limite correspondente.
+type ForwardingConfig struct {
+	Mode                string        // immediate ou sweep
+	MinAmount           int64         // valor líquido mínimo de uma transferência
+	SweepInterval       time.Duration // intervalo das varreduras de créditos pendentes
+	DailyCap            int64         // total repassado por dia pelo tenant
+	DestinationDailyCap int64         // total repassado por dia à conta de destino, somando os tenants
+
+	Destination string // account, pix_key ou brcode
+	PixKey      string // CPF, CNPJ, e-mail, telefone (+55...) ou chave aleatória
+	PixKeyTaxID string // CPF/CNPJ do titular de chaves e-mail, telefone ou aleatória
+	BRCode      string // BR Code pago com os repasses; dinâmico, de uso único ou de valor fixo recebe um só
+}
+
+// ApprovalConfig aprovação de operadores antes de repasses grandes ou a uma
+// conta de destino diferente da padrão
+type ApprovalConfig struct {
+	Threshold             int64         // valor líquido acima do qual o repasse exige aprovação, em centavos (0 desativa)
+	Required              int           // aprovações de operadores distintos para liberar o repasse
+	TTL                   time.Duration // prazo para aprovar antes que a solicitação expire
+	NonDefaultDestination bool          // exige aprovação quando o tenant repassa a outra conta que não a global
+}
+
+// WebhookSubscriptions são os eventos aceitos pela StarkBank em um webhook
+var WebhookSubscriptions = []string{
+	"invoice", "transfer", "deposit", "boleto", "boleto-payment", "boleto-holmes",
+	"brcode-payment", "utility-payment", "tax-payment", "darf-payment",
+}
+
+// StorageConfig configurações de armazenamento local
+type StorageConfig struct {
+	DataDir string
+}
+
+// ReversalConfig configurações de tratamento de estornos
+type ReversalConfig struct {
+	// Action define a compensação aplicada quando um invoice repassado é estornado:
+	// hold_payer, refund_request ou manual_case
+	Action string
+}
+
+// BalanceConfig configurações de snapshots e alertas de saldo
+type BalanceConfig struct {
+	SnapshotInterval    time.Duration
+	LowThreshold        int64 // em centavos; 0 desativa o alerta
+	DivergenceTolerance int64 // em centavos
+}
+
+// TransferConfig configurações da verificação de saldo antes dos repasses
+type TransferConfig struct {
+	Fee                 int64 // taxa estimada por transferência, em centavos
+	BalanceCacheTTL     time.Duration
+	HoldReleaseInterval time.Duration
+	ScheduleInterval    time.Duration // verificação das transferências agendadas e recorrentes
+}
+
+// CalendarConfig configurações do calendário de dias úteis
+type CalendarConfig struct {
+	HolidaysFile   string // feriados próprios além dos nacionais (ex: municipais)
+	Cutoff         string // HH:MM; movimentações depois contam para o próximo dia útil
+	InvoiceDueDays int    // vencimento dos invoices em dias úteis (0: padrão da StarkBank)
+	Timezone       string // fuso das datas de agendamento (ex: America/Sao_Paulo)
+}
+
+// LogConfig configurações de logging
+type LogConfig struct {
+	Level string // debug, info, warn ou error
+}
+
+// TracingConfig configurações de tracing (OpenTelemetry)
+type TracingConfig struct {
+	Exporter    string // none, stdout ou file
+	File        string // caminho do arquivo quando Exporter = file
+	ServiceName string
+}
+
+// HealthConfig configurações do endpoint de readiness
+type HealthConfig struct {
+	StarkBankCacheTTL time.Duration // cache da verificação de credenciais
+	MaxHoldBacklog    int64         // acima disso a aplicação fica degraded
+}
+
+// AuthConfig configurações de autenticação da API administrativa
+type AuthConfig struct {
+	// Keys são chaves fixas definidas em API_KEYS; chaves criadas pela API
+	// ficam no armazenamento local
+	Keys []APIKeyConfig
+}
+
+// RateLimitConfig configurações de proteção das rotas públicas
+type RateLimitConfig struct {
+	// Token bucket por IP e rota: RequestsPerSecond recarga, Burst capacidade.
+	// RequestsPerSecond = 0 desativa o limite.
+	RequestsPerSecond float64
+	Burst             int
+
+	// TrustedProxies são as faixas de proxies (ex: agente do ngrok) cujo
+	// X-Forwarded-For é usado para obter o IP do cliente
+	TrustedProxies []netip.Prefix
+
+	// WebhookAllowlist são as faixas de origem aceitas em /webhook; vazia
+	// aceita qualquer origem e só é permitida no sandbox
+	WebhookAllowlist []netip.Prefix
+}
+
+// APIKeyConfig chave de API definida por configuração (apenas o hash)
+type APIKeyConfig struct {
+	Name string
+	Role string // read, operator ou admin
+	Hash string // SHA-256 em hexadecimal da chave
+}
+
+// Load carrega as configurações da aplicação a partir do ambiente
+//
+// Equivale a NewLoader(nil).Load(): valores padrão, arquivo indicado em
+// CONFIG_FILE e variáveis de ambiente.
 func Load() (*Config, error) {
-	privateKey, err := loadPrivateKey()
+	loader, err := NewLoader(nil)
 	if err != nil {
 		return nil, err
 	}
+	return loader.Load()
+}
 
-	// Validar variáveis obrigatórias
-	projectID := os.Getenv("STARK_PROJECT_ID")
-	if projectID == "" {
-		return nil, fmt.Errorf("STARK_PROJECT_ID é obrigatório. Configure a variável de ambiente")
+// finalize completa os valores derivados de outros campos
+func (c *Config) finalize() error {
+	c.Log.Level = strings.ToLower(c.Log.Level)
+	if c.Tracing.File == "" {
+		c.Tracing.File = filepath.Join(c.Storage.DataDir, "traces.jsonl")
 	}
+	return nil
+}
 
-	port := getEnv("PORT", "8080")
-	environment := getEnv("STARK_ENVIRONMENT", "sandbox")
-
-	return &Config{
-		Server: ServerConfig{
-			Port: port,
-			Host: "0.0.0.0",
-		},
-		StarkBank: StarkBankConfig{
-			ProjectID:   projectID,
-			PrivateKey:  privateKey,
-			Environment: environment,
-		},
-		Destination: DestinationAccount{
-			BankCode:      "20018183",
-			BranchCode:    "0001",
-			AccountNumber: "6341320293482496",
-			Name:          "Stark Bank S.A.",
-			TaxID:         "20.018.183/0001-80",
-			AccountType:   "payment",
-		},
-	}, nil
-}
-
-// loadPrivateKey carrega a chave privada do arquivo ou variável de ambiente
-func loadPrivateKey() (string, error) {
-	privateKey := os.Getenv("PRIVATE_KEY")
-	if privateKey != "" {
-		return privateKey, nil
+// Validate confere a configuração completa e retorna todos os problemas encontrados
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
 	}
 
-	content, err := os.ReadFile("privateKeyChallenge.pem")
-	if err != nil {
-		return "", fmt.Errorf("erro ao ler chave privada: %w", err)
+	check(len(c.Tenants) > 0, "nenhum tenant configurado")
+	ids := make(map[string]bool)
+	production := false
+	for _, t := range c.Tenants {
+		check(!ids[t.ID], "tenant duplicado: %q", t.ID)
+		ids[t.ID] = true
+		errs = append(errs, t.validate(c.hasTenantSection())...)
+		production = production || t.StarkBank.Environment == "production"
 	}
 
-	return string(content), nil
+	// Fora do sandbox, /webhook não fica aberto a qualquer origem por omissão;
+	// 0.0.0.0/0,::/0 aceita todas explicitamente
+	check(!production || len(c.RateLimit.WebhookAllowlist) > 0,
+		"rate_limit.webhook_allowed_ips (WEBHOOK_ALLOWED_IPS) é obrigatório com starkbank.environment=production: informe as faixas de origem da Stark Bank")
+
+	check(oneOf(c.Reversal.Action, "hold_payer", "refund_request", "manual_case"),
+		"reversal.action inválido: %q (use hold_payer, refund_request ou manual_case)", c.Reversal.Action)
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"),
+		"log.level inválido: %q (use debug, info, warn ou error)", c.Log.Level)
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "file"),
+		"tracing.exporter inválido: %q (use none, stdout ou file)", c.Tracing.Exporter)
+
+	// O servidor encerraria a conexão antes de a rota responder com 503
+	check(c.Server.WriteTimeout > c.Server.RequestTimeout,
+		"server.write_timeout (%s) deve ser maior que server.request_timeout (%s)",
+		c.Server.WriteTimeout, c.Server.RequestTimeout)
+	_, cutoffErr := time.Parse("15:04", c.Calendar.Cutoff)
+	check(c.Calendar.Cutoff == "" || cutoffErr == nil, "calendar.cutoff inválido: %q (use HH:MM)", c.Calendar.Cutoff)
+	check(c.Calendar.InvoiceDueDays >= 0, "calendar.invoice_due_days não pode ser negativo")
+	_, tzErr := time.LoadLocation(c.Calendar.Timezone)
+	check(c.Calendar.Timezone != "" && tzErr == nil, "calendar.timezone inválido: %q (use um fuso IANA, ex: America/Sao_Paulo)", c.Calendar.Timezone)
+
+	check(c.Server.MaxBodyBytes > 0, "server.max_body_bytes deve ser maior que zero")
+	check(c.Server.WebhookMaxBodyBytes > 0, "server.webhook_max_body_bytes deve ser maior que zero")
+	check(c.RateLimit.RequestsPerSecond == 0 || c.RateLimit.Burst > 0,
+		"rate_limit.burst deve ser maior que zero com rate_limit.requests_per_second ativo")
+
+	return errors.Join(errs...)
+}
+
+func oneOf(value string, options ...string) bool {
+	for _, o := range options {
+		if value == o {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList separa uma lista por vírgulas, ignorando itens vazios
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
-// getEnv retorna o valor de uma variável de ambiente ou um valor padrão
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// parsePrefixes lê faixas CIDR ou IPs isolados separados por vírgula
+func parsePrefixes(key, value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%s: IP inválido %q", key, entry)
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: faixa inválida %q (use CIDR, ex: 203.0.113.0/24)", key, entry)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// parseAPIKeys lê chaves no formato nome:papel:sha256hex separadas por vírgula
+func parseAPIKeys(key, value string) ([]APIKeyConfig, error) {
+	var keys []APIKeyConfig
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%s inválido: %q (use nome:papel:sha256)", key, entry)
+		}
+		name, role, hash := parts[0], parts[1], strings.ToLower(parts[2])
+
+		switch role {
+		case "read", "operator", "admin":
+		default:
+			return nil, fmt.Errorf("%s: papel inválido %q para %s (use read, operator ou admin)", key, role, name)
+		}
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 64 {
+			return nil, fmt.Errorf("%s: hash de %s deve ser SHA-256 em hexadecimal", key, name)
+		}
+
+		keys = append(keys, APIKeyConfig{Name: name, Role: role, Hash: hash})
 	}
-	return defaultValue
+	return keys, nil
 }
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Loader monta a configuração em camadas: valores padrão, arquivo YAML,
+// variáveis de ambiente e flags de linha de comando (a última vence)
+//
+// As flags são lidas uma única vez; Load pode ser chamado novamente para
+// reler o arquivo e o ambiente (ex: ao receber SIGHUP).
+type Loader struct {
+	File        string // arquivo YAML (flag -config ou CONFIG_FILE); vazio dispensa o arquivo
+	PrintConfig bool   // flag -print-config: imprimir a configuração efetiva e sair
+
+	flags map[string]string
+}
+
+// NewLoader interpreta os argumentos de linha de comando
+//
+// Cada campo do schema tem uma flag com o nome da sua chave
+// (ex: -log.level=debug). Retorna flag.ErrHelp para -h.
+func NewLoader(args []string) (*Loader, error) {
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	l := BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("argumento inesperado: %s", fs.Arg(0))
+	}
+	return l, nil
+}
+
+// BindFlags registra em fs as flags do Loader (-config, -print-config e uma
+// por campo do schema), para comandos que também têm argumentos próprios
+// (cmd/ctl). O Loader só deve ser usado depois de fs.Parse.
+func BindFlags(fs *flag.FlagSet) *Loader {
+	l := &Loader{
+		File:  os.Getenv("CONFIG_FILE"),
+		flags: make(map[string]string),
+	}
+
+	fs.StringVar(&l.File, "config", l.File, "arquivo de configuração YAML (env CONFIG_FILE)")
+	fs.BoolVar(&l.PrintConfig, "print-config", false, "imprime a configuração efetiva (sem segredos) e sai")
+	for _, f := range schema {
+		f := f
+		usage := fmt.Sprintf("%s (env %s)", f.Help, f.Env)
+		if f.Default != "" {
+			usage += fmt.Sprintf(" (padrão %q)", f.Default)
+		}
+		fs.Func(f.Key, usage, func(value string) error {
+			// Validar já na leitura para que -h e erros de digitação apareçam cedo
+			if err := f.set(&Config{}, value, "-"+f.Key); err != nil {
+				return err
+			}
+			l.flags[f.Key] = value
+			return nil
+		})
+	}
+	return l
+}
+
+// Load monta e valida a configuração
+func (l *Loader) Load() (*Config, error) {
+	cfg := &Config{}
+	var tenants []map[string]string
+	for _, f := range schema {
+		if err := f.set(cfg, f.Default, f.Key); err != nil {
+			return nil, fmt.Errorf("valor padrão inválido: %w", err)
+		}
+	}
+
+	if l.File != "" {
+		values, entries, err := readConfigFile(l.File)
+		if err != nil {
+			return nil, err
+		}
+		tenants = entries
+		for _, f := range schema {
+			if value, ok := values[f.Key]; ok {
+				if err := f.set(cfg, value, l.File+": "+f.Key); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, f := range schema {
+		if value, ok := os.LookupEnv(f.Env); ok && value != "" {
+			if err := f.set(cfg, value, f.Env); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, f := range schema {
+		if value, ok := l.flags[f.Key]; ok {
+			if err := f.set(cfg, value, "-"+f.Key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Tenants vêm só do arquivo e herdam o resultado das demais camadas
+	if err := cfg.buildTenants(tenants, l.File); err != nil {
+		return nil, err
+	}
+	if err := cfg.finalize(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuração inválida:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// readConfigFile lê o arquivo YAML como chaves planas (server.port: "8080");
+// cada item da seção tenants é lido à parte, também como chaves planas
+func readConfigFile(path string) (map[string]string, []map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler arquivo de configuração: %w", err)
+	}
+
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(content, &tree); err != nil {
+		return nil, nil, fmt.Errorf("arquivo de configuração inválido (%s): %w", path, err)
+	}
+
+	var tenants []map[string]string
+	if raw, ok := tree["tenants"]; ok {
+		delete(tree, "tenants")
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: tenants deve ser uma lista", path)
+		}
+		for i, item := range items {
+			node, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf("%s: tenants[%d] deve ser um mapa", path, i)
+			}
+			entry := make(map[string]string)
+			flatten("", node, entry)
+			for key := range entry {
+				if err := checkTenantKey(key); err != nil {
+					return nil, nil, fmt.Errorf("%s: tenants[%d]: %w", path, i, err)
+				}
+			}
+			tenants = append(tenants, entry)
+		}
+	}
+
+	values := make(map[string]string)
+	flatten("", tree, values)
+
+	for key := range values {
+		if _, ok := lookupField(key); !ok {
+			return nil, nil, fmt.Errorf("%s: chave desconhecida %q", path, key)
+		}
+	}
+	return values, tenants, nil
+}
+
+// flatten converte seções aninhadas em chaves com ponto; listas viram valores
+// separados por vírgula, como nas variáveis de ambiente
+func flatten(prefix string, node map[string]interface{}, out map[string]string) {
+	for key, value := range node {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(key, v, out)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// WriteRedacted imprime a configuração efetiva em YAML, com segredos mascarados
+func (c *Config) WriteRedacted(w io.Writer) {
+	section := ""
+	for _, f := range schema {
+		group, name, _ := strings.Cut(f.Key, ".")
+		if group != section {
+			section = group
+			fmt.Fprintf(w, "%s:\n", group)
+		}
+
+		note := ""
+		if f.Reloadable {
+			note = " # recarregável"
+		}
+		fmt.Fprintf(w, "  %s: %q%s\n", name, f.redacted(c), note)
+	}
+	c.writeTenants(w)
+}
+
+// Reload aplica de next apenas os campos recarregáveis
+//
+// Retorna a configuração resultante (current não é alterada), as chaves
+// aplicadas e as que mudaram mas exigem reinício para ter efeito.
+func Reload(current, next *Config) (*Config, []string, []string) {
+	result := *current
+	var applied, ignored []string
+
+	for _, f := range schema {
+		if f.format(current) == f.format(next) {
+			continue
+		}
+		if !f.Reloadable {
+			ignored = append(ignored, f.Key)
+			continue
+		}
+		f.copyTo(&result, next)
+		applied = append(applied, f.Key)
+	}
+
+	tenants, reloaded, changed := reloadTenants(current.Tenants, next.Tenants)
+	result.Tenants = tenants
+	for _, key := range reloaded {
+		if !slices.Contains(applied, key) {
+			applied = append(applied, key)
+		}
+	}
+	if (current.hasTenantSection() || next.hasTenantSection()) && changed {
+		ignored = append(ignored, "tenants")
+	}
+
+	sort.Strings(applied)
+	sort.Strings(ignored)
+	return &result, applied, ignored
+}
+
+// reloadTenants aplica aos tenants de current os campos recarregáveis das
+// seções por tenant vindos do tenant de mesmo ID em next (current não é
+// alterada); changed indica outras diferenças, que exigem reinício
+func reloadTenants(current, next []TenantConfig) (tenants []TenantConfig, applied []string, changed bool) {
+	tenants = slices.Clone(current)
+	changed = len(current) != len(next)
+	for i, t := range tenants {
+		j := slices.IndexFunc(next, func(n TenantConfig) bool { return n.ID == t.ID })
+		if j < 0 {
+			changed = true
+			continue
+		}
+
+		scoped, from := t.sections(), next[j].sections()
+		for _, f := range schema {
+			if !f.Reloadable || !isTenantField(f.Key) || f.format(&scoped) == f.format(&from) {
+				continue
+			}
+			f.copyTo(&scoped, &from)
+			applied = append(applied, f.Key)
+		}
+		tenants[i] = t.withSections(scoped)
+		changed = changed || !reflect.DeepEqual(tenants[i], next[j])
+	}
+	return tenants, applied, changed
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field descreve uma configuração: chave no arquivo e na linha de comando,
+// variável de ambiente, valor padrão e se pode ser recarregada sem reinício
+type field struct {
+	Key        string // chave no arquivo YAML e nome da flag (-server.port)
+	Env        string
+	Default    string
+	Help       string
+	Secret     bool // mascarado ao imprimir a configuração
+	Reloadable bool // aplicado por SIGHUP sem reiniciar o processo
+	ptr        func(c *Config) interface{}
+}
+
+// schema é a fonte única das configurações da aplicação
+var schema = []field{
+	{Key: "server.host", Env: "HOST", Default: "0.0.0.0", Help: "endereço de escuta",
+		ptr: func(c *Config) interface{} { return &c.Server.Host }},
+	{Key: "server.port", Env: "PORT", Default: "8080", Help: "porta HTTP",
+		ptr: func(c *Config) interface{} { return &c.Server.Port }},
+	{Key: "server.read_timeout", Env: "HTTP_READ_TIMEOUT", Default: "15s", Help: "tempo máximo de leitura da requisição",
+		ptr: func(c *Config) interface{} { return &c.Server.ReadTimeout }},
+	{Key: "server.write_timeout", Env: "HTTP_WRITE_TIMEOUT", Default: "30s", Help: "tempo máximo de escrita da resposta",
+		ptr: func(c *Config) interface{} { return &c.Server.WriteTimeout }},
+	{Key: "server.idle_timeout", Env: "HTTP_IDLE_TIMEOUT", Default: "60s", Help: "tempo máximo de conexões ociosas",
+		ptr: func(c *Config) interface{} { return &c.Server.IdleTimeout }},
+	{Key: "server.request_timeout", Env: "HTTP_REQUEST_TIMEOUT", Default: "10s", Help: "tempo limite das rotas da API", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Server.RequestTimeout }},
+	{Key: "server.max_body_bytes", Env: "HTTP_MAX_BODY_BYTES", Default: "1048576", Help: "corpo máximo das rotas da API", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Server.MaxBodyBytes }},
+	{Key: "server.webhook_max_body_bytes", Env: "WEBHOOK_MAX_BODY_BYTES", Default: "262144", Help: "corpo máximo de /webhook", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Server.WebhookMaxBodyBytes }},
+	{Key: "server.hsts", Env: "HTTP_HSTS", Default: "false", Help: "envia Strict-Transport-Security",
+		ptr: func(c *Config) interface{} { return &c.Server.HSTS }},
+
+	{Key: "starkbank.project_id", Env: "STARK_PROJECT_ID", Help: "ID do projeto na StarkBank",
+		ptr: func(c *Config) interface{} { return &c.StarkBank.ProjectID }},
+	{Key: "starkbank.environment", Env: "STARK_ENVIRONMENT", Default: "sandbox", Help: "sandbox ou production",
+		ptr: func(c *Config) interface{} { return &c.StarkBank.Environment }},
+	{Key: "starkbank.key_source", Env: "PRIVATE_KEY_SOURCE", Default: "auto", Help: "origem da chave privada: auto, env, file, secret ou keystore",
+		ptr: func(c *Config) interface{} { return &c.StarkBank.KeySource }},
+	{Key: "starkbank.private_key", Env: "PRIVATE_KEY", Help: "chave privada em PEM", Secret: true,
+		ptr: func(c *Config) interface{} { return &c.StarkBank.PrivateKey }},
+	{Key: "starkbank.private_key_file", Env: "PRIVATE_KEY_FILE", Default: "privateKeyChallenge.pem", Help: "arquivo da chave privada",
+		ptr: func(c *Config) interface{} { return &c.StarkBank.PrivateKeyFile }},
+	{Key: "starkbank.secrets_dir", Env: "SECRETS_DIR", Default: "/run/secrets", Help: "diretório de secrets montado pelo Docker/Kubernetes",
+		ptr: func(c *Config) interface{} { return &c.StarkBank.SecretsDir }},
+	{Key: "starkbank.secret_name", Env: "PRIVATE_KEY_SECRET", Default: "stark_private_key", Help: "nome do secret com a chave privada",
+		ptr: func(c *Config) interface{} { return &c.StarkBank.SecretName }},
+	{Key: "starkbank.keystore_file", Env: "KEYSTORE_FILE", Help: "keystore cifrado com a chave privada (ver cmd/keystore)",
+		ptr: func(c *Config) interface{} { return &c.StarkBank.KeystoreFile }},
+	{Key: "starkbank.keystore_passphrase", Env: "KEYSTORE_PASSPHRASE", Help: "senha do keystore", Secret: true,
+		ptr: func(c *Config) interface{} { return &c.StarkBank.KeystorePassphrase }},
+	{Key: "starkbank.keystore_passphrase_file", Env: "KEYSTORE_PASSPHRASE_FILE", Help: "arquivo com a senha do keystore",
+		ptr: func(c *Config) interface{} { return &c.StarkBank.KeystorePassphraseFile }},
+	{Key: "starkbank.verify_credentials", Env: "STARK_VERIFY_CREDENTIALS", Default: "true", Help: "confere na inicialização se a chave pertence ao projeto",
+		ptr: func(c *Config) interface{} { return &c.StarkBank.VerifyCredentials }},
+
+	{Key: "destination.bank_code", Env: "DESTINATION_BANK_CODE", Default: "20018183", Help: "banco da conta de destino",
+		ptr: func(c *Config) interface{} { return &c.Destination.BankCode }},
+	{Key: "destination.branch_code", Env: "DESTINATION_BRANCH_CODE", Default: "0001", Help: "agência da conta de destino",
+		ptr: func(c *Config) interface{} { return &c.Destination.BranchCode }},
+	{Key: "destination.account_number", Env: "DESTINATION_ACCOUNT_NUMBER", Default: "6341320293482496", Help: "número da conta de destino", Secret: true,
+		ptr: func(c *Config) interface{} { return &c.Destination.AccountNumber }},
+	{Key: "destination.name", Env: "DESTINATION_NAME", Default: "Stark Bank S.A.", Help: "titular da conta de destino",
+		ptr: func(c *Config) interface{} { return &c.Destination.Name }},
+	{Key: "destination.tax_id", Env: "DESTINATION_TAX_ID", Default: "20.018.183/0001-80", Help: "CPF/CNPJ do titular", Secret: true,
+		ptr: func(c *Config) interface{} { return &c.Destination.TaxID }},
+	{Key: "destination.account_type", Env: "DESTINATION_ACCOUNT_TYPE", Default: "payment", Help: "tipo da conta de destino",
+		ptr: func(c *Config) interface{} { return &c.Destination.AccountType }},
+
+	{Key: "storage.data_dir", Env: "DATA_DIR", Default: "data", Help: "diretório dos dados locais",
+		ptr: func(c *Config) interface{} { return &c.Storage.DataDir }},
+
+	{Key: "scheduler.enabled", Env: "SCHEDULER_ENABLED", Default: "true", Help: "gera lotes de invoices periodicamente",
+		ptr: func(c *Config) interface{} { return &c.Scheduler.Enabled }},
+	{Key: "scheduler.interval", Env: "SCHEDULER_INTERVAL", Default: "3h", Help: "intervalo entre lotes de invoices",
+		ptr: func(c *Config) interface{} { return &c.Scheduler.Interval }},
+	{Key: "scheduler.duration", Env: "SCHEDULER_DURATION", Default: "24h", Help: "por quanto tempo gerar lotes após o início",
+		ptr: func(c *Config) interface{} { return &c.Scheduler.Duration }},
+	{Key: "scheduler.min_batch", Env: "SCHEDULER_MIN_BATCH", Default: "8", Help: "mínimo de invoices por lote",
+		ptr: func(c *Config) interface{} { return &c.Scheduler.MinBatch }},
+	{Key: "scheduler.max_batch", Env: "SCHEDULER_MAX_BATCH", Default: "12", Help: "máximo de invoices por lote (até 100)",
+		ptr: func(c *Config) interface{} { return &c.Scheduler.MaxBatch }},
+
+	{Key: "webhook.url", Env: "WEBHOOK_URL", Help: "URL pública do webhook cadastrada na StarkBank (ctl webhooks sync)",
+		ptr: func(c *Config) interface{} { return &c.Webhook.URL }},
+	{Key: "webhook.subscriptions", Env: "WEBHOOK_SUBSCRIPTIONS", Default: "invoice", Help: "eventos assinados pelo webhook, separados por vírgula",
+		ptr: func(c *Config) interface{} { return &c.Webhook.Subscriptions }},
+
+	{Key: "polling.source", Env: "POLLING_SOURCE", Default: "off", Help: "consulta periódica de eventos: off, events ou invoice_logs",
+		ptr: func(c *Config) interface{} { return &c.Polling.Source }},
+	{Key: "polling.interval", Env: "POLLING_INTERVAL", Default: "30s", Help: "intervalo entre consultas de eventos",
+		ptr: func(c *Config) interface{} { return &c.Polling.Interval }},
+	{Key: "polling.lookback", Env: "POLLING_LOOKBACK", Default: "24h", Help: "janela da primeira consulta de eventos (sem cursor salvo)",
+		ptr: func(c *Config) interface{} { return &c.Polling.Lookback }},
+
+	{Key: "forwarding.mode", Env: "FORWARDING_MODE", Default: "immediate", Help: "repasse dos invoices creditados: immediate ou sweep",
+		ptr: func(c *Config) interface{} { return &c.Forwarding.Mode }},
+	{Key: "forwarding.min_amount", Env: "FORWARDING_MIN_AMOUNT", Default: "0", Help: "valor mínimo de uma transferência em centavos (0 desativa)", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Forwarding.MinAmount }},
+	{Key: "forwarding.sweep_interval", Env: "FORWARDING_SWEEP_INTERVAL", Default: "1h", Help: "intervalo das varreduras de créditos pendentes",
+		ptr: func(c *Config) interface{} { return &c.Forwarding.SweepInterval }},
+	{Key: "forwarding.daily_cap", Env: "FORWARDING_DAILY_CAP", Default: "0", Help: "total repassado por dia em centavos (0 desativa)", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Forwarding.DailyCap }},
+	{Key: "forwarding.destination_daily_cap", Env: "FORWARDING_DESTINATION_DAILY_CAP", Default: "0", Help: "total por dia à mesma conta de destino, somando os tenants (0 desativa)", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Forwarding.DestinationDailyCap }},
+	{Key: "forwarding.destination", Env: "FORWARDING_DESTINATION", Default: "account", Help: "destino dos repasses: account, pix_key ou brcode",
+		ptr: func(c *Config) interface{} { return &c.Forwarding.Destination }},
+	{Key: "forwarding.pix_key", Env: "FORWARDING_PIX_KEY", Help: "chave Pix de destino com forwarding.destination=pix_key", Secret: true,
+		ptr: func(c *Config) interface{} { return &c.Forwarding.PixKey }},
+	{Key: "forwarding.pix_key_tax_id", Env: "FORWARDING_PIX_KEY_TAX_ID", Help: "CPF/CNPJ do titular da chave Pix de destino, obrigatório em chaves e-mail, telefone ou aleatória", Secret: true,
+		ptr: func(c *Config) interface{} { return &c.Forwarding.PixKeyTaxID }},
+	{Key: "forwarding.brcode", Env: "FORWARDING_BRCODE", Help: "BR Code estático ou dinâmico pago com forwarding.destination=brcode (dinâmico, de uso único ou de valor fixo recebe um único repasse)", Secret: true,
+		ptr: func(c *Config) interface{} { return &c.Forwarding.BRCode }},
+
+	{Key: "approval.threshold", Env: "APPROVAL_THRESHOLD", Default: "0", Help: "repasses acima deste valor em centavos exigem aprovação (0 desativa)", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Approval.Threshold }},
+	{Key: "approval.required", Env: "APPROVAL_REQUIRED", Default: "1", Help: "aprovações de operadores distintos para liberar um repasse", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Approval.Required }},
+	{Key: "approval.ttl", Env: "APPROVAL_TTL", Default: "24h", Help: "prazo para aprovar um repasse antes que a solicitação expire", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Approval.TTL }},
+	{Key: "approval.non_default_destination", Env: "APPROVAL_NON_DEFAULT_DESTINATION", Default: "false", Help: "exige aprovação quando o tenant repassa a uma conta diferente da global", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Approval.NonDefaultDestination }},
+
+	{Key: "reversal.action", Env: "REVERSAL_ACTION", Default: "manual_case", Help: "hold_payer, refund_request ou manual_case", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Reversal.Action }},
+
+	{Key: "balance.snapshot_interval", Env: "BALANCE_SNAPSHOT_INTERVAL", Default: "15m", Help: "intervalo dos snapshots de saldo",
+		ptr: func(c *Config) interface{} { return &c.Balance.SnapshotInterval }},
+	{Key: "balance.low_threshold", Env: "BALANCE_LOW_THRESHOLD", Default: "0", Help: "alerta de saldo baixo em centavos (0 desativa)", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Balance.LowThreshold }},
+	{Key: "balance.divergence_tolerance", Env: "BALANCE_DIVERGENCE_TOLERANCE", Default: "0", Help: "diferença aceita entre saldo e razão em centavos", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Balance.DivergenceTolerance }},
+
+	{Key: "transfer.fee", Env: "TRANSFER_FEE", Default: "0", Help: "taxa estimada por transferência em centavos",
+		ptr: func(c *Config) interface{} { return &c.Transfer.Fee }},
+	{Key: "transfer.balance_cache_ttl", Env: "BALANCE_CACHE_TTL", Default: "30s", Help: "cache do saldo consultado antes dos repasses",
+		ptr: func(c *Config) interface{} { return &c.Transfer.BalanceCacheTTL }},
+	{Key: "transfer.hold_release_interval", Env: "HOLD_RELEASE_INTERVAL", Default: "5m", Help: "intervalo de liberação da fila de retenção",
+		ptr: func(c *Config) interface{} { return &c.Transfer.HoldReleaseInterval }},
+	{Key: "transfer.schedule_interval", Env: "TRANSFER_SCHEDULE_INTERVAL", Default: "1m", Help: "intervalo de verificação das transferências agendadas e recorrentes",
+		ptr: func(c *Config) interface{} { return &c.Transfer.ScheduleInterval }},
+
+	{Key: "calendar.holidays_file", Env: "HOLIDAYS_FILE", Help: "arquivo de feriados além dos nacionais (AAAA-MM-DD ou MM-DD e nome, um por linha)",
+		ptr: func(c *Config) interface{} { return &c.Calendar.HolidaysFile }},
+	{Key: "calendar.cutoff", Env: "BUSINESS_DAY_CUTOFF", Help: "horário de corte HH:MM dos relatórios por dia útil (vazio: meia-noite)",
+		ptr: func(c *Config) interface{} { return &c.Calendar.Cutoff }},
+	{Key: "calendar.invoice_due_days", Env: "INVOICE_DUE_BUSINESS_DAYS", Default: "0", Help: "vencimento dos invoices em dias úteis (0: padrão da StarkBank)",
+		ptr: func(c *Config) interface{} { return &c.Calendar.InvoiceDueDays }},
+	{Key: "calendar.timezone", Env: "CALENDAR_TIMEZONE", Default: "America/Sao_Paulo", Help: "fuso IANA das transferências agendadas e da renovação dos limites diários",
+		ptr: func(c *Config) interface{} { return &c.Calendar.Timezone }},
+
+	{Key: "log.level", Env: "LOG_LEVEL", Default: "info", Help: "debug, info, warn ou error", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.Log.Level }},
+
+	{Key: "tracing.exporter", Env: "TRACING_EXPORTER", Default: "none", Help: "none, stdout ou file",
+		ptr: func(c *Config) interface{} { return &c.Tracing.Exporter }},
+	{Key: "tracing.file", Env: "TRACING_FILE", Help: "arquivo dos spans (padrão: <data_dir>/traces.jsonl)",
+		ptr: func(c *Config) interface{} { return &c.Tracing.File }},
+	{Key: "tracing.service_name", Env: "TRACING_SERVICE_NAME", Default: "challenge-joao-barbosa", Help: "nome do serviço nos spans",
+		ptr: func(c *Config) interface{} { return &c.Tracing.ServiceName }},
+
+	{Key: "health.starkbank_cache_ttl", Env: "HEALTH_STARKBANK_CACHE_TTL", Default: "1m", Help: "cache da verificação de credenciais",
+		ptr: func(c *Config) interface{} { return &c.Health.StarkBankCacheTTL }},
+	{Key: "health.max_hold_backlog", Env: "HEALTH_MAX_HOLD_BACKLOG", Default: "10", Help: "repasses retidos antes de ficar degraded",
+		ptr: func(c *Config) interface{} { return &c.Health.MaxHoldBacklog }},
+
+	{Key: "auth.api_keys", Env: "API_KEYS", Help: "chaves nome:papel:sha256 separadas por vírgula",
+		ptr: func(c *Config) interface{} { return &c.Auth.Keys }},
+
+	{Key: "rate_limit.requests_per_second", Env: "RATE_LIMIT_RPS", Default: "5", Help: "fichas por segundo por IP e rota (0 desativa)", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.RateLimit.RequestsPerSecond }},
+	{Key: "rate_limit.burst", Env: "RATE_LIMIT_BURST", Default: "20", Help: "capacidade do bucket", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.RateLimit.Burst }},
+	{Key: "rate_limit.trusted_proxies", Env: "TRUSTED_PROXIES", Default: "127.0.0.1/32,::1/128", Help: "proxies cujo X-Forwarded-For é usado",
+		ptr: func(c *Config) interface{} { return &c.RateLimit.TrustedProxies }},
+	{Key: "rate_limit.webhook_allowed_ips", Env: "WEBHOOK_ALLOWED_IPS", Help: "IPs/CIDRs aceitos em /webhook (obrigatório em production; vazio aceita todos no sandbox)", Reloadable: true,
+		ptr: func(c *Config) interface{} { return &c.RateLimit.WebhookAllowlist }},
+}
+
+// lookupField busca um campo pela chave
+func lookupField(key string) (field, bool) {
+	for _, f := range schema {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// set converte value para o tipo do campo; source identifica a origem nos erros
+func (f field) set(c *Config, value, source string) error {
+	value = strings.TrimSpace(value)
+
+	switch p := f.ptr(c).(type) {
+	case *string:
+		*p = value
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s inválido: %q (use true ou false)", source, value)
+		}
+		*p = b
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s inválido: %q (esperado inteiro não negativo)", source, value)
+		}
+		*p = n
+	case *int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s inválido: %q (esperado inteiro não negativo)", source, value)
+		}
+		*p = n
+	case *float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s inválido: %q (esperado número não negativo)", source, value)
+		}
+		*p = n
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("%s inválido: %q (use o formato 15m, 1h...)", source, value)
+		}
+		*p = d
+	case *[]string:
+		*p = splitList(value)
+	case *[]netip.Prefix:
+		prefixes, err := parsePrefixes(source, value)
+		if err != nil {
+			return err
+		}
+		*p = prefixes
+	case *[]APIKeyConfig:
+		keys, err := parseAPIKeys(source, value)
+		if err != nil {
+			return err
+		}
+		*p = keys
+	default:
+		return fmt.Errorf("tipo não suportado para %s", f.Key)
+	}
+	return nil
+}
+
+// format representa o valor do campo como texto (mesmo formato aceito por set)
+func (f field) format(c *Config) string {
+	switch p := f.ptr(c).(type) {
+	case *string:
+		return *p
+	case *[]string:
+		return strings.Join(*p, ",")
+	case *[]netip.Prefix:
+		values := make([]string, len(*p))
+		for i, prefix := range *p {
+			values[i] = prefix.String()
+		}
+		return strings.Join(values, ",")
+	case *[]APIKeyConfig:
+		values := make([]string, len(*p))
+		for i, k := range *p {
+			values[i] = k.Name + ":" + k.Role + ":" + k.Hash
+		}
+		return strings.Join(values, ",")
+	default:
+		return fmt.Sprint(reflect.ValueOf(p).Elem().Interface())
+	}
+}
+
+// redacted representa o valor para exibição, sem segredos
+func (f field) redacted(c *Config) string {
+	value := f.format(c)
+	switch {
+	case value == "":
+		return ""
+	case f.Secret:
+		return "[redacted]"
+	}
+
+	// Hashes de chaves de API não são segredos, mas não precisam aparecer
+	if keys, ok := f.ptr(c).(*[]APIKeyConfig); ok {
+		values := make([]string, len(*keys))
+		for i, k := range *keys {
+			values[i] = k.Name + ":" + k.Role
+		}
+		return strings.Join(values, ",")
+	}
+	return value
+}
+
+// copyTo copia o valor do campo de src para dst
+func (f field) copyTo(dst, src *Config) {
+	reflect.ValueOf(f.ptr(dst)).Elem().Set(reflect.ValueOf(f.ptr(src)).Elem())
+}
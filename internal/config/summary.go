@@ -0,0 +1,78 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// AuditSummary resume a configuração para a trilha de auditoria
+//
+// Segredos e dados pessoais ficam de fora: a chave privada não aparece e a
+// conta de destino é representada por uma impressão digital, suficiente para
+// detectar a troca da conta sem expor o número ou o CPF/CNPJ.
+func (c Config) AuditSummary() map[string]interface{} {
+	keys := make([]string, len(c.Auth.Keys))
+	for i, k := range c.Auth.Keys {
+		keys[i] = k.Name + ":" + k.Role
+	}
+
+	tenants := make([]map[string]interface{}, len(c.Tenants))
+	for i, t := range c.Tenants {
+		d := t.Destination
+		fingerprint := sha256.Sum256([]byte(strings.Join([]string{
+			d.BankCode, d.BranchCode, d.AccountNumber, d.TaxID, d.AccountType,
+		}, "|")))
+
+		tenants[i] = map[string]interface{}{
+			"id":                      t.ID,
+			"starkbank_project_id":    t.StarkBank.ProjectID,
+			"starkbank_environment":   t.StarkBank.Environment,
+			"destination_bank_code":   d.BankCode,
+			"destination_fingerprint": hex.EncodeToString(fingerprint[:8]),
+			"webhook_url":             t.Webhook.URL,
+			"webhook_subscriptions":   strings.Join(t.Webhook.Subscriptions, ","),
+			"scheduler": fmt.Sprintf("enabled=%t interval=%s duration=%s batch=%d-%d",
+				t.Scheduler.Enabled, t.Scheduler.Interval, t.Scheduler.Duration, t.Scheduler.MinBatch, t.Scheduler.MaxBatch),
+			"polling": fmt.Sprintf("source=%s interval=%s", t.Polling.Source, t.Polling.Interval),
+			"forwarding": fmt.Sprintf("mode=%s min=%d sweep=%s daily_cap=%d destination_daily_cap=%d destination=%s",
+				t.Forwarding.Mode, t.Forwarding.MinAmount, t.Forwarding.SweepInterval, t.Forwarding.DailyCap, t.Forwarding.DestinationDailyCap,
+				t.Forwarding.Destination),
+			"forwarding_target_fingerprint": forwardTargetFingerprint(t.Forwarding),
+			"approval": fmt.Sprintf("threshold=%d required=%d ttl=%s non_default_destination=%t",
+				t.Approval.Threshold, t.Approval.Required, t.Approval.TTL, t.Approval.NonDefaultDestination),
+		}
+	}
+
+	return map[string]interface{}{
+		"tenants":                    tenants,
+		"reversal_action":            c.Reversal.Action,
+		"transfer_fee":               c.Transfer.Fee,
+		"balance_cache_ttl":          c.Transfer.BalanceCacheTTL.String(),
+		"hold_release_interval":      c.Transfer.HoldReleaseInterval.String(),
+		"transfer_schedule_interval": c.Transfer.ScheduleInterval.String(),
+		"calendar": fmt.Sprintf("holidays_file=%s cutoff=%s invoice_due_days=%d timezone=%s",
+			c.Calendar.HolidaysFile, c.Calendar.Cutoff, c.Calendar.InvoiceDueDays, c.Calendar.Timezone),
+		"balance_snapshot_interval":    c.Balance.SnapshotInterval.String(),
+		"balance_low_threshold":        c.Balance.LowThreshold,
+		"balance_divergence_tolerance": c.Balance.DivergenceTolerance,
+		"api_keys":                     keys,
+	}
+}
+
+// forwardTargetFingerprint identifica a chave Pix ou o BR Code de destino sem
+// expô-los, como a impressão digital da conta de destino
+func forwardTargetFingerprint(f ForwardingConfig) string {
+	var target string
+	switch f.Destination {
+	case ForwardToPixKey:
+		target = f.PixKey
+	case ForwardToBRCode:
+		target = f.BRCode
+	default:
+		return ""
+	}
+	sum := sha256.Sum256([]byte(target))
+	return hex.EncodeToString(sum[:8])
+}
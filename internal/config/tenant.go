@@ -0,0 +1,254 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultTenantID identifica o tenant implícito, usado quando o arquivo de
+// configuração não define a seção tenants
+const DefaultTenantID = "default"
+
+// tenantIDPattern restringe o ID ao que pode aparecer em rotas e diretórios
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// TenantConfig projeto da StarkBank atendido pelo processo, com credenciais,
+// conta de destino e política do gerador de invoices próprias
+type TenantConfig struct {
+	ID          string // usado nas rotas (/webhook/<id>, /tenants/<id>/...) e no diretório de dados
+	Name        string
+	WorkspaceID string // roteia webhooks recebidos em /webhook pelo workspaceId do evento
+	DataDir     string // dados locais do tenant (repasses, razão, fila de retenção...)
+
+	StarkBank   StarkBankConfig
+	Destination DestinationAccount
+	Scheduler   SchedulerConfig
+	Webhook     WebhookConfig
+	Polling     PollingConfig
+	Forwarding  ForwardingConfig
+	Approval    ApprovalConfig
+}
+
+// tenantSections são as seções do schema que cada tenant pode redefinir
+var tenantSections = []string{"starkbank", "destination", "scheduler", "webhook", "polling", "forwarding", "approval"}
+
+// isTenantField indica se a chave pertence a uma seção redefinível por tenant
+func isTenantField(key string) bool {
+	section, _, _ := strings.Cut(key, ".")
+	for _, s := range tenantSections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// buildTenants monta os tenants a partir das entradas da seção tenants
+//
+// Cada tenant herda a conta de destino, a política do gerador, o webhook, a
+// consulta de eventos, a política de repasse e o ambiente da configuração
+// global, mas não as credenciais: uma chave herdada por engano assinaria
+// requisições de outro projeto.
+func (c *Config) buildTenants(entries []map[string]string, source string) error {
+	if len(entries) == 0 {
+		c.Tenants = []TenantConfig{{
+			ID:          DefaultTenantID,
+			DataDir:     c.Storage.DataDir,
+			StarkBank:   c.StarkBank,
+			Destination: c.Destination,
+			Scheduler:   c.Scheduler,
+			Webhook:     c.Webhook,
+			Polling:     c.Polling,
+			Forwarding:  c.Forwarding,
+			Approval:    c.Approval,
+		}}
+		return nil
+	}
+
+	c.Tenants = make([]TenantConfig, 0, len(entries))
+	for i, values := range entries {
+		id := values["id"]
+		scoped := *c
+		scoped.StarkBank = StarkBankConfig{
+			Environment:       c.StarkBank.Environment,
+			VerifyCredentials: c.StarkBank.VerifyCredentials,
+			SecretsDir:        c.StarkBank.SecretsDir,
+			KeySource:         "auto",
+			SecretName:        "stark_private_key_" + id,
+		}
+
+		for key, value := range values {
+			f, ok := lookupField(key)
+			if !ok {
+				continue // id, name e workspace_id
+			}
+			if err := f.set(&scoped, value, fmt.Sprintf("%s: tenants[%d].%s", source, i, key)); err != nil {
+				return err
+			}
+		}
+
+		c.Tenants = append(c.Tenants, TenantConfig{
+			ID:          id,
+			Name:        values["name"],
+			WorkspaceID: values["workspace_id"],
+			DataDir:     filepath.Join(c.Storage.DataDir, "tenants", id),
+			StarkBank:   scoped.StarkBank,
+			Destination: scoped.Destination,
+			Scheduler:   scoped.Scheduler,
+			Webhook:     scoped.Webhook,
+			Polling:     scoped.Polling,
+			Forwarding:  scoped.Forwarding,
+			Approval:    scoped.Approval,
+		})
+	}
+	return nil
+}
+
+// checkTenantKey confere uma chave da seção tenants do arquivo
+func checkTenantKey(key string) error {
+	switch key {
+	case "id", "name", "workspace_id":
+		return nil
+	}
+	if _, ok := lookupField(key); ok && isTenantField(key) {
+		return nil
+	}
+	return fmt.Errorf("chave %q não é aceita em tenants (use id, name, workspace_id e as seções %s)",
+		key, strings.Join(tenantSections, ", "))
+}
+
+// validate confere um tenant; explicit indica um tenant da seção tenants,
+// identificado nas mensagens (no implícito elas citam as variáveis de ambiente)
+func (t TenantConfig) validate(explicit bool) []error {
+	var errs []error
+	prefix := ""
+	if explicit {
+		prefix = fmt.Sprintf("tenants[%s].", t.ID)
+	}
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(prefix+format, args...))
+		}
+	}
+	env := func(name string) string {
+		if explicit {
+			return ""
+		}
+		return " (" + name + ")"
+	}
+
+	check(tenantIDPattern.MatchString(t.ID), "id inválido: %q (use letras minúsculas, números, - e _)", t.ID)
+
+	sb := t.StarkBank
+	check(sb.ProjectID != "", "starkbank.project_id%s é obrigatório", env("STARK_PROJECT_ID"))
+	check(oneOf(sb.Environment, "sandbox", "production"),
+		"starkbank.environment inválido: %q (use sandbox ou production)", sb.Environment)
+	check(oneOf(sb.KeySource, "auto", "env", "file", "secret", "keystore"),
+		"starkbank.key_source inválido: %q (use auto, env, file, secret ou keystore)", sb.KeySource)
+	check(sb.KeySource != "auto" || sb.PrivateKey != "" || sb.PrivateKeyFile != "",
+		"starkbank.private_key ou starkbank.private_key_file é obrigatório com starkbank.key_source=auto")
+	check(sb.KeySource != "keystore" || sb.KeystoreFile != "",
+		"starkbank.keystore_file%s é obrigatório com starkbank.key_source=keystore", env("KEYSTORE_FILE"))
+	check(sb.KeySource != "keystore" || sb.KeystorePassphrase != "" || sb.KeystorePassphraseFile != "",
+		"starkbank.keystore_passphrase ou starkbank.keystore_passphrase_file é obrigatório com starkbank.key_source=keystore")
+
+	d := t.Destination
+	check(d.BankCode != "" && d.BranchCode != "" && d.AccountNumber != "" && d.Name != "" && d.TaxID != "" && d.AccountType != "",
+		"destination: todos os campos da conta de destino são obrigatórios")
+
+	s := t.Scheduler
+	check(s.MinBatch >= 1 && s.MaxBatch >= s.MinBatch,
+		"scheduler: min_batch (%d) deve ser ao menos 1 e não maior que max_batch (%d)", s.MinBatch, s.MaxBatch)
+	check(s.MaxBatch <= 100, "scheduler.max_batch deve ser no máximo 100 (limite da StarkBank por requisição)")
+
+	w := t.Webhook
+	if w.URL != "" {
+		u, err := url.Parse(w.URL)
+		check(err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "",
+			"webhook.url%s inválida: %q (use uma URL http(s) completa)", env("WEBHOOK_URL"), w.URL)
+	}
+	check(len(w.Subscriptions) > 0, "webhook.subscriptions%s deve ter ao menos um evento", env("WEBHOOK_SUBSCRIPTIONS"))
+	for _, sub := range w.Subscriptions {
+		check(oneOf(sub, WebhookSubscriptions...), "webhook.subscriptions: evento desconhecido %q (use %s)",
+			sub, strings.Join(WebhookSubscriptions, ", "))
+	}
+
+	p := t.Polling
+	check(oneOf(p.Source, PollingOff, PollingEvents, PollingInvoiceLogs),
+		"polling.source%s inválido: %q (use off, events ou invoice_logs)", env("POLLING_SOURCE"), p.Source)
+	check(p.Interval >= time.Second, "polling.interval%s deve ser de ao menos 1s", env("POLLING_INTERVAL"))
+	check(p.Lookback > 0, "polling.lookback%s deve ser positivo", env("POLLING_LOOKBACK"))
+
+	f := t.Forwarding
+	check(oneOf(f.Mode, ForwardingImmediate, ForwardingSweep),
+		"forwarding.mode%s inválido: %q (use immediate ou sweep)", env("FORWARDING_MODE"), f.Mode)
+	check(f.SweepInterval >= time.Second, "forwarding.sweep_interval%s deve ser de ao menos 1s", env("FORWARDING_SWEEP_INTERVAL"))
+	check(f.MinAmount >= 0 && f.DailyCap >= 0 && f.DestinationDailyCap >= 0,
+		"forwarding: min_amount, daily_cap e destination_daily_cap não podem ser negativos")
+	check(f.DailyCap == 0 || f.MinAmount <= f.DailyCap,
+		"forwarding.min_amount (%d) não pode exceder forwarding.daily_cap (%d)", f.MinAmount, f.DailyCap)
+	check(oneOf(f.Destination, ForwardToAccount, ForwardToPixKey, ForwardToBRCode),
+		"forwarding.destination%s inválido: %q (use account, pix_key ou brcode)", env("FORWARDING_DESTINATION"), f.Destination)
+	check(f.Destination != ForwardToPixKey || f.PixKey != "",
+		"forwarding.pix_key%s é obrigatório com forwarding.destination=pix_key", env("FORWARDING_PIX_KEY"))
+	check(f.Destination != ForwardToBRCode || f.BRCode != "",
+		"forwarding.brcode%s é obrigatório com forwarding.destination=brcode", env("FORWARDING_BRCODE"))
+
+	a := t.Approval
+	check(a.Required >= 1, "approval.required%s deve ser ao menos 1", env("APPROVAL_REQUIRED"))
+	return errs
+}
+
+// Tenant busca um tenant pelo ID
+func (c *Config) Tenant(id string) (TenantConfig, bool) {
+	for _, t := range c.Tenants {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return TenantConfig{}, false
+}
+
+// sections retorna as seções redefiníveis do tenant em uma Config, para ler e
+// gravar seus campos pelo schema
+func (t TenantConfig) sections() Config {
+	return Config{StarkBank: t.StarkBank, Destination: t.Destination, Scheduler: t.Scheduler, Webhook: t.Webhook, Polling: t.Polling, Forwarding: t.Forwarding, Approval: t.Approval}
+}
+
+// withSections retorna o tenant com as seções redefiníveis de c
+func (t TenantConfig) withSections(c Config) TenantConfig {
+	t.StarkBank, t.Destination, t.Scheduler, t.Webhook = c.StarkBank, c.Destination, c.Scheduler, c.Webhook
+	t.Polling, t.Forwarding, t.Approval = c.Polling, c.Forwarding, c.Approval
+	return t
+}
+
+// hasTenantSection indica se os tenants vieram da seção tenants do arquivo
+func (c *Config) hasTenantSection() bool {
+	return len(c.Tenants) != 1 || c.Tenants[0].ID != DefaultTenantID || c.Tenants[0].DataDir != c.Storage.DataDir
+}
+
+// writeTenants imprime a seção tenants no formato aceito pelo arquivo
+func (c *Config) writeTenants(w io.Writer) {
+	if !c.hasTenantSection() {
+		return // tenant implícito: já representado pelas seções globais
+	}
+
+	fmt.Fprintln(w, "tenants:")
+	for _, t := range c.Tenants {
+		fmt.Fprintf(w, "  - id: %q\n", t.ID)
+		fmt.Fprintf(w, "    name: %q\n", t.Name)
+		fmt.Fprintf(w, "    workspace_id: %q\n", t.WorkspaceID)
+
+		scoped := t.sections()
+		for _, f := range schema {
+			if isTenantField(f.Key) {
+				fmt.Fprintf(w, "    %s: %q\n", f.Key, f.redacted(&scoped))
+			}
+		}
+	}
+}
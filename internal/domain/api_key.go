@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Papéis de acesso à API administrativa, do menor ao maior privilégio
+const (
+	RoleReadOnly = "read"     // consultas (saldo, razão, relatórios)
+	RoleOperator = "operator" // ações operacionais (resolver estornos, reprocessar, disparar jobs)
+	RoleAdmin    = "admin"    // gestão de chaves e contas de destino
+)
+
+var (
+	// ErrUnauthenticated indica chave ausente, inválida ou revogada
+	ErrUnauthenticated = errors.New("chave de API inválida")
+
+	// ErrForbidden indica chave válida sem o papel necessário
+	ErrForbidden = errors.New("papel insuficiente para esta operação")
+)
+
+var roleRank = map[string]int{
+	RoleReadOnly: 1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ValidRole indica se o papel existe
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RoleAllows indica se o papel have cobre as permissões de need
+//
+// Os papéis são hierárquicos: admin inclui operator, que inclui read.
+func RoleAllows(have, need string) bool {
+	return roleRank[have] > 0 && roleRank[have] >= roleRank[need]
+}
+
+// APIKey representa uma chave de acesso à API administrativa
+//
+// Apenas o hash SHA-256 da chave é armazenado; o valor em claro é exibido
+// uma única vez, na criação.
+type APIKey struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name"`
+	Role    string     `json:"role"`
+	Hash    string     `json:"hash"`
+	Source  string     `json:"source"` // config ou store
+	Created time.Time  `json:"created"`
+	Revoked *time.Time `json:"revoked,omitempty"`
+}
+
+// APIKeyRepository define a interface para persistir chaves de API
+type APIKeyRepository interface {
+	Save(key APIKey) error
+	GetByID(id string) (*APIKey, error)
+	GetByHash(hash string) (*APIKey, error)
+	List() ([]APIKey, error)
+}
+
+type apiKeyContextKey struct{}
+
+// ContextWithAPIKey associa a chave autenticada ao contexto da requisição
+func ContextWithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext retorna a chave autenticada (nil se a rota for pública)
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return key
+}
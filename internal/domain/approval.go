@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Status de uma solicitação de aprovação de repasse
+const (
+	ApprovalStatusPending  = "pending_approval"
+	ApprovalStatusApproved = "approved" // aprovada e transferência criada
+	ApprovalStatusRejected = "rejected"
+	ApprovalStatusExpired  = "expired"
+)
+
+// ErrApprovalClosed indica uma solicitação que não aceita mais aprovações
+var ErrApprovalClosed = errors.New("solicitação de aprovação não está pendente")
+
+// ErrAlreadyApproved indica um operador aprovando a mesma solicitação de novo
+var ErrAlreadyApproved = errors.New("operador já aprovou esta solicitação")
+
+// ErrApprovalOverCap indica uma solicitação aprovada cujo valor não cabe no
+// que resta dos limites diários; ela continua pendente
+var ErrApprovalOverCap = errors.New("repasse aprovado excede o limite diário")
+
+// Approval é a aprovação de um operador
+type Approval struct {
+	Actor   string // autor da ação (ex: api_key:<id>)
+	Name    string // nome da chave de API do operador
+	Created time.Time
+}
+
+// TransferApproval é um repasse retido até a aprovação de operadores
+type TransferApproval struct {
+	ID         string
+	Status     string
+	Reason     string   // por que o repasse exige aprovação
+	InvoiceIDs []string // créditos cobertos, que aguardam na fila de repasse
+	Amount     Money    // valor líquido a repassar
+	Required   int      // aprovações de operadores distintos necessárias
+	Approvals  []Approval
+	RejectedBy string
+	Note       string // justificativa da rejeição
+	TransferID string // transferência criada após a aprovação
+	LastError  string // falha ao criar a transferência aprovada ou limite diário esgotado
+	Created    time.Time
+	Expires    time.Time
+	Resolved   *time.Time
+}
+
+// Approved indica se a solicitação já tem as aprovações necessárias
+func (a TransferApproval) Approved() bool {
+	return len(a.Approvals) >= a.Required
+}
+
+// ApprovedBy indica se o autor já aprovou a solicitação
+func (a TransferApproval) ApprovedBy(actor string) bool {
+	for _, approval := range a.Approvals {
+		if approval.Actor == actor {
+			return true
+		}
+	}
+	return false
+}
+
+// Approvers retorna os nomes dos operadores que aprovaram
+func (a TransferApproval) Approvers() []string {
+	names := make([]string, len(a.Approvals))
+	for i, approval := range a.Approvals {
+		names[i] = approval.Name
+	}
+	return names
+}
+
+// TransferApprovalRepository define a interface para as solicitações de aprovação
+type TransferApprovalRepository interface {
+	Save(approval TransferApproval) error
+	Get(id string) (*TransferApproval, error)
+	List(status string) ([]TransferApproval, error) // vazio lista todas, mais recentes primeiro
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Ações registradas na trilha de auditoria
+const (
+	AuditInvoiceBatchCreated   = "invoice.batch_created"
+	AuditTransferCreated       = "transfer.created"
+	AuditTransferHeld          = "transfer.held"
+	AuditTransferReleased      = "transfer.released"
+	AuditTransferHoldCanceled  = "transfer.hold_canceled"
+	AuditTransferScheduled     = "transfer.scheduled"
+	AuditScheduleCanceled      = "transfer.schedule_canceled"
+	AuditApprovalRequested     = "approval.requested"
+	AuditApprovalApproved      = "approval.approved"
+	AuditApprovalRejected      = "approval.rejected"
+	AuditApprovalExpired       = "approval.expired"
+	AuditReversalRecorded      = "reversal.recorded"
+	AuditReversalResolved      = "reversal.resolved"
+	AuditRefundRequested       = "refund.requested"
+	AuditRefundSettled         = "refund.settled"
+	AuditAPIKeyCreated         = "api_key.created"
+	AuditAPIKeyRevoked         = "api_key.revoked"
+	AuditConfigChanged         = "config.changed"
+	AuditKeyRotationStarted    = "key_rotation.started"
+	AuditKeyRotationSwitched   = "key_rotation.switched"
+	AuditKeyRotationRolledBack = "key_rotation.rolled_back"
+	AuditKeyRotationRetired    = "key_rotation.retired"
+	AuditKeyRotationCancelled  = "key_rotation.cancelled"
+	AuditEventReplayed         = "event.replayed"
+	AuditJobTriggered          = "job.triggered"
+)
+
+// Atores usados por processos sem chave de API
+const (
+	ActorScheduler = "system:scheduler"
+	ActorHoldQueue = "system:hold_queue"
+	ActorSchedules = "system:transfer_schedule"
+	ActorWebhook   = "starkbank:webhook"
+	ActorPolling   = "starkbank:polling"
+	ActorStartup   = "system:startup"
+	ActorReload    = "system:reload"
+)
+
+// AuditGenesisHash é o hash anterior da primeira entrada da cadeia
+var AuditGenesisHash = strings.Repeat("0", 64)
+
+// AuditEntry é uma entrada da trilha de auditoria
+//
+// As entradas formam uma cadeia: cada uma guarda o hash da anterior, e o seu
+// próprio hash cobre todos os campos. Alterar, remover ou reordenar uma
+// entrada quebra a cadeia a partir daquele ponto.
+type AuditEntry struct {
+	Seq      int64           `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Actor    string          `json:"actor"`
+	Tenant   string          `json:"tenant,omitempty"` // vazio em ações globais (config, chaves de API)
+	Action   string          `json:"action"`
+	Target   string          `json:"target"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+// ComputeHash calcula o hash da entrada (todos os campos exceto Hash)
+func (e AuditEntry) ComputeHash() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter restringe a consulta da trilha (campos vazios não filtram)
+type AuditFilter struct {
+	Actor  string
+	Tenant string
+	Action string
+	Target string
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
+
+// Match indica se a entrada atende ao filtro
+func (f AuditFilter) Match(e AuditEntry) bool {
+	switch {
+	case f.Actor != "" && e.Actor != f.Actor:
+		return false
+	case f.Tenant != "" && e.Tenant != f.Tenant:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case f.Target != "" && e.Target != f.Target:
+		return false
+	case !f.From.IsZero() && e.Time.Before(f.From):
+		return false
+	case !f.To.IsZero() && e.Time.After(f.To):
+		return false
+	}
+	return true
+}
+
+// AuditChainError indica a primeira entrada em que a cadeia foi violada
+type AuditChainError struct {
+	Seq    int64
+	Reason string
+}
+
+func (e *AuditChainError) Error() string {
+	return fmt.Sprintf("trilha de auditoria violada na entrada %d: %s", e.Seq, e.Reason)
+}
+
+// VerifyAuditChain confere sequência, encadeamento e hash de todas as entradas
+func VerifyAuditChain(entries []AuditEntry) error {
+	prev := AuditGenesisHash
+	for i, e := range entries {
+		switch {
+		case e.Seq != int64(i+1):
+			return &AuditChainError{Seq: e.Seq, Reason: fmt.Sprintf("sequência esperada %d", i+1)}
+		case e.PrevHash != prev:
+			return &AuditChainError{Seq: e.Seq, Reason: "hash anterior não confere"}
+		case e.ComputeHash() != e.Hash:
+			return &AuditChainError{Seq: e.Seq, Reason: "conteúdo alterado"}
+		}
+		prev = e.Hash
+	}
+	return nil
+}
+
+// AuditRepository define a interface da trilha de auditoria
+//
+// Append atribui sequência, hash anterior e hash à entrada e a grava.
+type AuditRepository interface {
+	Append(entry AuditEntry) (AuditEntry, error)
+	List(filter AuditFilter) ([]AuditEntry, error)
+	All() ([]AuditEntry, error)
+}
+
+// Auditor registra ações que alteram estado
+type Auditor interface {
+	Record(ctx context.Context, action, target string, before, after interface{})
+}
+
+type actorContextKey struct{}
+
+// ContextWithActor identifica quem age em processos sem chave de API
+// (ex: "system:scheduler", "starkbank:webhook")
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext retorna o autor da ação: a chave de API autenticada, o
+// ator definido por ContextWithActor ou "system"
+func ActorFromContext(ctx context.Context) string {
+	if key := APIKeyFromContext(ctx); key != nil {
+		return "api_key:" + key.ID
+	}
+	if actor, ok := ctx.Value(actorContextKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return "system"
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Regras de alerta de saldo
+const (
+	BalanceAlertLowBalance = "low_balance"       // saldo abaixo do limite configurado
+	BalanceAlertDivergence = "ledger_divergence" // saldo diferente do previsto pelo razão
+)
+
+// Balance representa o saldo atual da conta
+type Balance struct {
+	Amount  Money
+	Updated *time.Time
+}
+
+// BalanceSnapshot registra o saldo da conta em um instante
+type BalanceSnapshot struct {
+	Amount  Money
+	Updated *time.Time // horário de atualização informado pela StarkBank
+	Taken   time.Time  // horário em que o snapshot foi registrado
+}
+
+// BalanceAlert representa um alerta de saldo disparado
+type BalanceAlert struct {
+	Rule     string
+	Message  string
+	Amount   Money
+	Expected Money
+	Fired    time.Time
+}
+
+// BalanceRepository define a interface para consulta do saldo
+type BalanceRepository interface {
+	Get(ctx context.Context) (*Balance, error)
+}
+
+// BalanceSnapshotRepository define a interface para o histórico de saldos
+type BalanceSnapshotRepository interface {
+	Save(snapshot BalanceSnapshot) error
+	List(from, to time.Time) ([]BalanceSnapshot, error)
+	Last() (*BalanceSnapshot, error)
+}
+
+// BalanceAlertRepository define a interface para persistir alertas disparados
+type BalanceAlertRepository interface {
+	Save(alert BalanceAlert) error
+	List(limit int) ([]BalanceAlert, error)
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "errors"
+
+// ErrNotFound indica que o registro procurado não existe no repositório
+var ErrNotFound = errors.New("registro não encontrado")
+
+// ErrCredentialsRejected indica que a StarkBank recusou a requisição
+// autenticada (chave privada não registrada no projeto ou projeto incorreto)
+var ErrCredentialsRejected = errors.New("credenciais recusadas pela StarkBank")
+
+// ErrInvalidInput indica dados de entrada inválidos (ex: invoice sem valor)
+var ErrInvalidInput = errors.New("dados inválidos")
+
+// ErrJobRunning indica que o job já está em execução
+var ErrJobRunning = errors.New("job já em execução")
+
+// ErrInvalidSignature indica que a assinatura digital do webhook não confere
+// com a chave pública da StarkBank
+var ErrInvalidSignature = errors.New("assinatura digital inválida")
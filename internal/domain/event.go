@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Event é um evento registrado na StarkBank, entregue ou não por webhook
+type Event struct {
+	ID           string     `json:"id"`
+	Subscription string     `json:"subscription"`
+	Type         string     `json:"type,omitempty"`        // tipo do log (ex: credited)
+	ResourceID   string     `json:"resource_id,omitempty"` // ID do recurso (ex: invoice)
+	WorkspaceID  string     `json:"workspace_id,omitempty"`
+	Delivered    bool       `json:"delivered"`
+	Created      *time.Time `json:"created,omitempty"`
+
+	// Webhook são os dados do evento no formato recebido pelo webhook,
+	// usados para reprocessá-lo
+	Webhook WebhookEvent `json:"-"`
+}
+
+// EventFilter restringe a consulta de eventos
+type EventFilter struct {
+	After       *time.Time // criados a partir desta data
+	Before      *time.Time // criados até esta data
+	Undelivered bool       // apenas eventos não entregues pelo webhook
+	Limit       int        // 0 retorna todos
+}
+
+// EventRepository define a interface para consultar os eventos da StarkBank
+type EventRepository interface {
+	Get(ctx context.Context, id string) (*Event, error)
+	List(ctx context.Context, filter EventFilter) ([]Event, error)
+
+	// MarkDelivered marca o evento como entregue, retirando-o das consultas
+	// de eventos não entregues
+	MarkDelivered(ctx context.Context, id string) error
+}
+
+// InvoiceLogRepository define a interface para consultar os logs de invoices
+// da StarkBank, convertidos em eventos (o ID do evento é o ID do log)
+type InvoiceLogRepository interface {
+	List(ctx context.Context, filter EventFilter, types ...string) ([]Event, error)
+}
+
+// EventCursor é a posição da consulta periódica de eventos
+//
+// A API filtra apenas por data, então cada consulta repete o dia de After;
+// Seen guarda os eventos já processados nesse intervalo para não repeti-los;
+// Attempts conta as falhas dos eventos que ainda serão tentados de novo.
+type EventCursor struct {
+	After    time.Time            `json:"after"`              // criação do último evento processado
+	Seen     map[string]time.Time `json:"seen"`               // ID -> criação dos eventos recentes já processados
+	Attempts map[string]int       `json:"attempts,omitempty"` // ID -> falhas seguidas
+}
+
+// EventCursorRepository define a interface para persistir o cursor da
+// consulta periódica de eventos
+type EventCursorRepository interface {
+	Get() (*EventCursor, error) // ErrNotFound antes da primeira consulta
+	Save(cursor EventCursor) error
+}
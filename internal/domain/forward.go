@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Forward registra o repasse de um invoice creditado para a conta de destino
+type Forward struct {
+	InvoiceID  string
+	TransferID string
+	Kind       string // vazio: transferência; brcode_payment: TransferID é o pagamento do BR Code
+	PayerName  string
+	PayerTaxID string
+	Amount     Money    // valor bruto recebido
+	Fee        Money    // taxa cobrada no invoice
+	Forwarded  Money    // valor líquido transferido
+	ApprovedBy []string // operadores que aprovaram o repasse, se exigido
+	Created    time.Time
+}
+
+// ForwardRepository define a interface para persistir repasses realizados
+type ForwardRepository interface {
+	Save(forward Forward) error
+	GetByInvoiceID(invoiceID string) (*Forward, error)
+	List() ([]Forward, error)
+}
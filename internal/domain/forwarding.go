@@ -0,0 +1,90 @@
+package domain
+
+import "time"
+
+// Ações da política de repasse
+const (
+	ForwardActionTransfer   = "transfer"   // invoice repassado imediatamente
+	ForwardActionAccumulate = "accumulate" // crédito acumulado para um repasse agrupado
+	ForwardActionHold       = "hold"       // crédito retido por exceder o limite diário
+	ForwardActionSweep      = "sweep"      // créditos pendentes repassados em uma transferência
+	ForwardActionSkip       = "skip"       // varredura sem repasse (mínimo, limite ou saldo)
+	ForwardActionApproval   = "approval"   // créditos aguardando a aprovação de operadores
+	ForwardActionPayerHold  = "payer_hold" // crédito de pagador com estorno pendente
+)
+
+// Regras que motivam uma decisão de repasse
+const (
+	ForwardRuleNone                = "none"
+	ForwardRuleMinAmount           = "min_amount"
+	ForwardRuleSweep               = "sweep"
+	ForwardRuleDailyCap            = "daily_cap"
+	ForwardRuleDestinationDailyCap = "destination_daily_cap"
+	ForwardRuleBalance             = "balance"
+	ForwardRuleApproval            = "approval"
+	ForwardRuleDestination         = "destination" // destino recusou o repasse (ex: BR Code inativo)
+	ForwardRulePayerHold           = "payer_hold"  // pagador bloqueado por estorno (REVERSAL_ACTION=hold_payer)
+)
+
+// PendingCredit é um crédito aguardando repasse: acumulado até o valor
+// mínimo ou a próxima varredura, retido até o limite diário permitir,
+// reservado para uma solicitação de aprovação ou bloqueado até a resolução
+// do estorno do pagador
+type PendingCredit struct {
+	InvoiceID  string
+	Event      WebhookEvent // evento de origem
+	Net        Money        // valor líquido a repassar
+	Action     string       // accumulate, hold, approval ou payer_hold
+	ApprovalID string       // solicitação que reserva o crédito (ação approval)
+	Created    time.Time
+}
+
+// ForwardDecision registra uma decisão da política de repasse e o motivo
+type ForwardDecision struct {
+	ID         string
+	Action     string
+	Rule       string   // regra determinante
+	InvoiceIDs []string // créditos envolvidos
+	Amount     Money    // valor líquido envolvido
+	TransferID string   // transferência criada, se houver
+	Reason     string   // explicação legível da decisão
+	Created    time.Time
+}
+
+// ForwardDecisionFilter restringe a consulta de decisões
+type ForwardDecisionFilter struct {
+	InvoiceID string
+	Action    string
+	Limit     int // 0 retorna todas
+}
+
+// Match indica se a decisão atende ao filtro (ignora Limit)
+func (f ForwardDecisionFilter) Match(d ForwardDecision) bool {
+	if f.Action != "" && d.Action != f.Action {
+		return false
+	}
+	if f.InvoiceID == "" {
+		return true
+	}
+	for _, id := range d.InvoiceIDs {
+		if id == f.InvoiceID {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingCreditRepository define a interface para os créditos aguardando repasse
+type PendingCreditRepository interface {
+	Save(credit PendingCredit) error
+	GetByInvoiceID(invoiceID string) (*PendingCredit, error)
+	List() ([]PendingCredit, error) // em ordem de chegada
+	Delete(invoiceIDs ...string) error
+}
+
+// ForwardDecisionRepository define a interface para o histórico de decisões
+// da política de repasse (somente inclusão)
+type ForwardDecisionRepository interface {
+	Append(decision ForwardDecision) error
+	List(filter ForwardDecisionFilter) ([]ForwardDecision, error) // mais recentes primeiro
+}
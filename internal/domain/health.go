@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Status de uma verificação de prontidão
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusDegraded  = "degraded"  // funciona, mas requer atenção
+	HealthStatusUnhealthy = "unhealthy" // não consegue atender requisições
+)
+
+// HealthCheck é o resultado de uma verificação de dependência
+type HealthCheck struct {
+	Name     string                 `json:"name"`
+	Status   string                 `json:"status"`
+	Message  string                 `json:"message,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	Duration string                 `json:"duration"`
+	Checked  time.Time              `json:"checked"`
+	Cached   bool                   `json:"cached,omitempty"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status de uma transferência retida
+const (
+	HeldTransferStatusHeld     = "held"
+	HeldTransferStatusReleased = "released"
+	HeldTransferStatusCanceled = "canceled" // invoice estornado antes da liberação
+)
+
+// ErrInsufficientBalance indica que o saldo não cobre o valor da transferência mais taxas
+var ErrInsufficientBalance = errors.New("saldo insuficiente para a transferência")
+
+// InsufficientBalanceError detalha o saldo faltante de uma transferência
+type InsufficientBalanceError struct {
+	Required  Money
+	Available Money
+}
+
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("%v: necessário %s, disponível %s", ErrInsufficientBalance, e.Required, e.Available)
+}
+
+// Unwrap permite usar errors.Is(err, ErrInsufficientBalance)
+func (e *InsufficientBalanceError) Unwrap() error {
+	return ErrInsufficientBalance
+}
+
+// HeldTransfer representa um repasse aguardando saldo suficiente
+type HeldTransfer struct {
+	ID         string
+	InvoiceID  string
+	Event      WebhookEvent // evento de origem, reprocessado na liberação
+	Required   Money        // valor líquido + taxa estimada
+	Available  Money        // saldo disponível no momento da retenção
+	Status     string
+	Attempts   int
+	LastError  string
+	TransferID string
+	Trace      map[string]string // contexto de trace de quem reteve o repasse
+	Created    time.Time
+	Released   *time.Time
+	Canceled   *time.Time
+	Note       string // motivo do cancelamento
+}
+
+// BalanceProvider fornece o saldo disponível para transferências
+type BalanceProvider interface {
+	Get(ctx context.Context) (*Balance, error)
+	Invalidate()
+}
+
+// HoldQueueRepository define a interface para a fila de transferências retidas
+type HoldQueueRepository interface {
+	Save(held HeldTransfer) error
+	GetByInvoiceID(invoiceID string) (*HeldTransfer, error)
+	ListByStatus(status string) ([]HeldTransfer, error)
+	// Cancel cancela a retenção ativa de um invoice; ErrNotFound se não houver
+	Cancel(invoiceID, note string) (*HeldTransfer, error)
+}
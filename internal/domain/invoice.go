@@ -1,23 +1,26 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Invoice representa uma fatura no domínio da aplicação
 type Invoice struct {
-	ID         string
-	Amount     int
-	Name       string
-	TaxID      string
-	Due        *time.Time
-	Expiration int
-	Status     string
-	Fee        int
-	Created    *time.Time
+	ID         string     `json:"id"`
+	Amount     Money      `json:"amount"`
+	Name       string     `json:"name"`
+	TaxID      string     `json:"tax_id"`
+	Due        *time.Time `json:"due,omitempty"`
+	Expiration int        `json:"expiration,omitempty"`
+	Status     string     `json:"status"`
+	Fee        Money      `json:"fee"`
+	Created    *time.Time `json:"created,omitempty"`
 }
 
 // InvoiceRepository define a interface para operações com invoices
 type InvoiceRepository interface {
-	Create(invoices []Invoice) ([]Invoice, error)
-	GetByID(id string) (*Invoice, error)
-	List(limit int) ([]Invoice, error)
+	Create(ctx context.Context, invoices []Invoice) ([]Invoice, error)
+	GetByID(ctx context.Context, id string) (*Invoice, error)
+	List(ctx context.Context, limit int) ([]Invoice, error)
 }
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Estados de uma rotação da chave privada da StarkBank
+const (
+	KeyRotationPending   = "pending"   // nova chave gerada, aguardando o cadastro da chave pública
+	KeyRotationSwitched  = "switched"  // assinando com a nova chave; a anterior segue carregada
+	KeyRotationRetired   = "retired"   // chave anterior descartada
+	KeyRotationCancelled = "cancelled" // nova chave descartada antes da troca
+)
+
+// ErrKeyRotationState indica uma etapa fora de ordem (ex.: aposentar antes de trocar)
+var ErrKeyRotationState = errors.New("etapa não permitida no estado atual da rotação de chave")
+
+// KeyRotation registra a troca da chave privada de um projeto
+//
+// As chaves privadas ficam no registro enquanto são necessárias para retomar
+// a rotação após um reinício, e nunca são expostas pela API. Com o keystore
+// como origem, são gravadas cifradas com a mesma senha.
+type KeyRotation struct {
+	ID             string     `json:"id"`
+	Status         string     `json:"status"`
+	PublicKey      string     `json:"public_key"` // PEM a cadastrar no painel da StarkBank
+	NewFingerprint string     `json:"new_fingerprint"`
+	OldFingerprint string     `json:"old_fingerprint"`
+	NewPrivateKey  string     `json:"new_private_key,omitempty"` // descartada quando gravada na origem configurada
+	OldPrivateKey  string     `json:"old_private_key,omitempty"` // descartada ao aposentar
+	Persisted      bool       `json:"persisted"`                 // nova chave gravada na origem configurada
+	Created        time.Time  `json:"created"`
+	Switched       *time.Time `json:"switched,omitempty"`
+	Retired        *time.Time `json:"retired,omitempty"`
+	Cancelled      *time.Time `json:"cancelled,omitempty"`
+}
+
+// Open indica se a rotação ainda tem etapas pendentes
+func (r KeyRotation) Open() bool {
+	return r.Status == KeyRotationPending || r.Status == KeyRotationSwitched
+}
+
+// KeyRotationRepository define a interface para persistir rotações de chave
+type KeyRotationRepository interface {
+	Save(rotation KeyRotation) error
+	Latest() (*KeyRotation, error) // ErrNotFound se nunca houve rotação
+	List() ([]KeyRotation, error)
+}
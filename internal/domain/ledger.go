@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Contas do razão interno
+const (
+	LedgerAccountReceivables = "receivables" // valores brutos recebidos de invoices
+	LedgerAccountFees        = "fees"        // taxas cobradas pela StarkBank
+	LedgerAccountPending     = "pending"     // valores líquidos aguardando repasse
+	LedgerAccountInTransit   = "in_transit"  // repasses enviados à StarkBank, aguardando o resultado
+	LedgerAccountForwarded   = "forwarded"   // valores repassados à conta de destino
+	LedgerAccountOwnFunds    = "own_funds"   // saldo próprio da conta, fora dos invoices
+	LedgerAccountScheduled   = "scheduled"   // transferências agendadas pagas com o saldo próprio
+	LedgerAccountReversed    = "reversed"    // valores de invoices estornados devolvidos ao pagador
+)
+
+// Tipos de lançamento
+const (
+	LedgerEntryInvoiceCredited   = "invoice_credited"
+	LedgerEntryTransferRequested = "transfer_requested" // intenção gravada antes de criar a transferência
+	LedgerEntryTransferCreated   = "transfer_created"
+	LedgerEntryTransferFailed    = "transfer_failed" // a criação falhou: o valor volta a pendente
+	LedgerEntryScheduledTransfer = "scheduled_transfer"
+	LedgerEntryScheduledReverted = "scheduled_reverted" // agendada cancelada ou recusada: o valor não saiu
+	LedgerEntryReversal          = "reversal"           // invoice estornado: o valor sai do saldo próprio
+)
+
+// ErrDuplicateLedgerEntry indica que um lançamento com a mesma chave já existe
+var ErrDuplicateLedgerEntry = errors.New("lançamento já registrado")
+
+// LedgerPosting representa uma partida (débito ou crédito) em uma conta
+//
+// O razão opera em uma única moeda (BRL), com valores em centavos.
+type LedgerPosting struct {
+	Account string
+	Debit   int64
+	Credit  int64
+}
+
+// LedgerEntry representa um lançamento contábil de partidas dobradas
+type LedgerEntry struct {
+	ID         string
+	Key        string // chave de idempotência do lançamento
+	Kind       string
+	InvoiceID  string
+	TransferID string
+	Postings   []LedgerPosting
+	Created    time.Time
+}
+
+// Balanced indica se a soma dos débitos é igual à soma dos créditos
+func (e LedgerEntry) Balanced() bool {
+	var debits, credits int64
+	for _, p := range e.Postings {
+		debits += p.Debit
+		credits += p.Credit
+	}
+	return debits == credits
+}
+
+// LedgerRepository define a interface para o razão (somente inclusão)
+type LedgerRepository interface {
+	Append(entry LedgerEntry) error
+	List() ([]LedgerEntry, error)
+	ListByInvoiceID(invoiceID string) ([]LedgerEntry, error)
+	ListByTransferID(transferID string) ([]LedgerEntry, error)
+}
@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CurrencyBRL é a moeda padrão das contas StarkBank
+const CurrencyBRL = "BRL"
+
+var (
+	// ErrMoneyOverflow indica que o resultado de uma operação não cabe em int64
+	ErrMoneyOverflow = errors.New("valor monetário excede o limite suportado")
+
+	// ErrCurrencyMismatch indica operação entre valores de moedas diferentes
+	ErrCurrencyMismatch = errors.New("moedas diferentes")
+)
+
+// Money representa um valor monetário em centavos inteiros
+//
+// O valor zero é R$ 0,00. Todas as operações aritméticas verificam overflow
+// e exigem a mesma moeda nos dois operandos.
+type Money struct {
+	cents    int64
+	currency string
+}
+
+// NewMoney cria um valor a partir de centavos
+func NewMoney(cents int64, currency string) Money {
+	return Money{cents: cents, currency: strings.ToUpper(currency)}
+}
+
+// BRL cria um valor em reais a partir de centavos
+func BRL(cents int64) Money {
+	return NewMoney(cents, CurrencyBRL)
+}
+
+// Cents retorna o valor em centavos
+func (m Money) Cents() int64 {
+	return m.cents
+}
+
+// Currency retorna a moeda do valor (BRL por padrão)
+func (m Money) Currency() string {
+	if m.currency == "" {
+		return CurrencyBRL
+	}
+	return m.currency
+}
+
+// IsZero indica se o valor é zero
+func (m Money) IsZero() bool {
+	return m.cents == 0
+}
+
+// IsPositive indica se o valor é maior que zero
+func (m Money) IsPositive() bool {
+	return m.cents > 0
+}
+
+// Cmp compara dois valores: -1 se m < o, 0 se iguais, 1 se m > o
+func (m Money) Cmp(o Money) (int, error) {
+	if m.Currency() != o.Currency() {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.cents < o.cents:
+		return -1, nil
+	case m.cents > o.cents:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Add soma dois valores
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency() != o.Currency() {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if (o.cents > 0 && m.cents > math.MaxInt64-o.cents) ||
+		(o.cents < 0 && m.cents < math.MinInt64-o.cents) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(m.cents+o.cents, m.Currency()), nil
+}
+
+// Sub subtrai o valor o de m
+func (m Money) Sub(o Money) (Money, error) {
+	if o.cents == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return m.Add(NewMoney(-o.cents, o.Currency()))
+}
+
+// Split divide o valor proporcionalmente aos pesos informados
+//
+// A soma das partes é sempre igual ao valor original: os centavos que sobram
+// do arredondamento são distribuídos às partes com maior resto.
+func (m Money) Split(weights ...int64) ([]Money, error) {
+	if len(weights) == 0 {
+		return nil, errors.New("nenhum peso informado")
+	}
+
+	total := new(big.Int)
+	for _, w := range weights {
+		if w < 0 {
+			return nil, errors.New("pesos não podem ser negativos")
+		}
+		total.Add(total, big.NewInt(w))
+	}
+	if total.Sign() == 0 {
+		return nil, errors.New("soma dos pesos deve ser maior que zero")
+	}
+
+	amount := big.NewInt(m.cents)
+	parts := make([]Money, len(weights))
+	remainders := make([]*big.Int, len(weights))
+	allocated := int64(0)
+
+	for i, w := range weights {
+		q, r := new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(w)), total, new(big.Int))
+		parts[i] = NewMoney(q.Int64(), m.Currency())
+		remainders[i] = r.Abs(r)
+		allocated += q.Int64()
+	}
+
+	// Distribuir os centavos restantes pelos maiores restos
+	step := int64(1)
+	if m.cents < 0 {
+		step = -1
+	}
+	for left := m.cents - allocated; left != 0; left -= step {
+		best := 0
+		for i := range remainders {
+			if remainders[i].Cmp(remainders[best]) > 0 {
+				best = i
+			}
+		}
+		parts[best].cents += step
+		remainders[best].SetInt64(-1)
+	}
+
+	return parts, nil
+}
+
+// String formata o valor para exibição (ex: R$ 1.234,56)
+func (m Money) String() string {
+	cents := m.cents
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+	}
+
+	abs := new(big.Int).Abs(big.NewInt(cents))
+	units, frac := new(big.Int).QuoRem(abs, big.NewInt(100), new(big.Int))
+
+	digits := units.String()
+	var grouped strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(d)
+	}
+
+	symbol := m.Currency()
+	if symbol == CurrencyBRL {
+		symbol = "R$"
+	}
+
+	return fmt.Sprintf("%s%s %s,%02d", sign, symbol, grouped.String(), frac.Int64())
+}
+
+// LogValue exibe o valor formatado nos logs estruturados
+func (m Money) LogValue() slog.Value {
+	return slog.StringValue(m.String())
+}
+
+// ParseCents converte um número JSON em centavos sem passar por float64
+func ParseCents(n json.Number, currency string) (Money, error) {
+	cents, err := strconv.ParseInt(n.String(), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("valor em centavos inválido %q: %w", n, err)
+	}
+	return NewMoney(cents, currency), nil
+}
+
+type moneyJSON struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON codifica o valor como {"amount": centavos, "currency": "BRL"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{
+		Amount:   json.Number(strconv.FormatInt(m.cents, 10)),
+		Currency: m.Currency(),
+	})
+}
+
+// UnmarshalJSON aceita o formato objeto ou um número inteiro de centavos em BRL
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '{' {
+		var v moneyJSON
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		parsed, err := ParseCents(v.Amount, v.Currency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+
+	parsed, err := ParseCents(json.Number(data), CurrencyBRL)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
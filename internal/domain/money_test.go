@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMoneyString(t *testing.T) {
+	cases := map[int64]string{
+		0:         "R$ 0,00",
+		5:         "R$ 0,05",
+		12345:     "R$ 123,45",
+		123456789: "R$ 1.234.567,89",
+		-100050:   "-R$ 1.000,50",
+	}
+
+	for cents, expected := range cases {
+		if got := BRL(cents).String(); got != expected {
+			t.Errorf("BRL(%d).String() = %q (esperado %q)", cents, got, expected)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := BRL(10000).Add(BRL(-50))
+	if err != nil || sum.Cents() != 9950 {
+		t.Errorf("soma incorreta: %v, %v", sum, err)
+	}
+
+	if _, err := BRL(math.MaxInt64).Add(BRL(1)); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("esperado overflow na soma, obtido %v", err)
+	}
+	if _, err := BRL(math.MinInt64).Sub(BRL(1)); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("esperado overflow na subtração, obtido %v", err)
+	}
+	if _, err := BRL(100).Add(NewMoney(100, "USD")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("esperado erro de moeda, obtido %v", err)
+	}
+}
+
+func TestMoneySplit(t *testing.T) {
+	parts, err := BRL(100).Split(1, 1, 1)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	var total int64
+	for _, p := range parts {
+		total += p.Cents()
+	}
+	if total != 100 {
+		t.Errorf("partes somam %d (esperado 100)", total)
+	}
+	if parts[0].Cents() != 34 || parts[1].Cents() != 33 || parts[2].Cents() != 33 {
+		t.Errorf("divisão incorreta: %v", parts)
+	}
+
+	parts, _ = BRL(-1000).Split(3, 7)
+	if parts[0].Cents() != -300 || parts[1].Cents() != -700 {
+		t.Errorf("divisão de valor negativo incorreta: %v", parts)
+	}
+
+	if _, err := BRL(100).Split(0, 0); err == nil {
+		t.Error("pesos zerados deveriam retornar erro")
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var m Money
+	if err := json.Unmarshal([]byte("9007199254740993"), &m); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if m.Cents() != 9007199254740993 {
+		t.Errorf("valor perdeu precisão: %d", m.Cents())
+	}
+
+	if err := json.Unmarshal([]byte("10.5"), &m); err == nil {
+		t.Error("valores fracionários deveriam ser rejeitados")
+	}
+
+	encoded, _ := json.Marshal(BRL(12345))
+	var decoded Money
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded != BRL(12345) {
+		t.Errorf("ida e volta em JSON falhou: %s -> %v (%v)", encoded, decoded, err)
+	}
+}
@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tipos de chave Pix
+const (
+	PixKeyCPF   = "cpf"
+	PixKeyCNPJ  = "cnpj"
+	PixKeyEmail = "email"
+	PixKeyPhone = "phone" // +55 seguido de DDD e número
+	PixKeyEVP   = "evp"   // chave aleatória (UUID)
+)
+
+var (
+	evpPattern   = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	phonePattern = regexp.MustCompile(`^\+55[0-9]{10,11}$`)
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// ParsePixKey identifica o tipo da chave e a normaliza no formato do DICT:
+// CPF e CNPJ só com dígitos, e-mail e chave aleatória em minúsculas e
+// telefone como +55DDDNÚMERO. Chaves inválidas retornam ErrInvalidInput.
+func ParsePixKey(key string) (normalized, keyType string, err error) {
+	key = strings.TrimSpace(key)
+	lower := strings.ToLower(key)
+
+	switch {
+	case evpPattern.MatchString(lower):
+		return lower, PixKeyEVP, nil
+	case strings.Contains(key, "@"):
+		if len(lower) > 77 || !emailPattern.MatchString(lower) {
+			break
+		}
+		return lower, PixKeyEmail, nil
+	case strings.HasPrefix(key, "+"):
+		phone := "+" + digits(key)
+		if !phonePattern.MatchString(phone) {
+			break
+		}
+		return phone, PixKeyPhone, nil
+	default:
+		if strings.Trim(key, "0123456789.-/") != "" {
+			break
+		}
+		switch d := digits(key); len(d) {
+		case 11:
+			return d, PixKeyCPF, nil
+		case 14:
+			return d, PixKeyCNPJ, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: chave Pix %q não é CPF, CNPJ, e-mail, telefone (+55...) nem chave aleatória", ErrInvalidInput, key)
+}
+
+func digits(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// MatchesMaskedTaxID indica se o CPF/CNPJ taxID confere com o documento
+// mascarado pelo DICT (ex: ***.456.789-**) nos dígitos visíveis
+func MatchesMaskedTaxID(masked, taxID string) bool {
+	visible := strings.Map(func(r rune) rune {
+		if r == '*' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, masked)
+	taxID = digits(taxID)
+	if len(visible) != len(taxID) {
+		return false
+	}
+	for i := range visible {
+		if visible[i] != '*' && visible[i] != taxID[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// PixKey é uma chave Pix consultada no DICT, com a conta que ela identifica
+type PixKey struct {
+	Key           string `json:"key"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	TaxID         string `json:"tax_id"` // mascarado pelo DICT (ex: ***.456.789-**)
+	OwnerType     string `json:"owner_type"`
+	BankName      string `json:"bank_name"`
+	ISPB          string `json:"ispb"` // código do banco que cria a transferência como Pix
+	BranchCode    string `json:"branch_code"`
+	AccountNumber string `json:"account_number"`
+	AccountType   string `json:"account_type"`
+	Status        string `json:"status"`
+}
+
+// PixKeyRepository consulta chaves Pix no DICT
+type PixKeyRepository interface {
+	Get(ctx context.Context, key string) (*PixKey, error)
+}
+
+// TransferKindBRCode identifica, em uma Transfer, o pagamento de um BR Code
+// feito no lugar da transferência
+const TransferKindBRCode = "brcode_payment"
+
+// BRCodePreview são os dados de um BR Code (QR Code Pix) antes do pagamento
+type BRCodePreview struct {
+	BRCode      string `json:"brcode"`
+	Status      string `json:"status"` // ex: active, paid, canceled
+	Name        string `json:"name"`
+	TaxID       string `json:"tax_id"`
+	BankCode    string `json:"bank_code"`
+	AccountType string `json:"account_type"`
+	AllowChange bool   `json:"allow_change"` // o valor pode ser alterado pelo pagador
+	Amount      Money  `json:"amount"`       // zero em BR Codes estáticos sem valor
+}
+
+// BRCodeInfo resume o que o próprio BR Code informa sobre o pagamento
+type BRCodeInfo struct {
+	Dynamic   bool   // dados e valor consultados em uma URL no lugar da chave
+	SingleUse bool   // ponto de iniciação 12: o código é pago uma única vez
+	Amount    string // valor fixo do código, se houver (campo 54)
+}
+
+// Reusable indica um BR Code estático e sem valor, pagável a cada repasse com
+// um valor diferente
+func (i BRCodeInfo) Reusable() bool {
+	return !i.Dynamic && !i.SingleUse && i.Amount == ""
+}
+
+// ParseBRCode lê os campos de um BR Code estático ou dinâmico; um código
+// malformado retorna ErrInvalidInput
+func ParseBRCode(brcode string) (BRCodeInfo, error) {
+	fields, err := emvFields(brcode)
+	if err != nil {
+		return BRCodeInfo{}, err
+	}
+	if fields["00"] != "01" {
+		return BRCodeInfo{}, fmt.Errorf("%w: não parece um BR Code (deve começar com 000201)", ErrInvalidInput)
+	}
+	info := BRCodeInfo{SingleUse: fields["01"] == "12", Amount: fields["54"]}
+	// Informações da conta do recebedor vão nos campos 26 a 51
+	for id := 26; id <= 51; id++ {
+		account, ok := fields[strconv.Itoa(id)]
+		if !ok {
+			continue
+		}
+		sub, err := emvFields(account)
+		if err != nil {
+			return BRCodeInfo{}, err
+		}
+		if _, ok := sub["25"]; ok {
+			info.Dynamic = true
+		}
+	}
+	return info, nil
+}
+
+// emvFields lê os campos ID-tamanho-valor de um BR Code (padrão EMV)
+func emvFields(value string) (map[string]string, error) {
+	fields := make(map[string]string)
+	runes := []rune(value)
+	for i := 0; i < len(runes); {
+		if i+4 > len(runes) {
+			return nil, fmt.Errorf("%w: BR Code malformado", ErrInvalidInput)
+		}
+		size, err := strconv.Atoi(string(runes[i+2 : i+4]))
+		if err != nil || i+4+size > len(runes) {
+			return nil, fmt.Errorf("%w: BR Code malformado", ErrInvalidInput)
+		}
+		fields[string(runes[i:i+2])] = string(runes[i+4 : i+4+size])
+		i += 4 + size
+	}
+	return fields, nil
+}
+
+// FixedAmount indica um BR Code que só aceita o próprio valor
+func (p BRCodePreview) FixedAmount() bool {
+	return !p.AllowChange && p.Amount.IsPositive()
+}
+
+// BRCodePayment é o pagamento de um BR Code
+type BRCodePayment struct {
+	ID          string     `json:"id"`
+	BRCode      string     `json:"brcode"`
+	TaxID       string     `json:"tax_id"` // CPF/CNPJ do recebedor, conferido pela StarkBank
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Amount      Money      `json:"amount"`
+	Tags        []string   `json:"tags,omitempty"`
+	Status      string     `json:"status"`
+	Fee         Money      `json:"fee"`
+	Created     *time.Time `json:"created,omitempty"`
+}
+
+// BRCodePaymentRepository consulta e paga BR Codes
+type BRCodePaymentRepository interface {
+	Preview(ctx context.Context, brcode string) (*BRCodePreview, error)
+	Create(ctx context.Context, payment BRCodePayment) (*BRCodePayment, error)
+	GetByID(ctx context.Context, id string) (*BRCodePayment, error)
+}
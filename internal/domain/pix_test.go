@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParsePixKey(t *testing.T) {
+	cases := []struct {
+		key, normalized, keyType string
+	}{
+		{"123.456.789-09", "12345678909", PixKeyCPF},
+		{"20.018.183/0001-80", "20018183000180", PixKeyCNPJ},
+		{" Financeiro@Example.com ", "financeiro@example.com", PixKeyEmail},
+		{"+55 (11) 99999-8888", "+5511999998888", PixKeyPhone},
+		{"A629532E-7693-4846-852D-1BBFF817B5A8", "a629532e-7693-4846-852d-1bbff817b5a8", PixKeyEVP},
+	}
+	for _, c := range cases {
+		normalized, keyType, err := ParsePixKey(c.key)
+		if err != nil || normalized != c.normalized || keyType != c.keyType {
+			t.Errorf("ParsePixKey(%q) = %q, %q, %v (esperado %q, %q)", c.key, normalized, keyType, err, c.normalized, c.keyType)
+		}
+	}
+
+	for _, invalid := range []string{"", "12345", "+1 555 0100", "sem-arroba.com", "1234567890a"} {
+		if _, _, err := ParsePixKey(invalid); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ParsePixKey(%q): esperado ErrInvalidInput, obtido %v", invalid, err)
+		}
+	}
+}
+
+func TestMatchesMaskedTaxID(t *testing.T) {
+	if !MatchesMaskedTaxID("***.456.789-**", "123.456.789-09") {
+		t.Errorf("CPF deveria conferir com a máscara")
+	}
+	if !MatchesMaskedTaxID("20.018.183/0001-**", "20018183000180") {
+		t.Errorf("CNPJ deveria conferir com a máscara")
+	}
+	for _, taxID := range []string{"12345600009", "20018183000180", ""} {
+		if MatchesMaskedTaxID("***.456.789-**", taxID) {
+			t.Errorf("%q não deveria conferir com ***.456.789-**", taxID)
+		}
+	}
+}
+
+// emv monta um campo ID-tamanho-valor de BR Code
+func emv(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+func TestParseBRCode(t *testing.T) {
+	key := emv("26", emv("00", "br.gov.bcb.pix")+emv("01", "a629532e-7693-4846-852d-1bbff817b5a8"))
+	url := emv("26", emv("00", "br.gov.bcb.pix")+emv("25", "pix.example.com/qr/v2/9d36b84f"))
+	tail := emv("52", "0000") + emv("53", "986") + emv("58", "BR") + emv("59", "Tesouraria") + emv("60", "Sao Paulo") + emv("63", "ABCD")
+
+	tests := map[string]struct {
+		brcode   string
+		want     BRCodeInfo
+		reusable bool
+	}{
+		"estático sem valor": {emv("00", "01") + key + tail, BRCodeInfo{}, true},
+		"valor fixo":         {emv("00", "01") + key + emv("54", "1000.00") + tail, BRCodeInfo{Amount: "1000.00"}, false},
+		"dinâmico":           {emv("00", "01") + emv("01", "12") + url + tail, BRCodeInfo{Dynamic: true, SingleUse: true}, false},
+		"uso único":          {emv("00", "01") + emv("01", "12") + key + tail, BRCodeInfo{SingleUse: true}, false},
+	}
+	for name, tt := range tests {
+		info, err := ParseBRCode(tt.brcode)
+		if err != nil {
+			t.Fatalf("%s: erro inesperado: %v", name, err)
+		}
+		if info != tt.want || info.Reusable() != tt.reusable {
+			t.Errorf("%s: obtido %+v (reutilizável: %v)", name, info, info.Reusable())
+		}
+	}
+
+	invalid := map[string]string{
+		"malformado":  emv("00", "01") + "2658",
+		"não BR Code": "qualquer coisa",
+	}
+	for name, brcode := range invalid {
+		if _, err := ParseBRCode(brcode); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: esperado ErrInvalidInput, obtido %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPayerHeld indica um pagador com estorno pendente, cujos repasses
+// aguardam a resolução do estorno
+var ErrPayerHeld = errors.New("pagador com estorno pendente")
+
+// Ações de compensação possíveis para um invoice estornado
+const (
+	ReversalActionHoldPayer     = "hold_payer"     // bloqueia novos repasses do pagador
+	ReversalActionRefundRequest = "refund_request" // solicita devolução à conta de destino
+	ReversalActionManualCase    = "manual_case"    // abre um caso para análise manual
+)
+
+// Status de um estorno
+const (
+	ReversalStatusOpen       = "open"
+	ReversalStatusResolved   = "resolved"
+	ReversalStatusNoExposure = "no_exposure" // estorno de invoice que não foi repassado
+)
+
+// Reversal representa o estorno de um invoice já creditado
+type Reversal struct {
+	ID         string
+	InvoiceID  string
+	TransferID string // transferência de repasse original, se houver
+	PayerName  string
+	PayerTaxID string
+	Amount     Money // valor estornado pela StarkBank
+	Exposure   Money // valor já repassado que ficou descoberto
+	Action     string
+	Status     string
+	Note       string
+	Created    time.Time
+	Resolved   *time.Time
+}
+
+// Status de uma solicitação de devolução
+const (
+	RefundStatusRequested = "requested"
+	RefundStatusSettled   = "settled"
+)
+
+// RefundRequest é a solicitação de devolução do valor repassado de um invoice
+// estornado, encerrada quando o estorno é resolvido
+type RefundRequest struct {
+	ID         string
+	ReversalID string
+	InvoiceID  string
+	TransferID string // transferência cujo valor deve ser devolvido
+	Amount     Money
+	Status     string
+	Note       string
+	Requested  time.Time
+	Settled    *time.Time
+}
+
+// PayerHold bloqueia repasses futuros de um pagador
+type PayerHold struct {
+	TaxID      string
+	ReversalID string
+	Created    time.Time
+}
+
+// ReversalRepository define a interface para persistir estornos
+type ReversalRepository interface {
+	Save(reversal Reversal) error
+	GetByID(id string) (*Reversal, error)
+	GetByInvoiceID(invoiceID string) (*Reversal, error)
+	List() ([]Reversal, error)
+}
+
+// RefundRequestRepository define a interface para solicitações de devolução
+type RefundRequestRepository interface {
+	Save(refund RefundRequest) error
+	GetByReversalID(reversalID string) (*RefundRequest, error)
+	List() ([]RefundRequest, error)
+}
+
+// PayerHoldRepository define a interface para bloqueios de pagadores
+type PayerHoldRepository interface {
+	Save(hold PayerHold) error
+	Delete(taxID string) error
+	Get(taxID string) (*PayerHold, error)
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Tipos de transferência agendada
+const (
+	ScheduleOnce      = "once"      // transferência única em data futura, agendada na StarkBank
+	ScheduleRecurring = "recurring" // transferência repetida nos dias e horário da regra
+)
+
+// Status de uma transferência agendada
+const (
+	ScheduleStatusActive   = "active"   // aguardando execução
+	ScheduleStatusDone     = "done"     // transferência única executada
+	ScheduleStatusCanceled = "canceled" // cancelada antes da execução
+)
+
+// ErrScheduleClosed indica um agendamento que não pode mais ser cancelado
+var ErrScheduleClosed = errors.New("agendamento não está ativo")
+
+// BankAccount é uma conta de destino de transferências
+type BankAccount struct {
+	BankCode      string `json:"bank_code"`
+	BranchCode    string `json:"branch_code"`
+	AccountNumber string `json:"account_number"`
+	Name          string `json:"name"`
+	TaxID         string `json:"tax_id"`
+	AccountType   string `json:"account_type,omitempty"`
+}
+
+// ScheduledTransfer é uma transferência futura ou recorrente
+//
+// O valor é fixo (Amount) ou o excedente do saldo acima de KeepBalance, para
+// varreduras (ex: tudo acima de R$ 10.000,00 para a tesouraria).
+type ScheduledTransfer struct {
+	ID          string       `json:"id"`
+	Kind        string       `json:"kind"` // once ou recurring
+	Status      string       `json:"status"`
+	Description string       `json:"description"`
+	Destination *BankAccount `json:"destination,omitempty"`  // ausente: conta de destino do tenant
+	Amount      Money        `json:"amount"`                 // valor fixo; zero com KeepBalance
+	KeepBalance *Money       `json:"keep_balance,omitempty"` // transfere o saldo acima deste valor
+
+	// Recorrência: dias da semana (mon...sun, weekdays ou daily) e horário
+	// HH:MM no fuso do servidor
+	Days []string `json:"days,omitempty"`
+	At   string   `json:"at,omitempty"`
+
+	Next       *time.Time `json:"next,omitempty"` // próxima execução, já em dia útil
+	LastRun    *time.Time `json:"last_run,omitempty"`
+	LastResult string     `json:"last_result,omitempty"`
+	TransferID string     `json:"transfer_id,omitempty"` // última transferência criada
+	Created    time.Time  `json:"created"`
+	CreatedBy  string     `json:"created_by"`
+	Canceled   *time.Time `json:"canceled,omitempty"`
+}
+
+// ScheduledTransferRepository define a interface para os agendamentos
+type ScheduledTransferRepository interface {
+	Save(schedule ScheduledTransfer) error
+	Get(id string) (*ScheduledTransfer, error)
+	List(status string) ([]ScheduledTransfer, error) // vazio lista todos, em ordem de criação
+}
@@ -1,27 +1,34 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Transfer representa uma transferência no domínio da aplicação
 type Transfer struct {
-	ID            string
-	Amount        int
-	BankCode      string
-	BranchCode    string
-	AccountNumber string
-	Name          string
-	TaxID         string
-	AccountType   string
-	Description   string
-	ExternalID    string // ID único para idempotência
-	Status        string
-	Fee           int
-	Created       *time.Time
+	ID            string     `json:"id"`
+	Kind          string     `json:"kind,omitempty"` // vazio: transferência; brcode_payment: pagamento de BR Code
+	Amount        Money      `json:"amount"`
+	BankCode      string     `json:"bank_code"`
+	BranchCode    string     `json:"branch_code"`
+	AccountNumber string     `json:"account_number"`
+	Name          string     `json:"name"`
+	TaxID         string     `json:"tax_id"`
+	AccountType   string     `json:"account_type,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	ExternalID    string     `json:"external_id,omitempty"` // ID único para idempotência
+	Tags          []string   `json:"tags,omitempty"`        // ex: approved-by:<operador>
+	Scheduled     *time.Time `json:"scheduled,omitempty"`   // data de execução agendada na StarkBank
+	Status        string     `json:"status"`
+	Fee           Money      `json:"fee"`
+	Created       *time.Time `json:"created,omitempty"`
 }
 
 // TransferRepository define a interface para operações com transferências
 type TransferRepository interface {
-	Create(transfers []Transfer) ([]Transfer, error)
-	GetByID(id string) (*Transfer, error)
-	List(limit int) ([]Transfer, error)
+	Create(ctx context.Context, transfers []Transfer) ([]Transfer, error)
+	GetByID(ctx context.Context, id string) (*Transfer, error)
+	List(ctx context.Context, limit int) ([]Transfer, error)
+	Cancel(ctx context.Context, id string) (*Transfer, error) // apenas transferências ainda não processadas
 }
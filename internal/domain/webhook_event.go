@@ -1,17 +1,29 @@
 package domain
 
+import "context"
+
 // WebhookEvent representa um evento de webhook
 type WebhookEvent struct {
+	EventID      string
 	Subscription string
 	EventType    string
 	InvoiceID    string
-	Amount       int64
-	Fee          int64
+	Amount       Money
+	Fee          Money
 	Status       string
+	PayerName    string
+	PayerTaxID   string
 }
 
 // WebhookService define a interface para processar webhooks
 type WebhookService interface {
-	ProcessEvent(event WebhookEvent) error
-	ValidateSignature(body, signature string) bool
+	ProcessEvent(ctx context.Context, event WebhookEvent) error
+	// VerifySignature confere o cabeçalho Digital-Signature com o corpo
+	// recebido; devolve ErrInvalidSignature quando a assinatura não confere
+	VerifySignature(ctx context.Context, body, signature string) error
+}
+
+// EventVerifier verifica a assinatura de eventos com as credenciais do tenant
+type EventVerifier interface {
+	Verify(ctx context.Context, content, signature string) error
 }
@@ -0,0 +1,18 @@
+package domain
+
+import "context"
+
+// WebhookSubscription é um webhook cadastrado no projeto da StarkBank
+type WebhookSubscription struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Subscriptions []string `json:"subscriptions"`
+}
+
+// WebhookSubscriptionRepository define a interface para gerenciar os
+// webhooks cadastrados na StarkBank
+type WebhookSubscriptionRepository interface {
+	List(ctx context.Context) ([]WebhookSubscription, error)
+	Create(ctx context.Context, url string, subscriptions []string) (*WebhookSubscription, error)
+	Delete(ctx context.Context, id string) error
+}
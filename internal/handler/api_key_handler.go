@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// APIKeyHandler gerencia as chaves de acesso à API administrativa
+type APIKeyHandler struct {
+	authService *service.AuthService
+}
+
+// NewAPIKeyHandler cria uma nova instância do handler
+func NewAPIKeyHandler(authService *service.AuthService) *APIKeyHandler {
+	return &APIKeyHandler{
+		authService: authService,
+	}
+}
+
+// apiKeyView é a representação pública de uma chave (sem o hash)
+type apiKeyView struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name"`
+	Role    string     `json:"role"`
+	Source  string     `json:"source"`
+	Created time.Time  `json:"created"`
+	Revoked *time.Time `json:"revoked,omitempty"`
+}
+
+func newAPIKeyView(k domain.APIKey) apiKeyView {
+	return apiKeyView{
+		ID:      k.ID,
+		Name:    k.Name,
+		Role:    k.Role,
+		Source:  k.Source,
+		Created: k.Created,
+		Revoked: k.Revoked,
+	}
+}
+
+// Keys lista as chaves (GET) ou cria uma nova chave (POST)
+func (h *APIKeyHandler) Keys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w, r)
+	case http.MethodPost:
+		h.create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *APIKeyHandler) list(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.authService.List()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao listar chaves de API", "error", err)
+		http.Error(w, "Erro ao listar chaves", http.StatusInternalServerError)
+		return
+	}
+
+	views := make([]apiKeyView, len(keys))
+	for i, k := range keys {
+		views[i] = newAPIKeyView(k)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(views)
+}
+
+func (h *APIKeyHandler) create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	plain, key, err := h.authService.Create(r.Context(), req.Name, req.Role)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"key":     plain,
+		"api_key": newAPIKeyView(*key),
+		"warning": "guarde a chave agora: ela não será exibida novamente",
+	})
+}
+
+// Revoke revoga uma chave armazenada
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "Campo 'id' é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.authService.Revoke(r.Context(), req.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.Error(w, "Chave não encontrada", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newAPIKeyView(*key))
+}
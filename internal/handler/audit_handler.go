@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// defaultAuditLimit limita a consulta quando o parâmetro limit não é informado
+const defaultAuditLimit = 100
+
+// AuditHandler gerencia consultas à trilha de auditoria
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler cria uma nova instância do handler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// Entries lista entradas da trilha, filtradas por actor, action, target,
+// from e to (RFC 3339) e limit
+func (h *AuditHandler) Entries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := domain.AuditFilter{
+		Actor:  query.Get("actor"),
+		Tenant: query.Get("tenant"),
+		Action: query.Get("action"),
+		Target: query.Get("target"),
+		Limit:  defaultAuditLimit,
+	}
+
+	var err error
+	if v := query.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "Parâmetro 'from' inválido (use RFC 3339)", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "Parâmetro 'to' inválido (use RFC 3339)", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 0 {
+			http.Error(w, "Parâmetro 'limit' inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := h.auditService.Query(filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar trilha de auditoria", "error", err)
+		http.Error(w, "Erro ao consultar trilha de auditoria", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
+
+// Verify confere o encadeamento da trilha
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := h.auditService.Verify()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao verificar trilha de auditoria", "error", err)
+		http.Error(w, "Erro ao verificar trilha de auditoria", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if !result.Valid {
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -3,18 +3,24 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
-	Balance "github.com/starkbank/sdk-go/starkbank/balance"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
 )
 
 // BalanceHandler gerencia requisições de consulta de saldo
-type BalanceHandler struct{}
+type BalanceHandler struct {
+	balanceService *service.BalanceService
+}
 
 // NewBalanceHandler cria uma nova instância do handler
-func NewBalanceHandler() *BalanceHandler {
-	return &BalanceHandler{}
+func NewBalanceHandler(balanceService *service.BalanceService) *BalanceHandler {
+	return &BalanceHandler{
+		balanceService: balanceService,
+	}
 }
 
 // Handle processa requisições de consulta de saldo
@@ -24,32 +30,36 @@ func (h *BalanceHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Println("💰 Consultando saldo da conta...")
+	slog.DebugContext(r.Context(), "consultando saldo da conta")
 
 	// Buscar saldo na StarkBank
-	balance, err := Balance.Get(nil)
-	if err.Errors != nil {
-		log.Printf("❌ Erro ao consultar saldo: %v\n", err.Errors)
+	balance, err := h.balanceService.Current(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar saldo", "error", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"error":   "Erro ao consultar saldo",
-			"details": err.Errors,
+			"details": err.Error(),
 		})
 		return
 	}
 
-	log.Printf("✅ Saldo consultado com sucesso: R$%.2f\n", float64(balance.Amount)/100)
+	slog.InfoContext(r.Context(), "saldo consultado", "amount", balance.Amount)
 
 	// Preparar resposta
+	updated := ""
+	if balance.Updated != nil {
+		updated = balance.Updated.Format("02/01/2006 15:04:05")
+	}
 	response := map[string]interface{}{
-		"amount":   balance.Amount,
-		"currency": balance.Currency,
+		"amount":   balance.Amount.Cents(),
+		"currency": balance.Amount.Currency(),
 		"updated":  balance.Updated,
 		"formatted": map[string]string{
-			"amount":   fmt.Sprintf("R$ %.2f", float64(balance.Amount)/100),
-			"currency": balance.Currency,
-			"updated":  balance.Updated.Format("02/01/2006 15:04:05"),
+			"amount":   balance.Amount.String(),
+			"currency": balance.Amount.Currency(),
+			"updated":  updated,
 		},
 	}
 
@@ -57,3 +67,108 @@ func (h *BalanceHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// History retorna o histórico de saldos agregado por intervalo
+//
+// Parâmetros: from e to (RFC3339, padrão últimas 24h) e interval
+// (raw, hour, day ou uma duração como 15m; padrão hour).
+func (h *BalanceHandler) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Parâmetro 'from' inválido (use RFC3339)", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if v := query.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Parâmetro 'to' inválido (use RFC3339)", http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+	if !from.Before(to) {
+		http.Error(w, "'from' deve ser anterior a 'to'", http.StatusBadRequest)
+		return
+	}
+
+	interval, err := parseInterval(query.Get("interval"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	points, err := h.balanceService.History(from, to, interval)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar histórico de saldo", "error", err)
+		http.Error(w, "Erro ao consultar histórico", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":     from,
+		"to":       to,
+		"interval": interval.String(),
+		"points":   points,
+	})
+}
+
+// Alerts lista os alertas de saldo disparados
+func (h *BalanceHandler) Alerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "Parâmetro 'limit' inválido", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	alerts, err := h.balanceService.Alerts(limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar alertas de saldo", "error", err)
+		http.Error(w, "Erro ao consultar alertas", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(alerts)
+}
+
+// parseInterval converte o parâmetro interval em duração (0 = sem agregação)
+func parseInterval(value string) (time.Duration, error) {
+	switch value {
+	case "", "hour":
+		return time.Hour, nil
+	case "day":
+		return 24 * time.Hour, nil
+	case "raw":
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("parâmetro 'interval' inválido (use raw, hour, day ou uma duração como 15m)")
+	}
+	return d, nil
+}
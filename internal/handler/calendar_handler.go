@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/calendar"
+)
+
+// CalendarHandler gerencia consultas ao calendário de dias úteis
+type CalendarHandler struct {
+	calendar *calendar.Calendar
+}
+
+// NewCalendarHandler cria uma nova instância do handler
+func NewCalendarHandler(cal *calendar.Calendar) *CalendarHandler {
+	return &CalendarHandler{
+		calendar: cal,
+	}
+}
+
+// Holidays lista os feriados do ano (parâmetro year, padrão o ano atual)
+func (h *CalendarHandler) Holidays(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	year := time.Now().Year()
+	if v := r.URL.Query().Get("year"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1900 || n > 2200 {
+			http.Error(w, "Parâmetro 'year' inválido", http.StatusBadRequest)
+			return
+		}
+		year = n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"year":     year,
+		"cutoff":   h.calendar.Cutoff(),
+		"holidays": h.calendar.Holidays(year),
+	})
+}
+
+// BusinessDay informa se uma data é dia útil e faz a conta de dias úteis
+//
+// Parâmetros: date (AAAA-MM-DD, padrão hoje) e add (dias úteis a somar ou,
+// negativo, subtrair; padrão 1).
+func (h *CalendarHandler) BusinessDay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	date := time.Now()
+	if v := query.Get("date"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			http.Error(w, "Parâmetro 'date' inválido (use AAAA-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		date = t
+	}
+	add := 1
+	if v := query.Get("add"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < -366 || n > 366 {
+			http.Error(w, "Parâmetro 'add' inválido", http.StatusBadRequest)
+			return
+		}
+		add = n
+	}
+
+	holiday, _ := h.calendar.Holiday(date)
+	from, to := h.calendar.Window(date)
+	day := func(t time.Time) string { return t.Format("2006-01-02") }
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"date":          day(date),
+		"business_day":  h.calendar.IsBusinessDay(date),
+		"holiday":       holiday,
+		"next":          day(h.calendar.NextBusinessDay(date)),
+		"previous":      day(h.calendar.PreviousBusinessDay(date)),
+		"add":           add,
+		"result":        day(h.calendar.AddBusinessDays(date, add)),
+		"cutoff_window": map[string]time.Time{"from": from, "to": to},
+	})
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// EventHandler gerencia consultas e reprocessamento de eventos da StarkBank
+type EventHandler struct {
+	eventService *service.EventService
+}
+
+// NewEventHandler cria uma nova instância do handler
+func NewEventHandler(eventService *service.EventService) *EventHandler {
+	return &EventHandler{
+		eventService: eventService,
+	}
+}
+
+// List lista eventos, filtrados por after e before (AAAA-MM-DD),
+// undelivered=true e limit
+func (h *EventHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := queryLimit(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	filter := domain.EventFilter{Limit: limit, Undelivered: query.Get("undelivered") == "true"}
+	for name, target := range map[string]**time.Time{"after": &filter.After, "before": &filter.Before} {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				http.Error(w, "Parâmetro '"+name+"' inválido (use AAAA-MM-DD)", http.StatusBadRequest)
+				return
+			}
+			*target = &t
+		}
+	}
+
+	events, err := h.eventService.List(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar eventos", "error", err)
+		http.Error(w, "Erro ao consultar eventos", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(events)
+}
+
+// Replay reprocessa um evento: {"id": "..."}
+func (h *EventHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "Campo 'id' é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventService.Replay(r.Context(), req.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao reprocessar evento", "event_id", req.ID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(event)
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// ForwardingHandler gerencia consultas à política de repasse e as aprovações
+// de repasses
+type ForwardingHandler struct {
+	forwardingService *service.ForwardingService
+}
+
+// NewForwardingHandler cria uma nova instância do handler
+func NewForwardingHandler(forwardingService *service.ForwardingService) *ForwardingHandler {
+	return &ForwardingHandler{
+		forwardingService: forwardingService,
+	}
+}
+
+// Status retorna a política vigente, o repassado no dia e os créditos pendentes
+func (h *ForwardingHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, err := h.forwardingService.Status(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar política de repasse", "error", err)
+		http.Error(w, "Erro ao consultar política de repasse", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(status)
+}
+
+// Destination consulta o destino dos repasses: a conta da chave Pix no DICT
+// ou o recebedor e o valor do BR Code, para conferência
+func (h *ForwardingHandler) Destination(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	destination, err := h.forwardingService.Destination(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar destino dos repasses", "error", err)
+		http.Error(w, "Erro ao consultar destino dos repasses na StarkBank", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(destination)
+}
+
+// Decisions lista as decisões da política, filtradas por invoice_id, action e limit
+func (h *ForwardingHandler) Decisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := queryLimit(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	filter := domain.ForwardDecisionFilter{
+		InvoiceID: query.Get("invoice_id"),
+		Action:    query.Get("action"),
+		Limit:     limit,
+	}
+
+	decisions, err := h.forwardingService.Decisions(filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar decisões de repasse", "error", err)
+		http.Error(w, "Erro ao consultar decisões de repasse", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(decisions)
+}
+
+// Approvals lista as solicitações de aprovação de repasse; status filtra
+// (pending_approval, approved, rejected, expired) e all lista todas
+func (h *ForwardingHandler) Approvals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = domain.ApprovalStatusPending
+	case "all":
+		status = ""
+	}
+
+	approvals, err := h.forwardingService.Approvals(status)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar solicitações de aprovação", "error", err)
+		http.Error(w, "Erro ao consultar solicitações de aprovação", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(approvals)
+}
+
+// Approve registra a aprovação da chave de API autenticada: {"id": "apr-..."}
+//
+// Com as aprovações necessárias, a transferência é criada; se a criação
+// falhar, responde 502 com a solicitação e o erro registrado. Se o valor não
+// couber no limite diário restante, responde 409 com a solicitação pendente.
+func (h *ForwardingHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "Campo 'id' é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	approval, err := h.forwardingService.Approve(r.Context(), req.ID)
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		http.Error(w, "Solicitação de aprovação não encontrada", http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrApprovalClosed), errors.Is(err, domain.ErrAlreadyApproved):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, domain.ErrApprovalOverCap):
+		status = http.StatusConflict
+	case err != nil && approval == nil:
+		slog.ErrorContext(r.Context(), "erro ao aprovar repasse", "approval_id", req.ID, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "erro ao criar transferência aprovada", "approval_id", req.ID, "error", err)
+		status = http.StatusBadGateway
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(approval)
+}
+
+// Reject encerra uma solicitação sem repasse: {"id": "apr-...", "note": "..."}
+func (h *ForwardingHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID   string `json:"id"`
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "Campo 'id' é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	approval, err := h.forwardingService.Reject(r.Context(), req.ID, req.Note)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		http.Error(w, "Solicitação de aprovação não encontrada", http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrApprovalClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "erro ao rejeitar repasse", "approval_id", req.ID, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(approval)
+}
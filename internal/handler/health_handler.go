@@ -4,31 +4,52 @@ import (
 	"encoding/json"
 	"net/http"
 	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
 )
 
-// HealthHandler gerencia o endpoint de health check
+// HealthHandler gerencia os endpoints de liveness e readiness
 type HealthHandler struct {
-	startTime time.Time
+	healthService *service.HealthService
 }
 
 // NewHealthHandler cria uma nova instância do handler
-func NewHealthHandler() *HealthHandler {
+func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
 	return &HealthHandler{
-		startTime: time.Now(),
+		healthService: healthService,
 	}
 }
 
-// Handle processa requisições de health check
-func (h *HealthHandler) Handle(w http.ResponseWriter, r *http.Request) {
+// Live responde se o processo está de pé (não verifica dependências)
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
 	response := map[string]interface{}{
-		"status":    "healthy",
+		"status":    domain.HealthStatusHealthy,
 		"service":   "starkbank-challenge",
-		"uptime":    time.Since(h.startTime).String(),
+		"uptime":    h.healthService.Uptime().Round(time.Second).String(),
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
 
 	json.NewEncoder(w).Encode(response)
 }
+
+// Ready verifica as dependências e responde com o detalhe de cada uma
+//
+// healthy e degraded respondem 200 (a instância continua recebendo tráfego);
+// unhealthy responde 503.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.healthService.Ready(r.Context())
+
+	code := http.StatusOK
+	if report.Status == domain.HealthStatusUnhealthy {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Health-Status", report.Status)
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// HoldQueueHandler gerencia consultas à fila de repasses retidos
+type HoldQueueHandler struct {
+	holdQueueService *service.HoldQueueService
+}
+
+// NewHoldQueueHandler cria uma nova instância do handler
+func NewHoldQueueHandler(holdQueueService *service.HoldQueueService) *HoldQueueHandler {
+	return &HoldQueueHandler{
+		holdQueueService: holdQueueService,
+	}
+}
+
+// Handle lista os repasses retidos (?status=held|released|canceled|all, padrão held)
+func (h *HoldQueueHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = domain.HeldTransferStatusHeld
+	case "all":
+		status = ""
+	}
+
+	held, err := h.holdQueueService.List(status)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar fila de retenção", "error", err)
+		http.Error(w, "Erro ao consultar fila de retenção", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(held)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// Limites das consultas repassadas à StarkBank
+const (
+	defaultQueryLimit = 20
+	maxQueryLimit     = 100
+)
+
+// InvoiceHandler gerencia consultas e emissão de invoices
+type InvoiceHandler struct {
+	invoiceService *service.InvoiceService
+}
+
+// NewInvoiceHandler cria uma nova instância do handler
+func NewInvoiceHandler(invoiceService *service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+	}
+}
+
+// List lista os invoices mais recentes (?limit=, padrão 20, máximo 100) ou
+// um invoice (?id=)
+func (h *InvoiceHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var result interface{}
+	var err error
+	if id := r.URL.Query().Get("id"); id != "" {
+		result, err = h.invoiceService.GetByID(r.Context(), id)
+	} else {
+		limit, ok := queryLimit(w, r)
+		if !ok {
+			return
+		}
+		result, err = h.invoiceService.List(r.Context(), limit)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar invoices", "error", err)
+		http.Error(w, "Erro ao consultar invoices", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// Create emite invoices: {"invoices": [{"amount": 10000, "name": "...",
+// "tax_id": "..."}]} ou {"count": 5} para um lote aleatório
+func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Invoices []domain.Invoice `json:"invoices"`
+		Count    int              `json:"count"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	var created []domain.Invoice
+	var err error
+	switch {
+	case req.Count > 0 && len(req.Invoices) > 0:
+		http.Error(w, "Informe 'invoices' ou 'count', não ambos", http.StatusBadRequest)
+		return
+	case req.Count > maxQueryLimit:
+		http.Error(w, "Campo 'count' deve ser no máximo 100", http.StatusBadRequest)
+		return
+	case req.Count > 0:
+		created, err = h.invoiceService.GenerateRandomInvoices(r.Context(), req.Count)
+	default:
+		created, err = h.invoiceService.Create(r.Context(), req.Invoices)
+	}
+	if errors.Is(err, domain.ErrInvalidInput) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao emitir invoices", "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// queryLimit lê o parâmetro limit; em caso de erro já responde 400
+func queryLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return defaultQueryLimit, true
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > maxQueryLimit {
+		http.Error(w, "Parâmetro 'limit' inválido (1 a 100)", http.StatusBadRequest)
+		return 0, false
+	}
+	return limit, true
+}
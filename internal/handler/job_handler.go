@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// JobHandler lista e dispara os jobs em background de um tenant
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+// NewJobHandler cria uma nova instância do handler
+func NewJobHandler(jobService *service.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// List lista os jobs disponíveis e suas últimas execuções manuais
+func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.jobService.List())
+}
+
+// Run executa um job e aguarda o resultado: {"job": "hold-release"}
+func (h *JobHandler) Run(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Job string `json:"job"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Job == "" {
+		http.Error(w, "Campo 'job' é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.jobService.Run(r.Context(), req.Job)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrJobRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if run.Error != "" {
+		status = http.StatusBadGateway
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(run)
+}
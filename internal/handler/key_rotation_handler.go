@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// KeyRotationHandler expõe as etapas da rotação da chave privada da StarkBank
+type KeyRotationHandler struct {
+	rotationService *service.KeyRotationService
+}
+
+// NewKeyRotationHandler cria uma nova instância do handler
+func NewKeyRotationHandler(rotationService *service.KeyRotationService) *KeyRotationHandler {
+	return &KeyRotationHandler{
+		rotationService: rotationService,
+	}
+}
+
+// keyRotationView é a representação pública de uma rotação (sem chaves privadas)
+type keyRotationView struct {
+	ID             string     `json:"id"`
+	Status         string     `json:"status"`
+	PublicKey      string     `json:"public_key,omitempty"`
+	NewFingerprint string     `json:"new_fingerprint"`
+	OldFingerprint string     `json:"old_fingerprint"`
+	Persisted      bool       `json:"persisted"`
+	Created        time.Time  `json:"created"`
+	Switched       *time.Time `json:"switched,omitempty"`
+	Retired        *time.Time `json:"retired,omitempty"`
+	Cancelled      *time.Time `json:"cancelled,omitempty"`
+}
+
+func newKeyRotationView(r domain.KeyRotation) keyRotationView {
+	view := keyRotationView{
+		ID:             r.ID,
+		Status:         r.Status,
+		NewFingerprint: r.NewFingerprint,
+		OldFingerprint: r.OldFingerprint,
+		Persisted:      r.Persisted,
+		Created:        r.Created,
+		Switched:       r.Switched,
+		Retired:        r.Retired,
+		Cancelled:      r.Cancelled,
+	}
+	if r.Status == domain.KeyRotationPending {
+		view.PublicKey = r.PublicKey // necessária apenas até o cadastro no painel
+	}
+	return view
+}
+
+// Rotation consulta a rotação atual (GET) ou inicia uma nova (POST)
+func (h *KeyRotationHandler) Rotation(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.status(w, r)
+	case http.MethodPost:
+		h.step(w, r, http.StatusCreated, h.rotationService.Start)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Confirm troca a assinatura para a nova chave
+func (h *KeyRotationHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	h.post(w, r, h.rotationService.Confirm)
+}
+
+// Rollback volta a assinar com a chave anterior
+func (h *KeyRotationHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	h.post(w, r, h.rotationService.Rollback)
+}
+
+// Retire descarta a chave anterior
+func (h *KeyRotationHandler) Retire(w http.ResponseWriter, r *http.Request) {
+	h.post(w, r, h.rotationService.Retire)
+}
+
+// Cancel descarta a nova chave antes da troca
+func (h *KeyRotationHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.post(w, r, h.rotationService.Cancel)
+}
+
+func (h *KeyRotationHandler) status(w http.ResponseWriter, r *http.Request) {
+	rotation, err := h.rotationService.Latest()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar rotação de chave", "error", err)
+		http.Error(w, "Erro ao consultar rotação de chave", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"active_fingerprint": h.rotationService.ActiveFingerprint(),
+		"loaded_keys":        h.rotationService.LoadedFingerprints(),
+	}
+	if rotation != nil {
+		response["rotation"] = newKeyRotationView(*rotation)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+type rotationStep func(ctx context.Context) (*domain.KeyRotation, error)
+
+func (h *KeyRotationHandler) post(w http.ResponseWriter, r *http.Request, step rotationStep) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.step(w, r, http.StatusOK, step)
+}
+
+func (h *KeyRotationHandler) step(w http.ResponseWriter, r *http.Request, status int, step rotationStep) {
+	rotation, err := step(r.Context())
+	switch {
+	case errors.Is(err, domain.ErrKeyRotationState):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, domain.ErrCredentialsRejected):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "erro na rotação de chave", "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(newKeyRotationView(*rotation))
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/calendar"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// LedgerHandler gerencia consultas ao razão interno
+type LedgerHandler struct {
+	ledgerService *service.LedgerService
+	calendar      *calendar.Calendar
+}
+
+// NewLedgerHandler cria uma nova instância do handler
+func NewLedgerHandler(ledgerService *service.LedgerService, cal *calendar.Calendar) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+		calendar:      cal,
+	}
+}
+
+// Entries lista lançamentos, opcionalmente filtrados por invoice_id,
+// transfer_id ou business_day (AAAA-MM-DD: do corte do dia útil anterior ao
+// corte do dia)
+func (h *LedgerHandler) Entries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var (
+		entries []domain.LedgerEntry
+		err     error
+	)
+
+	query := r.URL.Query()
+	switch {
+	case query.Get("invoice_id") != "":
+		entries, err = h.ledgerService.EntriesByInvoice(query.Get("invoice_id"))
+	case query.Get("transfer_id") != "":
+		entries, err = h.ledgerService.EntriesByTransfer(query.Get("transfer_id"))
+	case query.Get("business_day") != "":
+		day, parseErr := time.ParseInLocation("2006-01-02", query.Get("business_day"), time.Local)
+		if parseErr != nil {
+			http.Error(w, "Parâmetro 'business_day' inválido (use AAAA-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		from, to := h.calendar.Window(day)
+		entries, err = h.ledgerService.EntriesBetween(from, to)
+	default:
+		entries, err = h.ledgerService.Entries()
+	}
+
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar razão", "error", err)
+		http.Error(w, "Erro ao consultar razão", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
+
+// Verify verifica se todos os créditos estão cobertos por repasses mais taxas
+func (h *LedgerHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := h.ledgerService.Verify()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao verificar razão", "error", err)
+		http.Error(w, "Erro ao verificar razão", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if !result.Balanced {
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// ReversalHandler gerencia requisições de relatório e resolução de estornos
+type ReversalHandler struct {
+	reversalService *service.ReversalService
+}
+
+// NewReversalHandler cria uma nova instância do handler
+func NewReversalHandler(reversalService *service.ReversalService) *ReversalHandler {
+	return &ReversalHandler{
+		reversalService: reversalService,
+	}
+}
+
+// Report retorna os estornos registrados e a exposição em aberto
+func (h *ReversalHandler) Report(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, err := h.reversalService.Report()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao gerar relatório de estornos", "error", err)
+		http.Error(w, "Erro ao gerar relatório", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// Resolve encerra um estorno aberto
+func (h *ReversalHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID   string `json:"id"`
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "Campo 'id' é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	reversal, err := h.reversalService.Resolve(r.Context(), req.ID, req.Note)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.Error(w, "Estorno não encontrado", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao resolver estorno", "error", err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reversal)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// TransferHandler gerencia consultas de transferências
+type TransferHandler struct {
+	transferService *service.TransferService
+}
+
+// NewTransferHandler cria uma nova instância do handler
+func NewTransferHandler(transferService *service.TransferService) *TransferHandler {
+	return &TransferHandler{
+		transferService: transferService,
+	}
+}
+
+// List lista as transferências mais recentes (?limit=, padrão 20, máximo
+// 100) ou uma transferência (?id=)
+func (h *TransferHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var result interface{}
+	var err error
+	if id := r.URL.Query().Get("id"); id != "" {
+		result, err = h.transferService.GetByID(r.Context(), id)
+	} else {
+		limit, ok := queryLimit(w, r)
+		if !ok {
+			return
+		}
+		result, err = h.transferService.List(r.Context(), limit)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar transferências", "error", err)
+		http.Error(w, "Erro ao consultar transferências", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
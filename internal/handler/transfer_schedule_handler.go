@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// TransferScheduleHandler gerencia transferências agendadas e recorrentes
+type TransferScheduleHandler struct {
+	scheduleService *service.TransferScheduleService
+}
+
+// NewTransferScheduleHandler cria uma nova instância do handler
+func NewTransferScheduleHandler(scheduleService *service.TransferScheduleService) *TransferScheduleHandler {
+	return &TransferScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// List lista os agendamentos; status filtra (active, done, canceled) e all
+// lista todos
+func (h *TransferScheduleHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = domain.ScheduleStatusActive
+	case "all":
+		status = ""
+	}
+
+	schedules, err := h.scheduleService.List(status)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "erro ao consultar agendamentos", "error", err)
+		http.Error(w, "Erro ao consultar agendamentos", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedules)
+}
+
+// Create agenda uma transferência (valores em centavos)
+//
+//	{"date": "2024-07-01", "amount": 50000}
+//	{"days": ["weekdays"], "at": "17:00", "keep_balance": 1000000, "destination": {...}}
+func (h *TransferScheduleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Description string              `json:"description"`
+		Date        string              `json:"date"` // AAAA-MM-DD ou RFC3339; ausente para recorrentes
+		Days        []string            `json:"days"`
+		At          string              `json:"at"`
+		Amount      int64               `json:"amount"`
+		KeepBalance *int64              `json:"keep_balance"`
+		Destination *domain.BankAccount `json:"destination"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	schedule := domain.ScheduledTransfer{
+		Kind:        domain.ScheduleRecurring,
+		Description: req.Description,
+		Destination: req.Destination,
+		Amount:      domain.BRL(req.Amount),
+		Days:        req.Days,
+		At:          req.At,
+	}
+	if req.KeepBalance != nil {
+		keep := domain.BRL(*req.KeepBalance)
+		schedule.KeepBalance = &keep
+	}
+	if req.Date != "" {
+		date, err := parseDate(req.Date, h.scheduleService.Location())
+		if err != nil {
+			http.Error(w, "Campo 'date' inválido (use AAAA-MM-DD ou RFC3339)", http.StatusBadRequest)
+			return
+		}
+		schedule.Kind = domain.ScheduleOnce
+		schedule.Next = &date
+	}
+
+	created, err := h.scheduleService.Schedule(r.Context(), schedule)
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "erro ao agendar transferência", "error", err)
+		http.Error(w, "Erro ao agendar transferência: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// Cancel cancela um agendamento ativo: {"id": "sch-..."}
+func (h *TransferScheduleHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "Campo 'id' é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.scheduleService.Cancel(r.Context(), req.ID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		http.Error(w, "Agendamento não encontrado", http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrScheduleClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "erro ao cancelar agendamento", "schedule_id", req.ID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// parseDate aceita uma data (meia-noite no fuso dos agendamentos) ou um RFC3339
+func parseDate(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, location); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -1,13 +1,23 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
 )
 
 // WebhookHandler gerencia requisições de webhook
@@ -29,39 +39,59 @@ func (h *WebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Println("📨 Webhook recebido!")
+	// Continua o trace do chamador, se houver cabeçalho traceparent
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := tracing.Start(ctx, "WebhookHandler.Handle")
+	defer span.End()
+
+	slog.InfoContext(ctx, "webhook recebido")
 
 	// Ler o corpo da requisição
 	body, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		slog.WarnContext(ctx, "corpo do webhook excede o limite", "limit_bytes", tooLarge.Limit)
+		metrics.WebhookEvents.Inc("unknown", "unknown", "invalid")
+		http.Error(w, "Corpo da requisição muito grande", http.StatusRequestEntityTooLarge)
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Erro ao ler corpo da requisição: %v\n", err)
+		slog.ErrorContext(ctx, "erro ao ler corpo da requisição", "error", err)
 		http.Error(w, "Erro ao ler requisição", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
 
-	// Obter a assinatura digital do header
-	signature := r.Header.Get("Digital-Signature")
-
-	// Validar assinatura (em produção, deve rejeitar sem assinatura válida)
-	if signature == "" {
-		log.Println("⚠️  Webhook sem assinatura digital")
+	// Rejeitar eventos cuja assinatura não confere com a chave da StarkBank
+	if err := h.verify(ctx, body, r.Header.Get("Digital-Signature")); err != nil {
+		span.SetStatus(codes.Error, "assinatura não verificada")
+		rejectSignature(ctx, w, err)
+		return
 	}
 
 	// Parse do evento
-	event, err := h.parseEvent(body)
+	_, parseSpan := tracing.Start(ctx, "WebhookHandler.parseEvent")
+	event, err := h.parseEvent(ctx, body)
+	tracing.End(parseSpan, err)
 	if err != nil {
-		log.Printf("❌ Erro ao fazer parse do evento: %v\n", err)
+		span.SetStatus(codes.Error, "evento inválido")
+		slog.ErrorContext(ctx, "erro ao fazer parse do evento", "error", err)
+		metrics.WebhookEvents.Inc("unknown", "unknown", "invalid")
 		http.Error(w, "Evento inválido", http.StatusBadRequest)
 		return
 	}
 
 	// Processar o evento
-	if err := h.webhookService.ProcessEvent(*event); err != nil {
-		log.Printf("❌ Erro ao processar evento: %v\n", err)
+	ctx = logging.WithEventID(ctx, event.EventID)
+	ctx = domain.ContextWithActor(ctx, domain.ActorWebhook)
+	if err := h.webhookService.ProcessEvent(ctx, *event); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(ctx, "erro ao processar evento", "error", err)
+		metrics.ObserveWebhookEvent(event.Subscription, event.EventType, "error")
 		http.Error(w, "Erro ao processar evento", http.StatusInternalServerError)
 		return
 	}
+	metrics.ObserveWebhookEvent(event.Subscription, event.EventType, "processed")
 
 	// Responder com 200 OK
 	w.WriteHeader(http.StatusOK)
@@ -71,13 +101,35 @@ func (h *WebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// verify confere a assinatura do corpo com as credenciais do tenant
+func (h *WebhookHandler) verify(ctx context.Context, body []byte, signature string) error {
+	return h.webhookService.VerifySignature(ctx, string(body), signature)
+}
+
+// rejectSignature responde a um webhook cuja assinatura não foi confirmada:
+// 401 quando ela não confere e 503 quando a verificação falhou (ex: chave
+// pública indisponível), para que a StarkBank reenvie o evento
+func rejectSignature(ctx context.Context, w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrInvalidSignature) {
+		slog.WarnContext(ctx, "webhook rejeitado: assinatura inválida", "error", err)
+		metrics.WebhookEvents.Inc("unknown", "unknown", "invalid")
+		http.Error(w, "Assinatura inválida", http.StatusUnauthorized)
+		return
+	}
+	slog.ErrorContext(ctx, "erro ao verificar assinatura do webhook", "error", err)
+	metrics.WebhookEvents.Inc("unknown", "unknown", "error")
+	http.Error(w, "Não foi possível verificar a assinatura", http.StatusServiceUnavailable)
+}
+
 // parseEvent faz o parse do JSON do webhook para domain.WebhookEvent
-func (h *WebhookHandler) parseEvent(body []byte) (*domain.WebhookEvent, error) {
-	// LOG DETALHADO PARA DEBUG
-	log.Printf("🔍 Body recebido: %s\n", string(body))
+func (h *WebhookHandler) parseEvent(ctx context.Context, body []byte) (*domain.WebhookEvent, error) {
+	slog.DebugContext(ctx, "corpo do webhook recebido", "size_bytes", len(body))
 
 	var eventData map[string]interface{}
-	if err := json.Unmarshal(body, &eventData); err != nil {
+	// UseNumber preserva os valores em centavos sem passar por float64
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&eventData); err != nil {
 		return nil, err
 	}
 
@@ -85,20 +137,22 @@ func (h *WebhookHandler) parseEvent(body []byte) (*domain.WebhookEvent, error) {
 	var eventLog map[string]interface{}
 	if wrapper, ok := eventData["event"].(map[string]interface{}); ok {
 		eventLog = wrapper
-		log.Println("✅ Formato com wrapper 'event' detectado")
+		slog.DebugContext(ctx, "formato com wrapper 'event' detectado")
 	} else {
 		// Formato direto (nosso teste)
 		eventLog = eventData
-		log.Println("✅ Formato direto detectado")
+		slog.DebugContext(ctx, "formato direto detectado")
 	}
 
+	eventID, _ := eventLog["id"].(string)
 	subscription, _ := eventLog["subscription"].(string)
-	log.Printf("📋 Subscription: %s\n", subscription)
+	slog.DebugContext(ctx, "subscription do evento", "subscription", subscription)
 
 	// FILTRAR: Processar apenas webhooks de invoice
 	if subscription != "invoice" {
-		log.Printf("⏭️  Webhook ignorado: subscription=%s (esperado: invoice)\n", subscription)
+		slog.InfoContext(ctx, "webhook ignorado", "subscription", subscription, "expected", "invoice")
 		return &domain.WebhookEvent{
+			EventID:      eventID,
 			Subscription: subscription,
 		}, nil
 	}
@@ -106,34 +160,62 @@ func (h *WebhookHandler) parseEvent(body []byte) (*domain.WebhookEvent, error) {
 	// Extrair o log do evento
 	logData, ok := eventLog["log"].(map[string]interface{})
 	if !ok {
-		log.Printf("❌ Campo 'log' não encontrado ou inválido. Dados: %+v\n", eventLog)
 		return nil, fmt.Errorf("campo 'log' não encontrado no webhook")
 	}
 
 	eventType, _ := logData["type"].(string)
-	log.Printf("📋 Event Type: %s\n", eventType)
 
 	// Extrair dados do invoice
 	invoiceData, ok := logData["invoice"].(map[string]interface{})
 	if !ok {
-		log.Printf("❌ Campo 'invoice' não encontrado. LogData: %+v\n", logData)
 		return nil, fmt.Errorf("campo 'invoice' não encontrado no log do webhook")
 	}
 
 	invoiceID, _ := invoiceData["id"].(string)
 	status, _ := invoiceData["status"].(string)
-	amount, _ := invoiceData["amount"].(float64)
-	fee, _ := invoiceData["fee"].(float64)
+	amount, err := parseMoneyField(invoiceData, "amount")
+	if err != nil {
+		return nil, err
+	}
+	fee, err := parseMoneyField(invoiceData, "fee")
+	if err != nil {
+		return nil, err
+	}
+	payerName, _ := invoiceData["name"].(string)
+	payerTaxID, _ := invoiceData["taxId"].(string)
 
-	log.Printf("💰 Invoice: ID=%s, Amount=%.2f, Fee=%.2f, Status=%s\n",
-		invoiceID, amount/100, fee/100, status)
+	slog.InfoContext(ctx, "evento de invoice recebido",
+		"event_type", eventType,
+		"invoice_id", invoiceID,
+		"amount", amount,
+		"fee", fee,
+		"status", status,
+	)
 
 	return &domain.WebhookEvent{
+		EventID:      eventID,
 		Subscription: subscription,
 		EventType:    eventType,
 		InvoiceID:    invoiceID,
-		Amount:       int64(amount),
-		Fee:          int64(fee),
+		Amount:       amount,
+		Fee:          fee,
 		Status:       status,
+		PayerName:    payerName,
+		PayerTaxID:   payerTaxID,
 	}, nil
 }
+
+// parseMoneyField lê um campo em centavos do payload (ausente equivale a zero)
+func parseMoneyField(data map[string]interface{}, field string) (domain.Money, error) {
+	raw, ok := data[field]
+	if !ok || raw == nil {
+		return domain.BRL(0), nil
+	}
+
+	number, ok := raw.(json.Number)
+	if !ok {
+		return domain.Money{}, fmt.Errorf("campo '%s' não é numérico", field)
+	}
+
+	return domain.ParseCents(number, domain.CurrencyBRL)
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
+)
+
+// WebhookRouter encaminha webhooks ao handler do tenant correspondente
+//
+// Em /webhook/<tenant> o tenant vem do caminho. Em /webhook ele é
+// identificado pelo workspaceId do evento, lido só depois de a assinatura ser
+// confirmada com as credenciais de algum tenant; com um único tenant, todos
+// os eventos vão para ele. O handler do tenant verifica a assinatura de novo.
+type WebhookRouter struct {
+	handlers   map[string]*WebhookHandler
+	workspaces map[string]string // workspaceId → tenant
+	order      []string
+}
+
+// NewWebhookRouter cria um roteador sem tenants
+func NewWebhookRouter() *WebhookRouter {
+	return &WebhookRouter{
+		handlers:   make(map[string]*WebhookHandler),
+		workspaces: make(map[string]string),
+	}
+}
+
+// Add registra o handler de um tenant; workspaceID pode ser vazio quando o
+// tenant só recebe webhooks pelo caminho
+func (rt *WebhookRouter) Add(tenant, workspaceID string, h *WebhookHandler) {
+	rt.handlers[tenant] = h
+	rt.order = append(rt.order, tenant)
+	if workspaceID != "" {
+		rt.workspaces[workspaceID] = tenant
+	}
+}
+
+// Tenant retorna o handler de /webhook/<tenant>
+func (rt *WebhookRouter) Tenant(tenant string) http.HandlerFunc {
+	h := rt.handlers[tenant]
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.Handle(w, r.WithContext(logging.WithTenant(r.Context(), tenant)))
+	}
+}
+
+// Handle processa /webhook identificando o tenant pelo evento
+func (rt *WebhookRouter) Handle(w http.ResponseWriter, r *http.Request) {
+	if len(rt.order) == 1 {
+		rt.Tenant(rt.order[0])(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	body, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		slog.WarnContext(ctx, "corpo do webhook excede o limite", "limit_bytes", tooLarge.Limit)
+		metrics.WebhookEvents.Inc("unknown", "unknown", "invalid")
+		http.Error(w, "Corpo da requisição muito grande", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao ler corpo da requisição", "error", err)
+		http.Error(w, "Erro ao ler requisição", http.StatusBadRequest)
+		return
+	}
+
+	// O workspaceId só é confiável depois de verificada a assinatura
+	if err := rt.verify(ctx, body, r.Header.Get("Digital-Signature")); err != nil {
+		rejectSignature(ctx, w, err)
+		return
+	}
+
+	workspaceID := eventWorkspaceID(body)
+	tenant, ok := rt.workspaces[workspaceID]
+	if !ok {
+		slog.WarnContext(ctx, "webhook de workspace sem tenant configurado", "workspace_id", workspaceID)
+		metrics.WebhookEvents.Inc("unknown", "unknown", "invalid")
+		http.Error(w, "Tenant não encontrado para o workspace do evento", http.StatusNotFound)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	rt.Tenant(tenant)(w, r)
+}
+
+// verify confere a assinatura com as credenciais de cada tenant, na ordem de
+// registro; basta um tenant confirmar. Falhas que não sejam de assinatura
+// inválida são devolvidas para que a StarkBank reenvie o evento
+func (rt *WebhookRouter) verify(ctx context.Context, body []byte, signature string) error {
+	var failure error
+	for _, tenant := range rt.order {
+		err := rt.handlers[tenant].verify(ctx, body, signature)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, domain.ErrInvalidSignature) {
+			failure = err
+		}
+	}
+	if failure != nil {
+		return failure
+	}
+	return domain.ErrInvalidSignature
+}
+
+// eventWorkspaceID lê o workspaceId do evento (com ou sem o wrapper "event")
+func eventWorkspaceID(body []byte) string {
+	var payload struct {
+		WorkspaceID string `json:"workspaceId"`
+		Event       struct {
+			WorkspaceID string `json:"workspaceId"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	if payload.Event.WorkspaceID != "" {
+		return payload.Event.WorkspaceID
+	}
+	return payload.WorkspaceID
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// stubWebhookService aceita apenas a assinatura configurada
+type stubWebhookService struct {
+	signature string
+	err       error
+	processed []string
+}
+
+func (s *stubWebhookService) ProcessEvent(ctx context.Context, event domain.WebhookEvent) error {
+	s.processed = append(s.processed, event.EventID)
+	return nil
+}
+
+func (s *stubWebhookService) VerifySignature(ctx context.Context, body, signature string) error {
+	if s.err != nil {
+		return s.err
+	}
+	if signature != s.signature {
+		return domain.ErrInvalidSignature
+	}
+	return nil
+}
+
+const routedEvent = `{"event":{"id":"evt-1","workspaceId":"ws-b","subscription":"transfer"}}`
+
+func postWebhook(rt *WebhookRouter, signature string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(routedEvent))
+	if signature != "" {
+		req.Header.Set("Digital-Signature", signature)
+	}
+	rec := httptest.NewRecorder()
+	rt.Handle(rec, req)
+	return rec.Code
+}
+
+func TestWebhookRouterVerifiesBeforeRouting(t *testing.T) {
+	a := &stubWebhookService{signature: "assinada"}
+	b := &stubWebhookService{signature: "assinada"}
+	rt := NewWebhookRouter()
+	rt.Add("a", "ws-a", NewWebhookHandler(a))
+	rt.Add("b", "ws-b", NewWebhookHandler(b))
+
+	for _, signature := range []string{"", "forjada"} {
+		if code := postWebhook(rt, signature); code != http.StatusUnauthorized {
+			t.Fatalf("assinatura %q: esperado 401, obtido %d", signature, code)
+		}
+	}
+	if len(a.processed)+len(b.processed) != 0 {
+		t.Fatal("evento sem assinatura válida não deveria ser processado")
+	}
+
+	if code := postWebhook(rt, "assinada"); code != http.StatusOK {
+		t.Fatalf("esperado 200, obtido %d", code)
+	}
+	if len(a.processed) != 0 || len(b.processed) != 1 {
+		t.Fatalf("evento deveria ir só para o tenant do workspace: a=%v b=%v", a.processed, b.processed)
+	}
+}
+
+func TestWebhookRouterVerificationFailure(t *testing.T) {
+	a := &stubWebhookService{err: errors.New("chave pública indisponível")}
+	b := &stubWebhookService{signature: "outra"}
+	rt := NewWebhookRouter()
+	rt.Add("a", "ws-a", NewWebhookHandler(a))
+	rt.Add("b", "ws-b", NewWebhookHandler(b))
+
+	// Sem confirmação e com falha de verificação, a StarkBank deve reenviar
+	if code := postWebhook(rt, "assinada"); code != http.StatusServiceUnavailable {
+		t.Fatalf("esperado 503, obtido %d", code)
+	}
+	if len(b.processed) != 0 {
+		t.Fatal("evento não verificado não deveria ser processado")
+	}
+}
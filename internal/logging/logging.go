@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	eventIDKey
+	tenantKey
+)
+
+// New cria um logger JSON com o nível informado, IDs de correlação e redação de PII
+//
+// Com um *slog.LevelVar o nível pode ser alterado com o processo em execução.
+func New(level slog.Leveler, w io.Writer) *slog.Logger {
+	var handler slog.Handler = slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	})
+	handler = &contextHandler{Handler: handler}
+	return slog.New(handler)
+}
+
+// ParseLevel converte debug, info, warn ou error em slog.Level
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(value) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("nível de log inválido: %q (use debug, info, warn ou error)", value)
+}
+
+// WithRequestID associa o ID da requisição HTTP ao contexto
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID retorna o ID da requisição HTTP do contexto
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithEventID associa o ID do evento StarkBank ao contexto
+func WithEventID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, eventIDKey, id)
+}
+
+// EventID retorna o ID do evento StarkBank do contexto
+func EventID(ctx context.Context) string {
+	id, _ := ctx.Value(eventIDKey).(string)
+	return id
+}
+
+// WithTenant associa o tenant (projeto da StarkBank) ao contexto
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey, id)
+}
+
+// Tenant retorna o tenant do contexto
+func Tenant(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey).(string)
+	return id
+}
+
+// contextHandler inclui os IDs de correlação e de trace do contexto em cada linha
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if id := EventID(ctx); id != "" {
+			r.AddAttrs(slog.String("event_id", id))
+		}
+		if id := Tenant(ctx); id != "" {
+			r.AddAttrs(slog.String("tenant", id))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(
+				slog.String("trace_id", sc.TraceID().String()),
+				slog.String("span_id", sc.SpanID().String()),
+			)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Atributos cujo valor é sempre mascarado
+var sensitiveKeys = map[string]func(string) string{
+	"tax_id":         MaskTaxID,
+	"payer_tax_id":   MaskTaxID,
+	"name":           MaskName,
+	"payer_name":     MaskName,
+	"account_number": MaskAccount,
+}
+
+// CPFs e CNPJs, formatados ou não, que aparecem no meio de textos
+var taxIDPattern = regexp.MustCompile(
+	`\b\d{2}\.\d{3}\.\d{3}/\d{4}-\d{2}\b|\b\d{3}\.\d{3}\.\d{3}-\d{2}\b|\b\d{14}\b|\b\d{11}\b`,
+)
+
+// redactAttr mascara dados pessoais antes da escrita do log
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindString {
+		return a
+	}
+
+	if mask, ok := sensitiveKeys[strings.ToLower(a.Key)]; ok {
+		return slog.String(a.Key, mask(a.Value.String()))
+	}
+
+	value := a.Value.String()
+	if taxIDPattern.MatchString(value) {
+		return slog.String(a.Key, taxIDPattern.ReplaceAllStringFunc(value, MaskTaxID))
+	}
+
+	return a
+}
+
+// MaskTaxID mascara um CPF/CNPJ mantendo apenas os dois últimos dígitos
+func MaskTaxID(taxID string) string {
+	return maskKeepLast(taxID, 2)
+}
+
+// MaskAccount mascara um número de conta mantendo os quatro últimos dígitos
+func MaskAccount(account string) string {
+	return maskKeepLast(account, 4)
+}
+
+// MaskName mantém apenas a inicial de cada parte do nome (ex: J*** S****)
+func MaskName(name string) string {
+	parts := strings.Fields(name)
+	for i, p := range parts {
+		runes := []rune(p)
+		parts[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(parts, " ")
+}
+
+// maskKeepLast substitui os dígitos por '*', exceto os n últimos
+func maskKeepLast(value string, n int) string {
+	digits := 0
+	for _, c := range value {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+
+	var b strings.Builder
+	seen := 0
+	for _, c := range value {
+		if c >= '0' && c <= '9' {
+			seen++
+			if seen <= digits-n {
+				b.WriteByte('*')
+				continue
+			}
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
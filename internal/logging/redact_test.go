@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(slog.LevelInfo, &buf)
+
+	logger.Info("invoice gerado",
+		"tax_id", "123.456.789-09",
+		"name", "João Silva",
+		"account_number", "6341320293482496",
+		"note", "pagador 12345678909 bloqueado")
+
+	out := buf.String()
+	for _, leaked := range []string{"123.456.789", "João Silva", "634132029348", "123456789"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("log contém dado sensível %q: %s", leaked, out)
+		}
+	}
+	for _, kept := range []string{"***.***.***-09", "J*** S****", "************2496", "*********09"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("log deveria conter %q: %s", kept, out)
+		}
+	}
+}
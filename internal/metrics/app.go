@@ -0,0 +1,97 @@
+package metrics
+
+import "time"
+
+// Default é o registro exposto pela aplicação em /metrics
+var Default = NewRegistry()
+
+// Buckets de latência em segundos (5ms a 10s)
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var (
+	// HTTPRequestDuration mede a duração das requisições por rota, método e status
+	HTTPRequestDuration = Default.NewHistogramVec(
+		"http_request_duration_seconds",
+		"Duração das requisições HTTP em segundos.",
+		latencyBuckets, "route", "method", "status")
+
+	// WebhookEvents conta os eventos recebidos por subscription, tipo e resultado
+	WebhookEvents = Default.NewCounterVec(
+		"webhook_events_total",
+		"Eventos de webhook recebidos por subscription, tipo e resultado.",
+		"subscription", "type", "outcome")
+
+	// InvoiceBatchSize mede a quantidade de invoices criados por lote
+	InvoiceBatchSize = Default.NewHistogramVec(
+		"invoice_batch_size",
+		"Quantidade de invoices criados por lote.",
+		[]float64{1, 4, 8, 10, 12, 16, 32})
+
+	// InvoicesCreated conta os invoices criados
+	InvoicesCreated = Default.NewCounterVec(
+		"invoices_created_total",
+		"Invoices criados na StarkBank.")
+
+	// TransfersCreated conta as transferências criadas pelo status retornado
+	TransfersCreated = Default.NewCounterVec(
+		"transfers_created_total",
+		"Transferências criadas por status retornado pela API.",
+		"status")
+
+	// ForwardedAmount soma os valores repassados, em centavos
+	ForwardedAmount = Default.NewCounterVec(
+		"forwarded_amount_cents_total",
+		"Valor total repassado em centavos.",
+		"currency")
+
+	// ForwardDecisions conta as decisões da política de repasse por ação e regra
+	ForwardDecisions = Default.NewCounterVec(
+		"forward_decisions_total",
+		"Decisões da política de repasse por ação e regra determinante.",
+		"action", "rule")
+
+	// SDKCallDuration mede a latência das chamadas ao SDK da StarkBank por operação
+	SDKCallDuration = Default.NewHistogramVec(
+		"starkbank_sdk_call_duration_seconds",
+		"Latência das chamadas ao SDK da StarkBank em segundos.",
+		latencyBuckets, "operation")
+
+	// SDKCallErrors conta as chamadas ao SDK que falharam por operação
+	SDKCallErrors = Default.NewCounterVec(
+		"starkbank_sdk_call_errors_total",
+		"Chamadas ao SDK da StarkBank que retornaram erro.",
+		"operation")
+
+	// HTTPRequestsRejected conta as requisições recusadas antes do handler
+	// (reason: rate_limited ou ip_not_allowed)
+	HTTPRequestsRejected = Default.NewCounterVec(
+		"http_requests_rejected_total",
+		"Requisições recusadas por limite de taxa ou lista de IPs permitidos.",
+		"route", "reason")
+)
+
+// ObserveSDKCall registra a latência e o resultado de uma chamada ao SDK
+//
+// A operação segue o formato recurso.ação (ex: transfer.create).
+func ObserveSDKCall(operation string, start time.Time, failed bool) {
+	SDKCallDuration.Observe(time.Since(start).Seconds(), operation)
+	if failed {
+		SDKCallErrors.Inc(operation)
+	}
+}
+
+// ObserveWebhookEvent contabiliza um evento processado pelo pipeline do
+// webhook, recebido por HTTP ou pela consulta de eventos; eventos fora da
+// subscription invoice processados sem erro contam como ignorados
+func ObserveWebhookEvent(subscription, eventType, outcome string) {
+	if subscription == "" {
+		subscription = "unknown"
+	}
+	if eventType == "" {
+		eventType = "unknown"
+	}
+	if subscription != "invoice" && outcome == "processed" {
+		outcome = "ignored"
+	}
+	WebhookEvents.Inc(subscription, eventType, outcome)
+}
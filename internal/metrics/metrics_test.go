@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("events_total", "Eventos.", "type")
+	h := r.NewHistogramVec("latency_seconds", "Latência.", []float64{0.1, 1}, "op")
+
+	c.Inc("credited")
+	c.Add(2, "credited")
+	c.Inc(`a"b`)
+	h.Observe(0.05, "get")
+	h.Observe(0.5, "get")
+	h.Observe(3, "get")
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	out := buf.String()
+
+	expected := []string{
+		"# TYPE events_total counter",
+		`events_total{type="credited"} 3`,
+		`events_total{type="a\"b"} 1`,
+		"# TYPE latency_seconds histogram",
+		`latency_seconds_bucket{op="get",le="0.1"} 1`,
+		`latency_seconds_bucket{op="get",le="1"} 2`,
+		`latency_seconds_bucket{op="get",le="+Inf"} 3`,
+		`latency_seconds_sum{op="get"} 3.55`,
+		`latency_seconds_count{op="get"} 3`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("linha ausente: %s\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/service"
+)
+
+// RequireRole middleware que exige uma chave de API com o papel informado
+//
+// A chave é lida de "Authorization: Bearer <chave>" ou do cabeçalho X-API-Key.
+// Responde 401 para chave ausente ou inválida e 403 para papel insuficiente.
+func RequireRole(auth *service.AuthService, role string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		key, err := auth.Authenticate(ctx, apiKeyFromRequest(r))
+		if err == nil {
+			err = auth.Authorize(key, role)
+		}
+
+		switch {
+		case errors.Is(err, domain.ErrUnauthenticated):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			writeAuthError(w, http.StatusUnauthorized, err)
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			slog.WarnContext(ctx, "acesso negado", "key_id", key.ID, "role", key.Role, "required", role, "path", r.URL.Path)
+			writeAuthError(w, http.StatusForbidden, err)
+			return
+		case err != nil:
+			slog.ErrorContext(ctx, "erro ao autenticar requisição", "error", err)
+			http.Error(w, "Erro ao autenticar", http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(domain.ContextWithAPIKey(ctx, key)))
+	})
+}
+
+// apiKeyFromRequest extrai a chave dos cabeçalhos da requisição
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+func writeAuthError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
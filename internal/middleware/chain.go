@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+)
+
+// Middleware envolve um handler HTTP
+type Middleware func(http.Handler) http.Handler
+
+// Chain aplica os middlewares a h; o primeiro da lista é o mais externo
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// RouteLimits limites de corpo e de tempo de uma rota
+type RouteLimits struct {
+	MaxBodyBytes int64
+	Timeout      time.Duration
+}
+
+// LimitsFunc retorna os limites vigentes de uma rota (lidos a cada requisição
+// para acompanhar a recarga da configuração)
+type LimitsFunc func() RouteLimits
+
+// DefaultLimits limites das rotas da API
+func DefaultLimits(cfg config.ServerConfig) RouteLimits {
+	return RouteLimits{MaxBodyBytes: cfg.MaxBodyBytes, Timeout: cfg.RequestTimeout}
+}
+
+// WebhookLimits limites da rota do webhook
+//
+// O webhook não tem tempo limite: um 503 por tempo esgotado faria a
+// StarkBank reenviar o evento enquanto o handler, que continua executando,
+// ainda cria a transferência.
+func WebhookLimits(cfg config.ServerConfig) RouteLimits {
+	return RouteLimits{MaxBodyBytes: cfg.WebhookMaxBodyBytes}
+}
+
+// TransferLimits limites das rotas administrativas que movimentam dinheiro
+// (aprovação, jobs, agendamentos, invoices, reprocessamento e estornos)
+//
+// Sem tempo limite pelo mesmo motivo do webhook: um 503 enquanto a chamada à
+// StarkBank continua levaria o operador a aprovar ou enviar de novo.
+func TransferLimits(cfg config.ServerConfig) RouteLimits {
+	return RouteLimits{MaxBodyBytes: cfg.MaxBodyBytes}
+}
+
+// Server middlewares aplicados a todas as requisições, inclusive às que não
+// correspondem a nenhuma rota
+//
+// RequestID vem primeiro para que o log da requisição e de um eventual panic
+// tenham o mesmo request_id; Logger fica fora de Recovery para registrar o
+// 500 gerado por ele.
+func Server(cfg config.ServerConfig) []Middleware {
+	return []Middleware{
+		RequestID,
+		Logger,
+		Recovery,
+		SecurityHeaders(cfg.HSTS),
+	}
+}
+
+// Route middlewares aplicados a uma rota registrada
+//
+// Timeout executa o handler em outra goroutine, por isso a rota tem o seu
+// próprio Recovery: o panic é capturado (com o stack trace original) antes de
+// atravessar o TimeoutHandler. Rotas com Timeout zero não têm tempo limite.
+func Route(route string, limits LimitsFunc) []Middleware {
+	return []Middleware{
+		func(h http.Handler) http.Handler { return Metrics(route, h) },
+		func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if d := limits().Timeout; d > 0 {
+					Timeout(d, h).ServeHTTP(w, r)
+					return
+				}
+				h.ServeHTTP(w, r)
+			})
+		},
+		Recovery,
+		func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				BodyLimit(limits().MaxBodyBytes, h).ServeHTTP(w, r)
+			})
+		},
+	}
+}
+
+// Public middlewares de proteção das rotas públicas (sem chave de API)
+//
+// A lista de IPs vem antes do limite de taxa para que origens recusadas não
+// consumam as fichas dos clientes legítimos.
+func Public(route string, limiter *RateLimiter, clientIP *ClientIP, allowlist func() []netip.Prefix) []Middleware {
+	return []Middleware{
+		AllowIPs(route, allowlist, clientIP),
+		RateLimit(route, limiter, clientIP),
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+)
+
+func newTestServer(limits RouteLimits, h http.HandlerFunc) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", Chain(h, Route("/", func() RouteLimits { return limits })...))
+	return Chain(mux, Server(config.ServerConfig{})...)
+}
+
+func TestRequestIDHonoredOrGenerated(t *testing.T) {
+	var seen string
+	srv := newTestServer(RouteLimits{MaxBodyBytes: 1024, Timeout: time.Second}, func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if seen != "abc-123" || rec.Header().Get(RequestIDHeader) != "abc-123" {
+		t.Errorf("X-Request-ID do cliente não foi mantido (contexto %q, resposta %q)", seen, rec.Header().Get(RequestIDHeader))
+	}
+	if rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Error("cabeçalhos de segurança ausentes")
+	}
+
+	// IDs com caracteres inválidos são substituídos
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "x\ninjetado")
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if seen == "" || strings.Contains(seen, "\n") {
+		t.Errorf("request_id inválido aceito: %q", seen)
+	}
+}
+
+func TestRouteLimits(t *testing.T) {
+	srv := newTestServer(RouteLimits{MaxBodyBytes: 8, Timeout: 20 * time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			<-r.Context().Done()
+		case "/panic":
+			panic("falha inesperada")
+		default:
+			if _, err := io.ReadAll(r.Body); err != nil {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			}
+		}
+	})
+
+	cases := []struct {
+		path string
+		body string
+		want int
+	}{
+		{"/", "pequeno", http.StatusOK},
+		{"/", "corpo acima do limite", http.StatusRequestEntityTooLarge},
+		{"/slow", "", http.StatusServiceUnavailable},
+		{"/panic", "", http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, c.path, strings.NewReader(c.body)))
+		if rec.Code != c.want {
+			t.Errorf("%s: status = %d, esperado %d", c.path, rec.Code, c.want)
+		}
+	}
+}
+
+func TestWebhookHasNoTimeout(t *testing.T) {
+	cfg := config.ServerConfig{MaxBodyBytes: 1024, WebhookMaxBodyBytes: 1024, RequestTimeout: 10 * time.Millisecond}
+	for name, limits := range map[string]RouteLimits{"webhook": WebhookLimits(cfg), "transferência": TransferLimits(cfg)} {
+		srv := newTestServer(limits, func(w http.ResponseWriter, r *http.Request) {
+			// Processamento mais longo que o tempo limite das demais rotas
+			time.Sleep(30 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s respondeu %d; o processamento não deveria ser interrompido", name, rec.Code)
+		}
+	}
+}
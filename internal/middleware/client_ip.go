@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ClientIP identifica o IP de origem das requisições
+//
+// Só confia em X-Forwarded-For quando a conexão vem de um proxy conhecido
+// (ex: o agente do ngrok em 127.0.0.1). O cabeçalho é lido da direita para a
+// esquerda e o primeiro endereço fora dos proxies conhecidos é o cliente:
+// entradas à esquerda dele podem ter sido forjadas.
+type ClientIP struct {
+	trusted []netip.Prefix
+}
+
+// NewClientIP cria o resolvedor com as faixas de proxies confiáveis
+func NewClientIP(trusted []netip.Prefix) *ClientIP {
+	return &ClientIP{trusted: trusted}
+}
+
+// Resolve retorna o IP do cliente (inválido se não puder ser determinado)
+func (c *ClientIP) Resolve(r *http.Request) netip.Addr {
+	remote := parseAddr(r.RemoteAddr)
+	if !remote.IsValid() || !c.isTrusted(remote) {
+		return remote
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr := parseAddr(strings.TrimSpace(hops[i]))
+		if !addr.IsValid() {
+			break
+		}
+		if !c.isTrusted(addr) {
+			return addr
+		}
+		remote = addr
+	}
+	return remote
+}
+
+func (c *ClientIP) isTrusted(addr netip.Addr) bool {
+	return containsAddr(c.trusted, addr)
+}
+
+// parseAddr aceita "ip" ou "ip:porta"
+func parseAddr(value string) netip.Addr {
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
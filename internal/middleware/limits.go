@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// BodyLimit middleware que limita o tamanho do corpo da requisição
+//
+// Requisições com Content-Length acima do limite são recusadas com 413 antes
+// de chegar ao handler; nas demais, a leitura além do limite falha com
+// *http.MaxBytesError.
+func BodyLimit(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			http.Error(w, "Corpo da requisição muito grande", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Timeout middleware que limita o tempo de processamento da requisição
+//
+// Ao exceder o limite o contexto da requisição é cancelado e o cliente recebe
+// 503. O handler continua executando em segundo plano até terminar, por isso
+// o limite deve ficar abaixo do WriteTimeout do servidor.
+func Timeout(d time.Duration, next http.Handler) http.Handler {
+	return http.TimeoutHandler(next, d, "Tempo limite da requisição excedido")
+}
@@ -1,12 +1,15 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 )
 
 // Logger middleware para logging de requisições HTTP
+//
+// Deve ser aplicado depois de RequestID para que a linha de log inclua o
+// request_id da requisição.
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -16,12 +19,11 @@ func Logger(next http.Handler) http.Handler {
 
 		next.ServeHTTP(wrapped, r)
 
-		log.Printf(
-			"%s %s %d %s",
-			r.Method,
-			r.RequestURI,
-			wrapped.statusCode,
-			time.Since(start),
+		slog.InfoContext(r.Context(), "requisição HTTP",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", wrapped.statusCode,
+			"duration_ms", time.Since(start).Milliseconds(),
 		)
 	})
 }
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
+)
+
+// Metrics middleware que mede a duração das requisições de uma rota
+//
+// A rota é informada no registro (e não lida de r.URL.Path) para manter a
+// cardinalidade dos rótulos limitada às rotas conhecidas.
+func Metrics(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(wrapped, r)
+
+		metrics.HTTPRequestDuration.Observe(
+			time.Since(start).Seconds(),
+			route, r.Method, strconv.Itoa(wrapped.statusCode),
+		)
+	})
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
+)
+
+// RateLimiter limita requisições com um token bucket por chave
+//
+// Cada chave começa com burst fichas e recebe rate fichas por segundo; cada
+// requisição consome uma. Buckets ociosos (já cheios) são descartados
+// periodicamente para que IPs de passagem não acumulem memória. Com rate = 0
+// o limite fica desativado.
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// sweepInterval define a frequência da limpeza de buckets ociosos
+const sweepInterval = time.Minute
+
+// NewRateLimiter cria um limitador com rate fichas por segundo e capacidade burst
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// SetLimits altera a recarga e a capacidade (usado na recarga da configuração)
+//
+// Os buckets existentes são descartados e recomeçam cheios.
+func (l *RateLimiter) SetLimits(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate = rate
+	l.burst = float64(burst)
+	l.buckets = make(map[string]*tokenBucket)
+}
+
+// Allow consome uma ficha da chave; quando não há ficha, retorna em quanto
+// tempo a próxima estará disponível
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate == 0 {
+		return true, 0
+	}
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep remove os buckets que já teriam se recarregado por completo
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimit middleware que aplica o limitador por IP do cliente e rota
+//
+// Acima do limite responde 429 com Retry-After. Requisições cujo IP não pode
+// ser determinado compartilham um único bucket.
+func RateLimit(route string, limiter *RateLimiter, clientIP *ClientIP) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP.Resolve(r)
+
+			allowed, wait := limiter.Allow(route + "|" + ip.String())
+			if !allowed {
+				metrics.HTTPRequestsRejected.Inc(route, "rate_limited")
+				slog.WarnContext(r.Context(), "requisição acima do limite de taxa", "route", route, "client_ip", ip.String())
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, "Muitas requisições", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AllowIPs middleware que aceita apenas clientes dentro das faixas informadas
+//
+// A lista é consultada a cada requisição; vazia, aceita qualquer origem. Fora
+// da lista responde 403.
+func AllowIPs(route string, allowed func() []netip.Prefix, clientIP *ClientIP) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed := allowed()
+			if len(allowed) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ip := clientIP.Resolve(r)
+			if !ip.IsValid() || !containsAddr(allowed, ip) {
+				metrics.HTTPRequestsRejected.Inc(route, "ip_not_allowed")
+				slog.WarnContext(r.Context(), "origem fora da lista de IPs permitidos", "route", route, "client_ip", ip.String())
+				http.Error(w, "Origem não permitida", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
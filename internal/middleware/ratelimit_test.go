@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRefills(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("requisição %d deveria caber no burst", i+1)
+		}
+	}
+	ok, wait := limiter.Allow("a")
+	if ok || wait != time.Second {
+		t.Fatalf("terceira requisição deveria esperar 1s (ok=%v, wait=%s)", ok, wait)
+	}
+	if ok, _ := limiter.Allow("b"); !ok {
+		t.Error("chaves diferentes não devem compartilhar o bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Error("bucket deveria ter recarregado uma ficha")
+	}
+}
+
+func TestClientIPAndAllowlist(t *testing.T) {
+	clientIP := NewClientIP([]netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")})
+	allowlist := []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24")}
+	allow := AllowIPs("/webhook", func() []netip.Prefix { return allowlist }, clientIP)
+	h := allow(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cases := []struct {
+		name   string
+		remote string
+		xff    string
+		want   int
+	}{
+		// Via proxy confiável: vale o último endereço não confiável
+		{"proxy com origem permitida", "127.0.0.1:5000", "198.51.100.7, 203.0.113.10", http.StatusOK},
+		{"proxy com origem recusada", "127.0.0.1:5000", "203.0.113.10, 198.51.100.7", http.StatusForbidden},
+		// Conexão direta: X-Forwarded-For é ignorado
+		{"cabeçalho forjado", "198.51.100.7:5000", "203.0.113.10", http.StatusForbidden},
+		{"conexão direta permitida", "203.0.113.99:5000", "", http.StatusOK},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+		req.RemoteAddr = c.remote
+		if c.xff != "" {
+			req.Header.Set("X-Forwarded-For", c.xff)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != c.want {
+			t.Errorf("%s: status = %d, esperado %d", c.name, rec.Code, c.want)
+		}
+	}
+}
@@ -1,16 +1,29 @@
 package middleware
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
 )
 
 // Recovery middleware para recuperar de panics
+//
+// O panic é registrado com o stack trace e o request_id do contexto; o
+// cliente recebe apenas um 500 genérico com o X-Request-ID para correlação.
 func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("❌ PANIC: %v\n", err)
+				// http.ErrAbortHandler é usado para abortar a resposta de propósito
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				slog.ErrorContext(r.Context(), "panic ao processar requisição",
+					"panic", fmt.Sprint(err),
+					"method", r.Method,
+					"path", r.URL.Path,
+					"stack", string(debug.Stack()))
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			}
 		}()
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+)
+
+// RequestIDHeader é o cabeçalho que propaga o ID da requisição
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limita IDs recebidos de clientes
+const maxRequestIDLength = 128
+
+// RequestID middleware que identifica cada requisição
+//
+// Um X-Request-ID válido enviado pelo cliente (ou por um proxy) é mantido;
+// caso contrário, um novo ID é gerado. O ID é devolvido na resposta e
+// propagado no contexto para todas as linhas de log da requisição.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID aceita apenas caracteres seguros para logs e cabeçalhos
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID gera um identificador aleatório de 16 caracteres hexadecimais
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
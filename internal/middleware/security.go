@@ -0,0 +1,26 @@
+package middleware
+
+import "net/http"
+
+// SecurityHeaders middleware que adiciona cabeçalhos de segurança às respostas
+//
+// A API só responde JSON e texto: nenhum conteúdo deve ser interpretado como
+// HTML, embutido em frames ou guardado em cache. Strict-Transport-Security só
+// é enviado com hsts = true, quando a aplicação está atrás de HTTPS.
+func SecurityHeaders(hsts bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("X-Frame-Options", "DENY")
+			h.Set("Referrer-Policy", "no-referrer")
+			h.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+			h.Set("Cache-Control", "no-store")
+			if hsts {
+				h.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
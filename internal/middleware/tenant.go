@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+)
+
+// Tenant associa o tenant da rota ao contexto, identificando-o nos logs e na
+// trilha de auditoria
+func Tenant(id string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(logging.WithTenant(r.Context(), id)))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// CachedBalanceRepository mantém o saldo em cache por um tempo limitado
+//
+// Evita uma chamada à API a cada repasse; o cache deve ser invalidado
+// sempre que uma transferência for criada.
+type CachedBalanceRepository struct {
+	inner   domain.BalanceRepository
+	ttl     time.Duration
+	mu      sync.Mutex
+	cached  *domain.Balance
+	fetched time.Time
+}
+
+// NewCachedBalanceRepository cria uma nova instância do repositório
+func NewCachedBalanceRepository(inner domain.BalanceRepository, ttl time.Duration) *CachedBalanceRepository {
+	return &CachedBalanceRepository{
+		inner: inner,
+		ttl:   ttl,
+	}
+}
+
+// Get retorna o saldo em cache ou consulta a API se o cache expirou
+func (r *CachedBalanceRepository) Get(ctx context.Context) (*domain.Balance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cached != nil && time.Since(r.fetched) < r.ttl {
+		balance := *r.cached
+		return &balance, nil
+	}
+
+	balance, err := r.inner.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cached = balance
+	r.fetched = time.Now()
+
+	result := *balance
+	return &result, nil
+}
+
+// Invalidate descarta o saldo em cache
+func (r *CachedBalanceRepository) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cached = nil
+}
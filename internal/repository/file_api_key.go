@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileAPIKeyRepository implementa APIKeyRepository persistindo em arquivo JSON
+type FileAPIKeyRepository struct {
+	store *jsonFileStore[domain.APIKey]
+}
+
+// NewFileAPIKeyRepository cria uma nova instância do repositório
+func NewFileAPIKeyRepository(dataDir string) (*FileAPIKeyRepository, error) {
+	store, err := newJSONFileStore[domain.APIKey](dataDir, "api_keys.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileAPIKeyRepository{store: store}, nil
+}
+
+// Save cria ou atualiza uma chave
+func (r *FileAPIKeyRepository) Save(key domain.APIKey) error {
+	return r.store.update(func(items []domain.APIKey) ([]domain.APIKey, error) {
+		for i := range items {
+			if items[i].ID == key.ID {
+				items[i] = key
+				return items, nil
+			}
+		}
+		return append(items, key), nil
+	})
+}
+
+// GetByID busca uma chave por ID
+func (r *FileAPIKeyRepository) GetByID(id string) (*domain.APIKey, error) {
+	for _, k := range r.store.all() {
+		if k.ID == id {
+			return &k, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// GetByHash busca uma chave pelo hash do seu valor
+func (r *FileAPIKeyRepository) GetByHash(hash string) (*domain.APIKey, error) {
+	for _, k := range r.store.all() {
+		if k.Hash == hash {
+			return &k, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List lista todas as chaves
+func (r *FileAPIKeyRepository) List() ([]domain.APIKey, error) {
+	return r.store.all(), nil
+}
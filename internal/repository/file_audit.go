@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// AuditFileName é o nome do arquivo da trilha de auditoria dentro de DATA_DIR
+const AuditFileName = "audit.jsonl"
+
+// FileAuditRepository implementa AuditRepository em um arquivo JSON Lines
+//
+// Assim como o razão, o arquivo é somente inclusão. Cada entrada é encadeada
+// à anterior pelo hash, calculado aqui sob o lock para que a ordem gravada
+// seja a ordem da cadeia.
+type FileAuditRepository struct {
+	mu      sync.RWMutex
+	file    *os.File
+	entries []domain.AuditEntry
+}
+
+// NewFileAuditRepository abre (ou cria) a trilha em dataDir/audit.jsonl
+func NewFileAuditRepository(dataDir string) (*FileAuditRepository, error) {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de dados: %w", err)
+	}
+
+	path := filepath.Join(dataDir, AuditFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir trilha de auditoria: %w", err)
+	}
+
+	entries, err := readAuditEntries(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &FileAuditRepository{
+		file:    file,
+		entries: entries,
+	}, nil
+}
+
+// ReadAuditFile lê uma trilha de auditoria sem abri-la para escrita
+func ReadAuditFile(path string) ([]domain.AuditEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir trilha de auditoria: %w", err)
+	}
+	defer file.Close()
+
+	return readAuditEntries(file)
+}
+
+func readAuditEntries(r io.Reader) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry domain.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("trilha de auditoria corrompida na linha %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler trilha de auditoria: %w", err)
+	}
+	return entries, nil
+}
+
+// Append encadeia a entrada à última gravada e a inclui na trilha
+func (r *FileAuditRepository) Append(entry domain.AuditEntry) (domain.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.Seq = 1
+	entry.PrevHash = domain.AuditGenesisHash
+	if n := len(r.entries); n > 0 {
+		entry.Seq = r.entries[n-1].Seq + 1
+		entry.PrevHash = r.entries[n-1].Hash
+	}
+	entry.Hash = entry.ComputeHash()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return domain.AuditEntry{}, fmt.Errorf("erro ao codificar entrada de auditoria: %w", err)
+	}
+
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return domain.AuditEntry{}, fmt.Errorf("erro ao gravar entrada de auditoria: %w", err)
+	}
+	if err := r.file.Sync(); err != nil {
+		return domain.AuditEntry{}, fmt.Errorf("erro ao sincronizar trilha de auditoria: %w", err)
+	}
+
+	r.entries = append(r.entries, entry)
+	return entry, nil
+}
+
+// List lista as entradas que atendem ao filtro, das mais recentes para as mais antigas
+func (r *FileAuditRepository) List(filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []domain.AuditEntry{}
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+		if filter.Match(r.entries[i]) {
+			result = append(result, r.entries[i])
+		}
+	}
+	return result, nil
+}
+
+// All lista todas as entradas em ordem de inclusão
+func (r *FileAuditRepository) All() ([]domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]domain.AuditEntry(nil), r.entries...), nil
+}
+
+// Close fecha o arquivo da trilha
+func (r *FileAuditRepository) Close() error {
+	return r.file.Close()
+}
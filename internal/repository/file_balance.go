@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileBalanceSnapshotRepository implementa BalanceSnapshotRepository persistindo em arquivo JSON
+type FileBalanceSnapshotRepository struct {
+	store *jsonFileStore[domain.BalanceSnapshot]
+}
+
+// NewFileBalanceSnapshotRepository cria uma nova instância do repositório
+func NewFileBalanceSnapshotRepository(dataDir string) (*FileBalanceSnapshotRepository, error) {
+	store, err := newJSONFileStore[domain.BalanceSnapshot](dataDir, "balance_snapshots.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileBalanceSnapshotRepository{store: store}, nil
+}
+
+// Save registra um snapshot mantendo a ordem cronológica
+func (r *FileBalanceSnapshotRepository) Save(snapshot domain.BalanceSnapshot) error {
+	return r.store.update(func(items []domain.BalanceSnapshot) ([]domain.BalanceSnapshot, error) {
+		items = append(items, snapshot)
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Taken.Before(items[j].Taken) })
+		return items, nil
+	})
+}
+
+// List lista os snapshots registrados no intervalo [from, to]
+func (r *FileBalanceSnapshotRepository) List(from, to time.Time) ([]domain.BalanceSnapshot, error) {
+	result := []domain.BalanceSnapshot{}
+	for _, s := range r.store.all() {
+		if !s.Taken.Before(from) && !s.Taken.After(to) {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// Last retorna o snapshot mais recente
+func (r *FileBalanceSnapshotRepository) Last() (*domain.BalanceSnapshot, error) {
+	items := r.store.all()
+	if len(items) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return &items[len(items)-1], nil
+}
+
+// FileBalanceAlertRepository implementa BalanceAlertRepository persistindo em arquivo JSON
+type FileBalanceAlertRepository struct {
+	store *jsonFileStore[domain.BalanceAlert]
+}
+
+// NewFileBalanceAlertRepository cria uma nova instância do repositório
+func NewFileBalanceAlertRepository(dataDir string) (*FileBalanceAlertRepository, error) {
+	store, err := newJSONFileStore[domain.BalanceAlert](dataDir, "balance_alerts.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileBalanceAlertRepository{store: store}, nil
+}
+
+// Save registra um alerta disparado
+func (r *FileBalanceAlertRepository) Save(alert domain.BalanceAlert) error {
+	return r.store.update(func(items []domain.BalanceAlert) ([]domain.BalanceAlert, error) {
+		return append(items, alert), nil
+	})
+}
+
+// List lista os alertas mais recentes primeiro
+func (r *FileBalanceAlertRepository) List(limit int) ([]domain.BalanceAlert, error) {
+	items := r.store.all()
+	result := []domain.BalanceAlert{}
+	for i := len(items) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		result = append(result, items[i])
+	}
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileEventCursorRepository implementa EventCursorRepository persistindo em
+// arquivo JSON
+type FileEventCursorRepository struct {
+	store *jsonFileStore[domain.EventCursor]
+}
+
+// NewFileEventCursorRepository cria uma nova instância do repositório
+func NewFileEventCursorRepository(dataDir string) (*FileEventCursorRepository, error) {
+	store, err := newJSONFileStore[domain.EventCursor](dataDir, "event_cursor.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileEventCursorRepository{store: store}, nil
+}
+
+// Get retorna o cursor salvo
+func (r *FileEventCursorRepository) Get() (*domain.EventCursor, error) {
+	items := r.store.all()
+	if len(items) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	cursor := items[len(items)-1]
+	return &cursor, nil
+}
+
+// Save substitui o cursor salvo
+func (r *FileEventCursorRepository) Save(cursor domain.EventCursor) error {
+	return r.store.update(func([]domain.EventCursor) ([]domain.EventCursor, error) {
+		return []domain.EventCursor{cursor}, nil
+	})
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileForwardRepository implementa ForwardRepository persistindo em arquivo JSON
+type FileForwardRepository struct {
+	store *jsonFileStore[domain.Forward]
+}
+
+// NewFileForwardRepository cria uma nova instância do repositório
+func NewFileForwardRepository(dataDir string) (*FileForwardRepository, error) {
+	store, err := newJSONFileStore[domain.Forward](dataDir, "forwards.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileForwardRepository{store: store}, nil
+}
+
+// Save registra um repasse
+func (r *FileForwardRepository) Save(forward domain.Forward) error {
+	return r.store.update(func(items []domain.Forward) ([]domain.Forward, error) {
+		return append(items, forward), nil
+	})
+}
+
+// GetByInvoiceID busca o repasse de um invoice
+func (r *FileForwardRepository) GetByInvoiceID(invoiceID string) (*domain.Forward, error) {
+	for _, f := range r.store.all() {
+		if f.InvoiceID == invoiceID {
+			return &f, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List lista todos os repasses
+func (r *FileForwardRepository) List() ([]domain.Forward, error) {
+	return r.store.all(), nil
+}
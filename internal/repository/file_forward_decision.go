@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileForwardDecisionRepository implementa ForwardDecisionRepository
+// persistindo em arquivo JSON
+type FileForwardDecisionRepository struct {
+	store *jsonFileStore[domain.ForwardDecision]
+}
+
+// NewFileForwardDecisionRepository cria uma nova instância do repositório
+func NewFileForwardDecisionRepository(dataDir string) (*FileForwardDecisionRepository, error) {
+	store, err := newJSONFileStore[domain.ForwardDecision](dataDir, "forward_decisions.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileForwardDecisionRepository{store: store}, nil
+}
+
+// Append registra uma decisão
+func (r *FileForwardDecisionRepository) Append(decision domain.ForwardDecision) error {
+	return r.store.update(func(items []domain.ForwardDecision) ([]domain.ForwardDecision, error) {
+		return append(items, decision), nil
+	})
+}
+
+// List lista as decisões que atendem ao filtro, das mais recentes para as
+// mais antigas
+func (r *FileForwardDecisionRepository) List(filter domain.ForwardDecisionFilter) ([]domain.ForwardDecision, error) {
+	items := r.store.all()
+	result := []domain.ForwardDecision{}
+	for i := len(items) - 1; i >= 0; i-- {
+		if !filter.Match(items[i]) {
+			continue
+		}
+		result = append(result, items[i])
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
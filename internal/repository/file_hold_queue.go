@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileHoldQueueRepository implementa HoldQueueRepository persistindo em arquivo JSON
+type FileHoldQueueRepository struct {
+	store *jsonFileStore[domain.HeldTransfer]
+}
+
+// NewFileHoldQueueRepository cria uma nova instância do repositório
+func NewFileHoldQueueRepository(dataDir string) (*FileHoldQueueRepository, error) {
+	store, err := newJSONFileStore[domain.HeldTransfer](dataDir, "hold_queue.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileHoldQueueRepository{store: store}, nil
+}
+
+// Save cria ou atualiza uma transferência retida
+func (r *FileHoldQueueRepository) Save(held domain.HeldTransfer) error {
+	return r.store.update(func(items []domain.HeldTransfer) ([]domain.HeldTransfer, error) {
+		for i := range items {
+			if items[i].ID == held.ID {
+				items[i] = held
+				return items, nil
+			}
+		}
+		return append(items, held), nil
+	})
+}
+
+// GetByInvoiceID busca a retenção ativa de um invoice
+func (r *FileHoldQueueRepository) GetByInvoiceID(invoiceID string) (*domain.HeldTransfer, error) {
+	for _, h := range r.store.all() {
+		if h.InvoiceID == invoiceID && h.Status == domain.HeldTransferStatusHeld {
+			return &h, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// Cancel cancela a retenção ativa de um invoice
+func (r *FileHoldQueueRepository) Cancel(invoiceID, note string) (*domain.HeldTransfer, error) {
+	var canceled *domain.HeldTransfer
+	err := r.store.update(func(items []domain.HeldTransfer) ([]domain.HeldTransfer, error) {
+		for i := range items {
+			if items[i].InvoiceID == invoiceID && items[i].Status == domain.HeldTransferStatusHeld {
+				now := time.Now()
+				items[i].Status = domain.HeldTransferStatusCanceled
+				items[i].Canceled = &now
+				items[i].Note = note
+				held := items[i]
+				canceled = &held
+				return items, nil
+			}
+		}
+		return nil, domain.ErrNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return canceled, nil
+}
+
+// ListByStatus lista as retenções com o status informado, em ordem de chegada
+func (r *FileHoldQueueRepository) ListByStatus(status string) ([]domain.HeldTransfer, error) {
+	result := []domain.HeldTransfer{}
+	for _, h := range r.store.all() {
+		if status == "" || h.Status == status {
+			result = append(result, h)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileKeyRotationRepository implementa KeyRotationRepository persistindo em
+// arquivo JSON (permissão 0600, pois guarda chaves privadas durante a rotação,
+// cifradas pelo serviço quando a origem é o keystore)
+type FileKeyRotationRepository struct {
+	store *jsonFileStore[domain.KeyRotation]
+}
+
+// NewFileKeyRotationRepository cria uma nova instância do repositório
+func NewFileKeyRotationRepository(dataDir string) (*FileKeyRotationRepository, error) {
+	store, err := newJSONFileStore[domain.KeyRotation](dataDir, "key_rotations.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileKeyRotationRepository{store: store}, nil
+}
+
+// Save cria ou atualiza uma rotação
+func (r *FileKeyRotationRepository) Save(rotation domain.KeyRotation) error {
+	return r.store.update(func(items []domain.KeyRotation) ([]domain.KeyRotation, error) {
+		for i := range items {
+			if items[i].ID == rotation.ID {
+				items[i] = rotation
+				return items, nil
+			}
+		}
+		return append(items, rotation), nil
+	})
+}
+
+// Latest retorna a rotação mais recente
+func (r *FileKeyRotationRepository) Latest() (*domain.KeyRotation, error) {
+	items := r.store.all()
+	if len(items) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	latest := items[len(items)-1]
+	return &latest, nil
+}
+
+// List lista todas as rotações, da mais antiga para a mais recente
+func (r *FileKeyRotationRepository) List() ([]domain.KeyRotation, error) {
+	return r.store.all(), nil
+}
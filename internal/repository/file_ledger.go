@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileLedgerRepository implementa LedgerRepository em um arquivo JSON Lines
+//
+// O arquivo é somente inclusão: cada lançamento é gravado em uma única
+// linha e sincronizado em disco antes de ficar visível nas consultas.
+type FileLedgerRepository struct {
+	mu      sync.RWMutex
+	file    *os.File
+	entries []domain.LedgerEntry
+	keys    map[string]bool
+}
+
+// NewFileLedgerRepository abre (ou cria) o razão em dataDir/ledger.jsonl
+func NewFileLedgerRepository(dataDir string) (*FileLedgerRepository, error) {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de dados: %w", err)
+	}
+
+	path := filepath.Join(dataDir, "ledger.jsonl")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir razão: %w", err)
+	}
+
+	r := &FileLedgerRepository{
+		file: file,
+		keys: make(map[string]bool),
+	}
+
+	if err := r.load(path); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// load lê os lançamentos gravados
+//
+// Cada lançamento é gravado com a quebra de linha em uma única escrita: uma
+// última linha sem quebra é o resto de uma gravação interrompida (ex: queda
+// do processo), nunca confirmada a quem a pediu, e é descartada com um
+// aviso. Linhas completas inválidas continuam impedindo a abertura.
+func (r *FileLedgerRepository) load(path string) error {
+	reader := bufio.NewReader(r.file)
+	var offset int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) > 0 {
+				slog.Warn("descartando lançamento incompleto no fim do razão",
+					"path", path, "line", line, "bytes", len(data))
+				if err := r.file.Truncate(offset); err != nil {
+					return fmt.Errorf("erro ao descartar lançamento incompleto do razão: %w", err)
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler razão: %w", err)
+		}
+		offset += int64(len(data))
+
+		var entry domain.LedgerEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("razão corrompido na linha %d: %w", line, err)
+		}
+		r.entries = append(r.entries, entry)
+		r.keys[entry.Key] = true
+	}
+}
+
+// Append inclui um lançamento balanceado no razão
+func (r *FileLedgerRepository) Append(entry domain.LedgerEntry) error {
+	if !entry.Balanced() {
+		return fmt.Errorf("lançamento %s desbalanceado", entry.Key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.keys[entry.Key] {
+		return domain.ErrDuplicateLedgerEntry
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("erro ao codificar lançamento: %w", err)
+	}
+
+	info, err := r.file.Stat()
+	if err != nil {
+		return fmt.Errorf("erro ao consultar razão: %w", err)
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return r.rollback(info.Size(), fmt.Errorf("erro ao gravar lançamento: %w", err))
+	}
+	if err := r.file.Sync(); err != nil {
+		return r.rollback(info.Size(), fmt.Errorf("erro ao sincronizar razão: %w", err))
+	}
+
+	r.entries = append(r.entries, entry)
+	r.keys[entry.Key] = true
+	return nil
+}
+
+// rollback descarta o que uma gravação com falha deixou depois de size, para
+// que uma linha parcial não fique no meio do razão quando o próximo
+// lançamento for incluído
+func (r *FileLedgerRepository) rollback(size int64, cause error) error {
+	if err := r.file.Truncate(size); err != nil {
+		slog.Error("erro ao descartar lançamento parcial do razão", "size", size, "error", err)
+		return errors.Join(cause, fmt.Errorf("erro ao descartar lançamento parcial: %w", err))
+	}
+	return cause
+}
+
+// List lista todos os lançamentos em ordem de inclusão
+func (r *FileLedgerRepository) List() ([]domain.LedgerEntry, error) {
+	return r.filter(func(domain.LedgerEntry) bool { return true }), nil
+}
+
+// ListByInvoiceID lista os lançamentos de um invoice
+func (r *FileLedgerRepository) ListByInvoiceID(invoiceID string) ([]domain.LedgerEntry, error) {
+	return r.filter(func(e domain.LedgerEntry) bool { return e.InvoiceID == invoiceID }), nil
+}
+
+// ListByTransferID lista os lançamentos de uma transferência
+func (r *FileLedgerRepository) ListByTransferID(transferID string) ([]domain.LedgerEntry, error) {
+	return r.filter(func(e domain.LedgerEntry) bool { return e.TransferID == transferID }), nil
+}
+
+// Close fecha o arquivo do razão
+func (r *FileLedgerRepository) Close() error {
+	return r.file.Close()
+}
+
+func (r *FileLedgerRepository) filter(match func(domain.LedgerEntry) bool) []domain.LedgerEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []domain.LedgerEntry{}
+	for _, e := range r.entries {
+		if match(e) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
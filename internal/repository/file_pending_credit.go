@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FilePendingCreditRepository implementa PendingCreditRepository persistindo
+// em arquivo JSON
+type FilePendingCreditRepository struct {
+	store *jsonFileStore[domain.PendingCredit]
+}
+
+// NewFilePendingCreditRepository cria uma nova instância do repositório
+func NewFilePendingCreditRepository(dataDir string) (*FilePendingCreditRepository, error) {
+	store, err := newJSONFileStore[domain.PendingCredit](dataDir, "pending_credits.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FilePendingCreditRepository{store: store}, nil
+}
+
+// Save cria ou atualiza o crédito pendente de um invoice
+func (r *FilePendingCreditRepository) Save(credit domain.PendingCredit) error {
+	return r.store.update(func(items []domain.PendingCredit) ([]domain.PendingCredit, error) {
+		for i := range items {
+			if items[i].InvoiceID == credit.InvoiceID {
+				items[i] = credit
+				return items, nil
+			}
+		}
+		return append(items, credit), nil
+	})
+}
+
+// GetByInvoiceID busca o crédito pendente de um invoice
+func (r *FilePendingCreditRepository) GetByInvoiceID(invoiceID string) (*domain.PendingCredit, error) {
+	for _, c := range r.store.all() {
+		if c.InvoiceID == invoiceID {
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List lista os créditos pendentes em ordem de chegada
+func (r *FilePendingCreditRepository) List() ([]domain.PendingCredit, error) {
+	return r.store.all(), nil
+}
+
+// Delete remove os créditos dos invoices informados
+func (r *FilePendingCreditRepository) Delete(invoiceIDs ...string) error {
+	remove := make(map[string]bool, len(invoiceIDs))
+	for _, id := range invoiceIDs {
+		remove[id] = true
+	}
+	return r.store.update(func(items []domain.PendingCredit) ([]domain.PendingCredit, error) {
+		kept := items[:0]
+		for _, c := range items {
+			if !remove[c.InvoiceID] {
+				kept = append(kept, c)
+			}
+		}
+		return kept, nil
+	})
+}
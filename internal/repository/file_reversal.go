@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileReversalRepository implementa ReversalRepository persistindo em arquivo JSON
+type FileReversalRepository struct {
+	store *jsonFileStore[domain.Reversal]
+}
+
+// NewFileReversalRepository cria uma nova instância do repositório
+func NewFileReversalRepository(dataDir string) (*FileReversalRepository, error) {
+	store, err := newJSONFileStore[domain.Reversal](dataDir, "reversals.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileReversalRepository{store: store}, nil
+}
+
+// Save cria ou atualiza um estorno
+func (r *FileReversalRepository) Save(reversal domain.Reversal) error {
+	return r.store.update(func(items []domain.Reversal) ([]domain.Reversal, error) {
+		for i := range items {
+			if items[i].ID == reversal.ID {
+				items[i] = reversal
+				return items, nil
+			}
+		}
+		return append(items, reversal), nil
+	})
+}
+
+// GetByID busca um estorno por ID
+func (r *FileReversalRepository) GetByID(id string) (*domain.Reversal, error) {
+	for _, rev := range r.store.all() {
+		if rev.ID == id {
+			return &rev, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// GetByInvoiceID busca o estorno de um invoice
+func (r *FileReversalRepository) GetByInvoiceID(invoiceID string) (*domain.Reversal, error) {
+	for _, rev := range r.store.all() {
+		if rev.InvoiceID == invoiceID {
+			return &rev, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List lista todos os estornos
+func (r *FileReversalRepository) List() ([]domain.Reversal, error) {
+	return r.store.all(), nil
+}
+
+// FileRefundRequestRepository implementa RefundRequestRepository persistindo em arquivo JSON
+type FileRefundRequestRepository struct {
+	store *jsonFileStore[domain.RefundRequest]
+}
+
+// NewFileRefundRequestRepository cria uma nova instância do repositório
+func NewFileRefundRequestRepository(dataDir string) (*FileRefundRequestRepository, error) {
+	store, err := newJSONFileStore[domain.RefundRequest](dataDir, "refund_requests.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileRefundRequestRepository{store: store}, nil
+}
+
+// Save cria ou atualiza uma solicitação de devolução
+func (r *FileRefundRequestRepository) Save(refund domain.RefundRequest) error {
+	return r.store.update(func(items []domain.RefundRequest) ([]domain.RefundRequest, error) {
+		for i := range items {
+			if items[i].ID == refund.ID {
+				items[i] = refund
+				return items, nil
+			}
+		}
+		return append(items, refund), nil
+	})
+}
+
+// GetByReversalID busca a solicitação de devolução de um estorno
+func (r *FileRefundRequestRepository) GetByReversalID(reversalID string) (*domain.RefundRequest, error) {
+	for _, ref := range r.store.all() {
+		if ref.ReversalID == reversalID {
+			return &ref, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List lista todas as solicitações de devolução
+func (r *FileRefundRequestRepository) List() ([]domain.RefundRequest, error) {
+	return r.store.all(), nil
+}
+
+// FilePayerHoldRepository implementa PayerHoldRepository persistindo em arquivo JSON
+type FilePayerHoldRepository struct {
+	store *jsonFileStore[domain.PayerHold]
+}
+
+// NewFilePayerHoldRepository cria uma nova instância do repositório
+func NewFilePayerHoldRepository(dataDir string) (*FilePayerHoldRepository, error) {
+	store, err := newJSONFileStore[domain.PayerHold](dataDir, "payer_holds.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FilePayerHoldRepository{store: store}, nil
+}
+
+// Save registra um bloqueio de pagador (substitui bloqueio existente)
+func (r *FilePayerHoldRepository) Save(hold domain.PayerHold) error {
+	return r.store.update(func(items []domain.PayerHold) ([]domain.PayerHold, error) {
+		for i := range items {
+			if items[i].TaxID == hold.TaxID {
+				items[i] = hold
+				return items, nil
+			}
+		}
+		return append(items, hold), nil
+	})
+}
+
+// Delete remove o bloqueio de um pagador
+func (r *FilePayerHoldRepository) Delete(taxID string) error {
+	return r.store.update(func(items []domain.PayerHold) ([]domain.PayerHold, error) {
+		result := items[:0]
+		for _, h := range items {
+			if h.TaxID != taxID {
+				result = append(result, h)
+			}
+		}
+		return result, nil
+	})
+}
+
+// Get busca o bloqueio de um pagador
+func (r *FilePayerHoldRepository) Get(taxID string) (*domain.PayerHold, error) {
+	for _, h := range r.store.all() {
+		if h.TaxID == taxID {
+			return &h, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
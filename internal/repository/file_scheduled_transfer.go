@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileScheduledTransferRepository implementa ScheduledTransferRepository
+// persistindo em arquivo JSON
+type FileScheduledTransferRepository struct {
+	store *jsonFileStore[domain.ScheduledTransfer]
+}
+
+// NewFileScheduledTransferRepository cria uma nova instância do repositório
+func NewFileScheduledTransferRepository(dataDir string) (*FileScheduledTransferRepository, error) {
+	store, err := newJSONFileStore[domain.ScheduledTransfer](dataDir, "scheduled_transfers.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileScheduledTransferRepository{store: store}, nil
+}
+
+// Save cria ou atualiza um agendamento
+func (r *FileScheduledTransferRepository) Save(schedule domain.ScheduledTransfer) error {
+	return r.store.update(func(items []domain.ScheduledTransfer) ([]domain.ScheduledTransfer, error) {
+		for i := range items {
+			if items[i].ID == schedule.ID {
+				items[i] = schedule
+				return items, nil
+			}
+		}
+		return append(items, schedule), nil
+	})
+}
+
+// Get busca um agendamento pelo ID
+func (r *FileScheduledTransferRepository) Get(id string) (*domain.ScheduledTransfer, error) {
+	for _, s := range r.store.all() {
+		if s.ID == id {
+			return &s, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List lista os agendamentos com o status informado (vazio lista todos), em
+// ordem de criação
+func (r *FileScheduledTransferRepository) List(status string) ([]domain.ScheduledTransfer, error) {
+	result := []domain.ScheduledTransfer{}
+	for _, s := range r.store.all() {
+		if status == "" || s.Status == status {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// jsonFileStore persiste uma coleção de registros em um arquivo JSON local
+//
+// Cada alteração reescreve o arquivo inteiro em um arquivo temporário e faz
+// rename, garantindo que o arquivo nunca fique pela metade em caso de falha.
+type jsonFileStore[T any] struct {
+	mu    sync.RWMutex
+	path  string
+	items []T
+}
+
+// newJSONFileStore abre (ou cria) a coleção armazenada em dir/name
+func newJSONFileStore[T any](dir, name string) (*jsonFileStore[T], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de dados: %w", err)
+	}
+
+	s := &jsonFileStore[T]{path: filepath.Join(dir, name)}
+
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("erro ao ler %s: %w", s.path, err)
+	}
+
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &s.items); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar %s: %w", s.path, err)
+		}
+	}
+
+	return s, nil
+}
+
+// all retorna uma cópia de todos os registros
+func (s *jsonFileStore[T]) all() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]T, len(s.items))
+	copy(result, s.items)
+	return result
+}
+
+// update aplica fn sobre os registros e persiste o resultado
+//
+// Se fn ou a escrita falharem, o estado em memória permanece inalterado.
+func (s *jsonFileStore[T]) update(fn func(items []T) ([]T, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := make([]T, len(s.items))
+	copy(current, s.items)
+
+	updated, err := fn(current)
+	if err != nil {
+		return err
+	}
+
+	if err := s.write(updated); err != nil {
+		return err
+	}
+
+	s.items = updated
+	return nil
+}
+
+// write grava os registros de forma atômica (arquivo temporário + rename)
+func (s *jsonFileStore[T]) write(items []T) error {
+	content, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar %s: %w", s.path, err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", tmp, err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("erro ao substituir %s: %w", s.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// FileTransferApprovalRepository implementa TransferApprovalRepository
+// persistindo em arquivo JSON
+type FileTransferApprovalRepository struct {
+	store *jsonFileStore[domain.TransferApproval]
+}
+
+// NewFileTransferApprovalRepository cria uma nova instância do repositório
+func NewFileTransferApprovalRepository(dataDir string) (*FileTransferApprovalRepository, error) {
+	store, err := newJSONFileStore[domain.TransferApproval](dataDir, "transfer_approvals.json")
+	if err != nil {
+		return nil, err
+	}
+	return &FileTransferApprovalRepository{store: store}, nil
+}
+
+// Save cria ou atualiza uma solicitação de aprovação
+func (r *FileTransferApprovalRepository) Save(approval domain.TransferApproval) error {
+	return r.store.update(func(items []domain.TransferApproval) ([]domain.TransferApproval, error) {
+		for i := range items {
+			if items[i].ID == approval.ID {
+				items[i] = approval
+				return items, nil
+			}
+		}
+		return append(items, approval), nil
+	})
+}
+
+// Get busca uma solicitação pelo ID
+func (r *FileTransferApprovalRepository) Get(id string) (*domain.TransferApproval, error) {
+	for _, a := range r.store.all() {
+		if a.ID == id {
+			return &a, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List lista as solicitações com o status informado (vazio lista todas), das
+// mais recentes para as mais antigas
+func (r *FileTransferApprovalRepository) List(status string) ([]domain.TransferApproval, error) {
+	items := r.store.all()
+	result := []domain.TransferApproval{}
+	for i := len(items) - 1; i >= 0; i-- {
+		if status == "" || items[i].Status == status {
+			result = append(result, items[i])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	Balance "github.com/starkbank/sdk-go/starkbank/balance"
+	"github.com/starkinfra/core-go/starkcore/user/user"
+)
+
+// StarkBankBalanceRepository implementa BalanceRepository usando o SDK da StarkBank
+type StarkBankBalanceRepository struct {
+	sdkUser user.User
+}
+
+// NewStarkBankBalanceRepository cria uma nova instância do repositório; as
+// chamadas ao SDK são assinadas com sdkUser (o projeto do tenant)
+func NewStarkBankBalanceRepository(sdkUser user.User) *StarkBankBalanceRepository {
+	return &StarkBankBalanceRepository{sdkUser: sdkUser}
+}
+
+// Get consulta o saldo atual da conta
+func (r *StarkBankBalanceRepository) Get(ctx context.Context) (_ *domain.Balance, err error) {
+	end := startSDKCall(ctx, "balance.get")
+	defer func() { end(err) }()
+
+	balance, sdkErr := Balance.Get(r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, credentialsError("erro ao consultar saldo", sdkErr)
+	}
+
+	return &domain.Balance{
+		Amount:  domain.NewMoney(int64(balance.Amount), balance.Currency),
+		Updated: balance.Updated,
+	}, nil
+}
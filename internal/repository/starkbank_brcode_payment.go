@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	BrcodePayment "github.com/starkbank/sdk-go/starkbank/brcodepayment"
+	PaymentPreview "github.com/starkbank/sdk-go/starkbank/paymentpreview"
+	"github.com/starkinfra/core-go/starkcore/user/user"
+)
+
+// StarkBankBRCodePaymentRepository implementa BRCodePaymentRepository usando
+// o SDK da StarkBank
+type StarkBankBRCodePaymentRepository struct {
+	sdkUser user.User
+}
+
+// NewStarkBankBRCodePaymentRepository cria uma nova instância do repositório;
+// as chamadas ao SDK são assinadas com sdkUser (o projeto do tenant)
+func NewStarkBankBRCodePaymentRepository(sdkUser user.User) *StarkBankBRCodePaymentRepository {
+	return &StarkBankBRCodePaymentRepository{sdkUser: sdkUser}
+}
+
+// Preview consulta o recebedor, o valor e o status de um BR Code
+func (r *StarkBankBRCodePaymentRepository) Preview(ctx context.Context, brcode string) (_ *domain.BRCodePreview, err error) {
+	end := startSDKCall(ctx, "paymentpreview.create")
+	defer func() { end(err) }()
+
+	previews, sdkErr := PaymentPreview.Create([]PaymentPreview.PaymentPreview{{Id: brcode}}, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao consultar BR Code: %v", sdkErr.Errors)
+	}
+	if len(previews) == 0 {
+		return nil, fmt.Errorf("erro ao consultar BR Code: resposta vazia")
+	}
+	p, ok := previews[0].Payment.(PaymentPreview.BrcodePreview)
+	if !ok {
+		return nil, fmt.Errorf("%w: código não é um BR Code Pix (tipo %q)", domain.ErrInvalidInput, previews[0].Type)
+	}
+
+	return &domain.BRCodePreview{
+		BRCode:      brcode,
+		Status:      p.Status,
+		Name:        p.Name,
+		TaxID:       p.TaxId,
+		BankCode:    p.BankCode,
+		AccountType: p.AccountType,
+		AllowChange: p.AllowChange,
+		Amount:      domain.BRL(int64(p.Amount)),
+	}, nil
+}
+
+// Create paga um BR Code
+func (r *StarkBankBRCodePaymentRepository) Create(ctx context.Context, payment domain.BRCodePayment) (_ *domain.BRCodePayment, err error) {
+	end := startSDKCall(ctx, "brcodepayment.create")
+	defer func() { end(err) }()
+
+	created, sdkErr := BrcodePayment.Create([]BrcodePayment.BrcodePayment{{
+		Brcode:      payment.BRCode,
+		TaxId:       payment.TaxID,
+		Description: payment.Description,
+		Amount:      int(payment.Amount.Cents()),
+		Tags:        payment.Tags,
+	}}, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao pagar BR Code: %v", sdkErr.Errors)
+	}
+	if len(created) == 0 {
+		return nil, fmt.Errorf("erro ao pagar BR Code: resposta vazia")
+	}
+	return fromSDKBRCodePayment(created[0]), nil
+}
+
+// GetByID busca um pagamento de BR Code por ID
+func (r *StarkBankBRCodePaymentRepository) GetByID(ctx context.Context, id string) (_ *domain.BRCodePayment, err error) {
+	end := startSDKCall(ctx, "brcodepayment.get")
+	defer func() { end(err) }()
+
+	p, sdkErr := BrcodePayment.Get(id, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao buscar pagamento de BR Code: %v", sdkErr.Errors)
+	}
+	return fromSDKBRCodePayment(p), nil
+}
+
+func fromSDKBRCodePayment(p BrcodePayment.BrcodePayment) *domain.BRCodePayment {
+	return &domain.BRCodePayment{
+		ID:          p.Id,
+		BRCode:      p.Brcode,
+		TaxID:       p.TaxId,
+		Name:        p.Name,
+		Description: p.Description,
+		Amount:      domain.BRL(int64(p.Amount)),
+		Tags:        p.Tags,
+		Status:      p.Status,
+		Fee:         domain.BRL(int64(p.Fee)),
+		Created:     p.Created,
+	}
+}
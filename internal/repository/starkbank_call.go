@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	starkerror "github.com/starkinfra/core-go/starkcore/error"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
+)
+
+// startSDKCall abre um span para uma chamada ao SDK da StarkBank e devolve a
+// função que o encerra, registrando também latência e erro nas métricas
+//
+// Uso:
+//
+//	end := startSDKCall(ctx, "transfer.create")
+//	defer func() { end(err) }()
+func startSDKCall(ctx context.Context, operation string) func(err error) {
+	start := time.Now()
+	_, span := tracing.Start(ctx, "starkbank."+operation, attribute.String("starkbank.operation", operation))
+
+	return func(err error) {
+		metrics.ObserveSDKCall(operation, start, err != nil)
+		tracing.End(span, err)
+	}
+}
+
+// credentialsError converte o erro de uma consulta sem parâmetros; como a
+// requisição não tem entrada a validar, qualquer erro devolvido pela API
+// (exceto falhas internas e de conexão) significa credenciais recusadas
+func credentialsError(message string, errs starkerror.StarkErrors) error {
+	for _, e := range errs.Errors {
+		switch e.Code {
+		case "unknownError", "internalServerError":
+		default:
+			return fmt.Errorf("%s: %w: %v", message, domain.ErrCredentialsRejected, errs.Errors)
+		}
+	}
+	return fmt.Errorf("%s: %v", message, errs.Errors)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	Event "github.com/starkbank/sdk-go/starkbank/event"
+	InvoiceLog "github.com/starkbank/sdk-go/starkbank/invoice/log"
+	"github.com/starkinfra/core-go/starkcore/user/user"
+)
+
+// StarkBankEventRepository implementa EventRepository usando o SDK da StarkBank
+type StarkBankEventRepository struct {
+	sdkUser user.User
+}
+
+// NewStarkBankEventRepository cria uma nova instância do repositório; as
+// chamadas ao SDK são assinadas com sdkUser (o projeto do tenant)
+func NewStarkBankEventRepository(sdkUser user.User) *StarkBankEventRepository {
+	return &StarkBankEventRepository{sdkUser: sdkUser}
+}
+
+// Get busca um evento por ID
+func (r *StarkBankEventRepository) Get(ctx context.Context, id string) (_ *domain.Event, err error) {
+	end := startSDKCall(ctx, "event.get")
+	defer func() { end(err) }()
+
+	e, sdkErr := Event.Get(id, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao buscar evento: %v", sdkErr.Errors)
+	}
+	event := toEvent(e)
+	return &event, nil
+}
+
+// List lista eventos, do mais recente para o mais antigo
+func (r *StarkBankEventRepository) List(ctx context.Context, filter domain.EventFilter) (_ []domain.Event, err error) {
+	params := map[string]interface{}{}
+	if filter.Limit > 0 {
+		params["limit"] = filter.Limit
+	}
+	if filter.After != nil {
+		params["after"] = filter.After.Format("2006-01-02")
+	}
+	if filter.Before != nil {
+		params["before"] = filter.Before.Format("2006-01-02")
+	}
+	if filter.Undelivered {
+		params["isDelivered"] = false
+	}
+
+	end := startSDKCall(ctx, "event.query")
+	defer func() { end(err) }()
+
+	events, errChan := Event.Query(params, r.sdkUser)
+	result := []domain.Event{}
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return result, nil
+			}
+			result = append(result, toEvent(e))
+		case sdkErr, ok := <-errChan:
+			if ok && sdkErr.Errors != nil {
+				return result, fmt.Errorf("erro ao listar eventos: %v", sdkErr.Errors)
+			}
+			return result, nil
+		}
+	}
+}
+
+// MarkDelivered marca o evento como entregue
+func (r *StarkBankEventRepository) MarkDelivered(ctx context.Context, id string) (err error) {
+	end := startSDKCall(ctx, "event.update")
+	defer func() { end(err) }()
+
+	_, sdkErr := Event.Update(id, map[string]interface{}{"isDelivered": true}, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return fmt.Errorf("erro ao marcar evento como entregue: %v", sdkErr.Errors)
+	}
+	return nil
+}
+
+// Verify confere a assinatura Digital-Signature de um webhook com a chave
+// pública da StarkBank, obtida com as credenciais do tenant
+func (r *StarkBankEventRepository) Verify(ctx context.Context, content, signature string) (err error) {
+	if signature == "" {
+		return fmt.Errorf("webhook sem assinatura: %w", domain.ErrInvalidSignature)
+	}
+
+	end := startSDKCall(ctx, "event.parse")
+	defer func() { end(err) }()
+
+	// O SDK entra em pânico quando a consulta da chave pública falha
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("erro ao verificar assinatura do evento: %v", p)
+		}
+	}()
+
+	_, sdkErr := Event.Parse(content, signature, r.sdkUser)
+	for _, e := range sdkErr.Errors {
+		if e.Code == "invalidSignatureError" {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidSignature, e.Message)
+		}
+	}
+	if sdkErr.Errors != nil {
+		return fmt.Errorf("erro ao verificar assinatura do evento: %v", sdkErr.Errors)
+	}
+	return nil
+}
+
+// toEvent converte o evento do SDK; apenas logs de invoice são convertidos
+// para o formato do webhook, os demais ficam só com os dados do evento
+func toEvent(e Event.Event) domain.Event {
+	event := domain.Event{
+		ID:           e.Id,
+		Subscription: e.Subscription,
+		WorkspaceID:  e.WorkspaceId,
+		Delivered:    e.IsDelivered,
+		Created:      e.Created,
+		Webhook:      domain.WebhookEvent{EventID: e.Id, Subscription: e.Subscription},
+	}
+
+	if log, ok := e.Log.(InvoiceLog.Log); ok {
+		event.Type = log.Type
+		event.ResourceID = log.Invoice.Id
+		fillInvoiceLog(&event.Webhook, log)
+	}
+	return event
+}
+
+// fillInvoiceLog preenche o evento de webhook com os dados do log de invoice
+func fillInvoiceLog(webhook *domain.WebhookEvent, log InvoiceLog.Log) {
+	webhook.EventType = log.Type
+	webhook.InvoiceID = log.Invoice.Id
+	webhook.Amount = domain.BRL(int64(log.Invoice.Amount))
+	webhook.Fee = domain.BRL(int64(log.Invoice.Fee))
+	webhook.Status = log.Invoice.Status
+	webhook.PayerName = log.Invoice.Name
+	webhook.PayerTaxID = log.Invoice.TaxId
+}
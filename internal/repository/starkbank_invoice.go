@@ -1,43 +1,50 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
 	Invoice "github.com/starkbank/sdk-go/starkbank/invoice"
+	"github.com/starkinfra/core-go/starkcore/user/user"
 )
 
 // StarkBankInvoiceRepository implementa InvoiceRepository usando o SDK da StarkBank
-type StarkBankInvoiceRepository struct{}
+type StarkBankInvoiceRepository struct {
+	sdkUser user.User
+}
 
-// NewStarkBankInvoiceRepository cria uma nova instância do repositório
-func NewStarkBankInvoiceRepository() *StarkBankInvoiceRepository {
-	return &StarkBankInvoiceRepository{}
+// NewStarkBankInvoiceRepository cria uma nova instância do repositório; as
+// chamadas ao SDK são assinadas com sdkUser (o projeto do tenant)
+func NewStarkBankInvoiceRepository(sdkUser user.User) *StarkBankInvoiceRepository {
+	return &StarkBankInvoiceRepository{sdkUser: sdkUser}
 }
 
 // Create cria invoices na StarkBank
-func (r *StarkBankInvoiceRepository) Create(invoices []domain.Invoice) ([]domain.Invoice, error) {
+func (r *StarkBankInvoiceRepository) Create(ctx context.Context, invoices []domain.Invoice) (_ []domain.Invoice, err error) {
 	// Converter domain.Invoice para Invoice.Invoice
 	sdkInvoices := make([]Invoice.Invoice, len(invoices))
 	for i, inv := range invoices {
 		sdkInvoices[i] = Invoice.Invoice{
-			Amount:   inv.Amount,
+			Amount:   int(inv.Amount.Cents()),
 			Name:     inv.Name,
 			TaxId:    inv.TaxID,
 			Fine:     2.5, // 2.5% multa após vencimento
 			Interest: 1.3, // 1.3% juros mensal
 		}
-		fmt.Printf("✅ Invoice %d: R$%.2f | %s | CPF:%s\n",
-			i+1, float64(inv.Amount)/100, inv.Name, inv.TaxID)
 	}
 
-	fmt.Printf("📤 Enviando %d invoices para StarkBank API...\n", len(sdkInvoices))
+	slog.DebugContext(ctx, "enviando invoices para a StarkBank", "count", len(sdkInvoices))
 
 	// Criar na StarkBank
-	created, err := Invoice.Create(sdkInvoices, nil)
-	if err.Errors != nil {
-		fmt.Printf("❌ Resposta da API: %+v\n", err)
-		return nil, fmt.Errorf("erro ao criar invoices: %v", err.Errors)
+	end := startSDKCall(ctx, "invoice.create")
+	defer func() { end(err) }()
+
+	created, sdkErr := Invoice.Create(sdkInvoices, r.sdkUser)
+	if sdkErr.Errors != nil {
+		slog.ErrorContext(ctx, "resposta de erro da StarkBank", "errors", fmt.Sprintf("%+v", sdkErr.Errors))
+		return nil, fmt.Errorf("erro ao criar invoices: %v", sdkErr.Errors)
 	}
 
 	// Converter de volta para domain.Invoice
@@ -45,13 +52,13 @@ func (r *StarkBankInvoiceRepository) Create(invoices []domain.Invoice) ([]domain
 	for i, inv := range created {
 		result[i] = domain.Invoice{
 			ID:         inv.Id,
-			Amount:     inv.Amount,
+			Amount:     domain.BRL(int64(inv.Amount)),
 			Name:       inv.Name,
 			TaxID:      inv.TaxId,
 			Due:        inv.Due,
 			Expiration: inv.Expiration,
 			Status:     inv.Status,
-			Fee:        inv.Fee,
+			Fee:        domain.BRL(int64(inv.Fee)),
 			Created:    inv.Created,
 		}
 	}
@@ -60,32 +67,38 @@ func (r *StarkBankInvoiceRepository) Create(invoices []domain.Invoice) ([]domain
 }
 
 // GetByID busca um invoice por ID
-func (r *StarkBankInvoiceRepository) GetByID(id string) (*domain.Invoice, error) {
-	inv, err := Invoice.Get(id, nil)
-	if err.Errors != nil {
-		return nil, fmt.Errorf("erro ao buscar invoice: %v", err.Errors)
+func (r *StarkBankInvoiceRepository) GetByID(ctx context.Context, id string) (_ *domain.Invoice, err error) {
+	end := startSDKCall(ctx, "invoice.get")
+	defer func() { end(err) }()
+
+	inv, sdkErr := Invoice.Get(id, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao buscar invoice: %v", sdkErr.Errors)
 	}
 
 	return &domain.Invoice{
 		ID:         inv.Id,
-		Amount:     inv.Amount,
+		Amount:     domain.BRL(int64(inv.Amount)),
 		Name:       inv.Name,
 		TaxID:      inv.TaxId,
 		Due:        inv.Due,
 		Expiration: inv.Expiration,
 		Status:     inv.Status,
-		Fee:        inv.Fee,
+		Fee:        domain.BRL(int64(inv.Fee)),
 		Created:    inv.Created,
 	}, nil
 }
 
 // List lista invoices
-func (r *StarkBankInvoiceRepository) List(limit int) ([]domain.Invoice, error) {
+func (r *StarkBankInvoiceRepository) List(ctx context.Context, limit int) (_ []domain.Invoice, err error) {
 	params := map[string]interface{}{
 		"limit": limit,
 	}
 
-	invoices, errChan := Invoice.Query(params, nil)
+	end := startSDKCall(ctx, "invoice.query")
+	defer func() { end(err) }()
+
+	invoices, errChan := Invoice.Query(params, r.sdkUser)
 	result := []domain.Invoice{}
 
 	for {
@@ -96,18 +109,18 @@ func (r *StarkBankInvoiceRepository) List(limit int) ([]domain.Invoice, error) {
 			}
 			result = append(result, domain.Invoice{
 				ID:         inv.Id,
-				Amount:     inv.Amount,
+				Amount:     domain.BRL(int64(inv.Amount)),
 				Name:       inv.Name,
 				TaxID:      inv.TaxId,
 				Due:        inv.Due,
 				Expiration: inv.Expiration,
 				Status:     inv.Status,
-				Fee:        inv.Fee,
+				Fee:        domain.BRL(int64(inv.Fee)),
 				Created:    inv.Created,
 			})
-		case err, ok := <-errChan:
-			if ok && err.Errors != nil {
-				return result, fmt.Errorf("erro ao listar invoices: %v", err.Errors)
+		case sdkErr, ok := <-errChan:
+			if ok && sdkErr.Errors != nil {
+				return result, fmt.Errorf("erro ao listar invoices: %v", sdkErr.Errors)
 			}
 			return result, nil
 		}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	InvoiceLog "github.com/starkbank/sdk-go/starkbank/invoice/log"
+	"github.com/starkinfra/core-go/starkcore/user/user"
+)
+
+// StarkBankInvoiceLogRepository implementa InvoiceLogRepository usando o SDK
+// da StarkBank
+type StarkBankInvoiceLogRepository struct {
+	sdkUser user.User
+}
+
+// NewStarkBankInvoiceLogRepository cria uma nova instância do repositório; as
+// chamadas ao SDK são assinadas com sdkUser (o projeto do tenant)
+func NewStarkBankInvoiceLogRepository(sdkUser user.User) *StarkBankInvoiceLogRepository {
+	return &StarkBankInvoiceLogRepository{sdkUser: sdkUser}
+}
+
+// List lista os logs de invoices dos tipos informados (todos, se vazio), do
+// mais recente para o mais antigo
+//
+// Logs não são entregues por webhook: Delivered é sempre false e
+// Undelivered é ignorado.
+func (r *StarkBankInvoiceLogRepository) List(ctx context.Context, filter domain.EventFilter, types ...string) (_ []domain.Event, err error) {
+	params := map[string]interface{}{}
+	if filter.Limit > 0 {
+		params["limit"] = filter.Limit
+	}
+	if filter.After != nil {
+		params["after"] = filter.After.Format("2006-01-02")
+	}
+	if filter.Before != nil {
+		params["before"] = filter.Before.Format("2006-01-02")
+	}
+	if len(types) > 0 {
+		params["types"] = types
+	}
+
+	end := startSDKCall(ctx, "invoice_log.query")
+	defer func() { end(err) }()
+
+	logs, errChan := InvoiceLog.Query(params, r.sdkUser)
+	result := []domain.Event{}
+
+	for {
+		select {
+		case log, ok := <-logs:
+			if !ok {
+				return result, nil
+			}
+			result = append(result, invoiceLogEvent(log))
+		case sdkErr, ok := <-errChan:
+			if ok && sdkErr.Errors != nil {
+				return result, fmt.Errorf("erro ao listar logs de invoices: %v", sdkErr.Errors)
+			}
+			return result, nil
+		}
+	}
+}
+
+// invoiceLogEvent converte o log no evento que o webhook de invoice entregaria
+func invoiceLogEvent(log InvoiceLog.Log) domain.Event {
+	event := domain.Event{
+		ID:           log.Id,
+		Subscription: "invoice",
+		Type:         log.Type,
+		ResourceID:   log.Invoice.Id,
+		Created:      log.Created,
+		Webhook:      domain.WebhookEvent{EventID: log.Id, Subscription: "invoice"},
+	}
+	fillInvoiceLog(&event.Webhook, log)
+	return event
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	DictKey "github.com/starkbank/sdk-go/starkbank/dictkey"
+	"github.com/starkinfra/core-go/starkcore/user/user"
+)
+
+// StarkBankPixKeyRepository implementa PixKeyRepository consultando o DICT
+// pela StarkBank
+type StarkBankPixKeyRepository struct {
+	sdkUser user.User
+}
+
+// NewStarkBankPixKeyRepository cria uma nova instância do repositório; as
+// chamadas ao SDK são assinadas com sdkUser (o projeto do tenant)
+func NewStarkBankPixKeyRepository(sdkUser user.User) *StarkBankPixKeyRepository {
+	return &StarkBankPixKeyRepository{sdkUser: sdkUser}
+}
+
+// Get consulta a chave Pix e a conta que ela identifica
+func (r *StarkBankPixKeyRepository) Get(ctx context.Context, key string) (_ *domain.PixKey, err error) {
+	end := startSDKCall(ctx, "dictkey.get")
+	defer func() { end(err) }()
+
+	k, sdkErr := DictKey.Get(key, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao consultar chave Pix: %v", sdkErr.Errors)
+	}
+
+	return &domain.PixKey{
+		Key:           k.Id,
+		Type:          k.Type,
+		Name:          k.Name,
+		TaxID:         k.TaxId,
+		OwnerType:     k.OwnerType,
+		BankName:      k.BankName,
+		ISPB:          k.Ispb,
+		BranchCode:    k.BranchCode,
+		AccountNumber: k.AccountNumber,
+		AccountType:   k.AccountType,
+		Status:        k.Status,
+	}, nil
+}
+
+// CachedPixKeyRepository mantém as chaves Pix consultadas em cache por um
+// tempo limitado
+//
+// As consultas ao DICT são limitadas por participante; com repasses
+// imediatos, cada invoice creditado consultaria a mesma chave.
+type CachedPixKeyRepository struct {
+	inner domain.PixKeyRepository
+	ttl   time.Duration
+	mu    sync.Mutex
+	keys  map[string]cachedPixKey
+}
+
+type cachedPixKey struct {
+	key     domain.PixKey
+	fetched time.Time
+}
+
+// NewCachedPixKeyRepository cria uma nova instância do repositório
+func NewCachedPixKeyRepository(inner domain.PixKeyRepository, ttl time.Duration) *CachedPixKeyRepository {
+	return &CachedPixKeyRepository{
+		inner: inner,
+		ttl:   ttl,
+		keys:  make(map[string]cachedPixKey),
+	}
+}
+
+// Get retorna a chave em cache ou consulta o DICT se o cache expirou
+func (r *CachedPixKeyRepository) Get(ctx context.Context, key string) (*domain.PixKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, ok := r.keys[key]; ok && time.Since(cached.fetched) < r.ttl {
+		result := cached.key
+		return &result, nil
+	}
+
+	pixKey, err := r.inner.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	r.keys[key] = cachedPixKey{key: *pixKey, fetched: time.Now()}
+
+	result := *pixKey
+	return &result, nil
+}
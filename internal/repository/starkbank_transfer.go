@@ -1,27 +1,32 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
 	Transfer "github.com/starkbank/sdk-go/starkbank/transfer"
+	"github.com/starkinfra/core-go/starkcore/user/user"
 )
 
 // StarkBankTransferRepository implementa TransferRepository usando o SDK da StarkBank
-type StarkBankTransferRepository struct{}
+type StarkBankTransferRepository struct {
+	sdkUser user.User
+}
 
-// NewStarkBankTransferRepository cria uma nova instância do repositório
-func NewStarkBankTransferRepository() *StarkBankTransferRepository {
-	return &StarkBankTransferRepository{}
+// NewStarkBankTransferRepository cria uma nova instância do repositório; as
+// chamadas ao SDK são assinadas com sdkUser (o projeto do tenant)
+func NewStarkBankTransferRepository(sdkUser user.User) *StarkBankTransferRepository {
+	return &StarkBankTransferRepository{sdkUser: sdkUser}
 }
 
 // Create cria transferências na StarkBank
-func (r *StarkBankTransferRepository) Create(transfers []domain.Transfer) ([]domain.Transfer, error) {
+func (r *StarkBankTransferRepository) Create(ctx context.Context, transfers []domain.Transfer) (_ []domain.Transfer, err error) {
 	// Converter domain.Transfer para Transfer.Transfer
 	sdkTransfers := make([]Transfer.Transfer, len(transfers))
 	for i, t := range transfers {
 		sdkTransfers[i] = Transfer.Transfer{
-			Amount:        t.Amount,
+			Amount:        int(t.Amount.Cents()),
 			BankCode:      t.BankCode,
 			BranchCode:    t.BranchCode,
 			AccountNumber: t.AccountNumber,
@@ -30,13 +35,18 @@ func (r *StarkBankTransferRepository) Create(transfers []domain.Transfer) ([]dom
 			AccountType:   t.AccountType,
 			Description:   t.Description,
 			ExternalId:    t.ExternalID, // ID único para idempotência (gerado no service)
+			Tags:          t.Tags,
+			Scheduled:     t.Scheduled,
 		}
 	}
 
 	// Criar na StarkBank
-	created, err := Transfer.Create(sdkTransfers, nil)
-	if err.Errors != nil {
-		return nil, fmt.Errorf("erro ao criar transferências: %v", err.Errors)
+	end := startSDKCall(ctx, "transfer.create")
+	defer func() { end(err) }()
+
+	created, sdkErr := Transfer.Create(sdkTransfers, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao criar transferências: %v", sdkErr.Errors)
 	}
 
 	// Converter de volta para domain.Transfer
@@ -44,7 +54,7 @@ func (r *StarkBankTransferRepository) Create(transfers []domain.Transfer) ([]dom
 	for i, t := range created {
 		result[i] = domain.Transfer{
 			ID:            t.Id,
-			Amount:        t.Amount,
+			Amount:        domain.BRL(int64(t.Amount)),
 			BankCode:      t.BankCode,
 			BranchCode:    t.BranchCode,
 			AccountNumber: t.AccountNumber,
@@ -52,8 +62,11 @@ func (r *StarkBankTransferRepository) Create(transfers []domain.Transfer) ([]dom
 			TaxID:         t.TaxId,
 			AccountType:   t.AccountType,
 			Description:   t.Description,
+			ExternalID:    t.ExternalId,
+			Tags:          t.Tags,
+			Scheduled:     t.Scheduled,
 			Status:        t.Status,
-			Fee:           t.Fee,
+			Fee:           domain.BRL(int64(t.Fee)),
 			Created:       t.Created,
 		}
 	}
@@ -62,15 +75,18 @@ func (r *StarkBankTransferRepository) Create(transfers []domain.Transfer) ([]dom
 }
 
 // GetByID busca uma transferência por ID
-func (r *StarkBankTransferRepository) GetByID(id string) (*domain.Transfer, error) {
-	t, err := Transfer.Get(id, nil)
-	if err.Errors != nil {
-		return nil, fmt.Errorf("erro ao buscar transferência: %v", err.Errors)
+func (r *StarkBankTransferRepository) GetByID(ctx context.Context, id string) (_ *domain.Transfer, err error) {
+	end := startSDKCall(ctx, "transfer.get")
+	defer func() { end(err) }()
+
+	t, sdkErr := Transfer.Get(id, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao buscar transferência: %v", sdkErr.Errors)
 	}
 
 	return &domain.Transfer{
 		ID:            t.Id,
-		Amount:        t.Amount,
+		Amount:        domain.BRL(int64(t.Amount)),
 		BankCode:      t.BankCode,
 		BranchCode:    t.BranchCode,
 		AccountNumber: t.AccountNumber,
@@ -78,19 +94,54 @@ func (r *StarkBankTransferRepository) GetByID(id string) (*domain.Transfer, erro
 		TaxID:         t.TaxId,
 		AccountType:   t.AccountType,
 		Description:   t.Description,
+		ExternalID:    t.ExternalId,
+		Tags:          t.Tags,
+		Scheduled:     t.Scheduled,
 		Status:        t.Status,
-		Fee:           t.Fee,
+		Fee:           domain.BRL(int64(t.Fee)),
+		Created:       t.Created,
+	}, nil
+}
+
+// Cancel cancela uma transferência ainda não processada (ex: agendada)
+func (r *StarkBankTransferRepository) Cancel(ctx context.Context, id string) (_ *domain.Transfer, err error) {
+	end := startSDKCall(ctx, "transfer.delete")
+	defer func() { end(err) }()
+
+	t, sdkErr := Transfer.Delete(id, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao cancelar transferência: %v", sdkErr.Errors)
+	}
+
+	return &domain.Transfer{
+		ID:            t.Id,
+		Amount:        domain.BRL(int64(t.Amount)),
+		BankCode:      t.BankCode,
+		BranchCode:    t.BranchCode,
+		AccountNumber: t.AccountNumber,
+		Name:          t.Name,
+		TaxID:         t.TaxId,
+		AccountType:   t.AccountType,
+		Description:   t.Description,
+		ExternalID:    t.ExternalId,
+		Tags:          t.Tags,
+		Scheduled:     t.Scheduled,
+		Status:        t.Status,
+		Fee:           domain.BRL(int64(t.Fee)),
 		Created:       t.Created,
 	}, nil
 }
 
 // List lista transferências
-func (r *StarkBankTransferRepository) List(limit int) ([]domain.Transfer, error) {
+func (r *StarkBankTransferRepository) List(ctx context.Context, limit int) (_ []domain.Transfer, err error) {
 	params := map[string]interface{}{
 		"limit": limit,
 	}
 
-	transfers, errChan := Transfer.Query(params, nil)
+	end := startSDKCall(ctx, "transfer.query")
+	defer func() { end(err) }()
+
+	transfers, errChan := Transfer.Query(params, r.sdkUser)
 	result := []domain.Transfer{}
 
 	for {
@@ -101,7 +152,7 @@ func (r *StarkBankTransferRepository) List(limit int) ([]domain.Transfer, error)
 			}
 			result = append(result, domain.Transfer{
 				ID:            t.Id,
-				Amount:        t.Amount,
+				Amount:        domain.BRL(int64(t.Amount)),
 				BankCode:      t.BankCode,
 				BranchCode:    t.BranchCode,
 				AccountNumber: t.AccountNumber,
@@ -109,13 +160,16 @@ func (r *StarkBankTransferRepository) List(limit int) ([]domain.Transfer, error)
 				TaxID:         t.TaxId,
 				AccountType:   t.AccountType,
 				Description:   t.Description,
+				ExternalID:    t.ExternalId,
+				Tags:          t.Tags,
+				Scheduled:     t.Scheduled,
 				Status:        t.Status,
-				Fee:           t.Fee,
+				Fee:           domain.BRL(int64(t.Fee)),
 				Created:       t.Created,
 			})
-		case err, ok := <-errChan:
-			if ok && err.Errors != nil {
-				return result, fmt.Errorf("erro ao listar transferências: %v", err.Errors)
+		case sdkErr, ok := <-errChan:
+			if ok && sdkErr.Errors != nil {
+				return result, fmt.Errorf("erro ao listar transferências: %v", sdkErr.Errors)
 			}
 			return result, nil
 		}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	Webhook "github.com/starkbank/sdk-go/starkbank/webhook"
+	"github.com/starkinfra/core-go/starkcore/user/user"
+)
+
+// StarkBankWebhookRepository implementa WebhookSubscriptionRepository usando o SDK da StarkBank
+type StarkBankWebhookRepository struct {
+	sdkUser user.User
+}
+
+// NewStarkBankWebhookRepository cria uma nova instância do repositório; as
+// chamadas ao SDK são assinadas com sdkUser (o projeto do tenant)
+func NewStarkBankWebhookRepository(sdkUser user.User) *StarkBankWebhookRepository {
+	return &StarkBankWebhookRepository{sdkUser: sdkUser}
+}
+
+// List lista todos os webhooks do projeto, percorrendo as páginas da API
+func (r *StarkBankWebhookRepository) List(ctx context.Context) (_ []domain.WebhookSubscription, err error) {
+	end := startSDKCall(ctx, "webhook.query")
+	defer func() { end(err) }()
+
+	var result []domain.WebhookSubscription
+	params := map[string]interface{}{"limit": 100}
+	for {
+		page, cursor, sdkErr := Webhook.Page(params, r.sdkUser)
+		if sdkErr.Errors != nil {
+			return nil, credentialsError("erro ao listar webhooks", sdkErr)
+		}
+		for _, w := range page {
+			result = append(result, toWebhookSubscription(w))
+		}
+		if cursor == "" {
+			return result, nil
+		}
+		params["cursor"] = cursor
+	}
+}
+
+// Create cadastra um webhook
+func (r *StarkBankWebhookRepository) Create(ctx context.Context, url string, subscriptions []string) (_ *domain.WebhookSubscription, err error) {
+	end := startSDKCall(ctx, "webhook.create")
+	defer func() { end(err) }()
+
+	created, sdkErr := Webhook.Create(Webhook.Webhook{Url: url, Subscriptions: subscriptions}, r.sdkUser)
+	if sdkErr.Errors != nil {
+		return nil, fmt.Errorf("erro ao criar webhook: %v", sdkErr.Errors)
+	}
+	w := toWebhookSubscription(created)
+	return &w, nil
+}
+
+// Delete remove um webhook
+func (r *StarkBankWebhookRepository) Delete(ctx context.Context, id string) (err error) {
+	end := startSDKCall(ctx, "webhook.delete")
+	defer func() { end(err) }()
+
+	if _, sdkErr := Webhook.Delete(id, r.sdkUser); sdkErr.Errors != nil {
+		return fmt.Errorf("erro ao remover webhook %s: %v", id, sdkErr.Errors)
+	}
+	return nil
+}
+
+func toWebhookSubscription(w Webhook.Webhook) domain.WebhookSubscription {
+	return domain.WebhookSubscription{ID: w.Id, URL: w.Url, Subscriptions: w.Subscriptions}
+}
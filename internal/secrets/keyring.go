@@ -0,0 +1,163 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/starkbank/ecdsa-go/v2/ellipticcurve/curve"
+	"github.com/starkbank/ecdsa-go/v2/ellipticcurve/privatekey"
+	"github.com/starkinfra/core-go/starkcore/user/project"
+	"github.com/starkinfra/core-go/starkcore/user/user"
+)
+
+// ErrKeyNotLoaded indica uma impressão digital que não está no chaveiro
+var ErrKeyNotLoaded = errors.New("chave não carregada")
+
+// KeyRing é o usuário do SDK de um projeto, capaz de trocar a chave de
+// assinatura sem reiniciar a aplicação
+//
+// Durante uma rotação o chaveiro mantém duas chaves: a ativa, usada em todas
+// as chamadas, e a nova (antes da troca) ou a anterior (até ser aposentada).
+type KeyRing struct {
+	projectID   string
+	environment string
+
+	mu     sync.RWMutex
+	active string               // impressão digital da chave ativa
+	keys   map[string]loadedKey // por impressão digital
+}
+
+type loadedKey struct {
+	pem    string
+	parsed privatekey.PrivateKey
+}
+
+// NewKeyRing cria o chaveiro com a chave ativa do projeto
+func NewKeyRing(projectID, environment, pemKey string) (*KeyRing, error) {
+	r := &KeyRing{
+		projectID:   projectID,
+		environment: environment,
+		keys:        make(map[string]loadedKey),
+	}
+	fingerprint, err := r.Add(pemKey)
+	if err != nil {
+		return nil, err
+	}
+	r.active = fingerprint
+	return r, nil
+}
+
+// GetAcessId implementa user.User
+func (r *KeyRing) GetAcessId() string {
+	return "project/" + r.projectID
+}
+
+// GetEnvironment implementa user.User
+func (r *KeyRing) GetEnvironment() string {
+	return r.environment
+}
+
+// GetPrivateKey implementa user.User com a chave ativa
+func (r *KeyRing) GetPrivateKey() *privatekey.PrivateKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key := r.keys[r.active].parsed
+	return &key
+}
+
+// Add valida e carrega uma chave sem ativá-la; retorna a impressão digital
+func (r *KeyRing) Add(pemKey string) (string, error) {
+	fingerprint, err := ValidatePrivateKey(pemKey)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := parseWithSDK(pemKey)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[fingerprint] = loadedKey{pem: pemKey, parsed: parsed}
+	return fingerprint, nil
+}
+
+// Activate passa a assinar com a chave carregada; a anterior continua carregada
+func (r *KeyRing) Activate(fingerprint string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[fingerprint]; !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotLoaded, fingerprint)
+	}
+	r.active = fingerprint
+	return nil
+}
+
+// Remove descarta uma chave carregada; a chave ativa não pode ser removida
+func (r *KeyRing) Remove(fingerprint string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fingerprint == r.active {
+		return fmt.Errorf("a chave ativa %s não pode ser removida", fingerprint)
+	}
+	delete(r.keys, fingerprint)
+	return nil
+}
+
+// Active retorna a impressão digital da chave ativa
+func (r *KeyRing) Active() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active
+}
+
+// Loaded lista as impressões digitais das chaves carregadas
+func (r *KeyRing) Loaded() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fingerprints := make([]string, 0, len(r.keys))
+	for fp := range r.keys {
+		fingerprints = append(fingerprints, fp)
+	}
+	sort.Strings(fingerprints)
+	return fingerprints
+}
+
+// User retorna um usuário do SDK fixo na chave indicada, usado para conferir
+// uma chave com a API antes de ativá-la
+func (r *KeyRing) User(fingerprint string) (user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[fingerprint]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotLoaded, fingerprint)
+	}
+	return project.Project{Id: r.projectID, Environment: r.environment, PrivateKey: key.pem}, nil
+}
+
+// PEM retorna a chave privada carregada com a impressão digital indicada
+func (r *KeyRing) PEM(fingerprint string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[fingerprint]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotLoaded, fingerprint)
+	}
+	return key.pem, nil
+}
+
+// GenerateKey gera um par de chaves secp256k1 no formato aceito pela StarkBank
+//
+// Retorna a chave privada e a pública em PEM; a pública é a que deve ser
+// cadastrada no painel do projeto.
+func GenerateKey() (privatePEM, publicPEM string, err error) {
+	key := privatekey.New(curve.Secp256k1)
+	privatePEM = strings.TrimSpace(key.ToPem())
+	if _, err := ValidatePrivateKey(privatePEM); err != nil {
+		return "", "", fmt.Errorf("chave gerada inválida: %w", err)
+	}
+	return privatePEM, strings.TrimSpace(key.PublicKey().ToPem()), nil
+}
@@ -0,0 +1,244 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Parâmetros do keystore
+const (
+	keystoreVersion    = 1
+	keystoreKDF        = "pbkdf2-sha256"
+	keystoreCipher     = "aes-256-gcm"
+	keystoreIterations = 600_000 // recomendação OWASP para PBKDF2-HMAC-SHA256
+	keystoreSaltSize   = 16
+	sealedPrefix       = "keystore:" // cópia cifrada da chave, ver SealPrivateKey
+)
+
+// ErrWrongPassphrase indica senha incorreta ou keystore adulterado
+var ErrWrongPassphrase = errors.New("senha do keystore incorreta ou arquivo adulterado")
+
+// Keystore é o arquivo JSON com a chave privada cifrada por uma senha
+//
+// A chave de cifra deriva da senha por PBKDF2-HMAC-SHA256 e o conteúdo é
+// cifrado com AES-256-GCM, que também detecta alterações no arquivo.
+type Keystore struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Cipher     string `json:"cipher"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Seal cifra a chave privada com a senha
+func Seal(privateKey, passphrase string) (*Keystore, error) {
+	if passphrase == "" {
+		return nil, errors.New("senha do keystore vazia")
+	}
+
+	ks := &Keystore{
+		Version:    keystoreVersion,
+		KDF:        keystoreKDF,
+		Iterations: keystoreIterations,
+		Salt:       make([]byte, keystoreSaltSize),
+		Cipher:     keystoreCipher,
+	}
+	if _, err := rand.Read(ks.Salt); err != nil {
+		return nil, fmt.Errorf("erro ao gerar salt: %w", err)
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ks.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(ks.Nonce); err != nil {
+		return nil, fmt.Errorf("erro ao gerar nonce: %w", err)
+	}
+	ks.Ciphertext = aead.Seal(nil, ks.Nonce, []byte(privateKey), ks.additionalData())
+	return ks, nil
+}
+
+// Open decifra a chave privada
+func (ks *Keystore) Open(passphrase string) (string, error) {
+	if ks.Version != keystoreVersion || ks.KDF != keystoreKDF || ks.Cipher != keystoreCipher {
+		return "", fmt.Errorf("formato de keystore não suportado (versão %d, %s, %s)", ks.Version, ks.KDF, ks.Cipher)
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := aead.Open(nil, ks.Nonce, ks.Ciphertext, ks.additionalData())
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(plaintext), nil
+}
+
+func (ks *Keystore) aead(passphrase string) (cipher.AEAD, error) {
+	if ks.Iterations < 1 || len(ks.Salt) == 0 {
+		return nil, errors.New("parâmetros de derivação do keystore inválidos")
+	}
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(passphrase), ks.Salt, ks.Iterations, 32))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData autentica os parâmetros junto com o conteúdo, impedindo que
+// sejam trocados no arquivo (ex: reduzir as iterações)
+func (ks *Keystore) additionalData() []byte {
+	return []byte(fmt.Sprintf("%d|%s|%d|%s", ks.Version, ks.KDF, ks.Iterations, ks.Cipher))
+}
+
+// WriteKeystore grava o keystore com permissão restrita ao dono; o arquivo
+// anterior só é substituído depois que o novo foi gravado por completo
+func WriteKeystore(path string, ks *Keystore) error {
+	content, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(content, '\n'))
+}
+
+// ReadKeystore lê um keystore gravado por WriteKeystore
+func ReadKeystore(path string) (*Keystore, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler keystore: %w", err)
+	}
+	var ks Keystore
+	if err := json.Unmarshal(content, &ks); err != nil {
+		return nil, fmt.Errorf("keystore inválido (%s): %w", path, err)
+	}
+	return &ks, nil
+}
+
+// KeystoreProvider decifra a chave de um keystore com a senha informada
+type KeystoreProvider struct {
+	path       string
+	passphrase func() (string, error)
+}
+
+// NewKeystoreProvider cria um provider para o keystore em path; passphrase é
+// consultada a cada leitura para que a senha não fique guardada no provider
+func NewKeystoreProvider(path string, passphrase func() (string, error)) *KeystoreProvider {
+	return &KeystoreProvider{path: path, passphrase: passphrase}
+}
+
+// Name identifica a origem
+func (p *KeystoreProvider) Name() string {
+	return "keystore:" + p.path
+}
+
+// PrivateKey lê e decifra o keystore
+func (p *KeystoreProvider) PrivateKey(ctx context.Context) (string, error) {
+	ks, err := ReadKeystore(p.path)
+	if err != nil {
+		return "", err
+	}
+	passphrase, err := p.passphrase()
+	if err != nil {
+		return "", fmt.Errorf("senha do keystore indisponível: %w", err)
+	}
+	return ks.Open(passphrase)
+}
+
+// StorePrivateKey cifra a nova chave com a mesma senha e substitui o keystore
+func (p *KeystoreProvider) StorePrivateKey(ctx context.Context, pemKey string) error {
+	passphrase, err := p.passphrase()
+	if err != nil {
+		return fmt.Errorf("senha do keystore indisponível: %w", err)
+	}
+	ks, err := Seal(pemKey, passphrase)
+	if err != nil {
+		return err
+	}
+	return WriteKeystore(p.path, ks)
+}
+
+// SealPrivateKey cifra uma cópia da chave com a senha do keystore; o
+// resultado é o keystore em JSON codificado em base64, com prefixo próprio
+func (p *KeystoreProvider) SealPrivateKey(ctx context.Context, pemKey string) (string, error) {
+	passphrase, err := p.passphrase()
+	if err != nil {
+		return "", fmt.Errorf("senha do keystore indisponível: %w", err)
+	}
+	ks, err := Seal(pemKey, passphrase)
+	if err != nil {
+		return "", err
+	}
+	content, err := json.Marshal(ks)
+	if err != nil {
+		return "", err
+	}
+	return sealedPrefix + base64.StdEncoding.EncodeToString(content), nil
+}
+
+// OpenPrivateKey decifra uma cópia gerada por SealPrivateKey
+func (p *KeystoreProvider) OpenPrivateKey(ctx context.Context, sealed string) (string, error) {
+	if !IsSealed(sealed) {
+		return "", errors.New("chave não está cifrada pelo keystore")
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("chave cifrada inválida: %w", err)
+	}
+	var ks Keystore
+	if err := json.Unmarshal(content, &ks); err != nil {
+		return "", fmt.Errorf("chave cifrada inválida: %w", err)
+	}
+	passphrase, err := p.passphrase()
+	if err != nil {
+		return "", fmt.Errorf("senha do keystore indisponível: %w", err)
+	}
+	return ks.Open(passphrase)
+}
+
+// IsSealed indica se o valor é uma chave cifrada por SealPrivateKey
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// pbkdf2SHA256 implementa PBKDF2 (RFC 8018) com HMAC-SHA256
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	blocks := (keyLen + prf.Size() - 1) / prf.Size()
+
+	var key []byte
+	u := make([]byte, 0, prf.Size())
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, uint32(block))
+		u = prf.Sum(u[:0])
+
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+)
+
+// Origens suportadas para a chave privada (starkbank.key_source)
+const (
+	SourceAuto     = "auto"
+	SourceEnv      = "env"
+	SourceFile     = "file"
+	SourceSecret   = "secret"
+	SourceKeystore = "keystore"
+)
+
+// Provider fornece a chave privada da StarkBank em PEM
+type Provider interface {
+	// Name identifica a origem nos logs (nunca inclui o conteúdo da chave)
+	Name() string
+	PrivateKey(ctx context.Context) (string, error)
+}
+
+// Store é implementado pelas origens que aceitam gravar uma nova chave
+//
+// Variáveis de ambiente e secrets montados são somente leitura para a
+// aplicação: após uma rotação, o operador atualiza a origem manualmente.
+type Store interface {
+	StorePrivateKey(ctx context.Context, pemKey string) error
+}
+
+// Sealer é implementado pelas origens que guardam a chave cifrada; cópias da
+// chave gravadas fora da origem (ex: registro de uma rotação) passam pela
+// mesma cifra para não ficarem em texto puro no disco
+type Sealer interface {
+	SealPrivateKey(ctx context.Context, pemKey string) (string, error)
+	OpenPrivateKey(ctx context.Context, sealed string) (string, error)
+}
+
+// NewProvider escolhe o provider conforme a configuração
+//
+// Com key_source=auto, usa PRIVATE_KEY quando informada e, senão, o arquivo
+// private_key_file, preservando o comportamento anterior.
+func NewProvider(cfg config.StarkBankConfig) (Provider, error) {
+	source := cfg.KeySource
+	if source == SourceAuto || source == "" {
+		source = SourceFile
+		if cfg.PrivateKey != "" {
+			source = SourceEnv
+		}
+	}
+
+	switch source {
+	case SourceEnv:
+		return NewEnvProvider("PRIVATE_KEY", cfg.PrivateKey), nil
+	case SourceFile:
+		return NewFileProvider(cfg.PrivateKeyFile), nil
+	case SourceSecret:
+		return NewMountProvider(cfg.SecretsDir, cfg.SecretName), nil
+	case SourceKeystore:
+		passphrase := func() (string, error) {
+			if cfg.KeystorePassphrase != "" {
+				return cfg.KeystorePassphrase, nil
+			}
+			return ReadFile(cfg.KeystorePassphraseFile)
+		}
+		return NewKeystoreProvider(cfg.KeystoreFile, passphrase), nil
+	default:
+		return nil, fmt.Errorf("origem de chave privada desconhecida: %s", cfg.KeySource)
+	}
+}
+
+// EnvProvider devolve a chave informada por variável de ambiente (ou flag)
+type EnvProvider struct {
+	name  string
+	value string
+}
+
+// NewEnvProvider cria um provider para o valor já lido de name
+func NewEnvProvider(name, value string) *EnvProvider {
+	return &EnvProvider{name: name, value: value}
+}
+
+// Name identifica a origem
+func (p *EnvProvider) Name() string {
+	return "env:" + p.name
+}
+
+// PrivateKey devolve a chave; aceita "\n" escapado, comum em arquivos .env
+func (p *EnvProvider) PrivateKey(ctx context.Context) (string, error) {
+	if p.value == "" {
+		return "", fmt.Errorf("%s não definida", p.name)
+	}
+	return strings.ReplaceAll(p.value, `\n`, "\n"), nil
+}
+
+// FileProvider lê a chave de um arquivo PEM
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider cria um provider para o arquivo em path
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+// Name identifica a origem
+func (p *FileProvider) Name() string {
+	return "file:" + p.path
+}
+
+// PrivateKey lê o arquivo a cada chamada
+func (p *FileProvider) PrivateKey(ctx context.Context) (string, error) {
+	return ReadFile(p.path)
+}
+
+// StorePrivateKey substitui o arquivo de forma atômica
+func (p *FileProvider) StorePrivateKey(ctx context.Context, pemKey string) error {
+	return writeFileAtomic(p.path, []byte(pemKey+"\n"))
+}
+
+// MountProvider lê a chave de um secret montado como arquivo
+//
+// O Docker monta secrets em /run/secrets/<nome>; no Kubernetes cada chave do
+// Secret vira um arquivo no diretório do volume (um link simbólico para a
+// versão atual, o que permite trocar a chave sem recriar o pod).
+type MountProvider struct {
+	dir  string
+	name string
+}
+
+// NewMountProvider cria um provider para o secret name em dir
+func NewMountProvider(dir, name string) *MountProvider {
+	return &MountProvider{dir: dir, name: name}
+}
+
+// Name identifica a origem
+func (p *MountProvider) Name() string {
+	return "secret:" + filepath.Join(p.dir, p.name)
+}
+
+// PrivateKey lê o secret montado
+func (p *MountProvider) PrivateKey(ctx context.Context) (string, error) {
+	key, err := ReadFile(filepath.Join(p.dir, p.name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("secret %q não montado em %s: %w", p.name, p.dir, err)
+	}
+	return key, err
+}
+
+// writeFileAtomic grava content em path (permissão 0600) via arquivo temporário e rename
+func writeFileAtomic(path string, content []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("erro ao substituir %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadFile lê um arquivo de segredo (chave, senha) sem espaços nas pontas
+func ReadFile(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("caminho do arquivo de segredo não informado")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	value := strings.TrimSpace(string(content))
+	if value == "" {
+		return "", fmt.Errorf("%s está vazio", path)
+	}
+	return value, nil
+}
@@ -0,0 +1,124 @@
+package secrets
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/starkbank/ecdsa-go/v2/ellipticcurve/curve"
+	"github.com/starkbank/ecdsa-go/v2/ellipticcurve/privatekey"
+)
+
+func TestValidatePrivateKey(t *testing.T) {
+	if _, err := ValidatePrivateKey(privatekey.New(curve.Secp256k1).ToPem()); err != nil {
+		t.Fatalf("chave secp256k1 válida rejeitada: %v", err)
+	}
+
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalECPrivateKey(p256)
+	cases := map[string]string{
+		"curva P-256": string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})),
+		"não é PEM":   "conteudo-da-chave-privada",
+		"tipo errado": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: der})),
+	}
+	for name, key := range cases {
+		if _, err := ValidatePrivateKey(key); err == nil {
+			t.Errorf("%s: chave inválida aceita", name)
+		}
+	}
+}
+
+func TestKeystoreProvider(t *testing.T) {
+	key := privatekey.New(curve.Secp256k1).ToPem()
+	path := filepath.Join(t.TempDir(), "keystore.json")
+
+	ks, err := Seal(key, "senha correta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteKeystore(path, ks); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewKeystoreProvider(path, func() (string, error) { return "senha correta", nil })
+	got, err := provider.PrivateKey(context.Background())
+	if err != nil || got != key {
+		t.Fatalf("chave decifrada diferente (erro %v)", err)
+	}
+
+	provider = NewKeystoreProvider(path, func() (string, error) { return "senha errada", nil })
+	if _, err := provider.PrivateKey(context.Background()); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("senha errada deveria falhar com ErrWrongPassphrase, obtido %v", err)
+	}
+}
+
+func TestPBKDF2Vector(t *testing.T) {
+	// RFC 7914, seção 11; c=80000 cobre a combinação das iterações
+	cases := []struct {
+		password, salt string
+		iterations     int
+		want           string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+			"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56" +
+			"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, c := range cases {
+		got := hex.EncodeToString(pbkdf2SHA256([]byte(c.password), []byte(c.salt), c.iterations, 64))
+		if got != c.want {
+			t.Errorf("pbkdf2(c=%d) = %s, esperado %s", c.iterations, got, c.want)
+		}
+	}
+}
+
+func TestKeyRingSwitchesSigningKey(t *testing.T) {
+	first, _, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, public, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(public, "PUBLIC KEY") {
+		t.Errorf("chave pública em formato inesperado: %q", public)
+	}
+
+	ring, err := NewKeyRing("123", "sandbox", first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstFP := ring.Active()
+	secondFP, err := ring.Add(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ring.Active() != firstFP {
+		t.Fatal("Add não deveria ativar a chave")
+	}
+
+	if err := ring.Activate(secondFP); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(ring.GetPrivateKey().ToPem()); got != second {
+		t.Error("SDK deveria assinar com a chave ativada")
+	}
+	if err := ring.Remove(secondFP); err == nil {
+		t.Error("a chave ativa não deveria ser removida")
+	}
+	if err := ring.Remove(firstFP); err != nil || len(ring.Loaded()) != 1 {
+		t.Errorf("chave anterior não removida: %v %v", err, ring.Loaded())
+	}
+	if ring.GetAcessId() != "project/123" {
+		t.Errorf("access id = %s", ring.GetAcessId())
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+)
+
+// AuditService grava e consulta a trilha de auditoria
+type AuditService struct {
+	repo domain.AuditRepository
+}
+
+// NewAuditService cria uma nova instância do serviço
+func NewAuditService(repo domain.AuditRepository) *AuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// AuditVerification resume a verificação da cadeia
+type AuditVerification struct {
+	Valid   bool   `json:"valid"`
+	Entries int    `json:"entries"`
+	Head    string `json:"head,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Record grava uma ação na trilha
+//
+// Falhas são registradas em log e não interrompem a ação auditada: ela já
+// aconteceu (ex: transferência criada) e não pode ser desfeita aqui.
+func (s *AuditService) Record(ctx context.Context, action, target string, before, after interface{}) {
+	entry := domain.AuditEntry{
+		Time:   time.Now().UTC(),
+		Actor:  domain.ActorFromContext(ctx),
+		Tenant: logging.Tenant(ctx),
+		Action: action,
+		Target: target,
+		Before: encodeAuditValue(ctx, before),
+		After:  encodeAuditValue(ctx, after),
+	}
+
+	recorded, err := s.repo.Append(entry)
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao gravar trilha de auditoria", "action", action, "target", target, "error", err)
+		return
+	}
+	slog.DebugContext(ctx, "ação auditada", "action", action, "target", target, "actor", recorded.Actor, "seq", recorded.Seq)
+}
+
+func encodeAuditValue(ctx context.Context, v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao codificar valor auditado", "error", err)
+		return nil
+	}
+	return data
+}
+
+// Query consulta a trilha, das entradas mais recentes para as mais antigas
+func (s *AuditService) Query(filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	return s.repo.List(filter)
+}
+
+// Verify confere o encadeamento de toda a trilha
+func (s *AuditService) Verify() (*AuditVerification, error) {
+	entries, err := s.repo.All()
+	if err != nil {
+		return nil, err
+	}
+	return VerifyAudit(entries), nil
+}
+
+// VerifyAudit confere uma trilha já carregada (usado também pela verificação offline)
+func VerifyAudit(entries []domain.AuditEntry) *AuditVerification {
+	result := &AuditVerification{Valid: true, Entries: len(entries)}
+	if err := domain.VerifyAuditChain(entries); err != nil {
+		result.Valid = false
+		result.Error = err.Error()
+		return result
+	}
+	if len(entries) > 0 {
+		result.Head = entries[len(entries)-1].Hash
+	}
+	return result
+}
+
+// RecordConfig registra a configuração em vigor quando ela difere da última auditada
+func (s *AuditService) RecordConfig(ctx context.Context, summary map[string]interface{}) {
+	current := encodeAuditValue(ctx, summary)
+
+	var previous interface{}
+	last, err := s.repo.List(domain.AuditFilter{Action: domain.AuditConfigChanged, Limit: 1})
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao consultar trilha de auditoria", "error", err)
+		return
+	}
+	if len(last) > 0 {
+		if bytes.Equal(last[0].After, current) {
+			return
+		}
+		previous = last[0].After
+	}
+
+	s.Record(ctx, domain.AuditConfigChanged, "config", previous, current)
+}
+
+// nopAuditor descarta as ações (usado quando a auditoria não é necessária, ex: testes)
+type nopAuditor struct{}
+
+func (nopAuditor) Record(context.Context, string, string, interface{}, interface{}) {}
+
+// NopAuditor é um Auditor que não grava nada
+var NopAuditor domain.Auditor = nopAuditor{}
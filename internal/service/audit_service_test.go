@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+)
+
+func TestAuditChainDetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := repository.NewFileAuditRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao criar trilha: %v", err)
+	}
+	svc := NewAuditService(repo)
+
+	admin := domain.ContextWithAPIKey(context.Background(), &domain.APIKey{ID: "key-1"})
+	svc.Record(admin, domain.AuditAPIKeyCreated, "key-2", nil, map[string]string{"role": "read"})
+	svc.Record(domain.ContextWithActor(context.Background(), domain.ActorWebhook),
+		domain.AuditTransferCreated, "tr-1", nil, map[string]interface{}{"amount": domain.BRL(1000)})
+	svc.RecordConfig(context.Background(), map[string]interface{}{"reversal_action": "manual_case"})
+	svc.RecordConfig(context.Background(), map[string]interface{}{"reversal_action": "manual_case"})
+	repo.Close()
+
+	// A trilha sobrevive à reabertura e a configuração repetida não é regravada
+	path := filepath.Join(dir, repository.AuditFileName)
+	entries, err := repository.ReadAuditFile(path)
+	if err != nil {
+		t.Fatalf("erro ao ler trilha: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("esperadas 3 entradas, obtidas %d", len(entries))
+	}
+	if entries[0].Actor != "api_key:key-1" || entries[1].Actor != domain.ActorWebhook {
+		t.Errorf("atores inesperados: %s, %s", entries[0].Actor, entries[1].Actor)
+	}
+	if result := VerifyAudit(entries); !result.Valid {
+		t.Fatalf("trilha íntegra reprovada: %s", result.Error)
+	}
+
+	// Alterar o valor de uma entrada quebra a cadeia
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), `"amount":1000`, `"amount":9900`, 1)
+	if tampered == string(data) {
+		t.Fatal("valor esperado não encontrado na trilha")
+	}
+	os.WriteFile(path, []byte(tampered), 0o600)
+
+	entries, _ = repository.ReadAuditFile(path)
+	result := VerifyAudit(entries)
+	if result.Valid || !strings.Contains(result.Error, "entrada 2") {
+		t.Errorf("adulteração da entrada 2 não detectada: %+v", result)
+	}
+}
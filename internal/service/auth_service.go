@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// Origem de uma chave de API
+const (
+	APIKeySourceConfig = "config"
+	APIKeySourceStore  = "store"
+)
+
+// apiKeyPrefix identifica as chaves geradas pela aplicação
+const apiKeyPrefix = "cjb_"
+
+// AuthService autentica chaves de API e gerencia as chaves armazenadas
+type AuthService struct {
+	repo       domain.APIKeyRepository
+	configured map[string]domain.APIKey // por hash
+	auditor    domain.Auditor
+}
+
+// NewAuthService cria uma nova instância do serviço
+func NewAuthService(repo domain.APIKeyRepository, keys []config.APIKeyConfig, auditor domain.Auditor) *AuthService {
+	configured := make(map[string]domain.APIKey, len(keys))
+	for _, k := range keys {
+		configured[k.Hash] = domain.APIKey{
+			ID:     "cfg-" + k.Name,
+			Name:   k.Name,
+			Role:   k.Role,
+			Hash:   k.Hash,
+			Source: APIKeySourceConfig,
+		}
+	}
+
+	return &AuthService{
+		repo:       repo,
+		configured: configured,
+		auditor:    auditor,
+	}
+}
+
+// HashAPIKey calcula o hash armazenado para uma chave
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate valida a chave e retorna os seus dados
+func (s *AuthService) Authenticate(ctx context.Context, key string) (*domain.APIKey, error) {
+	if key == "" {
+		return nil, domain.ErrUnauthenticated
+	}
+	hash := HashAPIKey(key)
+
+	if k, ok := s.configured[hash]; ok {
+		return &k, nil
+	}
+
+	k, err := s.repo.GetByHash(hash)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar chaves de API: %w", err)
+	}
+	if k.Revoked != nil {
+		slog.WarnContext(ctx, "chave de API revogada utilizada", "key_id", k.ID)
+		return nil, domain.ErrUnauthenticated
+	}
+	return k, nil
+}
+
+// Authorize verifica se a chave possui o papel necessário
+func (s *AuthService) Authorize(key *domain.APIKey, role string) error {
+	if key == nil {
+		return domain.ErrUnauthenticated
+	}
+	if !domain.RoleAllows(key.Role, role) {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// Create gera uma nova chave e retorna o valor em claro (exibido uma única vez)
+func (s *AuthService) Create(ctx context.Context, name, role string) (string, *domain.APIKey, error) {
+	if name == "" {
+		return "", nil, fmt.Errorf("nome da chave é obrigatório")
+	}
+	if !domain.ValidRole(role) {
+		return "", nil, fmt.Errorf("papel inválido: %s (use read, operator ou admin)", role)
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("erro ao gerar chave: %w", err)
+	}
+	plain := apiKeyPrefix + hex.EncodeToString(secret)
+
+	key := domain.APIKey{
+		ID:      "key-" + hex.EncodeToString(secret[:4]),
+		Name:    name,
+		Role:    role,
+		Hash:    HashAPIKey(plain),
+		Source:  APIKeySourceStore,
+		Created: time.Now(),
+	}
+	if err := s.repo.Save(key); err != nil {
+		return "", nil, fmt.Errorf("erro ao salvar chave: %w", err)
+	}
+
+	s.auditor.Record(ctx, domain.AuditAPIKeyCreated, key.ID, nil, map[string]interface{}{
+		"name": key.Name,
+		"role": key.Role,
+	})
+	slog.InfoContext(ctx, "chave de API criada", "key_id", key.ID, "role", key.Role)
+	return plain, &key, nil
+}
+
+// List lista as chaves configuradas e armazenadas
+func (s *AuthService) List() ([]domain.APIKey, error) {
+	stored, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]domain.APIKey, 0, len(s.configured)+len(stored))
+	for _, k := range s.configured {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return append(keys, stored...), nil
+}
+
+// Revoke revoga uma chave armazenada (chaves de configuração não podem ser revogadas pela API)
+func (s *AuthService) Revoke(ctx context.Context, id string) (*domain.APIKey, error) {
+	key, err := s.repo.GetByID(id)
+	if err != nil {
+		for _, k := range s.configured {
+			if k.ID == id {
+				return nil, fmt.Errorf("chave %s é definida em API_KEYS e só pode ser removida na configuração", id)
+			}
+		}
+		return nil, err
+	}
+	if key.Revoked != nil {
+		return key, nil
+	}
+
+	now := time.Now()
+	key.Revoked = &now
+	if err := s.repo.Save(*key); err != nil {
+		return nil, fmt.Errorf("erro ao revogar chave: %w", err)
+	}
+	s.auditor.Record(ctx, domain.AuditAPIKeyRevoked, key.ID,
+		map[string]interface{}{"role": key.Role, "revoked": false},
+		map[string]interface{}{"role": key.Role, "revoked": true})
+
+	slog.InfoContext(ctx, "chave de API revogada", "key_id", key.ID)
+	return key, nil
+}
+
+// HasKeys indica se existe ao menos uma chave ativa
+func (s *AuthService) HasKeys() bool {
+	if len(s.configured) > 0 {
+		return true
+	}
+	stored, err := s.repo.List()
+	if err != nil {
+		return false
+	}
+	for _, k := range stored {
+		if k.Revoked == nil {
+			return true
+		}
+	}
+	return false
+}
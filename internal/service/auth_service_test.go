@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+)
+
+func newTestAuthService(t *testing.T, keys ...config.APIKeyConfig) *AuthService {
+	t.Helper()
+	repo, err := repository.NewFileAPIKeyRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("erro ao criar repositório: %v", err)
+	}
+	return NewAuthService(repo, keys, NopAuditor)
+}
+
+func TestAuthenticateConfiguredKey(t *testing.T) {
+	svc := newTestAuthService(t, config.APIKeyConfig{Name: "grafana", Role: domain.RoleReadOnly, Hash: HashAPIKey("segredo")})
+	ctx := context.Background()
+
+	key, err := svc.Authenticate(ctx, "segredo")
+	if err != nil {
+		t.Fatalf("chave configurada deveria autenticar: %v", err)
+	}
+	if err := svc.Authorize(key, domain.RoleReadOnly); err != nil {
+		t.Errorf("read deveria acessar rotas de leitura: %v", err)
+	}
+	if err := svc.Authorize(key, domain.RoleOperator); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("read não deveria acessar rotas de operação: %v", err)
+	}
+
+	if _, err := svc.Authenticate(ctx, "outro"); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("chave desconhecida deveria ser recusada: %v", err)
+	}
+}
+
+func TestCreateAndRevokeKey(t *testing.T) {
+	svc := newTestAuthService(t)
+	ctx := context.Background()
+
+	plain, created, err := svc.Create(ctx, "ops", domain.RoleAdmin)
+	if err != nil {
+		t.Fatalf("erro ao criar chave: %v", err)
+	}
+	if created.Hash == plain {
+		t.Fatal("a chave não deve ser armazenada em claro")
+	}
+
+	key, err := svc.Authenticate(ctx, plain)
+	if err != nil {
+		t.Fatalf("chave criada deveria autenticar: %v", err)
+	}
+	if err := svc.Authorize(key, domain.RoleOperator); err != nil {
+		t.Errorf("admin deveria incluir operator: %v", err)
+	}
+
+	if _, err := svc.Revoke(ctx, created.ID); err != nil {
+		t.Fatalf("erro ao revogar chave: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, plain); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("chave revogada deveria ser recusada: %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
+)
+
+// BalanceService consulta o saldo, mantém o histórico e dispara alertas
+type BalanceService struct {
+	repo      domain.BalanceRepository
+	snapshots domain.BalanceSnapshotRepository
+	alerts    domain.BalanceAlertRepository
+	ledger    domain.LedgerRepository
+	cfg       config.BalanceConfig
+	fee       domain.Money // taxa estimada cobrada por transferência
+	stopChan  chan bool
+
+	mu sync.RWMutex // protege os limites de alerta de cfg, recarregáveis
+}
+
+// BalanceHistoryPoint agrega os snapshots de um intervalo de tempo
+type BalanceHistoryPoint struct {
+	Start time.Time    `json:"start"`
+	End   time.Time    `json:"end"`
+	Count int          `json:"count"`
+	Open  domain.Money `json:"open"`
+	Close domain.Money `json:"close"`
+	Min   domain.Money `json:"min"`
+	Max   domain.Money `json:"max"`
+}
+
+// NewBalanceService cria uma nova instância do serviço; fee é a taxa
+// estimada por transferência, descontada do saldo previsto pelo razão
+func NewBalanceService(
+	repo domain.BalanceRepository,
+	snapshots domain.BalanceSnapshotRepository,
+	alerts domain.BalanceAlertRepository,
+	ledger domain.LedgerRepository,
+	cfg config.BalanceConfig,
+	fee domain.Money,
+) *BalanceService {
+	return &BalanceService{
+		repo:      repo,
+		snapshots: snapshots,
+		alerts:    alerts,
+		ledger:    ledger,
+		cfg:       cfg,
+		fee:       fee,
+		stopChan:  make(chan bool),
+	}
+}
+
+// SetRules altera o limite de saldo baixo e a tolerância de divergência
+// avaliados nos próximos snapshots
+func (s *BalanceService) SetRules(lowThreshold, divergenceTolerance int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg.LowThreshold = lowThreshold
+	s.cfg.DivergenceTolerance = divergenceTolerance
+}
+
+// Current consulta o saldo atual, sem registrá-lo no histórico
+func (s *BalanceService) Current(ctx context.Context) (*domain.Balance, error) {
+	return s.repo.Get(ctx)
+}
+
+// StartSnapshots inicia a coleta periódica de snapshots de saldo; ctx é a
+// base das coletas (ex: identifica o tenant nos logs)
+func (s *BalanceService) StartSnapshots(ctx context.Context) {
+	slog.InfoContext(ctx, "iniciando snapshots de saldo", "interval", s.cfg.SnapshotInterval.String())
+
+	if err := s.Snapshot(ctx); err != nil {
+		slog.ErrorContext(ctx, "erro ao registrar snapshot inicial", "error", err)
+	}
+
+	ticker := time.NewTicker(s.cfg.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Snapshot(ctx); err != nil {
+				slog.ErrorContext(ctx, "erro ao registrar snapshot de saldo", "error", err)
+			}
+		case <-s.stopChan:
+			slog.InfoContext(ctx, "snapshots de saldo interrompidos")
+			return
+		}
+	}
+}
+
+// Snapshot executa uma coleta (agendada ou manual) em um trace próprio:
+// registra o saldo no histórico e avalia as regras de alerta
+func (s *BalanceService) Snapshot(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "BalanceService.Snapshot")
+	defer func() { tracing.End(span, err) }()
+
+	balance, err := s.Current(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.record(balance); err != nil {
+		return fmt.Errorf("erro ao registrar snapshot de saldo: %w", err)
+	}
+	return nil
+}
+
+// Stop para a coleta de snapshots
+func (s *BalanceService) Stop() {
+	close(s.stopChan)
+}
+
+// History agrega os snapshots do período em intervalos de tamanho fixo
+//
+// Com interval igual a zero, retorna um ponto por snapshot.
+func (s *BalanceService) History(from, to time.Time, interval time.Duration) ([]BalanceHistoryPoint, error) {
+	snapshots, err := s.snapshots.List(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []BalanceHistoryPoint{}
+	for _, snap := range snapshots {
+		start, end := snap.Taken, snap.Taken
+		if interval > 0 {
+			start = from.Add(snap.Taken.Sub(from) / interval * interval)
+			end = start.Add(interval)
+		}
+
+		last := len(points) - 1
+		if last >= 0 && points[last].Start.Equal(start) {
+			p := &points[last]
+			p.Count++
+			p.Close = snap.Amount
+			if c, _ := snap.Amount.Cmp(p.Min); c < 0 {
+				p.Min = snap.Amount
+			}
+			if c, _ := snap.Amount.Cmp(p.Max); c > 0 {
+				p.Max = snap.Amount
+			}
+			continue
+		}
+
+		points = append(points, BalanceHistoryPoint{
+			Start: start,
+			End:   end,
+			Count: 1,
+			Open:  snap.Amount,
+			Close: snap.Amount,
+			Min:   snap.Amount,
+			Max:   snap.Amount,
+		})
+	}
+
+	return points, nil
+}
+
+// Alerts lista os alertas disparados mais recentes
+func (s *BalanceService) Alerts(limit int) ([]domain.BalanceAlert, error) {
+	return s.alerts.List(limit)
+}
+
+// record registra o snapshot e avalia as regras de alerta contra o anterior
+func (s *BalanceService) record(balance *domain.Balance) error {
+	previous, err := s.snapshots.Last()
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	snapshot := domain.BalanceSnapshot{
+		Amount:  balance.Amount,
+		Updated: balance.Updated,
+		Taken:   time.Now(),
+	}
+	if err := s.snapshots.Save(snapshot); err != nil {
+		return err
+	}
+
+	s.checkLowBalance(previous, snapshot)
+	if previous != nil {
+		s.checkLedgerDivergence(*previous, snapshot)
+	}
+	return nil
+}
+
+// checkLowBalance dispara quando o saldo cruza o limite mínimo configurado
+func (s *BalanceService) checkLowBalance(previous *domain.BalanceSnapshot, current domain.BalanceSnapshot) {
+	s.mu.RLock()
+	low := s.cfg.LowThreshold
+	s.mu.RUnlock()
+	if low <= 0 {
+		return
+	}
+
+	threshold := domain.NewMoney(low, current.Amount.Currency())
+	if c, _ := current.Amount.Cmp(threshold); c >= 0 {
+		return
+	}
+
+	// Evitar alertas repetidos enquanto o saldo continua abaixo do limite
+	if previous != nil {
+		if c, _ := previous.Amount.Cmp(threshold); c < 0 {
+			return
+		}
+	}
+
+	s.fire(domain.BalanceAlert{
+		Rule:     domain.BalanceAlertLowBalance,
+		Message:  fmt.Sprintf("saldo %s abaixo do limite de %s", current.Amount, threshold),
+		Amount:   current.Amount,
+		Expected: threshold,
+		Fired:    current.Taken,
+	})
+}
+
+// checkLedgerDivergence compara a variação do saldo com a movimentação registrada no razão
+func (s *BalanceService) checkLedgerDivergence(previous, current domain.BalanceSnapshot) {
+	entries, err := s.ledger.List()
+	if err != nil {
+		slog.Error("erro ao consultar razão para conciliação de saldo", "error", err)
+		return
+	}
+
+	// Créditos líquidos entram na conta; repasses e transferências agendadas
+	// saem, cada transferência com a taxa estimada (uma transferência agrupada
+	// cobre vários invoices). Agendadas estornadas não saem nem pagam taxa;
+	// invoices estornados devolvem o valor ao pagador.
+	var movement int64
+	transfers := make(map[string]bool)
+	for _, e := range entries {
+		if !e.Created.After(previous.Taken) || e.Created.After(current.Taken) {
+			continue
+		}
+		for _, p := range e.Postings {
+			switch {
+			case e.Kind == domain.LedgerEntryInvoiceCredited && p.Account == domain.LedgerAccountPending:
+				movement += p.Debit
+			case e.Kind == domain.LedgerEntryTransferCreated && p.Account == domain.LedgerAccountForwarded:
+				movement -= p.Debit
+			case e.Kind == domain.LedgerEntryScheduledTransfer && p.Account == domain.LedgerAccountScheduled:
+				movement -= p.Debit
+			case e.Kind == domain.LedgerEntryScheduledReverted && p.Account == domain.LedgerAccountScheduled:
+				movement += p.Credit
+			case e.Kind == domain.LedgerEntryReversal && p.Account == domain.LedgerAccountReversed:
+				movement -= p.Debit
+			}
+		}
+		switch e.Kind {
+		case domain.LedgerEntryTransferCreated, domain.LedgerEntryScheduledTransfer:
+			transfers[e.TransferID] = true
+		case domain.LedgerEntryScheduledReverted:
+			delete(transfers, e.TransferID)
+		}
+	}
+	movement -= int64(len(transfers)) * s.fee.Cents()
+
+	expected, err := previous.Amount.Add(domain.NewMoney(movement, previous.Amount.Currency()))
+	if err != nil {
+		slog.Error("erro ao calcular saldo previsto", "error", err)
+		return
+	}
+
+	diff, err := current.Amount.Sub(expected)
+	if err != nil {
+		slog.Error("erro ao calcular divergência de saldo", "error", err)
+		return
+	}
+	s.mu.RLock()
+	tolerance := s.cfg.DivergenceTolerance
+	s.mu.RUnlock()
+	if diff.Cents() <= tolerance && -diff.Cents() <= tolerance {
+		return
+	}
+
+	s.fire(domain.BalanceAlert{
+		Rule:     domain.BalanceAlertDivergence,
+		Message:  fmt.Sprintf("saldo %s difere do previsto pelo razão (%s) em %s", current.Amount, expected, diff),
+		Amount:   current.Amount,
+		Expected: expected,
+		Fired:    current.Taken,
+	})
+}
+
+// fire registra e notifica um alerta
+func (s *BalanceService) fire(alert domain.BalanceAlert) {
+	slog.Warn("alerta de saldo", "rule", alert.Rule, "message", alert.Message, "amount", alert.Amount)
+
+	if err := s.alerts.Save(alert); err != nil {
+		slog.Error("erro ao registrar alerta de saldo", "error", err)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+)
+
+// stubBalanceRepo retorna o saldo atual da conta simulada
+type stubBalanceRepo struct {
+	amount int64
+}
+
+func (r *stubBalanceRepo) Get(ctx context.Context) (*domain.Balance, error) {
+	return &domain.Balance{Amount: domain.BRL(r.amount)}, nil
+}
+
+func newTestBalanceService(t *testing.T, cfg config.BalanceConfig, fee int64) (*BalanceService, *stubBalanceRepo, *LedgerService) {
+	t.Helper()
+	dir := t.TempDir()
+	ledgerRepo, err := repository.NewFileLedgerRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao abrir razão: %v", err)
+	}
+	t.Cleanup(func() { ledgerRepo.Close() })
+	snapshots, _ := repository.NewFileBalanceSnapshotRepository(dir)
+	alerts, _ := repository.NewFileBalanceAlertRepository(dir)
+
+	repo := &stubBalanceRepo{}
+	svc := NewBalanceService(repo, snapshots, alerts, ledgerRepo, cfg, domain.BRL(fee))
+	return svc, repo, NewLedgerService(ledgerRepo)
+}
+
+func TestBalanceCurrentDoesNotRecord(t *testing.T) {
+	svc, repo, _ := newTestBalanceService(t, config.BalanceConfig{LowThreshold: 1000}, 0)
+	repo.amount = 500
+
+	if _, err := svc.Current(context.Background()); err != nil {
+		t.Fatalf("erro ao consultar saldo: %v", err)
+	}
+	if _, err := svc.snapshots.Last(); err == nil {
+		t.Error("consulta do saldo não deveria registrar snapshot")
+	}
+	if alerts, _ := svc.Alerts(10); len(alerts) != 0 {
+		t.Errorf("consulta do saldo não deveria avaliar alertas: %+v", alerts)
+	}
+}
+
+func TestBalanceLowThresholdFiresOnCrossing(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestBalanceService(t, config.BalanceConfig{LowThreshold: 1000, DivergenceTolerance: 1 << 40}, 0)
+
+	for _, amount := range []int64{5000, 800, 700, 1500, 900} {
+		repo.amount = amount
+		if err := svc.Snapshot(ctx); err != nil {
+			t.Fatalf("erro no snapshot: %v", err)
+		}
+	}
+
+	// Alertas ao cruzar o limite (800 e 900), não enquanto continua abaixo (700)
+	alerts, _ := svc.Alerts(10)
+	if len(alerts) != 2 {
+		t.Fatalf("esperados 2 alertas, obtidos %d: %+v", len(alerts), alerts)
+	}
+	for _, a := range alerts {
+		if a.Rule != domain.BalanceAlertLowBalance {
+			t.Errorf("regra inesperada: %+v", a)
+		}
+	}
+}
+
+func TestBalanceLedgerDivergence(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, ledger := newTestBalanceService(t, config.BalanceConfig{}, 50)
+
+	repo.amount = 10000
+	svc.Snapshot(ctx)
+
+	// Crédito líquido de 1000 repassado em uma transferência com taxa de 50
+	ledger.RecordInvoiceCredit(ctx, "inv-1", domain.BRL(1100), domain.BRL(100))
+	ledger.RecordForwardIntent(ctx, "inv-1", domain.BRL(1000))
+	ledger.RecordForward(ctx, "inv-1", "tr-1", domain.BRL(1000))
+	repo.amount = 10000 - 50
+	svc.Snapshot(ctx)
+
+	if alerts, _ := svc.Alerts(10); len(alerts) != 0 {
+		t.Fatalf("repasse com taxa não deveria divergir do razão: %+v", alerts)
+	}
+
+	// Saída sem lançamento no razão
+	repo.amount = 9000
+	svc.Snapshot(ctx)
+
+	alerts, _ := svc.Alerts(10)
+	if len(alerts) != 1 || alerts[0].Rule != domain.BalanceAlertDivergence || alerts[0].Expected.Cents() != 9950 {
+		t.Errorf("esperado alerta de divergência com saldo previsto 9950: %+v", alerts)
+	}
+}
+
+func TestBalanceLedgerDivergenceScheduledTransfers(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, ledger := newTestBalanceService(t, config.BalanceConfig{}, 50)
+
+	repo.amount = 10000
+	svc.Snapshot(ctx)
+
+	// Transferência agendada de 2000 com taxa de 50; outra estornada não sai
+	ledger.RecordScheduledTransfer(ctx, "tr-1", domain.BRL(2000), time.Now())
+	ledger.RecordScheduledTransfer(ctx, "tr-2", domain.BRL(500), time.Now())
+	ledger.RecordScheduledReverted(ctx, "tr-2")
+	repo.amount = 10000 - 2000 - 50
+	svc.Snapshot(ctx)
+
+	if alerts, _ := svc.Alerts(10); len(alerts) != 0 {
+		t.Fatalf("transferência agendada no razão não deveria divergir: %+v", alerts)
+	}
+}
+
+func TestBalanceLedgerDivergenceReversal(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, ledger := newTestBalanceService(t, config.BalanceConfig{}, 50)
+
+	repo.amount = 10000
+	svc.Snapshot(ctx)
+
+	// Invoice estornado entre os snapshots: o valor devolvido sai do saldo
+	ledger.RecordReversal(ctx, "inv-1", domain.BRL(3000))
+	repo.amount = 10000 - 3000
+	svc.Snapshot(ctx)
+
+	if alerts, _ := svc.Alerts(10); len(alerts) != 0 {
+		t.Fatalf("estorno no razão não deveria divergir: %+v", alerts)
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
+)
+
+// seenRetention é por quanto tempo um evento processado fica no cursor; cobre
+// o dia repetido pela consulta (a API filtra por data) e diferenças de fuso
+const seenRetention = 48 * time.Hour
+
+// maxEventAttempts é quantas consultas seguidas um evento pode falhar antes
+// de ser descartado (ele segue disponível para POST /events/replay)
+const maxEventAttempts = 5
+
+// invoiceLogTypes são os logs que o pipeline do webhook trata
+var invoiceLogTypes = []string{"credited", "reversed"}
+
+// EventPollingService consulta periodicamente os eventos da StarkBank e os
+// processa pelo mesmo pipeline do webhook
+//
+// É a alternativa ao webhook para instalações sem URL pública (ex: atrás de
+// NAT): com source events, lê os eventos não entregues e os marca como
+// entregues; com invoice_logs, lê os logs de invoices e dispensa o webhook
+// cadastrado. O cursor persistido evita reprocessar eventos após reinícios.
+//
+// A consulta substitui o webhook, não o complementa: um evento entregue pelos
+// dois caminhos é processado duas vezes, e só o repasse descarta o invoice
+// repetido (ver ForwardingService.Submit).
+type EventPollingService struct {
+	policy    config.PollingConfig
+	events    domain.EventRepository
+	logs      domain.InvoiceLogRepository
+	cursors   domain.EventCursorRepository
+	processor domain.WebhookService
+	stopChan  chan bool
+
+	pollMu sync.Mutex // uma consulta por vez (agendada ou disparada pelo operador)
+
+	mu     sync.Mutex
+	status EventPollingStatus
+}
+
+// EventPollingStatus descreve o estado atual da consulta de eventos
+type EventPollingStatus struct {
+	Source    string     `json:"source"`
+	Cursor    *time.Time `json:"cursor,omitempty"`
+	LastPoll  *time.Time `json:"last_poll,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	Polls     int        `json:"polls"`
+	Processed int        `json:"processed"`
+}
+
+// NewEventPollingService cria uma nova instância do serviço com a política
+// de consulta (origem, intervalo e janela inicial)
+func NewEventPollingService(
+	policy config.PollingConfig,
+	events domain.EventRepository,
+	logs domain.InvoiceLogRepository,
+	cursors domain.EventCursorRepository,
+	processor domain.WebhookService,
+) *EventPollingService {
+	return &EventPollingService{
+		policy:    policy,
+		events:    events,
+		logs:      logs,
+		cursors:   cursors,
+		processor: processor,
+		stopChan:  make(chan bool),
+		status:    EventPollingStatus{Source: policy.Source},
+	}
+}
+
+// Enabled indica se a consulta de eventos está ativa
+func (s *EventPollingService) Enabled() bool {
+	return s.policy.Source != config.PollingOff
+}
+
+// Start inicia a consulta periódica; ctx é a base de cada consulta (ex:
+// identifica o tenant nos logs)
+func (s *EventPollingService) Start(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+
+	slog.InfoContext(ctx, "iniciando consulta de eventos",
+		"source", s.policy.Source,
+		"interval", s.policy.Interval.String())
+
+	if _, err := s.Poll(ctx); err != nil {
+		slog.ErrorContext(ctx, "erro ao consultar eventos", "error", err)
+	}
+
+	ticker := time.NewTicker(s.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Poll(ctx); err != nil {
+				slog.ErrorContext(ctx, "erro ao consultar eventos", "error", err)
+			}
+		case <-s.stopChan:
+			slog.InfoContext(ctx, "consulta de eventos interrompida")
+			return
+		}
+	}
+}
+
+// Stop para a consulta periódica
+func (s *EventPollingService) Stop() {
+	close(s.stopChan)
+}
+
+// Status retorna o estado atual da consulta
+func (s *EventPollingService) Status() EventPollingStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Poll consulta os eventos desde o cursor e os processa do mais antigo para
+// o mais recente, retornando quantos foram processados
+//
+// Um evento que falha não impede os seguintes: ele é tentado de novo nas
+// próximas consultas (o cursor não avança além dele) até maxEventAttempts
+// falhas, quando é descartado com um log de erro. O erro devolvido reúne as
+// falhas da consulta.
+func (s *EventPollingService) Poll(ctx context.Context) (processed int, err error) {
+	if !s.Enabled() {
+		return 0, errors.New("consulta de eventos desativada (polling.source=off)")
+	}
+
+	s.pollMu.Lock()
+	defer s.pollMu.Unlock()
+
+	ctx, span := tracing.Start(ctx, "EventPollingService.Poll")
+	defer func() {
+		tracing.End(span, err)
+		s.record(processed, err)
+	}()
+	ctx = domain.ContextWithActor(ctx, domain.ActorPolling)
+
+	cursor, err := s.cursors.Get()
+	if errors.Is(err, domain.ErrNotFound) {
+		cursor = &domain.EventCursor{After: time.Now().Add(-s.policy.Lookback)}
+	} else if err != nil {
+		return 0, fmt.Errorf("erro ao ler cursor de eventos: %w", err)
+	}
+	if cursor.Seen == nil {
+		cursor.Seen = make(map[string]time.Time)
+	}
+	if cursor.Attempts == nil {
+		cursor.Attempts = make(map[string]int)
+	}
+
+	events, err := s.fetch(ctx, cursor.After)
+	if err != nil {
+		return 0, err
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return created(events[i]).Before(created(events[j]))
+	})
+
+	var failures []error
+	retrying := false // um evento a repetir segura o cursor
+	advanced := false // cursor avançado sobre eventos já vistos, ainda não gravado
+	for _, event := range events {
+		if seen, ok := cursor.Seen[event.ID]; ok {
+			if !retrying && seen.After(cursor.After) {
+				cursor.After = seen // processado enquanto um evento anterior era repetido
+				advanced = true
+			}
+			continue
+		}
+		if err := s.process(ctx, event); err != nil {
+			cursor.Attempts[event.ID]++
+			attempts := cursor.Attempts[event.ID]
+			if attempts < maxEventAttempts {
+				failures = append(failures, fmt.Errorf("evento %s (tentativa %d de %d): %w", event.ID, attempts, maxEventAttempts, err))
+				retrying = true
+				if err := s.cursors.Save(*cursor); err != nil {
+					return processed, fmt.Errorf("erro ao salvar cursor de eventos: %w", err)
+				}
+				continue
+			}
+			slog.ErrorContext(ctx, "evento descartado após falhas seguidas; reprocesse com POST /events/replay",
+				"event_id", event.ID, "attempts", attempts, "error", err)
+			failures = append(failures, fmt.Errorf("evento %s descartado após %d falhas: %w", event.ID, attempts, err))
+		} else {
+			processed++
+		}
+		delete(cursor.Attempts, event.ID)
+
+		at := created(event)
+		cursor.Seen[event.ID] = at
+		if !retrying && at.After(cursor.After) {
+			cursor.After = at
+		}
+		for id, seen := range cursor.Seen {
+			if seen.Before(cursor.After.Add(-seenRetention)) {
+				delete(cursor.Seen, id)
+			}
+		}
+		if err := s.cursors.Save(*cursor); err != nil {
+			return processed, fmt.Errorf("erro ao salvar cursor de eventos: %w", err)
+		}
+		advanced = false
+	}
+	if advanced {
+		if err := s.cursors.Save(*cursor); err != nil {
+			return processed, fmt.Errorf("erro ao salvar cursor de eventos: %w", err)
+		}
+	}
+
+	if processed > 0 {
+		slog.InfoContext(ctx, "eventos consultados processados", "count", processed, "cursor", cursor.After)
+	}
+	return processed, errors.Join(failures...)
+}
+
+// fetch lista os eventos a partir do dia de after, conforme a origem
+func (s *EventPollingService) fetch(ctx context.Context, after time.Time) ([]domain.Event, error) {
+	filter := domain.EventFilter{After: &after}
+	if s.policy.Source == config.PollingInvoiceLogs {
+		return s.logs.List(ctx, filter, invoiceLogTypes...)
+	}
+	filter.Undelivered = true
+	return s.events.List(ctx, filter)
+}
+
+// process envia o evento ao pipeline do webhook e, com source events, o
+// marca como entregue para que não volte nas consultas
+func (s *EventPollingService) process(base context.Context, event domain.Event) error {
+	ctx := logging.WithEventID(base, event.ID)
+	webhook := event.Webhook
+
+	if err := s.processor.ProcessEvent(ctx, webhook); err != nil {
+		slog.ErrorContext(ctx, "erro ao processar evento consultado", "error", err)
+		metrics.ObserveWebhookEvent(webhook.Subscription, webhook.EventType, "error")
+		return err
+	}
+	metrics.ObserveWebhookEvent(webhook.Subscription, webhook.EventType, "processed")
+
+	if s.policy.Source == config.PollingEvents {
+		if err := s.events.MarkDelivered(ctx, event.ID); err != nil {
+			// Já processado: o cursor evita repeti-lo e o processamento é
+			// idempotente, então apenas registra a falha
+			slog.WarnContext(ctx, "erro ao marcar evento como entregue", "error", err)
+		}
+	}
+	return nil
+}
+
+// record registra o resultado de uma consulta no estado
+func (s *EventPollingService) record(processed int, err error) {
+	cursor, _ := s.cursors.Get()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.status.LastPoll = &now
+	s.status.Polls++
+	s.status.Processed += processed
+	s.status.LastError = ""
+	if err != nil {
+		s.status.LastError = err.Error()
+	}
+	if cursor != nil {
+		after := cursor.After
+		s.status.Cursor = &after
+	}
+}
+
+// created retorna a criação do evento (zero se ausente)
+func created(event domain.Event) time.Time {
+	if event.Created == nil {
+		return time.Time{}
+	}
+	return *event.Created
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+type fakeEventRepo struct {
+	events    []domain.Event
+	delivered []string
+}
+
+func (r *fakeEventRepo) Get(ctx context.Context, id string) (*domain.Event, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (r *fakeEventRepo) List(ctx context.Context, filter domain.EventFilter) ([]domain.Event, error) {
+	var result []domain.Event
+	for _, e := range r.events {
+		if !(filter.Undelivered && e.Delivered) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeEventRepo) MarkDelivered(ctx context.Context, id string) error {
+	r.delivered = append(r.delivered, id)
+	for i := range r.events {
+		if r.events[i].ID == id {
+			r.events[i].Delivered = true
+		}
+	}
+	return nil
+}
+
+type memoryCursorRepo struct {
+	cursor *domain.EventCursor
+}
+
+func (r *memoryCursorRepo) Get() (*domain.EventCursor, error) {
+	if r.cursor == nil {
+		return nil, domain.ErrNotFound
+	}
+	c := *r.cursor
+	return &c, nil
+}
+
+func (r *memoryCursorRepo) Save(cursor domain.EventCursor) error {
+	r.cursor = &cursor
+	return nil
+}
+
+type recordingProcessor struct {
+	processed []string
+	failOn    string
+}
+
+func (p *recordingProcessor) ProcessEvent(ctx context.Context, event domain.WebhookEvent) error {
+	if event.EventID == p.failOn {
+		return errors.New("falhou")
+	}
+	p.processed = append(p.processed, event.EventID)
+	return nil
+}
+
+func (p *recordingProcessor) VerifySignature(ctx context.Context, body, signature string) error {
+	return nil
+}
+
+func polledEvent(id string, created time.Time) domain.Event {
+	return domain.Event{
+		ID: id, Subscription: "invoice", Created: &created,
+		Webhook: domain.WebhookEvent{EventID: id, Subscription: "invoice", EventType: "credited"},
+	}
+}
+
+func TestEventPollingPoll(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	// A API retorna do mais recente para o mais antigo
+	repo := &fakeEventRepo{events: []domain.Event{
+		polledEvent("e3", now.Add(-time.Minute)),
+		polledEvent("e2", now.Add(-2*time.Minute)),
+		polledEvent("e1", now.Add(-3*time.Minute)),
+	}}
+	cursors := &memoryCursorRepo{}
+	processor := &recordingProcessor{failOn: "e2"}
+	policy := config.PollingConfig{Source: config.PollingEvents, Interval: time.Minute, Lookback: time.Hour}
+	svc := NewEventPollingService(policy, repo, nil, cursors, processor)
+
+	// O evento com falha não impede os seguintes, mas segura o cursor
+	n, err := svc.Poll(ctx)
+	if err == nil || n != 2 {
+		t.Fatalf("consulta deveria seguir após o evento com falha: n=%d err=%v", n, err)
+	}
+	if status := svc.Status(); status.LastError == "" {
+		t.Error("falha deveria ficar no estado da consulta")
+	}
+	if !cursors.cursor.After.Equal(*repo.events[2].Created) {
+		t.Errorf("cursor deveria parar antes do evento com falha: %v", cursors.cursor.After)
+	}
+
+	processor.failOn = ""
+	if n, err := svc.Poll(ctx); err != nil || n != 1 {
+		t.Fatalf("segunda consulta deveria processar o evento que falhou: n=%d err=%v", n, err)
+	}
+	want := []string{"e1", "e3", "e2"}
+	for i, id := range want {
+		if i >= len(processor.processed) || processor.processed[i] != id {
+			t.Fatalf("ordem de processamento inesperada: %v", processor.processed)
+		}
+	}
+	if !cursors.cursor.After.Equal(*repo.events[1].Created) {
+		t.Errorf("cursor deveria avançar até o evento reprocessado: %v", cursors.cursor.After)
+	}
+	if len(repo.delivered) != 3 {
+		t.Errorf("eventos processados deveriam ser marcados como entregues: %v", repo.delivered)
+	}
+
+	// Eventos já vistos não são reprocessados, mesmo que a API os retorne de novo
+	for i := range repo.events {
+		repo.events[i].Delivered = false
+	}
+	if n, err := svc.Poll(ctx); err != nil || n != 0 {
+		t.Errorf("eventos já processados não deveriam ser repetidos: n=%d err=%v", n, err)
+	}
+}
+
+func TestEventPollingDiscardsAfterAttempts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := &fakeEventRepo{events: []domain.Event{
+		polledEvent("e2", now.Add(-time.Minute)),
+		polledEvent("e1", now.Add(-2*time.Minute)),
+	}}
+	cursors := &memoryCursorRepo{}
+	processor := &recordingProcessor{failOn: "e1"}
+	policy := config.PollingConfig{Source: config.PollingEvents, Interval: time.Minute, Lookback: time.Hour}
+	svc := NewEventPollingService(policy, repo, nil, cursors, processor)
+
+	for i := 1; i < maxEventAttempts; i++ {
+		svc.Poll(ctx)
+		if cursors.cursor.Attempts["e1"] != i {
+			t.Fatalf("tentativa %d não registrada: %v", i, cursors.cursor.Attempts)
+		}
+	}
+	if _, err := svc.Poll(ctx); err == nil {
+		t.Fatal("descarte do evento deveria ser informado")
+	}
+	if _, ok := cursors.cursor.Seen["e1"]; !ok || len(cursors.cursor.Attempts) != 0 {
+		t.Errorf("evento deveria ser descartado após %d falhas: %+v", maxEventAttempts, cursors.cursor)
+	}
+	if !cursors.cursor.After.Equal(*repo.events[1].Created) {
+		t.Errorf("cursor deveria avançar após o descarte: %v", cursors.cursor.After)
+	}
+	if n, err := svc.Poll(ctx); err != nil || n != 0 {
+		t.Errorf("evento descartado não deveria ser tentado de novo: n=%d err=%v", n, err)
+	}
+}
+
+func TestEventPollingDisabled(t *testing.T) {
+	svc := NewEventPollingService(config.PollingConfig{Source: config.PollingOff}, nil, nil, &memoryCursorRepo{}, nil)
+	if svc.Enabled() {
+		t.Error("consulta com source off não deveria estar ativa")
+	}
+	if _, err := svc.Poll(context.Background()); err == nil {
+		t.Error("consulta desativada deveria retornar erro")
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
+)
+
+// EventService consulta os eventos da StarkBank e reprocessa os que não
+// chegaram (ou falharam) pelo webhook
+type EventService struct {
+	repo      domain.EventRepository
+	processor domain.WebhookService
+	auditor   domain.Auditor
+}
+
+// NewEventService cria uma nova instância do serviço
+func NewEventService(repo domain.EventRepository, processor domain.WebhookService, auditor domain.Auditor) *EventService {
+	return &EventService{
+		repo:      repo,
+		processor: processor,
+		auditor:   auditor,
+	}
+}
+
+// List lista os eventos do projeto
+func (s *EventService) List(ctx context.Context, filter domain.EventFilter) ([]domain.Event, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Replay busca o evento na StarkBank e o processa como se tivesse chegado
+// pelo webhook. O processamento é idempotente: um invoice já repassado não
+// gera nova transferência.
+func (s *EventService) Replay(ctx context.Context, id string) (_ *domain.Event, err error) {
+	ctx, span := tracing.Start(ctx, "EventService.Replay")
+	defer func() { tracing.End(span, err) }()
+
+	event, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = logging.WithEventID(ctx, event.ID)
+	slog.InfoContext(ctx, "reprocessando evento", "subscription", event.Subscription, "event_type", event.Type)
+	if err := s.processor.ProcessEvent(ctx, event.Webhook); err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, domain.AuditEventReplayed, event.ID, nil, map[string]interface{}{
+		"subscription": event.Subscription,
+		"type":         event.Type,
+		"resource_id":  event.ResourceID,
+	})
+	return event, nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// ForwardTarget é o destino dos repasses de invoices do tenant: a conta de
+// destino, uma chave Pix ou um BR Code
+type ForwardTarget struct {
+	Kind   string // config.ForwardToAccount, ForwardToPixKey ou ForwardToBRCode; vazio é a conta
+	PixKey string // normalizada no formato do DICT
+	TaxID  string // CPF/CNPJ do titular da chave, só com dígitos
+	BRCode string
+
+	// SingleUse marca um BR Code dinâmico, de uso único ou de valor fixo: ele
+	// recebe um único repasse, do valor do próprio código
+	SingleUse bool
+}
+
+// NewForwardTarget valida o destino dos repasses da política do tenant; uma
+// chave Pix inválida, sem o CPF/CNPJ do titular ou um BR Code malformado
+// retornam domain.ErrInvalidInput
+func NewForwardTarget(policy config.ForwardingConfig) (ForwardTarget, error) {
+	switch policy.Destination {
+	case config.ForwardToPixKey:
+		key, keyType, err := domain.ParsePixKey(policy.PixKey)
+		if err != nil {
+			return ForwardTarget{}, fmt.Errorf("forwarding.pix_key: %w", err)
+		}
+		// O DICT mascara o CPF/CNPJ do titular, que a StarkBank exige na
+		// transferência; em chaves CPF e CNPJ ele é a própria chave
+		taxID := key
+		if keyType != domain.PixKeyCPF && keyType != domain.PixKeyCNPJ {
+			var taxType string
+			taxID, taxType, err = domain.ParsePixKey(policy.PixKeyTaxID)
+			if err != nil || taxType != domain.PixKeyCPF && taxType != domain.PixKeyCNPJ {
+				return ForwardTarget{}, fmt.Errorf("forwarding.pix_key_tax_id: %w: CPF/CNPJ do titular obrigatório em chaves e-mail, telefone ou aleatória", domain.ErrInvalidInput)
+			}
+		}
+		return ForwardTarget{Kind: config.ForwardToPixKey, PixKey: key, TaxID: taxID}, nil
+	case config.ForwardToBRCode:
+		brcode := strings.TrimSpace(policy.BRCode)
+		info, err := domain.ParseBRCode(brcode)
+		if err != nil {
+			return ForwardTarget{}, fmt.Errorf("forwarding.brcode: %w", err)
+		}
+		return ForwardTarget{Kind: config.ForwardToBRCode, BRCode: brcode, SingleUse: !info.Reusable()}, nil
+	}
+	return ForwardTarget{Kind: config.ForwardToAccount}, nil
+}
+
+// kind retorna o tipo do destino, com a conta como padrão
+func (t ForwardTarget) kind() string {
+	if t.Kind == "" {
+		return config.ForwardToAccount
+	}
+	return t.Kind
+}
+
+// ForwardDestination é o destino dos repasses consultado na StarkBank
+type ForwardDestination struct {
+	Kind    string                `json:"kind"`
+	Account *domain.BankAccount   `json:"account,omitempty"`
+	PixKey  *domain.PixKey        `json:"pix_key,omitempty"`
+	BRCode  *domain.BRCodePreview `json:"brcode,omitempty"`
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+type stubPixKeyRepo struct {
+	key     domain.PixKey
+	lookups int
+}
+
+func (r *stubPixKeyRepo) Get(ctx context.Context, key string) (*domain.PixKey, error) {
+	r.lookups++
+	result := r.key
+	result.Key = key
+	return &result, nil
+}
+
+type recordingBRCodeRepo struct {
+	preview domain.BRCodePreview
+	paid    []domain.BRCodePayment
+}
+
+func (r *recordingBRCodeRepo) Preview(ctx context.Context, brcode string) (*domain.BRCodePreview, error) {
+	preview := r.preview
+	preview.BRCode = brcode
+	return &preview, nil
+}
+
+func (r *recordingBRCodeRepo) Create(ctx context.Context, payment domain.BRCodePayment) (*domain.BRCodePayment, error) {
+	payment.ID = "brp-1"
+	payment.Status = "created"
+	r.paid = append(r.paid, payment)
+	return &payment, nil
+}
+
+func (r *recordingBRCodeRepo) GetByID(ctx context.Context, id string) (*domain.BRCodePayment, error) {
+	return nil, domain.ErrNotFound
+}
+
+func TestForwardingToPixKey(t *testing.T) {
+	target, err := NewForwardTarget(config.ForwardingConfig{Destination: config.ForwardToPixKey, PixKey: "123.456.789-09"})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	pixKeys := &stubPixKeyRepo{key: domain.PixKey{
+		Type: domain.PixKeyCPF, Name: "Tesouraria", TaxID: "***.456.789-**",
+		ISPB: "20018183", BranchCode: "0001", AccountNumber: "123456-7", AccountType: "checking",
+	}}
+	svc, transfers := newTestForwardingServiceTo(t, config.ForwardingConfig{Mode: config.ForwardingImmediate},
+		config.ApprovalConfig{}, target, pixKeys, nil)
+
+	if _, err := svc.Submit(context.Background(), credit("inv-1", 5000)); err != nil {
+		t.Fatalf("erro ao repassar: %v", err)
+	}
+	if len(transfers.created) != 1 {
+		t.Fatalf("esperada uma transferência: %+v", transfers.created)
+	}
+	// ISPB como código do banco cria a transferência como Pix; em chave CPF,
+	// o CPF do titular é a própria chave, não o mascarado pelo DICT
+	tr := transfers.created[0]
+	if tr.BankCode != "20018183" || tr.AccountNumber != "123456-7" || tr.TaxID != "12345678909" {
+		t.Fatalf("transferência Pix inesperada: %+v", tr)
+	}
+	if pixKeys.lookups != 1 {
+		t.Errorf("esperada uma consulta ao DICT, obtido %d", pixKeys.lookups)
+	}
+}
+
+func TestForwardingToBRCode(t *testing.T) {
+	brcode := "00020126580014br.gov.bcb.pix0136a629532e-7693-4846-852d-1bbff817b5a8"
+	target, err := NewForwardTarget(config.ForwardingConfig{Destination: config.ForwardToBRCode, BRCode: brcode})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	brcodes := &recordingBRCodeRepo{preview: domain.BRCodePreview{
+		Status: "active", Name: "Parceiro", TaxID: "20.018.183/0001-80", AllowChange: true,
+	}}
+	svc, transfers := newTestForwardingServiceTo(t, config.ForwardingConfig{Mode: config.ForwardingImmediate},
+		config.ApprovalConfig{}, target, nil, brcodes)
+
+	transfer, err := svc.Submit(context.Background(), credit("inv-1", 5000))
+	if err != nil {
+		t.Fatalf("erro ao repassar: %v", err)
+	}
+	if len(transfers.created) != 0 || len(brcodes.paid) != 1 {
+		t.Fatalf("esperado um pagamento de BR Code e nenhuma transferência: %+v %+v", transfers.created, brcodes.paid)
+	}
+	if paid := brcodes.paid[0]; paid.Amount.Cents() != 5000 || paid.TaxID != "20.018.183/0001-80" {
+		t.Fatalf("pagamento inesperado: %+v", paid)
+	}
+	if transfer.Kind != domain.TransferKindBRCode || transfer.ID != "brp-1" {
+		t.Fatalf("resultado inesperado: %+v", transfer)
+	}
+
+	// Um valor fixo na consulta recusa o repasse: o crédito fica pendente
+	// em vez de falhar o evento, que a StarkBank reenviaria sem fim
+	brcodes.preview = domain.BRCodePreview{Status: "active", TaxID: "20.018.183/0001-80", Amount: domain.BRL(9000)}
+	if _, err := svc.Submit(context.Background(), credit("inv-2", 5000)); err != nil {
+		t.Fatalf("erro inesperado com valor fixo diferente: %v", err)
+	}
+	if len(brcodes.paid) != 1 {
+		t.Fatalf("nenhum pagamento esperado com valor fixo diferente")
+	}
+	if pending, _ := svc.pending.List(); len(pending) != 1 || pending[0].InvoiceID != "inv-2" {
+		t.Fatalf("esperado inv-2 pendente, obtido %+v", pending)
+	}
+}
+
+func TestForwardingToPixKeyWithTaxID(t *testing.T) {
+	for _, key := range []string{"financeiro@example.com", "+5511999998888", "a629532e-7693-4846-852d-1bbff817b5a8"} {
+		target, err := NewForwardTarget(config.ForwardingConfig{
+			Destination: config.ForwardToPixKey, PixKey: key, PixKeyTaxID: "123.456.789-09",
+		})
+		if err != nil {
+			t.Fatalf("%s: erro inesperado: %v", key, err)
+		}
+		pixKeys := &stubPixKeyRepo{key: domain.PixKey{
+			Name: "Tesouraria", TaxID: "***.456.789-**",
+			ISPB: "20018183", BranchCode: "0001", AccountNumber: "123456-7", AccountType: "checking",
+		}}
+		svc, transfers := newTestForwardingServiceTo(t, config.ForwardingConfig{Mode: config.ForwardingImmediate},
+			config.ApprovalConfig{}, target, pixKeys, nil)
+
+		if _, err := svc.Submit(context.Background(), credit("inv-1", 5000)); err != nil {
+			t.Fatalf("%s: erro ao repassar: %v", key, err)
+		}
+		// O documento configurado vai na transferência, não o mascarado pelo DICT
+		if len(transfers.created) != 1 || transfers.created[0].TaxID != "12345678909" {
+			t.Fatalf("%s: transferência Pix inesperada: %+v", key, transfers.created)
+		}
+
+		// A chave mudou de dono: o repasse fica pendente
+		pixKeys.key.TaxID = "***.999.888-**"
+		if _, err := svc.Submit(context.Background(), credit("inv-2", 5000)); err != nil {
+			t.Fatalf("%s: erro inesperado com titular divergente: %v", key, err)
+		}
+		if pending, _ := svc.pending.List(); len(transfers.created) != 1 || len(pending) != 1 {
+			t.Fatalf("%s: esperado inv-2 pendente sem transferência: %+v %+v", key, transfers.created, pending)
+		}
+	}
+}
+
+func TestNewForwardTargetRejectsInvalidKey(t *testing.T) {
+	if _, err := NewForwardTarget(config.ForwardingConfig{Destination: config.ForwardToPixKey, PixKey: "12345"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("esperado ErrInvalidInput, obtido %v", err)
+	}
+	// Chaves que não são CPF/CNPJ exigem o documento do titular
+	for _, taxID := range []string{"", "financeiro@example.com"} {
+		policy := config.ForwardingConfig{Destination: config.ForwardToPixKey, PixKey: "financeiro@example.com", PixKeyTaxID: taxID}
+		if _, err := NewForwardTarget(policy); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("pix_key_tax_id %q: esperado ErrInvalidInput, obtido %v", taxID, err)
+		}
+	}
+	if _, err := NewForwardTarget(config.ForwardingConfig{Destination: config.ForwardToBRCode, BRCode: "qualquer coisa"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("esperado ErrInvalidInput, obtido %v", err)
+	}
+}
+
+func TestForwardingToSingleUseBRCode(t *testing.T) {
+	// BR Code dinâmico: os dados e o valor vêm da URL do campo 25
+	dynamic := "00020101021226520014br.gov.bcb.pix2530pix.example.com/qr/v2/9d36b84f5204000053039865802BR"
+	target, err := NewForwardTarget(config.ForwardingConfig{Destination: config.ForwardToBRCode, BRCode: dynamic})
+	if err != nil {
+		t.Fatalf("BR Code dinâmico deveria ser aceito: %v", err)
+	}
+	if !target.SingleUse {
+		t.Fatalf("BR Code dinâmico deveria ser de uso único: %+v", target)
+	}
+	brcodes := &recordingBRCodeRepo{preview: domain.BRCodePreview{
+		Status: "active", Name: "Parceiro", TaxID: "20.018.183/0001-80", Amount: domain.BRL(5000),
+	}}
+	svc, _ := newTestForwardingServiceTo(t, config.ForwardingConfig{Mode: config.ForwardingImmediate},
+		config.ApprovalConfig{}, target, nil, brcodes)
+
+	// Valor diferente do código: o crédito aguarda
+	if _, err := svc.Submit(context.Background(), credit("inv-1", 3000)); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(brcodes.paid) != 0 {
+		t.Fatalf("nenhum pagamento esperado com valor diferente do código: %+v", brcodes.paid)
+	}
+
+	if _, err := svc.Submit(context.Background(), credit("inv-2", 5000)); err != nil {
+		t.Fatalf("erro ao repassar: %v", err)
+	}
+	if len(brcodes.paid) != 1 || brcodes.paid[0].Amount.Cents() != 5000 {
+		t.Fatalf("esperado um pagamento de 5000: %+v", brcodes.paid)
+	}
+
+	// O código foi pago: os próximos repasses aguardam um novo código, mesmo
+	// que a consulta ainda o mostre ativo
+	if _, err := svc.Submit(context.Background(), credit("inv-3", 5000)); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(brcodes.paid) != 1 {
+		t.Fatalf("BR Code de uso único pago duas vezes: %+v", brcodes.paid)
+	}
+	pending, _ := svc.pending.List()
+	if len(pending) != 2 {
+		t.Fatalf("esperados inv-1 e inv-3 pendentes, obtido %+v", pending)
+	}
+}
@@ -0,0 +1,1126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
+)
+
+// ForwardingService aplica a política de repasse aos invoices creditados
+//
+// Conforme a política, o crédito é repassado na hora, acumulado até o valor
+// mínimo ou até a próxima varredura (modo sweep), ou retido quando excede o
+// limite diário. Créditos pendentes são repassados juntos, em uma única
+// transferência, pelas varreduras periódicas. Repasses acima do limite de
+// aprovação, ou a uma conta diferente da padrão, aguardam a aprovação de
+// operadores. Cada decisão é registrada com a regra determinante e uma
+// explicação.
+type ForwardingService struct {
+	policy      config.ForwardingConfig
+	approval    config.ApprovalConfig
+	destination string // chave da conta de destino no limite por destino
+	nonDefault  bool   // o tenant repassa a uma conta diferente da global
+	transfers   *TransferService
+	ledger      *LedgerService
+	forwards    domain.ForwardRepository
+	pending     domain.PendingCreditRepository
+	decisions   domain.ForwardDecisionRepository
+	approvals   domain.TransferApprovalRepository
+	usage       *DestinationUsage
+	location    *time.Location // fuso em que os limites diários são renovados
+	auditor     domain.Auditor
+	stopChan    chan bool
+
+	// Decisões e repasses acontecem em série: o limite diário de cada um
+	// depende do que os anteriores repassaram
+	mu sync.Mutex
+}
+
+// ForwardingStatus resume a política vigente e os créditos pendentes
+type ForwardingStatus struct {
+	Mode           string                 `json:"mode"`
+	Destination    string                 `json:"destination"` // account, pix_key ou brcode
+	MinAmount      domain.Money           `json:"min_amount"`
+	SweepInterval  string                 `json:"sweep_interval"`
+	DailyCap       domain.Money           `json:"daily_cap"`
+	DestinationCap domain.Money           `json:"destination_daily_cap"`
+	ForwardedToday domain.Money           `json:"forwarded_today"`
+	Remaining      *domain.Money          `json:"remaining,omitempty"` // ausente sem limite diário
+	PendingTotal   domain.Money           `json:"pending_total"`
+	Pending        []domain.PendingCredit `json:"pending"`
+
+	ApprovalThreshold domain.Money              `json:"approval_threshold"`
+	AwaitingApproval  []domain.TransferApproval `json:"awaiting_approval"`
+}
+
+// NewForwardingService cria uma nova instância do serviço com as políticas de
+// repasse e de aprovação do tenant; o destino dos repasses é o de transfers,
+// defaultDestination é a conta de destino global, usage soma os repasses de
+// todos os tenants ao mesmo destino e os limites diários são renovados à
+// meia-noite em location
+func NewForwardingService(
+	policy config.ForwardingConfig,
+	approval config.ApprovalConfig,
+	defaultDestination config.DestinationAccount,
+	transfers *TransferService,
+	ledger *LedgerService,
+	forwards domain.ForwardRepository,
+	pending domain.PendingCreditRepository,
+	decisions domain.ForwardDecisionRepository,
+	approvals domain.TransferApprovalRepository,
+	usage *DestinationUsage,
+	location *time.Location,
+	auditor domain.Auditor,
+) *ForwardingService {
+	key := transfers.DestinationKey()
+	usage.Register(key, ledger.ForwardedSince)
+	return &ForwardingService{
+		policy:      policy,
+		approval:    approval,
+		destination: key,
+		nonDefault:  key != DestinationKey(defaultDestination),
+		transfers:   transfers,
+		ledger:      ledger,
+		forwards:    forwards,
+		pending:     pending,
+		decisions:   decisions,
+		approvals:   approvals,
+		usage:       usage,
+		location:    location,
+		auditor:     auditor,
+		stopChan:    make(chan bool),
+	}
+}
+
+// Submit aplica a política a um invoice creditado
+//
+// Retorna a transferência criada, ou nil se o crédito ficou pendente (ou foi
+// repassado por uma transferência agrupada) ou se o invoice já foi submetido
+// (evento duplicado). Retorna
+// domain.ErrInsufficientBalance se o saldo não cobrir o repasse imediato. Um
+// repasse recusado pelo destino (domain.ErrInvalidInput) fica pendente.
+func (s *ForwardingService) Submit(ctx context.Context, event domain.WebhookEvent) (*domain.Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Evento reenviado: o repasse deste invoice já foi feito ou está na fila.
+	// A verificação fica sob s.mu para que entregas simultâneas do mesmo
+	// evento (webhook e consulta de eventos) não criem duas transferências
+	if duplicate, err := s.submitted(ctx, event.InvoiceID); err != nil || duplicate {
+		return nil, err
+	}
+
+	net, err := event.Amount.Sub(event.Fee)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular valor líquido: %w", err)
+	}
+	credit := domain.PendingCredit{InvoiceID: event.InvoiceID, Event: event, Net: net, Created: time.Now()}
+	minimum := domain.BRL(s.policy.MinAmount)
+
+	if s.policy.Mode == config.ForwardingSweep {
+		credit.Action = domain.ForwardActionAccumulate
+		return nil, s.pend(ctx, credit, domain.ForwardRuleSweep,
+			fmt.Sprintf("modo sweep: %s acumulado para a próxima varredura (a cada %s)", net, s.policy.SweepInterval))
+	}
+
+	if s.policy.MinAmount > 0 && net.Cents() < s.policy.MinAmount {
+		credit.Action = domain.ForwardActionAccumulate
+		total, err := s.pendingTotal(domain.ForwardActionAccumulate)
+		if err != nil {
+			return nil, err
+		}
+		total += net.Cents()
+		if total < s.policy.MinAmount {
+			return nil, s.pend(ctx, credit, domain.ForwardRuleMinAmount,
+				fmt.Sprintf("valor líquido %s abaixo do mínimo %s; acumulado %s", net, minimum, domain.BRL(total)))
+		}
+		if err := s.pending.Save(credit); err != nil {
+			return nil, fmt.Errorf("erro ao registrar crédito pendente: %w", err)
+		}
+		_, err = s.sweep(ctx, domain.ForwardRuleMinAmount,
+			fmt.Sprintf("acumulado %s atingiu o mínimo %s", domain.BRL(total), minimum))
+		return nil, err
+	}
+
+	held, err := s.pendingTotal(domain.ForwardActionHold)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := s.capacity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if held > 0 {
+		// Retidos anteriores têm prioridade quando o limite for renovado
+		credit.Action = domain.ForwardActionHold
+		return nil, s.pend(ctx, credit, limit.rule,
+			fmt.Sprintf("há %s retidos pelo limite diário à frente; %s aguarda na fila", domain.BRL(held), net))
+	}
+	if limit.exceeded(net.Cents()) {
+		credit.Action = domain.ForwardActionHold
+		return nil, s.pend(ctx, credit, limit.rule,
+			fmt.Sprintf("valor líquido %s excede o restante de %s (%s)", net, domain.BRL(limit.remaining), limit.describe))
+	}
+	if reason, required := s.requiresApproval(net); required {
+		_, err := s.requestApproval(ctx, []domain.PendingCredit{credit}, reason)
+		return nil, err
+	}
+
+	credits := []domain.PendingCredit{credit}
+	transfer, err := s.send(ctx, credits, func() (*domain.Transfer, error) {
+		return s.transfers.CreateFromInvoicePayment(ctx, event.InvoiceID, event.Amount, event.Fee)
+	})
+	if errors.Is(err, domain.ErrInvalidInput) {
+		// O destino recusou o repasse: reenviar o evento não muda a resposta,
+		// então o crédito aguarda a próxima varredura
+		credit.Action = domain.ForwardActionAccumulate
+		return nil, s.pend(ctx, credit, domain.ForwardRuleDestination,
+			fmt.Sprintf("destino recusou o repasse de %s; acumulado para a próxima varredura: %v", net, err))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, transfer, credits, nil); err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("valor líquido %s repassado imediatamente", net)
+	if s.policy.MinAmount > 0 {
+		reason += fmt.Sprintf("; mínimo %s atendido", minimum)
+	}
+	if limit.limited {
+		reason += fmt.Sprintf("; restante de %s (%s)", domain.BRL(limit.remaining), limit.describe)
+	}
+	s.decide(ctx, domain.ForwardDecision{
+		Action:     domain.ForwardActionTransfer,
+		Rule:       domain.ForwardRuleNone,
+		InvoiceIDs: []string{event.InvoiceID},
+		Amount:     transfer.Amount,
+		TransferID: transfer.ID,
+		Reason:     reason,
+	})
+	return transfer, nil
+}
+
+// Sweep repassa os créditos pendentes em uma transferência, respeitando o
+// valor mínimo e os limites diários; antes, encerra as solicitações de
+// aprovação vencidas
+//
+// Retorna a decisão tomada, ou nil se não houver créditos pendentes.
+func (s *ForwardingService) Sweep(ctx context.Context) (*domain.ForwardDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.expireApprovals(ctx); err != nil {
+		return nil, err
+	}
+	return s.sweep(ctx, domain.ForwardRuleSweep, "varredura")
+}
+
+// sweep repassa os créditos pendentes em ordem de chegada, até o limite
+// diário; rule e trigger identificam o motivo da varredura
+func (s *ForwardingService) sweep(ctx context.Context, rule, trigger string) (*domain.ForwardDecision, error) {
+	credits, err := s.pending.List()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar créditos pendentes: %w", err)
+	}
+
+	// Um crédito já repassado (ex: falha entre a transferência e a limpeza
+	// da fila) não pode entrar em outro repasse
+	var queue []domain.PendingCredit
+	for _, c := range credits {
+		if c.Action == domain.ForwardActionApproval || c.Action == domain.ForwardActionPayerHold {
+			continue // reservado para uma solicitação de aprovação ou bloqueado por estorno
+		}
+		forwarded, err := s.ledger.HasForward(c.InvoiceID)
+		if err != nil {
+			return nil, err
+		}
+		if forwarded {
+			slog.WarnContext(ctx, "crédito pendente já repassado, removendo da fila", "invoice_id", c.InvoiceID)
+			if err := s.pending.Delete(c.InvoiceID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		queue = append(queue, c)
+	}
+	if len(queue) == 0 {
+		return nil, nil
+	}
+
+	limit, err := s.capacity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Em ordem de chegada: o primeiro que não cabe encerra a seleção
+	var selected []domain.PendingCredit
+	var total int64
+	for _, c := range queue {
+		if limit.exceeded(total + c.Net.Cents()) {
+			break
+		}
+		selected = append(selected, c)
+		total += c.Net.Cents()
+	}
+	pendingTotal := sumCredits(queue)
+
+	// O que não coube no limite fica retido até a renovação
+	for _, c := range queue[len(selected):] {
+		if c.Action != domain.ForwardActionHold {
+			c.Action = domain.ForwardActionHold
+			if err := s.pending.Save(c); err != nil {
+				return nil, fmt.Errorf("erro ao atualizar crédito pendente: %w", err)
+			}
+		}
+	}
+
+	skip := func(rule, reason string) (*domain.ForwardDecision, error) {
+		return s.decide(ctx, domain.ForwardDecision{
+			Action:     domain.ForwardActionSkip,
+			Rule:       rule,
+			InvoiceIDs: creditIDs(queue),
+			Amount:     domain.BRL(pendingTotal),
+			Reason:     fmt.Sprintf("%s: %s", trigger, reason),
+		}), nil
+	}
+	if len(selected) == 0 {
+		return skip(limit.rule, fmt.Sprintf("limite diário esgotado (%s); %d créditos (%s) aguardam a renovação",
+			limit.describe, len(queue), domain.BRL(pendingTotal)))
+	}
+	if s.policy.MinAmount > 0 && total < s.policy.MinAmount {
+		return skip(domain.ForwardRuleMinAmount, fmt.Sprintf("total %s abaixo do mínimo %s",
+			domain.BRL(total), domain.BRL(s.policy.MinAmount)))
+	}
+
+	if reason, required := s.requiresApproval(domain.BRL(total)); required {
+		return s.requestApproval(ctx, selected, fmt.Sprintf("%s: %s", trigger, reason))
+	}
+
+	ids := creditIDs(selected)
+	transfer, err := s.send(ctx, selected, func() (*domain.Transfer, error) {
+		return s.transfers.CreateFromCredits(ctx, ids, domain.BRL(total))
+	})
+	var insufficient *domain.InsufficientBalanceError
+	if errors.As(err, &insufficient) {
+		return skip(domain.ForwardRuleBalance, fmt.Sprintf("saldo insuficiente (necessário %s, disponível %s)",
+			insufficient.Required, insufficient.Available))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A transferência já foi criada: a fila é limpa antes dos registros, e a
+	// verificação no razão acima cobre uma falha aqui
+	if err := s.pending.Delete(ids...); err != nil {
+		slog.ErrorContext(ctx, "erro ao remover créditos repassados da fila", "transfer_id", transfer.ID, "error", err)
+	}
+	if err := s.record(ctx, transfer, selected, nil); err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("%s: %d créditos repassados em uma transferência de %s", trigger, len(selected), transfer.Amount)
+	if held := len(queue) - len(selected); held > 0 {
+		reason += fmt.Sprintf("; %d retidos pelo limite diário (%s)", held, limit.describe)
+	}
+	return s.decide(ctx, domain.ForwardDecision{
+		Action:     domain.ForwardActionSweep,
+		Rule:       rule,
+		InvoiceIDs: ids,
+		Amount:     transfer.Amount,
+		TransferID: transfer.ID,
+		Reason:     reason,
+	}), nil
+}
+
+// HoldPayer guarda na fila o crédito de um pagador com estorno pendente; ele
+// fica fora das varreduras até ReleasePayer
+func (s *ForwardingService) HoldPayer(ctx context.Context, event domain.WebhookEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if duplicate, err := s.submitted(ctx, event.InvoiceID); err != nil || duplicate {
+		return err
+	}
+	net, err := event.Amount.Sub(event.Fee)
+	if err != nil {
+		return fmt.Errorf("erro ao calcular valor líquido: %w", err)
+	}
+	credit := domain.PendingCredit{
+		InvoiceID: event.InvoiceID,
+		Event:     event,
+		Net:       net,
+		Action:    domain.ForwardActionPayerHold,
+		Created:   time.Now(),
+	}
+	return s.pend(ctx, credit, domain.ForwardRulePayerHold,
+		fmt.Sprintf("pagador com estorno pendente: %s aguarda a resolução do estorno", net))
+}
+
+// ReleasePayer devolve à fila os créditos bloqueados do pagador (estorno
+// resolvido) e os repassa em uma varredura; retorna quantos foram liberados
+//
+// Uma falha na varredura não desfaz a liberação: os créditos seguem para a
+// próxima varredura periódica.
+func (s *ForwardingService) ReleasePayer(ctx context.Context, taxID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	credits, err := s.pending.List()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar créditos pendentes: %w", err)
+	}
+	var released []domain.PendingCredit
+	for _, c := range credits {
+		if c.Action != domain.ForwardActionPayerHold || normalizeTaxID(c.Event.PayerTaxID) != normalizeTaxID(taxID) {
+			continue
+		}
+		c.Action = domain.ForwardActionAccumulate
+		if err := s.pending.Save(c); err != nil {
+			return len(released), fmt.Errorf("erro ao liberar crédito pendente: %w", err)
+		}
+		released = append(released, c)
+	}
+	if len(released) == 0 {
+		return 0, nil
+	}
+
+	s.decide(ctx, domain.ForwardDecision{
+		Action:     domain.ForwardActionAccumulate,
+		Rule:       domain.ForwardRulePayerHold,
+		InvoiceIDs: creditIDs(released),
+		Amount:     domain.BRL(sumCredits(released)),
+		Reason:     fmt.Sprintf("estorno do pagador resolvido: %d créditos liberados para repasse", len(released)),
+	})
+	if _, err := s.sweep(ctx, domain.ForwardRulePayerHold, "pagador liberado"); err != nil {
+		slog.ErrorContext(ctx, "erro ao repassar créditos liberados; seguem para a próxima varredura", "error", err)
+	}
+	return len(released), nil
+}
+
+// Cancel remove o crédito pendente de um invoice (ex: estornado antes do
+// repasse); retorna false se o invoice não estava pendente
+func (s *ForwardingService) Cancel(ctx context.Context, invoiceID, reason string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	credit, err := s.pending.GetByInvoiceID(invoiceID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if credit.ApprovalID != "" {
+		// A solicitação cobre o crédito estornado: é encerrada, e os demais
+		// créditos voltam à fila para a próxima varredura
+		approval, err := s.approvals.Get(credit.ApprovalID)
+		if err != nil {
+			return false, fmt.Errorf("erro ao consultar solicitação de aprovação: %w", err)
+		}
+		if approval.Status == domain.ApprovalStatusPending {
+			if err := s.close(ctx, approval, domain.ApprovalStatusRejected, "invoice estornado: "+invoiceID, true); err != nil {
+				return false, err
+			}
+		}
+	}
+	if err := s.pending.Delete(invoiceID); err != nil {
+		return false, err
+	}
+	s.decide(ctx, domain.ForwardDecision{
+		Action:     domain.ForwardActionSkip,
+		Rule:       domain.ForwardRuleNone,
+		InvoiceIDs: []string{invoiceID},
+		Amount:     credit.Net,
+		Reason:     "crédito removido da fila: " + reason,
+	})
+	return true, nil
+}
+
+// submitted indica se o invoice já foi repassado ou aguarda na fila; deve
+// ser chamado com s.mu
+func (s *ForwardingService) submitted(ctx context.Context, invoiceID string) (bool, error) {
+	forwarded, err := s.ledger.HasForward(invoiceID)
+	if err != nil {
+		return false, err
+	}
+	if forwarded {
+		slog.InfoContext(ctx, "invoice já repassado, ignorando evento duplicado", "invoice_id", invoiceID)
+		return true, nil
+	}
+	pending, err := s.IsPending(invoiceID)
+	if err != nil {
+		return false, err
+	}
+	if pending {
+		slog.InfoContext(ctx, "invoice já aguarda repasse, ignorando evento duplicado", "invoice_id", invoiceID)
+	}
+	return pending, nil
+}
+
+// Reserved soma os créditos que aguardam repasse na fila da política,
+// inclusive os reservados para aprovação, que não podem ser varridos do saldo
+func (s *ForwardingService) Reserved() (domain.Money, error) {
+	credits, err := s.pending.List()
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("erro ao consultar créditos pendentes: %w", err)
+	}
+	var total int64
+	for _, c := range credits {
+		total += c.Net.Cents()
+	}
+	return domain.BRL(total), nil
+}
+
+// IsPending indica se o invoice aguarda repasse na fila da política
+func (s *ForwardingService) IsPending(invoiceID string) (bool, error) {
+	_, err := s.pending.GetByInvoiceID(invoiceID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Destination consulta na StarkBank o destino dos repasses (conta, chave Pix
+// ou BR Code)
+func (s *ForwardingService) Destination(ctx context.Context) (*ForwardDestination, error) {
+	return s.transfers.ResolveDestination(ctx)
+}
+
+// SetRules altera o mínimo, os limites diários e a política de aprovação
+// aplicados às próximas decisões; modo, intervalo e destino exigem reinício.
+// Créditos pendentes seguem as regras novas na próxima varredura;
+// solicitações de aprovação abertas mantêm as de quando foram criadas.
+func (s *ForwardingService) SetRules(policy config.ForwardingConfig, approval config.ApprovalConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy.MinAmount = policy.MinAmount
+	s.policy.DailyCap = policy.DailyCap
+	s.policy.DestinationDailyCap = policy.DestinationDailyCap
+	s.approval = approval
+}
+
+// Status resume a política, o repassado no dia e os créditos pendentes
+func (s *ForwardingService) Status(ctx context.Context) (*ForwardingStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	credits, err := s.pending.List()
+	if err != nil {
+		return nil, err
+	}
+	forwarded, err := s.ledger.ForwardedSince(s.today())
+	if err != nil {
+		return nil, err
+	}
+	limit, err := s.capacity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	awaiting, err := s.approvals.List(domain.ApprovalStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ForwardingStatus{
+		Mode:           s.policy.Mode,
+		Destination:    s.transfers.target.kind(),
+		MinAmount:      domain.BRL(s.policy.MinAmount),
+		SweepInterval:  s.policy.SweepInterval.String(),
+		DailyCap:       domain.BRL(s.policy.DailyCap),
+		DestinationCap: domain.BRL(s.policy.DestinationDailyCap),
+		ForwardedToday: forwarded,
+		PendingTotal:   domain.BRL(sumCredits(credits)),
+		Pending:        credits,
+
+		ApprovalThreshold: domain.BRL(s.approval.Threshold),
+		AwaitingApproval:  awaiting,
+	}
+	if limit.limited {
+		remaining := domain.BRL(limit.remaining)
+		status.Remaining = &remaining
+	}
+	return status, nil
+}
+
+// Decisions lista as decisões registradas, das mais recentes para as mais antigas
+func (s *ForwardingService) Decisions(filter domain.ForwardDecisionFilter) ([]domain.ForwardDecision, error) {
+	return s.decisions.List(filter)
+}
+
+// Approvals lista as solicitações de aprovação com o status informado
+// (vazio lista todas), das mais recentes para as mais antigas
+func (s *ForwardingService) Approvals(status string) ([]domain.TransferApproval, error) {
+	return s.approvals.List(status)
+}
+
+// Approve registra a aprovação do operador do contexto (chave de API)
+//
+// Ao atingir as aprovações necessárias, a transferência é criada com os
+// aprovadores nas tags. Se a criação falhar, a solicitação continua pendente
+// com o erro registrado, e uma nova chamada tenta de novo com o mesmo
+// external ID. Os limites diários são conferidos de novo no envio, que pode
+// ser dias depois da solicitação: se o valor não couber no restante, a
+// solicitação continua pendente e retorna domain.ErrApprovalOverCap.
+// Retorna domain.ErrApprovalClosed para solicitações encerradas ou vencidas e
+// domain.ErrAlreadyApproved se o operador já aprovou.
+func (s *ForwardingService) Approve(ctx context.Context, id string) (*domain.TransferApproval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	approval, err := s.openApproval(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !approval.Approved() {
+		actor := domain.ActorFromContext(ctx)
+		if approval.ApprovedBy(actor) {
+			return approval, domain.ErrAlreadyApproved
+		}
+		approval.Approvals = append(approval.Approvals, domain.Approval{
+			Actor:   actor,
+			Name:    operatorName(ctx),
+			Created: time.Now(),
+		})
+		if err := s.approvals.Save(*approval); err != nil {
+			return nil, fmt.Errorf("erro ao registrar aprovação: %w", err)
+		}
+		s.auditor.Record(ctx, domain.AuditApprovalApproved, approval.ID, nil, map[string]interface{}{
+			"approvals": len(approval.Approvals),
+			"required":  approval.Required,
+			"amount":    approval.Amount,
+		})
+		slog.InfoContext(ctx, "repasse aprovado por operador",
+			"approval_id", approval.ID,
+			"approvals", len(approval.Approvals),
+			"required", approval.Required)
+		if !approval.Approved() {
+			return approval, nil
+		}
+	}
+
+	limit, err := s.capacity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if limit.exceeded(approval.Amount.Cents()) {
+		reason := fmt.Sprintf("valor %s excede o restante de %s (%s)", approval.Amount, domain.BRL(limit.remaining), limit.describe)
+		approval.LastError = reason + "; aprove de novo quando o limite for renovado"
+		if err := s.approvals.Save(*approval); err != nil {
+			slog.ErrorContext(ctx, "erro ao registrar limite da solicitação de aprovação", "approval_id", approval.ID, "error", err)
+		}
+		s.decide(ctx, domain.ForwardDecision{
+			Action:     domain.ForwardActionApproval,
+			Rule:       limit.rule,
+			InvoiceIDs: approval.InvoiceIDs,
+			Amount:     approval.Amount,
+			Reason:     fmt.Sprintf("solicitação %s aprovada, mas aguarda o limite diário: %s", approval.ID, reason),
+		})
+		return approval, fmt.Errorf("%w: %s", domain.ErrApprovalOverCap, reason)
+	}
+
+	credits, err := s.reserved(approval.ID)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := s.send(ctx, credits, func() (*domain.Transfer, error) {
+		return s.transfers.CreateFromApproval(ctx, *approval)
+	})
+	if err != nil {
+		approval.LastError = err.Error()
+		if err := s.approvals.Save(*approval); err != nil {
+			slog.ErrorContext(ctx, "erro ao registrar falha da solicitação de aprovação", "approval_id", approval.ID, "error", err)
+		}
+		return approval, fmt.Errorf("aprovação registrada, mas a transferência falhou (aprove de novo para repetir): %w", err)
+	}
+
+	now := time.Now()
+	approval.Status = domain.ApprovalStatusApproved
+	approval.TransferID = transfer.ID
+	approval.LastError = ""
+	approval.Resolved = &now
+	if err := s.approvals.Save(*approval); err != nil {
+		slog.ErrorContext(ctx, "erro ao encerrar solicitação de aprovação", "approval_id", approval.ID, "error", err)
+	}
+	if err := s.pending.Delete(approval.InvoiceIDs...); err != nil {
+		slog.ErrorContext(ctx, "erro ao remover créditos repassados da fila", "transfer_id", transfer.ID, "error", err)
+	}
+	approvers := approval.Approvers()
+	if err := s.record(ctx, transfer, credits, approvers); err != nil {
+		return approval, err
+	}
+	s.decide(ctx, domain.ForwardDecision{
+		Action:     domain.ForwardActionTransfer,
+		Rule:       domain.ForwardRuleApproval,
+		InvoiceIDs: approval.InvoiceIDs,
+		Amount:     transfer.Amount,
+		TransferID: transfer.ID,
+		Reason: fmt.Sprintf("solicitação %s aprovada por %s: %s repassado",
+			approval.ID, strings.Join(approvers, ", "), transfer.Amount),
+	})
+	return approval, nil
+}
+
+// Reject encerra a solicitação sem repasse; os créditos saem da fila e só
+// voltam a ser repassados se os eventos forem reprocessados
+func (s *ForwardingService) Reject(ctx context.Context, id, note string) (*domain.TransferApproval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	approval, err := s.openApproval(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	approval.RejectedBy = operatorName(ctx)
+	if err := s.close(ctx, approval, domain.ApprovalStatusRejected, note, false); err != nil {
+		return nil, err
+	}
+	return approval, nil
+}
+
+// openApproval busca uma solicitação pendente; uma solicitação vencida é
+// encerrada e retorna domain.ErrApprovalClosed
+func (s *ForwardingService) openApproval(ctx context.Context, id string) (*domain.TransferApproval, error) {
+	approval, err := s.approvals.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != domain.ApprovalStatusPending {
+		return nil, fmt.Errorf("%w (%s)", domain.ErrApprovalClosed, approval.Status)
+	}
+	if time.Now().After(approval.Expires) {
+		if err := s.close(ctx, approval, domain.ApprovalStatusExpired, "prazo de aprovação esgotado", false); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w (expirou em %s)", domain.ErrApprovalClosed, approval.Expires.Format(time.RFC3339))
+	}
+	return approval, nil
+}
+
+// expireApprovals encerra as solicitações pendentes cujo prazo esgotou
+func (s *ForwardingService) expireApprovals(ctx context.Context) error {
+	pending, err := s.approvals.List(domain.ApprovalStatusPending)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar solicitações de aprovação: %w", err)
+	}
+	now := time.Now()
+	for i := range pending {
+		if now.After(pending[i].Expires) {
+			if err := s.close(ctx, &pending[i], domain.ApprovalStatusExpired, "prazo de aprovação esgotado", false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// requiresApproval indica se um repasse do valor informado exige aprovação e por quê
+func (s *ForwardingService) requiresApproval(amount domain.Money) (string, bool) {
+	if s.approval.Threshold > 0 && amount.Cents() > s.approval.Threshold {
+		return fmt.Sprintf("valor líquido %s acima do limite de aprovação %s", amount, domain.BRL(s.approval.Threshold)), true
+	}
+	if s.approval.NonDefaultDestination && s.nonDefault {
+		return "repasse a uma conta de destino diferente da padrão", true
+	}
+	return "", false
+}
+
+// requestApproval reserva os créditos para uma nova solicitação de aprovação
+// e registra a decisão
+func (s *ForwardingService) requestApproval(ctx context.Context, credits []domain.PendingCredit, reason string) (*domain.ForwardDecision, error) {
+	now := time.Now()
+	approval := domain.TransferApproval{
+		ID:         fmt.Sprintf("apr-%d", now.UnixNano()),
+		Status:     domain.ApprovalStatusPending,
+		Reason:     reason,
+		InvoiceIDs: creditIDs(credits),
+		Amount:     domain.BRL(sumCredits(credits)),
+		Required:   s.approval.Required,
+		Created:    now,
+		Expires:    now.Add(s.approval.TTL),
+	}
+	if err := s.approvals.Save(approval); err != nil {
+		return nil, fmt.Errorf("erro ao registrar solicitação de aprovação: %w", err)
+	}
+	for _, c := range credits {
+		c.Action = domain.ForwardActionApproval
+		c.ApprovalID = approval.ID
+		if err := s.pending.Save(c); err != nil {
+			return nil, fmt.Errorf("erro ao registrar crédito pendente: %w", err)
+		}
+	}
+
+	s.auditor.Record(ctx, domain.AuditApprovalRequested, approval.ID, nil, map[string]interface{}{
+		"invoice_ids": approval.InvoiceIDs,
+		"amount":      approval.Amount,
+		"reason":      reason,
+		"required":    approval.Required,
+		"expires":     approval.Expires,
+	})
+	return s.decide(ctx, domain.ForwardDecision{
+		Action:     domain.ForwardActionApproval,
+		Rule:       domain.ForwardRuleApproval,
+		InvoiceIDs: approval.InvoiceIDs,
+		Amount:     approval.Amount,
+		Reason: fmt.Sprintf("%s; solicitação %s aguarda %d aprovação(ões) até %s",
+			reason, approval.ID, approval.Required, approval.Expires.Format(time.RFC3339)),
+	}), nil
+}
+
+// close encerra a solicitação sem repasse (rejeitada ou expirada); os
+// créditos reservados saem da fila ou, com requeue, voltam a ela
+func (s *ForwardingService) close(ctx context.Context, approval *domain.TransferApproval, status, note string, requeue bool) error {
+	credits, err := s.reserved(approval.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	approval.Status = status
+	approval.Note = note
+	approval.Resolved = &now
+	if err := s.approvals.Save(*approval); err != nil {
+		return fmt.Errorf("erro ao encerrar solicitação de aprovação: %w", err)
+	}
+
+	outcome := "créditos fora da fila (reprocesse os eventos para solicitar de novo)"
+	if requeue {
+		outcome = "créditos de volta à fila"
+		for _, c := range credits {
+			c.Action = domain.ForwardActionAccumulate
+			c.ApprovalID = ""
+			if err := s.pending.Save(c); err != nil {
+				return fmt.Errorf("erro ao atualizar crédito pendente: %w", err)
+			}
+		}
+	} else if err := s.pending.Delete(creditIDs(credits)...); err != nil {
+		return fmt.Errorf("erro ao remover créditos da fila: %w", err)
+	}
+
+	action, verb := domain.AuditApprovalRejected, "rejeitada"
+	if status == domain.ApprovalStatusExpired {
+		action, verb = domain.AuditApprovalExpired, "expirada"
+	}
+	s.auditor.Record(ctx, action, approval.ID, nil, map[string]interface{}{
+		"invoice_ids": approval.InvoiceIDs,
+		"amount":      approval.Amount,
+		"note":        note,
+	})
+	reason := fmt.Sprintf("solicitação %s %s", approval.ID, verb)
+	if approval.RejectedBy != "" {
+		reason += " por " + approval.RejectedBy
+	}
+	if note != "" {
+		reason += ": " + note
+	}
+	s.decide(ctx, domain.ForwardDecision{
+		Action:     domain.ForwardActionSkip,
+		Rule:       domain.ForwardRuleApproval,
+		InvoiceIDs: approval.InvoiceIDs,
+		Amount:     approval.Amount,
+		Reason:     reason + "; " + outcome,
+	})
+	return nil
+}
+
+// reserved lista os créditos reservados para a solicitação
+func (s *ForwardingService) reserved(approvalID string) ([]domain.PendingCredit, error) {
+	credits, err := s.pending.List()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar créditos pendentes: %w", err)
+	}
+	var result []domain.PendingCredit
+	for _, c := range credits {
+		if c.ApprovalID == approvalID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// operatorName identifica o operador nas aprovações: o nome da chave de API
+// ou, sem chave, o autor do contexto
+func operatorName(ctx context.Context) string {
+	if key := domain.APIKeyFromContext(ctx); key != nil {
+		return key.Name
+	}
+	return domain.ActorFromContext(ctx)
+}
+
+// StartSweeps inicia as varreduras periódicas; ctx é a base de cada
+// varredura (ex: identifica o tenant nos logs)
+//
+// No modo immediate as varreduras liberam os créditos retidos quando o
+// limite diário é renovado. Cada varredura também encerra as solicitações de
+// aprovação vencidas.
+func (s *ForwardingService) StartSweeps(ctx context.Context) {
+	slog.InfoContext(ctx, "iniciando varreduras de repasse",
+		"mode", s.policy.Mode,
+		"interval", s.policy.SweepInterval.String())
+
+	ticker := time.NewTicker(s.policy.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Sweep(ctx); err != nil {
+				slog.ErrorContext(ctx, "erro na varredura de repasses", "error", err)
+			}
+		case <-s.stopChan:
+			slog.InfoContext(ctx, "varreduras de repasse interrompidas")
+			return
+		}
+	}
+}
+
+// Stop para as varreduras periódicas
+func (s *ForwardingService) Stop() {
+	close(s.stopChan)
+}
+
+// pend coloca o crédito na fila e registra a decisão
+func (s *ForwardingService) pend(ctx context.Context, credit domain.PendingCredit, rule, reason string) error {
+	if err := s.pending.Save(credit); err != nil {
+		return fmt.Errorf("erro ao registrar crédito pendente: %w", err)
+	}
+	s.decide(ctx, domain.ForwardDecision{
+		Action:     credit.Action,
+		Rule:       rule,
+		InvoiceIDs: []string{credit.InvoiceID},
+		Amount:     credit.Net,
+		Reason:     reason,
+	})
+	return nil
+}
+
+// send grava no razão a intenção de repasse dos créditos, cria a
+// transferência com create e, se a criação falhar, devolve os créditos a
+// pendente
+//
+// Uma falha ao gravar a intenção impede a transferência. Se o processo cair
+// entre a chamada e o registro do resultado, os créditos ficam em trânsito:
+// eventos reenviados não os repassam de novo.
+func (s *ForwardingService) send(ctx context.Context, credits []domain.PendingCredit, create func() (*domain.Transfer, error)) (*domain.Transfer, error) {
+	for i, c := range credits {
+		if err := s.ledger.RecordForwardIntent(ctx, c.InvoiceID, c.Net); err != nil {
+			s.release(ctx, credits[:i])
+			return nil, err
+		}
+	}
+
+	transfer, err := create()
+	if err != nil {
+		s.release(ctx, credits)
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// release devolve a pendente os créditos cuja transferência não foi criada
+func (s *ForwardingService) release(ctx context.Context, credits []domain.PendingCredit) {
+	for _, c := range credits {
+		if err := s.ledger.RecordForwardFailed(ctx, c.InvoiceID, c.Net); err != nil {
+			// O crédito continua em trânsito e bloqueado para novos repasses
+			// até a conciliação manual
+			slog.ErrorContext(ctx, "erro ao devolver crédito a pendente no razão", "invoice_id", c.InvoiceID, "error", err)
+		}
+	}
+}
+
+// record concilia no razão os créditos cobertos pela transferência e
+// registra os repasses, com quem os aprovou, se exigido
+//
+// A transferência já foi criada e os créditos seguem em trânsito no razão
+// se o registro falhar: o erro é devolvido para que o evento seja tratado
+// como falha, e o reenvio não provoca um novo repasse.
+func (s *ForwardingService) record(ctx context.Context, transfer *domain.Transfer, credits []domain.PendingCredit, approvedBy []string) error {
+	var errs []error
+	for _, c := range credits {
+		if err := s.ledger.RecordForward(ctx, c.InvoiceID, transfer.ID, c.Net); err != nil {
+			slog.ErrorContext(ctx, "erro ao registrar repasse no razão", "invoice_id", c.InvoiceID, "error", err)
+			errs = append(errs, err)
+		}
+
+		// Registrar o repasse para poder compensar estornos futuros
+		if err := s.forwards.Save(domain.Forward{
+			InvoiceID:  c.InvoiceID,
+			TransferID: transfer.ID,
+			Kind:       transfer.Kind,
+			PayerName:  c.Event.PayerName,
+			PayerTaxID: c.Event.PayerTaxID,
+			Amount:     c.Event.Amount,
+			Fee:        c.Event.Fee,
+			Forwarded:  c.Net,
+			ApprovedBy: approvedBy,
+			Created:    time.Now(),
+		}); err != nil {
+			slog.ErrorContext(ctx, "erro ao registrar repasse", "invoice_id", c.InvoiceID, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("transferência %s criada, mas o registro do repasse falhou: %w", transfer.ID, errors.Join(errs...))
+	}
+	return nil
+}
+
+// decide registra a decisão (falhas de gravação não interrompem o repasse)
+func (s *ForwardingService) decide(ctx context.Context, decision domain.ForwardDecision) *domain.ForwardDecision {
+	decision.Created = time.Now()
+	decision.ID = fmt.Sprintf("fwd-%d", decision.Created.UnixNano())
+	if err := s.decisions.Append(decision); err != nil {
+		slog.ErrorContext(ctx, "erro ao registrar decisão de repasse", "error", err)
+	}
+	metrics.ForwardDecisions.Inc(decision.Action, decision.Rule)
+	slog.InfoContext(ctx, "decisão de repasse",
+		"action", decision.Action,
+		"rule", decision.Rule,
+		"invoices", len(decision.InvoiceIDs),
+		"amount", decision.Amount,
+		"reason", decision.Reason)
+	return &decision
+}
+
+// pendingTotal soma os créditos pendentes com a ação informada, em centavos
+func (s *ForwardingService) pendingTotal(action string) (int64, error) {
+	credits, err := s.pending.List()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar créditos pendentes: %w", err)
+	}
+	var total int64
+	for _, c := range credits {
+		if c.Action == action {
+			total += c.Net.Cents()
+		}
+	}
+	return total, nil
+}
+
+// dailyLimit é o quanto ainda pode ser repassado hoje pelo limite mais restritivo
+type dailyLimit struct {
+	limited   bool
+	remaining int64  // em centavos
+	rule      string // limite determinante
+	describe  string // explicação do limite para as decisões
+}
+
+// exceeded indica se o valor ultrapassa o restante do limite
+func (l dailyLimit) exceeded(cents int64) bool {
+	return l.limited && cents > l.remaining
+}
+
+// capacity calcula o restante dos limites diários do tenant e da conta de destino
+func (s *ForwardingService) capacity(ctx context.Context) (dailyLimit, error) {
+	since := s.today()
+	limit := dailyLimit{rule: domain.ForwardRuleDailyCap}
+
+	apply := func(limitCents int64, used domain.Money, rule, label string) {
+		remaining := limitCents - used.Cents()
+		if remaining < 0 {
+			remaining = 0
+		}
+		if !limit.limited || remaining < limit.remaining {
+			limit = dailyLimit{
+				limited:   true,
+				remaining: remaining,
+				rule:      rule,
+				describe:  fmt.Sprintf("%s %s, já repassado %s", label, domain.BRL(limitCents), used),
+			}
+		}
+	}
+
+	if s.policy.DailyCap > 0 {
+		used, err := s.ledger.ForwardedSince(since)
+		if err != nil {
+			return limit, fmt.Errorf("erro ao calcular repasses do dia: %w", err)
+		}
+		apply(s.policy.DailyCap, used, domain.ForwardRuleDailyCap, "limite diário")
+	}
+	if s.policy.DestinationDailyCap > 0 {
+		used, err := s.usage.Since(s.destination, since)
+		if err != nil {
+			return limit, fmt.Errorf("erro ao calcular repasses do dia à conta de destino: %w", err)
+		}
+		apply(s.policy.DestinationDailyCap, used, domain.ForwardRuleDestinationDailyCap, "limite diário da conta de destino")
+	}
+	return limit, nil
+}
+
+// today retorna o início do dia corrente no fuso configurado, a partir do
+// qual os limites diários são somados
+func (s *ForwardingService) today() time.Time {
+	return startOfDay(time.Now().In(s.location))
+}
+
+// DestinationUsage soma o que os tenants repassaram a cada conta de destino,
+// para o limite diário por destino
+type DestinationUsage struct {
+	mu      sync.RWMutex
+	sources map[string][]func(since time.Time) (domain.Money, error)
+}
+
+// NewDestinationUsage cria o registro compartilhado pelos tenants
+func NewDestinationUsage() *DestinationUsage {
+	return &DestinationUsage{sources: make(map[string][]func(time.Time) (domain.Money, error))}
+}
+
+// Register adiciona um tenant que repassa à conta de destino
+func (u *DestinationUsage) Register(destination string, source func(since time.Time) (domain.Money, error)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.sources[destination] = append(u.sources[destination], source)
+}
+
+// Since soma os repasses de todos os tenants à conta a partir de since
+func (u *DestinationUsage) Since(destination string, since time.Time) (domain.Money, error) {
+	u.mu.RLock()
+	sources := u.sources[destination]
+	u.mu.RUnlock()
+
+	var total int64
+	for _, source := range sources {
+		used, err := source(since)
+		if err != nil {
+			return domain.Money{}, err
+		}
+		total += used.Cents()
+	}
+	return domain.BRL(total), nil
+}
+
+// DestinationKey identifica a conta de destino no limite por destino
+func DestinationKey(d config.DestinationAccount) string {
+	return d.BankCode + "/" + d.BranchCode + "/" + d.AccountNumber
+}
+
+// startOfDay retorna a meia-noite do dia de t, no fuso de t
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func creditIDs(credits []domain.PendingCredit) []string {
+	ids := make([]string, len(credits))
+	for i, c := range credits {
+		ids[i] = c.InvoiceID
+	}
+	return ids
+}
+
+func sumCredits(credits []domain.PendingCredit) int64 {
+	var total int64
+	for _, c := range credits {
+		total += c.Net.Cents()
+	}
+	return total
+}
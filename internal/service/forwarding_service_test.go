@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+)
+
+// recordingTransferRepo registra as transferências criadas; com err, a
+// criação falha
+type recordingTransferRepo struct {
+	created []domain.Transfer
+	err     error
+}
+
+func (r *recordingTransferRepo) Create(ctx context.Context, transfers []domain.Transfer) ([]domain.Transfer, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for i := range transfers {
+		transfers[i].ID = fmt.Sprintf("tr-%d", len(r.created)+1)
+		r.created = append(r.created, transfers[i])
+	}
+	return transfers, nil
+}
+
+func (r *recordingTransferRepo) GetByID(ctx context.Context, id string) (*domain.Transfer, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (r *recordingTransferRepo) Cancel(ctx context.Context, id string) (*domain.Transfer, error) {
+	for i := range r.created {
+		if r.created[i].ID == id {
+			r.created[i].Status = "canceled"
+			return &r.created[i], nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *recordingTransferRepo) List(ctx context.Context, limit int) ([]domain.Transfer, error) {
+	return r.created, nil
+}
+
+func newTestForwardingService(t *testing.T, policy config.ForwardingConfig, approval config.ApprovalConfig) (*ForwardingService, *recordingTransferRepo) {
+	t.Helper()
+	return newTestForwardingServiceTo(t, policy, approval, ForwardTarget{}, nil, nil)
+}
+
+// newTestForwardingServiceTo cria o serviço com repasses a target
+func newTestForwardingServiceTo(t *testing.T, policy config.ForwardingConfig, approval config.ApprovalConfig,
+	target ForwardTarget, pixKeys domain.PixKeyRepository, brcodes domain.BRCodePaymentRepository) (*ForwardingService, *recordingTransferRepo) {
+	t.Helper()
+	dir := t.TempDir()
+	ledgerRepo, err := repository.NewFileLedgerRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao abrir razão: %v", err)
+	}
+	t.Cleanup(func() { ledgerRepo.Close() })
+	forwards, _ := repository.NewFileForwardRepository(dir)
+	pending, _ := repository.NewFilePendingCreditRepository(dir)
+	decisions, _ := repository.NewFileForwardDecisionRepository(dir)
+	approvals, _ := repository.NewFileTransferApprovalRepository(dir)
+
+	transfers := &recordingTransferRepo{}
+	balance := &stubBalanceProvider{amount: domain.BRL(1_000_000)}
+	transferService := NewTransferService(transfers, config.DestinationAccount{}, target, pixKeys, brcodes, balance, domain.BRL(0), NopAuditor)
+	svc := NewForwardingService(policy, approval, config.DestinationAccount{}, transferService,
+		NewLedgerService(ledgerRepo), forwards, pending, decisions, approvals, NewDestinationUsage(), time.Local, NopAuditor)
+	return svc, transfers
+}
+
+func credit(id string, cents int64) domain.WebhookEvent {
+	return domain.WebhookEvent{InvoiceID: id, Subscription: "invoice", EventType: "credited", Amount: domain.BRL(cents), Fee: domain.BRL(0)}
+}
+
+func TestForwardingMinAmountAccumulates(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{
+		Mode: config.ForwardingImmediate, MinAmount: 1000,
+	}, config.ApprovalConfig{})
+
+	for _, id := range []string{"inv-1", "inv-2"} {
+		if transfer, err := svc.Submit(ctx, credit(id, 400)); err != nil || transfer != nil {
+			t.Fatalf("crédito abaixo do mínimo deveria ser acumulado: %v %v", transfer, err)
+		}
+	}
+	if len(transfers.created) != 0 {
+		t.Fatalf("nenhuma transferência esperada antes do mínimo: %+v", transfers.created)
+	}
+
+	// O terceiro crédito leva o acumulado a 1200, acima do mínimo
+	svc.Submit(ctx, credit("inv-3", 400))
+	if len(transfers.created) != 1 || transfers.created[0].Amount.Cents() != 1200 {
+		t.Fatalf("esperada uma transferência agrupada de 1200: %+v", transfers.created)
+	}
+	if pending, _ := svc.IsPending("inv-1"); pending {
+		t.Error("créditos repassados deveriam sair da fila")
+	}
+
+	decisions, _ := svc.Decisions(domain.ForwardDecisionFilter{InvoiceID: "inv-1"})
+	if len(decisions) != 2 || decisions[0].Action != domain.ForwardActionSweep || decisions[1].Action != domain.ForwardActionAccumulate {
+		t.Errorf("decisões inesperadas para inv-1: %+v", decisions)
+	}
+	for _, d := range decisions {
+		if d.Reason == "" {
+			t.Errorf("decisão sem explicação: %+v", d)
+		}
+	}
+}
+
+func TestForwardingSetRules(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{Mode: config.ForwardingImmediate}, config.ApprovalConfig{})
+
+	// Regras recarregadas valem para a próxima decisão
+	svc.SetRules(config.ForwardingConfig{MinAmount: 1000}, config.ApprovalConfig{Threshold: 2000, Required: 1, TTL: time.Hour})
+	svc.Submit(ctx, credit("inv-1", 600))
+	if len(transfers.created) != 0 {
+		t.Fatalf("crédito abaixo do novo mínimo não deveria ser repassado: %+v", transfers.created)
+	}
+	svc.Submit(ctx, credit("inv-2", 3000))
+	if len(transfers.created) != 0 {
+		t.Fatalf("repasse acima do novo limite de aprovação não deveria sair: %+v", transfers.created)
+	}
+
+	status, _ := svc.Status(ctx)
+	if status.Mode != config.ForwardingImmediate || status.MinAmount.Cents() != 1000 || len(status.AwaitingApproval) != 1 {
+		t.Errorf("status inesperado após a recarga: %+v", status)
+	}
+}
+
+func TestForwardingRecordsIntentBeforeTransfer(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{Mode: config.ForwardingImmediate}, config.ApprovalConfig{})
+	svc.ledger.RecordInvoiceCredit(ctx, "inv-1", domain.BRL(1000), domain.BRL(0))
+
+	// A transferência falhou: o crédito volta a pendente e pode ser repassado
+	transfers.err = errors.New("falha na StarkBank")
+	if _, err := svc.Submit(ctx, credit("inv-1", 1000)); err == nil {
+		t.Fatal("falha da transferência deveria ser devolvida")
+	}
+	if forwarded, _ := svc.ledger.HasForward("inv-1"); forwarded {
+		t.Fatal("transferência que falhou não deveria bloquear nova tentativa")
+	}
+
+	transfers.err = nil
+	if transfer, err := svc.Submit(ctx, credit("inv-1", 1000)); err != nil || transfer == nil {
+		t.Fatalf("nova tentativa deveria repassar: %v", err)
+	}
+	result, _ := svc.ledger.Verify()
+	if !result.Balanced || len(result.InTransitInvoices) != 0 || result.Accounts[domain.LedgerAccountForwarded] != 1000 {
+		t.Errorf("razão deveria registrar um repasse conciliado: %+v", result)
+	}
+}
+
+func TestForwardingConcurrentDuplicates(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{Mode: config.ForwardingImmediate}, config.ApprovalConfig{})
+	svc.ledger.RecordInvoiceCredit(ctx, "inv-1", domain.BRL(1000), domain.BRL(0))
+
+	// Webhook e consulta de eventos entregam o mesmo invoice ao mesmo tempo
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.Submit(ctx, credit("inv-1", 1000)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(transfers.created) != 1 {
+		t.Fatalf("esperada uma transferência, criadas %d", len(transfers.created))
+	}
+	if id := transfers.created[0].ExternalID; id != "inv-inv-1" {
+		t.Errorf("external ID deveria ser determinístico, obtido %s", id)
+	}
+}
+
+func TestForwardingDailyCapHolds(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{
+		Mode: config.ForwardingImmediate, DailyCap: 1000,
+	}, config.ApprovalConfig{})
+
+	if transfer, err := svc.Submit(ctx, credit("inv-1", 700)); err != nil || transfer == nil {
+		t.Fatalf("crédito dentro do limite deveria ser repassado: %v", err)
+	}
+	if transfer, err := svc.Submit(ctx, credit("inv-2", 500)); err != nil || transfer != nil {
+		t.Fatalf("crédito acima do restante deveria ser retido: %v %v", transfer, err)
+	}
+	// Mesmo cabendo no limite, um crédito novo não passa à frente dos retidos
+	if transfer, _ := svc.Submit(ctx, credit("inv-3", 100)); transfer != nil {
+		t.Error("crédito não deveria passar à frente dos retidos")
+	}
+
+	status, err := svc.Status(ctx)
+	if err != nil {
+		t.Fatalf("erro ao consultar estado: %v", err)
+	}
+	if status.Remaining == nil || status.Remaining.Cents() != 300 || len(status.Pending) != 2 {
+		t.Errorf("estado inesperado: %+v", status)
+	}
+
+	// A varredura não libera nada enquanto o primeiro retido não couber
+	decision, err := svc.Sweep(ctx)
+	if err != nil || decision == nil || decision.Action != domain.ForwardActionSkip || decision.Rule != domain.ForwardRuleDailyCap {
+		t.Errorf("varredura deveria ser recusada pelo limite diário: %+v %v", decision, err)
+	}
+	if len(transfers.created) != 1 {
+		t.Errorf("esperada apenas a primeira transferência: %+v", transfers.created)
+	}
+}
+
+func TestForwardingDailyCapResetsInLocation(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestForwardingService(t, config.ForwardingConfig{
+		Mode: config.ForwardingImmediate, DailyCap: 1000,
+	}, config.ApprovalConfig{})
+	// Fuso distante do servidor: a meia-noite dele não é a do host
+	svc.location = time.FixedZone("UTC+14", 14*60*60)
+
+	midnight := startOfDay(time.Now().In(svc.location))
+	for i, created := range []time.Time{midnight.Add(-time.Second), midnight} {
+		entry := domain.LedgerEntry{
+			Key:  fmt.Sprintf("transfer_created:inv-%d", i),
+			Kind: domain.LedgerEntryTransferCreated, InvoiceID: fmt.Sprintf("inv-%d", i),
+			Postings: []domain.LedgerPosting{
+				{Account: domain.LedgerAccountForwarded, Debit: 400},
+				{Account: domain.LedgerAccountInTransit, Credit: 400},
+			},
+			Created: created,
+		}
+		if err := svc.ledger.repo.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	status, err := svc.Status(ctx)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	// Só o repasse depois da meia-noite no fuso configurado conta para hoje
+	if status.ForwardedToday.Cents() != 400 || status.Remaining == nil || status.Remaining.Cents() != 600 {
+		t.Fatalf("repassado hoje = %s, restante %v; esperado 4,00 e 6,00", status.ForwardedToday, status.Remaining)
+	}
+}
+
+func TestForwardingSweepMode(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{
+		Mode: config.ForwardingSweep,
+	}, config.ApprovalConfig{})
+
+	svc.Submit(ctx, credit("inv-1", 300))
+	svc.Submit(ctx, credit("inv-2", 200))
+	if len(transfers.created) != 0 {
+		t.Fatal("modo sweep não deveria repassar na chegada")
+	}
+
+	decision, err := svc.Sweep(ctx)
+	if err != nil || decision.Action != domain.ForwardActionSweep || len(decision.InvoiceIDs) != 2 {
+		t.Fatalf("varredura deveria repassar os dois créditos: %+v %v", decision, err)
+	}
+	if len(transfers.created) != 1 || transfers.created[0].Amount.Cents() != 500 {
+		t.Errorf("esperada uma transferência de 500: %+v", transfers.created)
+	}
+
+	if decision, _ := svc.Sweep(ctx); decision != nil {
+		t.Errorf("varredura sem pendências não deveria gerar decisão: %+v", decision)
+	}
+}
+
+func TestForwardingSweepRetryKeepsExternalID(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{
+		Mode: config.ForwardingSweep,
+	}, config.ApprovalConfig{})
+
+	svc.Submit(ctx, credit("inv-1", 300))
+	svc.Submit(ctx, credit("inv-2", 200))
+
+	// A primeira tentativa falha (ex: timeout); a seguinte usa o mesmo ID
+	transfers.err = errors.New("timeout")
+	if _, err := svc.Sweep(ctx); err == nil {
+		t.Fatal("esperado erro na varredura")
+	}
+	transfers.err = nil
+	if _, err := svc.Sweep(ctx); err != nil || len(transfers.created) != 1 {
+		t.Fatalf("varredura deveria repassar na nova tentativa: %+v %v", transfers.created, err)
+	}
+	if id, want := transfers.created[0].ExternalID, sweepExternalID([]string{"inv-2", "inv-1"}); id != want {
+		t.Errorf("external ID = %s, esperado %s, independente da ordem e do horário", id, want)
+	}
+}
+
+func TestForwardingApproval(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{
+		Mode: config.ForwardingImmediate,
+	}, config.ApprovalConfig{Threshold: 1000, Required: 2, TTL: time.Hour})
+
+	if transfer, _ := svc.Submit(ctx, credit("inv-small", 800)); transfer == nil {
+		t.Fatal("crédito abaixo do limite de aprovação deveria ser repassado")
+	}
+	if transfer, err := svc.Submit(ctx, credit("inv-big", 1500)); err != nil || transfer != nil {
+		t.Fatalf("crédito acima do limite deveria aguardar aprovação: %v %v", transfer, err)
+	}
+	approvals, _ := svc.Approvals(domain.ApprovalStatusPending)
+	if len(approvals) != 1 || approvals[0].Amount.Cents() != 1500 {
+		t.Fatalf("esperada uma solicitação pendente de 1500: %+v", approvals)
+	}
+	id := approvals[0].ID
+
+	// Créditos reservados não entram nas varreduras
+	if decision, _ := svc.Sweep(ctx); decision != nil {
+		t.Errorf("varredura não deveria repassar créditos aguardando aprovação: %+v", decision)
+	}
+
+	alice := domain.ContextWithActor(ctx, "alice")
+	if approval, err := svc.Approve(alice, id); err != nil || approval.Status != domain.ApprovalStatusPending {
+		t.Fatalf("primeira aprovação deveria manter a solicitação pendente: %+v %v", approval, err)
+	}
+	if _, err := svc.Approve(alice, id); !errors.Is(err, domain.ErrAlreadyApproved) {
+		t.Errorf("o mesmo operador não pode aprovar duas vezes: %v", err)
+	}
+	approval, err := svc.Approve(domain.ContextWithActor(ctx, "bob"), id)
+	if err != nil || approval.Status != domain.ApprovalStatusApproved || approval.TransferID == "" {
+		t.Fatalf("segunda aprovação deveria criar a transferência: %+v %v", approval, err)
+	}
+
+	last := transfers.created[len(transfers.created)-1]
+	if last.Amount.Cents() != 1500 || len(last.Tags) != 2 || last.Tags[0] != "approved-by:alice" {
+		t.Errorf("transferência aprovada inesperada: %+v", last)
+	}
+	if pending, _ := svc.IsPending("inv-big"); pending {
+		t.Error("crédito aprovado deveria sair da fila")
+	}
+	if _, err := svc.Approve(ctx, id); !errors.Is(err, domain.ErrApprovalClosed) {
+		t.Errorf("solicitação aprovada não aceita novas aprovações: %v", err)
+	}
+}
+
+func TestForwardingApprovalRechecksDailyCap(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{
+		Mode: config.ForwardingImmediate, DailyCap: 2000,
+	}, config.ApprovalConfig{Threshold: 1000, Required: 1, TTL: time.Hour})
+
+	// As duas cabem no limite na solicitação, mas não juntas
+	svc.Submit(ctx, credit("inv-a", 1500))
+	svc.Submit(ctx, credit("inv-b", 1500))
+	approvals, _ := svc.Approvals(domain.ApprovalStatusPending)
+	if len(approvals) != 2 {
+		t.Fatalf("esperadas duas solicitações pendentes: %+v", approvals)
+	}
+
+	if _, err := svc.Approve(ctx, approvals[0].ID); err != nil {
+		t.Fatalf("primeira aprovação deveria repassar: %v", err)
+	}
+	approval, err := svc.Approve(ctx, approvals[1].ID)
+	if !errors.Is(err, domain.ErrApprovalOverCap) || approval.Status != domain.ApprovalStatusPending || approval.LastError == "" {
+		t.Fatalf("segunda aprovação deveria aguardar o limite: %+v %v", approval, err)
+	}
+	if len(transfers.created) != 1 {
+		t.Fatalf("limite diário ultrapassado: %+v", transfers.created)
+	}
+
+	// Com o limite renovado, uma nova chamada cria a transferência
+	svc.policy.DailyCap = 5000
+	if approval, err := svc.Approve(ctx, approvals[1].ID); err != nil || approval.Status != domain.ApprovalStatusApproved {
+		t.Fatalf("aprovação deveria repassar com limite disponível: %+v %v", approval, err)
+	}
+}
+
+func TestForwardingApprovalExpires(t *testing.T) {
+	ctx := context.Background()
+	svc, transfers := newTestForwardingService(t, config.ForwardingConfig{
+		Mode: config.ForwardingImmediate,
+	}, config.ApprovalConfig{Threshold: 1000, Required: 1, TTL: time.Millisecond})
+
+	svc.Submit(ctx, credit("inv-1", 1500))
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := svc.Sweep(ctx); err != nil {
+		t.Fatalf("erro na varredura: %v", err)
+	}
+	expired, _ := svc.Approvals(domain.ApprovalStatusExpired)
+	if len(expired) != 1 {
+		t.Fatalf("solicitação vencida deveria expirar na varredura: %+v", expired)
+	}
+	if _, err := svc.Approve(ctx, expired[0].ID); !errors.Is(err, domain.ErrApprovalClosed) {
+		t.Errorf("solicitação expirada não aceita aprovações: %v", err)
+	}
+	if pending, _ := svc.IsPending("inv-1"); pending || len(transfers.created) != 0 {
+		t.Error("crédito de solicitação expirada não deveria ser repassado nem ficar na fila")
+	}
+}
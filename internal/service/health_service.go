@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// checkTimeout limita o tempo de cada verificação de prontidão
+const checkTimeout = 5 * time.Second
+
+// HealthCheckFunc executa a verificação de uma dependência
+type HealthCheckFunc func(ctx context.Context) domain.HealthCheck
+
+// HealthService executa as verificações de liveness e readiness
+type HealthService struct {
+	startTime time.Time
+	checks    []HealthCheckFunc
+}
+
+// HealthReport é o resultado agregado das verificações de prontidão
+type HealthReport struct {
+	Status    string               `json:"status"`
+	Uptime    string               `json:"uptime"`
+	Timestamp time.Time            `json:"timestamp"`
+	Checks    []domain.HealthCheck `json:"checks"`
+}
+
+// NewHealthService cria uma nova instância do serviço
+func NewHealthService(checks ...HealthCheckFunc) *HealthService {
+	return &HealthService{
+		startTime: time.Now(),
+		checks:    checks,
+	}
+}
+
+// Uptime retorna há quanto tempo a aplicação está no ar
+func (s *HealthService) Uptime() time.Duration {
+	return time.Since(s.startTime)
+}
+
+// Ready executa todas as verificações em paralelo e agrega o resultado
+//
+// O status geral é o pior entre as verificações: qualquer unhealthy torna a
+// aplicação unhealthy; qualquer degraded a torna degraded.
+func (s *HealthService) Ready(ctx context.Context) HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := make([]domain.HealthCheck, len(s.checks))
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheckFunc) {
+			defer wg.Done()
+			results[i] = check(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	status := domain.HealthStatusHealthy
+	for _, r := range results {
+		status = worseStatus(status, r.Status)
+	}
+
+	return HealthReport{
+		Status:    status,
+		Uptime:    s.Uptime().Round(time.Second).String(),
+		Timestamp: time.Now(),
+		Checks:    results,
+	}
+}
+
+func worseStatus(a, b string) string {
+	rank := map[string]int{
+		domain.HealthStatusHealthy:   0,
+		domain.HealthStatusDegraded:  1,
+		domain.HealthStatusUnhealthy: 2,
+	}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// timed executa fn e preenche nome, duração e horário da verificação
+func timed(name string, fn func() domain.HealthCheck) domain.HealthCheck {
+	start := time.Now()
+	result := fn()
+	result.Name = name
+	result.Duration = time.Since(start).Round(time.Microsecond).String()
+	result.Checked = start
+	return result
+}
+
+// StarkBankCheck valida as credenciais do SDK consultando o saldo
+//
+// O resultado fica em cache por ttl para que sondas frequentes não gerem uma
+// chamada à API a cada requisição.
+func StarkBankCheck(repo domain.BalanceRepository, ttl time.Duration) HealthCheckFunc {
+	var mu sync.Mutex
+	var last *domain.HealthCheck
+
+	return func(ctx context.Context) domain.HealthCheck {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if last != nil && time.Since(last.Checked) < ttl {
+			cached := *last
+			cached.Cached = true
+			return cached
+		}
+
+		result := timed("starkbank", func() domain.HealthCheck {
+			if _, err := repo.Get(ctx); err != nil {
+				return domain.HealthCheck{
+					Status:  domain.HealthStatusUnhealthy,
+					Message: fmt.Sprintf("credenciais ou API indisponíveis: %v", err),
+				}
+			}
+			return domain.HealthCheck{Status: domain.HealthStatusHealthy}
+		})
+		last = &result
+		return result
+	}
+}
+
+// VerifyCredentials confirma na inicialização que a chave privada pertence ao projeto
+//
+// Apenas a recusa das credenciais pela API é fatal; falhas de rede são
+// registradas e ficam a cargo do readiness (/readyz).
+func VerifyCredentials(ctx context.Context, repo domain.BalanceRepository) error {
+	err := CheckCredentials(ctx, repo)
+	switch {
+	case err == nil:
+		slog.InfoContext(ctx, "credenciais da StarkBank confirmadas")
+		return nil
+	case errors.Is(err, domain.ErrCredentialsRejected):
+		return err
+	default:
+		slog.WarnContext(ctx, "não foi possível confirmar as credenciais da StarkBank", "error", err)
+		return nil
+	}
+}
+
+// CheckCredentials consulta o saldo com as credenciais de repo e retorna o
+// erro da chamada, sem distinguir recusa de falha de rede
+func CheckCredentials(ctx context.Context, repo domain.BalanceRepository) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*checkTimeout)
+	defer cancel()
+
+	// O SDK não aceita contexto; sem rede a chamada pode demorar bem mais que
+	// o limite, então a resposta é aguardada em paralelo
+	result := make(chan error, 1)
+	go func() {
+		_, err := repo.Get(ctx)
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ForTenant identifica no relatório de prontidão as verificações de um tenant
+func ForTenant(tenant string, check HealthCheckFunc) HealthCheckFunc {
+	return func(ctx context.Context) domain.HealthCheck {
+		result := check(ctx)
+		result.Name = tenant + ":" + result.Name
+		return result
+	}
+}
+
+// StorageCheck verifica se o diretório de dados aceita escrita
+func StorageCheck(dataDir string) HealthCheckFunc {
+	return func(ctx context.Context) domain.HealthCheck {
+		return timed("storage", func() domain.HealthCheck {
+			f, err := os.CreateTemp(dataDir, ".readyz-*")
+			if err != nil {
+				return domain.HealthCheck{
+					Status:  domain.HealthStatusUnhealthy,
+					Message: fmt.Sprintf("diretório de dados sem escrita: %v", err),
+					Details: map[string]interface{}{"data_dir": filepath.Clean(dataDir)},
+				}
+			}
+			f.Close()
+			os.Remove(f.Name())
+			return domain.HealthCheck{
+				Status:  domain.HealthStatusHealthy,
+				Details: map[string]interface{}{"data_dir": filepath.Clean(dataDir)},
+			}
+		})
+	}
+}
+
+// HoldQueueCheck sinaliza degradação quando a fila de retenção passa de maxBacklog
+func HoldQueueCheck(holds *HoldQueueService, maxBacklog int) HealthCheckFunc {
+	return func(ctx context.Context) domain.HealthCheck {
+		return timed("hold_queue", func() domain.HealthCheck {
+			held, err := holds.List(domain.HeldTransferStatusHeld)
+			if err != nil {
+				return domain.HealthCheck{
+					Status:  domain.HealthStatusUnhealthy,
+					Message: fmt.Sprintf("erro ao consultar fila de retenção: %v", err),
+				}
+			}
+
+			result := domain.HealthCheck{
+				Status:  domain.HealthStatusHealthy,
+				Details: map[string]interface{}{"backlog": len(held), "max_backlog": maxBacklog},
+			}
+			if len(held) > maxBacklog {
+				result.Status = domain.HealthStatusDegraded
+				result.Message = fmt.Sprintf("%d repasses retidos aguardando saldo", len(held))
+			}
+			return result
+		})
+	}
+}
+
+// SchedulerCheck reporta o estado do gerador de invoices
+//
+// Concluir a duração configurada (ou estar desativado) é esperado; uma interrupção ou a falha do último lote
+// deixam a aplicação degradada.
+func SchedulerCheck(scheduler *SchedulerService) HealthCheckFunc {
+	return func(ctx context.Context) domain.HealthCheck {
+		return timed("scheduler", func() domain.HealthCheck {
+			status := scheduler.Status()
+			result := domain.HealthCheck{
+				Status: domain.HealthStatusHealthy,
+				Details: map[string]interface{}{
+					"state": status.State,
+					"runs":  status.Runs,
+				},
+			}
+			if status.LastRun != nil {
+				result.Details["last_run"] = status.LastRun
+			}
+
+			switch {
+			case status.LastError != "":
+				result.Status = domain.HealthStatusDegraded
+				result.Message = "último lote falhou: " + status.LastError
+			case status.State == SchedulerStateStopped:
+				result.Status = domain.HealthStatusDegraded
+				result.Message = "gerador de invoices interrompido"
+			}
+			return result
+		})
+	}
+}
+
+// EventPollingCheck reporta o estado da consulta periódica de eventos
+//
+// A falha da última consulta deixa a aplicação degradada: eventos pendentes
+// só serão processados quando a consulta voltar a funcionar.
+func EventPollingCheck(polling *EventPollingService) HealthCheckFunc {
+	return func(ctx context.Context) domain.HealthCheck {
+		return timed("event_polling", func() domain.HealthCheck {
+			status := polling.Status()
+			result := domain.HealthCheck{
+				Status: domain.HealthStatusHealthy,
+				Details: map[string]interface{}{
+					"source":    status.Source,
+					"polls":     status.Polls,
+					"processed": status.Processed,
+				},
+			}
+			if status.LastPoll != nil {
+				result.Details["last_poll"] = status.LastPoll
+			}
+			if status.Cursor != nil {
+				result.Details["cursor"] = status.Cursor
+			}
+
+			if status.LastError != "" {
+				result.Status = domain.HealthStatusDegraded
+				result.Message = "última consulta falhou: " + status.LastError
+			}
+			return result
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// countingBalanceRepository conta as consultas e falha quando err != nil
+type countingBalanceRepository struct {
+	calls int
+	err   error
+}
+
+func (r *countingBalanceRepository) Get(ctx context.Context) (*domain.Balance, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &domain.Balance{Amount: domain.BRL(100)}, nil
+}
+
+func TestReadyAggregatesWorstStatus(t *testing.T) {
+	fixed := func(status string) HealthCheckFunc {
+		return func(ctx context.Context) domain.HealthCheck {
+			return domain.HealthCheck{Name: status, Status: status}
+		}
+	}
+
+	svc := NewHealthService(fixed(domain.HealthStatusHealthy), fixed(domain.HealthStatusDegraded))
+	if report := svc.Ready(context.Background()); report.Status != domain.HealthStatusDegraded {
+		t.Errorf("status = %s, esperado degraded", report.Status)
+	}
+
+	svc = NewHealthService(fixed(domain.HealthStatusDegraded), fixed(domain.HealthStatusUnhealthy))
+	report := svc.Ready(context.Background())
+	if report.Status != domain.HealthStatusUnhealthy {
+		t.Errorf("status = %s, esperado unhealthy", report.Status)
+	}
+	if len(report.Checks) != 2 {
+		t.Errorf("esperados 2 checks, obtidos %d", len(report.Checks))
+	}
+}
+
+func TestStarkBankCheckIsCached(t *testing.T) {
+	repo := &countingBalanceRepository{err: errors.New("chave inválida")}
+	check := StarkBankCheck(repo, time.Minute)
+
+	first := check(context.Background())
+	second := check(context.Background())
+
+	if first.Status != domain.HealthStatusUnhealthy {
+		t.Errorf("status = %s, esperado unhealthy", first.Status)
+	}
+	if !second.Cached || repo.calls != 1 {
+		t.Errorf("segunda verificação deveria vir do cache (chamadas: %d)", repo.calls)
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/logging"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
+)
+
+// HoldQueueService mantém repasses retidos por falta de saldo e os libera
+// automaticamente quando o saldo se recupera
+type HoldQueueService struct {
+	repo     domain.HoldQueueRepository
+	balance  domain.BalanceProvider
+	interval time.Duration
+	auditor  domain.Auditor
+	stopChan chan bool
+
+	// Liberações e cancelamentos acontecem em série: um estorno não cancela
+	// uma retenção no meio da liberação
+	mu sync.Mutex
+}
+
+// ForwardFunc cria o repasse de um evento creditado
+type ForwardFunc func(ctx context.Context, event domain.WebhookEvent) (*domain.Transfer, error)
+
+// NewHoldQueueService cria uma nova instância do serviço
+func NewHoldQueueService(repo domain.HoldQueueRepository, balance domain.BalanceProvider, interval time.Duration, auditor domain.Auditor) *HoldQueueService {
+	return &HoldQueueService{
+		repo:     repo,
+		balance:  balance,
+		interval: interval,
+		auditor:  auditor,
+		stopChan: make(chan bool),
+	}
+}
+
+// Hold coloca o repasse de um invoice na fila de retenção
+func (s *HoldQueueService) Hold(ctx context.Context, event domain.WebhookEvent, reason domain.InsufficientBalanceError) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.repo.GetByInvoiceID(event.InvoiceID)
+	if err == nil {
+		slog.InfoContext(ctx, "repasse já está retido", "invoice_id", event.InvoiceID, "hold_id", existing.ID)
+		return nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	held := domain.HeldTransfer{
+		ID:        fmt.Sprintf("hold-%s-%d", event.InvoiceID, time.Now().Unix()),
+		InvoiceID: event.InvoiceID,
+		Event:     event,
+		Required:  reason.Required,
+		Available: reason.Available,
+		Status:    domain.HeldTransferStatusHeld,
+		Trace:     tracing.Inject(ctx),
+		Created:   time.Now(),
+	}
+
+	if err := s.repo.Save(held); err != nil {
+		return fmt.Errorf("erro ao reter repasse: %w", err)
+	}
+	s.auditor.Record(ctx, domain.AuditTransferHeld, held.ID, nil, map[string]interface{}{
+		"invoice_id": held.InvoiceID,
+		"status":     held.Status,
+		"required":   held.Required,
+		"available":  held.Available,
+	})
+
+	slog.WarnContext(ctx, "repasse retido por saldo insuficiente",
+		"invoice_id", event.InvoiceID,
+		"required", reason.Required,
+		"available", reason.Available)
+	return nil
+}
+
+// Cancel cancela a retenção de um invoice (ex: estornado antes da
+// liberação), que não será mais repassado; retorna false se o invoice não
+// estava retido
+func (s *HoldQueueService) Cancel(ctx context.Context, invoiceID, reason string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	held, err := s.repo.Cancel(invoiceID, reason)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("erro ao cancelar retenção: %w", err)
+	}
+	s.auditor.Record(ctx, domain.AuditTransferHoldCanceled, held.ID,
+		map[string]interface{}{"status": domain.HeldTransferStatusHeld},
+		map[string]interface{}{"status": held.Status, "invoice_id": held.InvoiceID, "note": reason})
+
+	slog.WarnContext(ctx, "retenção cancelada", "invoice_id", invoiceID, "hold_id", held.ID, "reason", reason)
+	return true, nil
+}
+
+// List lista as retenções com o status informado (vazio = todas)
+func (s *HoldQueueService) List(status string) ([]domain.HeldTransfer, error) {
+	return s.repo.ListByStatus(status)
+}
+
+// Reserved soma o valor das transferências retidas (líquido mais taxa), que
+// já pertence aos repasses e não pode ser varrido do saldo
+func (s *HoldQueueService) Reserved() (domain.Money, error) {
+	held, err := s.repo.ListByStatus(domain.HeldTransferStatusHeld)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("erro ao consultar transferências retidas: %w", err)
+	}
+	var total int64
+	for _, h := range held {
+		total += h.Required.Cents()
+	}
+	return domain.BRL(total), nil
+}
+
+// StartRelease inicia a liberação periódica da fila usando forward para criar
+// os repasses; ctx é a base de cada liberação
+func (s *HoldQueueService) StartRelease(ctx context.Context, forward ForwardFunc) {
+	slog.InfoContext(ctx, "iniciando liberação de repasses retidos", "interval", s.interval.String())
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Release(ctx, forward)
+		case <-s.stopChan:
+			slog.InfoContext(ctx, "liberação de repasses retidos interrompida")
+			return
+		}
+	}
+}
+
+// Stop para a liberação periódica
+func (s *HoldQueueService) Stop() {
+	close(s.stopChan)
+}
+
+// Release tenta criar os repasses retidos em ordem de chegada
+//
+// A liberação para no primeiro repasse que o saldo ainda não cobre, para
+// que retenções mais antigas tenham prioridade. Retenções canceladas ficam
+// de fora, e as de pagadores com estorno pendente continuam retidas.
+func (s *HoldQueueService) Release(base context.Context, forward ForwardFunc) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, err := s.repo.ListByStatus(domain.HeldTransferStatusHeld)
+	if err != nil {
+		slog.ErrorContext(base, "erro ao consultar fila de retenção", "error", err)
+		return 0
+	}
+	if len(queue) == 0 {
+		return 0
+	}
+
+	// Saldo possivelmente alterado desde a última verificação
+	s.balance.Invalidate()
+
+	released := 0
+	for _, held := range queue {
+		ctx := domain.ContextWithActor(base, domain.ActorHoldQueue)
+		ctx = logging.WithEventID(ctx, held.Event.EventID)
+		ctx, span := tracing.StartLinked(ctx, "HoldQueueService.Release", held.Trace,
+			attribute.String("invoice.id", held.InvoiceID),
+			attribute.Int("hold.attempts", held.Attempts+1))
+		held.Attempts++
+		transfer, err := forward(ctx, held.Event)
+		tracing.End(span, err)
+
+		var insufficient *domain.InsufficientBalanceError
+		if errors.As(err, &insufficient) {
+			held.Available = insufficient.Available
+			held.LastError = err.Error()
+			s.save(held)
+			slog.InfoContext(ctx, "saldo ainda insuficiente para liberar a fila", "pending", len(queue)-released)
+			return released
+		}
+		if errors.Is(err, domain.ErrPayerHeld) {
+			// Continua retido até a resolução do estorno, sem travar a fila
+			held.LastError = err.Error()
+			s.save(held)
+			slog.InfoContext(ctx, "repasse retido: pagador com estorno pendente", "invoice_id", held.InvoiceID)
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "erro ao liberar repasse", "invoice_id", held.InvoiceID, "error", err)
+			held.LastError = err.Error()
+			s.save(held)
+			continue
+		}
+
+		now := time.Now()
+		held.Status = domain.HeldTransferStatusReleased
+		held.Released = &now
+		held.LastError = ""
+		if transfer != nil {
+			held.TransferID = transfer.ID
+		} else {
+			held.Note = "crédito entregue à política de repasse (pendente na fila ou já repassado)"
+		}
+		s.save(held)
+		released++
+		s.auditor.Record(ctx, domain.AuditTransferReleased, held.ID,
+			map[string]interface{}{"status": domain.HeldTransferStatusHeld},
+			map[string]interface{}{"status": held.Status, "transfer_id": held.TransferID, "attempts": held.Attempts})
+
+		slog.InfoContext(ctx, "repasse liberado", "invoice_id", held.InvoiceID)
+	}
+
+	return released
+}
+
+func (s *HoldQueueService) save(held domain.HeldTransfer) {
+	if err := s.repo.Save(held); err != nil {
+		slog.Error("erro ao atualizar retenção", "hold_id", held.ID, "error", err)
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+)
+
+// stubBalanceProvider retorna um saldo fixo
+type stubBalanceProvider struct {
+	amount domain.Money
+}
+
+func (p *stubBalanceProvider) Get(ctx context.Context) (*domain.Balance, error) {
+	return &domain.Balance{Amount: p.amount}, nil
+}
+
+func (p *stubBalanceProvider) Invalidate() {}
+
+func TestHoldQueueReleaseInOrder(t *testing.T) {
+	repo, err := repository.NewFileHoldQueueRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("erro ao criar fila: %v", err)
+	}
+
+	balance := &stubBalanceProvider{amount: domain.BRL(0)}
+	svc := NewHoldQueueService(repo, balance, 0, NopAuditor)
+
+	for _, id := range []string{"inv-1", "inv-2", "inv-3"} {
+		reason := domain.InsufficientBalanceError{Required: domain.BRL(1000), Available: domain.BRL(0)}
+		if err := svc.Hold(context.Background(), domain.WebhookEvent{InvoiceID: id, Amount: domain.BRL(1000)}, reason); err != nil {
+			t.Fatalf("erro ao reter %s: %v", id, err)
+		}
+	}
+
+	// Retenção repetida do mesmo invoice não duplica a fila
+	svc.Hold(context.Background(), domain.WebhookEvent{InvoiceID: "inv-1"}, domain.InsufficientBalanceError{})
+
+	// Saldo cobre apenas os dois primeiros repasses
+	forwarded := []string{}
+	forward := func(ctx context.Context, event domain.WebhookEvent) (*domain.Transfer, error) {
+		if len(forwarded) == 2 {
+			return nil, &domain.InsufficientBalanceError{Required: event.Amount, Available: domain.BRL(0)}
+		}
+		forwarded = append(forwarded, event.InvoiceID)
+		return &domain.Transfer{ID: "tr-" + event.InvoiceID}, nil
+	}
+
+	if released := svc.Release(context.Background(), forward); released != 2 {
+		t.Errorf("esperado 2 repasses liberados, obtido %d", released)
+	}
+	if forwarded[0] != "inv-1" || forwarded[1] != "inv-2" {
+		t.Errorf("repasses liberados fora de ordem: %v", forwarded)
+	}
+
+	pending, _ := svc.List(domain.HeldTransferStatusHeld)
+	if len(pending) != 1 || pending[0].InvoiceID != "inv-3" || pending[0].Attempts != 1 {
+		t.Errorf("fila de retenção incorreta: %+v", pending)
+	}
+
+	released, _ := svc.List(domain.HeldTransferStatusReleased)
+	if len(released) != 2 || released[0].TransferID != "tr-inv-1" {
+		t.Errorf("retenções liberadas incorretas: %+v", released)
+	}
+}
+
+func TestHoldQueueReversalWhileHeld(t *testing.T) {
+	ctx := context.Background()
+	forwarding, transfers := newTestForwardingService(t, config.ForwardingConfig{Mode: config.ForwardingImmediate}, config.ApprovalConfig{})
+	reversals, _ := newTestReversalService(t, domain.ReversalActionHoldPayer, forwarding)
+	repo, err := repository.NewFileHoldQueueRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("erro ao criar fila: %v", err)
+	}
+	holds := NewHoldQueueService(repo, &stubBalanceProvider{}, 0, NopAuditor)
+	webhook := NewWebhookService(forwarding, reversals, forwarding.ledger, holds, nil)
+
+	event := credit("inv-1", 1000)
+	holds.Hold(ctx, event, domain.InsufficientBalanceError{Required: domain.BRL(1000)})
+
+	// Estorno enquanto o repasse aguarda saldo
+	reversed := event
+	reversed.EventType = "reversed"
+	if err := webhook.ProcessEvent(ctx, reversed); err != nil {
+		t.Fatalf("erro ao processar estorno: %v", err)
+	}
+
+	if released := holds.Release(ctx, webhook.Forward); released != 0 {
+		t.Errorf("retenção cancelada não deveria ser liberada: %d liberadas", released)
+	}
+	if len(transfers.created) != 0 {
+		t.Errorf("invoice estornado não deveria ser repassado: %+v", transfers.created)
+	}
+	canceled, _ := holds.List(domain.HeldTransferStatusCanceled)
+	if len(canceled) != 1 || canceled[0].InvoiceID != "inv-1" || canceled[0].Canceled == nil {
+		t.Errorf("retenção deveria estar cancelada: %+v", canceled)
+	}
+}
+
+func TestHeldPayerCreditsReleasedOnResolve(t *testing.T) {
+	ctx := context.Background()
+	forwarding, transfers := newTestForwardingService(t, config.ForwardingConfig{Mode: config.ForwardingImmediate}, config.ApprovalConfig{})
+	reversals, forwards := newTestReversalService(t, domain.ReversalActionHoldPayer, forwarding)
+	repo, err := repository.NewFileHoldQueueRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("erro ao criar fila: %v", err)
+	}
+	holds := NewHoldQueueService(repo, &stubBalanceProvider{}, 0, NopAuditor)
+	webhook := NewWebhookService(forwarding, reversals, forwarding.ledger, holds, nil)
+
+	fromPayer := func(id string, cents int64) domain.WebhookEvent {
+		event := credit(id, cents)
+		event.PayerTaxID = "012.345.678-90"
+		return event
+	}
+
+	// Estorno de um invoice já repassado bloqueia o pagador
+	forwards.Save(domain.Forward{InvoiceID: "inv-1", TransferID: "tr-0", PayerTaxID: "012.345.678-90", Forwarded: domain.BRL(1000)})
+	reversed := fromPayer("inv-1", 1000)
+	reversed.EventType = "reversed"
+	if err := webhook.ProcessEvent(ctx, reversed); err != nil {
+		t.Fatalf("erro ao processar estorno: %v", err)
+	}
+
+	// Novo crédito do pagador fica na fila, e uma retenção continua retida
+	if err := webhook.ProcessEvent(ctx, fromPayer("inv-2", 500)); err != nil {
+		t.Fatalf("erro ao processar crédito: %v", err)
+	}
+	holds.Hold(ctx, fromPayer("inv-3", 700), domain.InsufficientBalanceError{Required: domain.BRL(700)})
+	if released := holds.Release(ctx, webhook.Forward); released != 0 {
+		t.Errorf("retenção de pagador bloqueado não deveria ser liberada: %d liberadas", released)
+	}
+	if decision, _ := forwarding.Sweep(ctx); decision != nil || len(transfers.created) != 0 {
+		t.Fatalf("créditos de pagador bloqueado não deveriam ser repassados: %+v %+v", decision, transfers.created)
+	}
+	if pending, _ := forwarding.IsPending("inv-2"); !pending {
+		t.Fatal("crédito do pagador bloqueado deveria aguardar na fila")
+	}
+
+	// Resolver o estorno repassa o crédito da fila e libera a retenção
+	report, _ := reversals.Report()
+	if _, err := reversals.Resolve(ctx, report.Reversals[0].ID, ""); err != nil {
+		t.Fatalf("erro ao resolver: %v", err)
+	}
+	if len(transfers.created) != 1 || transfers.created[0].Amount.Cents() != 500 {
+		t.Fatalf("esperado o repasse de inv-2 na resolução: %+v", transfers.created)
+	}
+	if released := holds.Release(ctx, webhook.Forward); released != 1 {
+		t.Errorf("retenção deveria ser liberada após a resolução: %d liberadas", released)
+	}
+}
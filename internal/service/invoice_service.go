@@ -1,52 +1,128 @@
 package service
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"math/rand"
+	"strings"
+	"time"
 
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/calendar"
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
 )
 
+// maxInvoiceBatch é o máximo de invoices por requisição aceito pela StarkBank
+const maxInvoiceBatch = 100
+
 // InvoiceService gerencia a lógica de negócio relacionada a invoices
 type InvoiceService struct {
-	repo domain.InvoiceRepository
+	repo     domain.InvoiceRepository
+	calendar *calendar.Calendar
+	dueDays  int // vencimento em dias úteis; zero mantém o padrão da StarkBank
+	auditor  domain.Auditor
 }
 
 // NewInvoiceService cria uma nova instância do serviço
-func NewInvoiceService(repo domain.InvoiceRepository) *InvoiceService {
+func NewInvoiceService(repo domain.InvoiceRepository, cal *calendar.Calendar, dueDays int, auditor domain.Auditor) *InvoiceService {
 	return &InvoiceService{
-		repo: repo,
+		repo:     repo,
+		calendar: cal,
+		dueDays:  dueDays,
+		auditor:  auditor,
 	}
 }
 
-// GenerateRandomInvoices gera entre 8 e 12 invoices aleatórios
-func (s *InvoiceService) GenerateRandomInvoices() ([]domain.Invoice, error) {
-	count := rand.Intn(5) + 8 // 8-12
-	log.Printf("📝 Gerando %d invoices...\n", count)
+// GenerateRandomInvoices gera count invoices aleatórios
+func (s *InvoiceService) GenerateRandomInvoices(ctx context.Context, count int) (_ []domain.Invoice, err error) {
+	ctx, span := tracing.Start(ctx, "InvoiceService.GenerateRandomInvoices")
+	defer func() { tracing.End(span, err) }()
+
+	slog.InfoContext(ctx, "gerando invoices", "count", count)
 
 	invoices := make([]domain.Invoice, count)
 	for i := 0; i < count; i++ {
 		invoices[i] = s.generateRandomInvoice()
 	}
+	return s.create(ctx, invoices)
+}
+
+// Create emite os invoices informados (ex: pelo operador, via API ou CLI)
+//
+// Retorna domain.ErrInvalidInput se algum invoice não tiver valor positivo
+// em BRL, nome ou CPF/CNPJ.
+func (s *InvoiceService) Create(ctx context.Context, invoices []domain.Invoice) (_ []domain.Invoice, err error) {
+	ctx, span := tracing.Start(ctx, "InvoiceService.Create")
+	defer func() { tracing.End(span, err) }()
 
-	created, err := s.repo.Create(invoices)
+	if len(invoices) == 0 || len(invoices) > maxInvoiceBatch {
+		return nil, fmt.Errorf("%w: informe de 1 a %d invoices", domain.ErrInvalidInput, maxInvoiceBatch)
+	}
+	for i, inv := range invoices {
+		switch {
+		case !inv.Amount.IsPositive() || inv.Amount.Currency() != domain.CurrencyBRL:
+			return nil, fmt.Errorf("%w: invoice %d: valor deve ser positivo em BRL", domain.ErrInvalidInput, i+1)
+		case strings.TrimSpace(inv.Name) == "":
+			return nil, fmt.Errorf("%w: invoice %d: nome obrigatório", domain.ErrInvalidInput, i+1)
+		case strings.TrimSpace(inv.TaxID) == "":
+			return nil, fmt.Errorf("%w: invoice %d: CPF/CNPJ obrigatório", domain.ErrInvalidInput, i+1)
+		}
+	}
+	return s.create(ctx, invoices)
+}
+
+// create envia os invoices à StarkBank e registra o lote na auditoria
+func (s *InvoiceService) create(ctx context.Context, invoices []domain.Invoice) ([]domain.Invoice, error) {
+	if s.dueDays > 0 {
+		due := s.Due(time.Now())
+		for i := range invoices {
+			if invoices[i].Due == nil {
+				invoices[i].Due = &due
+			}
+		}
+	}
+
+	created, err := s.repo.Create(ctx, invoices)
 	if err != nil {
-		log.Printf("❌ Erro ao criar invoices: %v\n", err)
+		slog.ErrorContext(ctx, "erro ao criar invoices", "error", err)
 		return nil, err
 	}
 
 	// Log dos invoices criados
-	for _, invoice := range created {
-		log.Printf("✅ Invoice criado: ID=%s | Valor=R$%.2f | Nome=%s\n",
-			invoice.ID,
-			float64(invoice.Amount)/100,
-			invoice.Name)
+	ids := make([]string, len(created))
+	total := domain.BRL(0)
+	for i, invoice := range created {
+		ids[i] = invoice.ID
+		if sum, err := total.Add(invoice.Amount); err == nil {
+			total = sum
+		}
+		slog.InfoContext(ctx, "invoice criado",
+			"invoice_id", invoice.ID,
+			"amount", invoice.Amount,
+			"name", invoice.Name)
 	}
 
-	log.Printf("🎉 Total de %d invoices criados com sucesso!\n", len(created))
+	s.auditor.Record(ctx, domain.AuditInvoiceBatchCreated, "invoices", nil, map[string]interface{}{
+		"count":       len(created),
+		"total":       total,
+		"invoice_ids": ids,
+	})
+
+	metrics.InvoiceBatchSize.Observe(float64(len(created)))
+	metrics.InvoicesCreated.Add(float64(len(created)))
+	slog.InfoContext(ctx, "invoices criados", "count", len(created))
 	return created, nil
 }
 
+// Due retorna o vencimento de um invoice emitido em issued: o fim do dia
+// útil D+dueDays, para que o prazo não termine em fim de semana ou feriado
+func (s *InvoiceService) Due(issued time.Time) time.Time {
+	day := s.calendar.AddBusinessDays(startOfDay(issued), s.dueDays)
+	return day.Add(24*time.Hour - time.Second)
+}
+
 // generateRandomInvoice gera um invoice com dados aleatórios
 func (s *InvoiceService) generateRandomInvoice() domain.Invoice {
 	names := []string{
@@ -57,7 +133,7 @@ func (s *InvoiceService) generateRandomInvoice() domain.Invoice {
 
 	// CPFs válidos para testes (SEM formatação - apenas números)
 	// Valor aleatório entre R$ 100 e R$ 1000
-	amount := rand.Intn(90000) + 10000 // R$ 100 a R$ 1000
+	amount := domain.BRL(int64(rand.Intn(90000) + 10000)) // R$ 100 a R$ 1000
 	name := names[rand.Intn(len(names))]
 
 	// GERAR CPF DINAMICAMENTE - sempre válido!
@@ -69,18 +145,17 @@ func (s *InvoiceService) generateRandomInvoice() domain.Invoice {
 		TaxID:  taxId,
 	}
 
-	log.Printf("🎲 Invoice: %s | CPF:%s | R$%.2f\n",
-		name, taxId, float64(amount)/100)
+	slog.Debug("invoice gerado", "name", name, "tax_id", taxId, "amount", amount)
 
 	return invoice
 }
 
 // GetByID busca um invoice por ID
-func (s *InvoiceService) GetByID(id string) (*domain.Invoice, error) {
-	return s.repo.GetByID(id)
+func (s *InvoiceService) GetByID(ctx context.Context, id string) (*domain.Invoice, error) {
+	return s.repo.GetByID(ctx, id)
 }
 
 // List lista invoices
-func (s *InvoiceService) List(limit int) ([]domain.Invoice, error) {
-	return s.repo.List(limit)
+func (s *InvoiceService) List(ctx context.Context, limit int) ([]domain.Invoice, error) {
+	return s.repo.List(ctx, limit)
 }
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// Job é uma tarefa em background que também pode ser disparada manualmente
+type Job struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context) (string, error) // retorna um resumo do resultado
+}
+
+// JobRun é o resultado de uma execução manual
+type JobRun struct {
+	Job      string    `json:"job"`
+	Started  time.Time `json:"started"`
+	Duration string    `json:"duration"`
+	Result   string    `json:"result,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// JobStatus descreve um job e sua última execução manual
+type JobStatus struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Running     bool    `json:"running"`
+	LastRun     *JobRun `json:"last_run,omitempty"`
+}
+
+// JobService dispara os jobs de um tenant sob demanda
+type JobService struct {
+	jobs    []Job
+	auditor domain.Auditor
+
+	mu      sync.Mutex
+	running map[string]bool
+	last    map[string]JobRun
+}
+
+// NewJobService cria uma nova instância do serviço com os jobs disponíveis
+func NewJobService(auditor domain.Auditor, jobs ...Job) *JobService {
+	return &JobService{
+		jobs:    jobs,
+		auditor: auditor,
+		running: make(map[string]bool),
+		last:    make(map[string]JobRun),
+	}
+}
+
+// List lista os jobs e suas últimas execuções manuais
+func (s *JobService) List() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]JobStatus, len(s.jobs))
+	for i, job := range s.jobs {
+		result[i] = JobStatus{Name: job.Name, Description: job.Description, Running: s.running[job.Name]}
+		if run, ok := s.last[job.Name]; ok {
+			result[i].LastRun = &run
+		}
+	}
+	return result
+}
+
+// Run executa o job e aguarda o resultado
+//
+// Retorna domain.ErrNotFound para um job desconhecido e domain.ErrJobRunning
+// se ele já estiver em execução. Falhas do próprio job ficam em JobRun.Error.
+func (s *JobService) Run(ctx context.Context, name string) (*JobRun, error) {
+	var job *Job
+	for i := range s.jobs {
+		if s.jobs[i].Name == name {
+			job = &s.jobs[i]
+		}
+	}
+	if job == nil {
+		return nil, fmt.Errorf("%w: job %q", domain.ErrNotFound, name)
+	}
+
+	s.mu.Lock()
+	if s.running[name] {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", domain.ErrJobRunning, name)
+	}
+	s.running[name] = true
+	s.mu.Unlock()
+
+	slog.InfoContext(ctx, "job disparado manualmente", "job", name)
+	run := JobRun{Job: name, Started: time.Now()}
+	result, err := job.Run(ctx)
+	run.Duration = time.Since(run.Started).Round(time.Millisecond).String()
+	run.Result = result
+	if err != nil {
+		run.Error = err.Error()
+		slog.ErrorContext(ctx, "erro no job disparado manualmente", "job", name, "error", err)
+	}
+
+	s.mu.Lock()
+	s.running[name] = false
+	s.last[name] = run
+	s.mu.Unlock()
+
+	s.auditor.Record(ctx, domain.AuditJobTriggered, name, nil, map[string]interface{}{
+		"result": run.Result,
+		"error":  run.Error,
+	})
+	return &run, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+func TestJobServiceRun(t *testing.T) {
+	ctx := context.Background()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	svc := NewJobService(NopAuditor,
+		Job{Name: "slow", Run: func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "ok", nil
+		}},
+		Job{Name: "broken", Run: func(ctx context.Context) (string, error) {
+			return "", errors.New("falhou")
+		}},
+	)
+
+	if _, err := svc.Run(ctx, "missing"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("job desconhecido deveria retornar ErrNotFound, obtido %v", err)
+	}
+
+	done := make(chan *JobRun)
+	go func() {
+		run, _ := svc.Run(ctx, "slow")
+		done <- run
+	}()
+	<-started
+	if _, err := svc.Run(ctx, "slow"); !errors.Is(err, domain.ErrJobRunning) {
+		t.Errorf("execução concorrente deveria retornar ErrJobRunning, obtido %v", err)
+	}
+	close(release)
+	if run := <-done; run.Result != "ok" || run.Error != "" {
+		t.Errorf("resultado inesperado: %+v", run)
+	}
+
+	run, err := svc.Run(ctx, "broken")
+	if err != nil || run.Error != "falhou" {
+		t.Errorf("falha do job deveria ficar em JobRun.Error: %+v %v", run, err)
+	}
+
+	for _, status := range svc.List() {
+		if status.Running || status.LastRun == nil {
+			t.Errorf("%s: estado inesperado %+v", status.Name, status)
+		}
+	}
+}
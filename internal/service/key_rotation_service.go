@@ -0,0 +1,446 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/secrets"
+)
+
+// KeyVerifier confirma com a API que a chave carregada com a impressão
+// digital indicada é aceita para o projeto
+type KeyVerifier func(ctx context.Context, fingerprint string) error
+
+// KeyRotationService conduz a troca da chave privada sem reinício
+//
+// As etapas são: Start gera o novo par e carrega a chave sem ativá-la;
+// Confirm confere a nova chave com a API (a chave pública precisa estar
+// cadastrada) e passa a assinar com ela; Retire descarta a chave anterior.
+// Até Retire, a chave anterior segue carregada e Rollback volta a usá-la.
+type KeyRotationService struct {
+	repo    domain.KeyRotationRepository
+	keys    *secrets.KeyRing
+	store   secrets.Store  // nil quando a origem da chave é somente leitura
+	sealer  secrets.Sealer // nil quando a origem não cifra a chave
+	verify  KeyVerifier
+	auditor domain.Auditor
+
+	mu sync.Mutex
+}
+
+// NewKeyRotationService cria uma nova instância do serviço
+func NewKeyRotationService(
+	repo domain.KeyRotationRepository,
+	keys *secrets.KeyRing,
+	store secrets.Store,
+	verify KeyVerifier,
+	auditor domain.Auditor,
+) *KeyRotationService {
+	// Com o keystore, as chaves guardadas no registro também ficam cifradas
+	sealer, _ := store.(secrets.Sealer)
+	return &KeyRotationService{
+		repo:    repo,
+		keys:    keys,
+		store:   store,
+		sealer:  sealer,
+		verify:  verify,
+		auditor: auditor,
+	}
+}
+
+// Restore retoma na inicialização uma rotação em andamento
+//
+// A chave da origem configurada já está no chaveiro; conforme a etapa da
+// última rotação, a nova chave é recarregada (pending), ativada mantendo a
+// anterior (switched) ou ativada no lugar de uma origem ainda desatualizada
+// (retired sem gravação na origem).
+func (s *KeyRotationService) Restore(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.repo.Latest()
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao consultar rotação de chave: %w", err)
+	}
+	if err := s.sealStored(ctx, r); err != nil {
+		return err
+	}
+	configured := s.keys.Active()
+
+	switch r.Status {
+	case domain.KeyRotationPending:
+		pemKey, err := s.open(ctx, r.NewPrivateKey)
+		if err != nil {
+			return fmt.Errorf("nova chave da rotação %s indisponível: %w", r.ID, err)
+		}
+		if _, err := s.keys.Add(pemKey); err != nil {
+			return fmt.Errorf("nova chave da rotação %s inválida: %w", r.ID, err)
+		}
+		slog.InfoContext(ctx, "rotação de chave aguardando confirmação",
+			"rotation_id", r.ID, "new_fingerprint", r.NewFingerprint)
+
+	case domain.KeyRotationSwitched:
+		for _, stored := range []string{r.NewPrivateKey, r.OldPrivateKey} {
+			if stored == "" {
+				continue
+			}
+			pemKey, err := s.open(ctx, stored)
+			if err != nil {
+				return fmt.Errorf("chave da rotação %s indisponível: %w", r.ID, err)
+			}
+			if _, err := s.keys.Add(pemKey); err != nil {
+				return fmt.Errorf("chave da rotação %s inválida: %w", r.ID, err)
+			}
+		}
+		if err := s.keys.Activate(r.NewFingerprint); err != nil {
+			return fmt.Errorf("nova chave da rotação %s indisponível: %w", r.ID, err)
+		}
+		slog.InfoContext(ctx, "rotação de chave aguardando aposentadoria da chave anterior",
+			"rotation_id", r.ID, "new_fingerprint", r.NewFingerprint, "old_fingerprint", r.OldFingerprint)
+
+	case domain.KeyRotationRetired:
+		if configured == r.NewFingerprint {
+			if r.NewPrivateKey != "" {
+				// A origem já foi atualizada: a cópia da chave no registro não é mais necessária
+				r.NewPrivateKey = ""
+				return s.repo.Save(*r)
+			}
+			return nil
+		}
+		if r.NewPrivateKey == "" {
+			slog.WarnContext(ctx, "chave configurada difere da última rotação",
+				"rotation_id", r.ID, "configured_fingerprint", configured, "rotated_fingerprint", r.NewFingerprint)
+			return nil
+		}
+		pemKey, err := s.open(ctx, r.NewPrivateKey)
+		if err != nil {
+			return fmt.Errorf("chave da rotação %s indisponível: %w", r.ID, err)
+		}
+		if _, err := s.keys.Add(pemKey); err != nil {
+			return fmt.Errorf("chave da rotação %s inválida: %w", r.ID, err)
+		}
+		if err := s.keys.Activate(r.NewFingerprint); err != nil {
+			return err
+		}
+		s.keys.Remove(configured)
+		slog.WarnContext(ctx, "origem configurada ainda contém a chave aposentada; usando a chave da última rotação (atualize a origem)",
+			"rotation_id", r.ID, "fingerprint", r.NewFingerprint)
+	}
+	return nil
+}
+
+// Start gera um novo par de chaves e carrega a chave privada sem ativá-la
+//
+// A chave pública retornada deve ser cadastrada no painel da StarkBank antes
+// de Confirm.
+func (s *KeyRotationService) Start(ctx context.Context) (*domain.KeyRotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, err := s.latest(); err != nil {
+		return nil, err
+	} else if r != nil && r.Open() {
+		return nil, fmt.Errorf("%w: rotação %s está %s", domain.ErrKeyRotationState, r.ID, r.Status)
+	}
+
+	privatePEM, publicPEM, err := secrets.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.seal(ctx, privatePEM)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := s.keys.Add(privatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("erro ao gerar ID da rotação: %w", err)
+	}
+	r := domain.KeyRotation{
+		ID:             "rot-" + hex.EncodeToString(id),
+		Status:         domain.KeyRotationPending,
+		PublicKey:      publicPEM,
+		NewFingerprint: fingerprint,
+		OldFingerprint: s.keys.Active(),
+		NewPrivateKey:  stored,
+		Created:        time.Now(),
+	}
+	if err := s.repo.Save(r); err != nil {
+		s.keys.Remove(fingerprint)
+		return nil, fmt.Errorf("erro ao salvar rotação: %w", err)
+	}
+
+	s.auditor.Record(ctx, domain.AuditKeyRotationStarted, r.ID, nil, rotationAudit(r))
+	slog.InfoContext(ctx, "rotação de chave iniciada", "rotation_id", r.ID, "new_fingerprint", fingerprint)
+	return &r, nil
+}
+
+// Confirm confere a nova chave com a API e passa a assinar com ela
+//
+// Quando a origem configurada aceita escrita (arquivo ou keystore), a nova
+// chave é gravada nela; senão, o registro da rotação a preserva para os
+// próximos reinícios até que o operador atualize a origem.
+func (s *KeyRotationService) Confirm(ctx context.Context) (*domain.KeyRotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.expect(domain.KeyRotationPending)
+	if err != nil {
+		return nil, err
+	}
+	before := rotationAudit(*r)
+
+	if err := s.verify(ctx, r.NewFingerprint); err != nil {
+		return nil, fmt.Errorf("nova chave não confirmada pela StarkBank (a chave pública foi cadastrada no painel?): %w", err)
+	}
+
+	// A chave anterior fica no registro até ser aposentada, para que um
+	// reinício (ou Rollback) no meio da rotação ainda consiga carregá-la
+	oldKey, err := s.keys.PEM(r.OldFingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if r.OldPrivateKey, err = s.seal(ctx, oldKey); err != nil {
+		return nil, err
+	}
+	newKey, err := s.keys.PEM(r.NewFingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.keys.Activate(r.NewFingerprint); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	r.Status = domain.KeyRotationSwitched
+	r.Switched = &now
+	if s.store != nil {
+		if err := s.store.StorePrivateKey(ctx, newKey); err != nil {
+			slog.ErrorContext(ctx, "erro ao gravar a nova chave na origem configurada", "rotation_id", r.ID, "error", err)
+		} else {
+			r.Persisted = true
+		}
+	}
+	if err := s.repo.Save(*r); err != nil {
+		s.keys.Activate(r.OldFingerprint)
+		return nil, fmt.Errorf("erro ao salvar rotação: %w", err)
+	}
+
+	s.auditor.Record(ctx, domain.AuditKeyRotationSwitched, r.ID, before, rotationAudit(*r))
+	slog.InfoContext(ctx, "assinatura trocada para a nova chave",
+		"rotation_id", r.ID, "fingerprint", r.NewFingerprint, "persisted", r.Persisted)
+	return r, nil
+}
+
+// Rollback volta a assinar com a chave anterior; a rotação retorna a pending
+func (s *KeyRotationService) Rollback(ctx context.Context) (*domain.KeyRotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.expect(domain.KeyRotationSwitched)
+	if err != nil {
+		return nil, err
+	}
+	before := rotationAudit(*r)
+
+	if r.Persisted {
+		oldKey, err := s.keys.PEM(r.OldFingerprint)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.store.StorePrivateKey(ctx, oldKey); err != nil {
+			return nil, fmt.Errorf("erro ao restaurar a chave anterior na origem configurada: %w", err)
+		}
+	}
+	if err := s.keys.Activate(r.OldFingerprint); err != nil {
+		return nil, err
+	}
+
+	r.Status = domain.KeyRotationPending
+	r.Switched = nil
+	r.Persisted = false
+	r.OldPrivateKey = ""
+	if err := s.repo.Save(*r); err != nil {
+		return nil, fmt.Errorf("erro ao salvar rotação: %w", err)
+	}
+
+	s.auditor.Record(ctx, domain.AuditKeyRotationRolledBack, r.ID, before, rotationAudit(*r))
+	slog.WarnContext(ctx, "assinatura voltou para a chave anterior", "rotation_id", r.ID, "fingerprint", r.OldFingerprint)
+	return r, nil
+}
+
+// Retire descarta a chave anterior; a partir daqui não há como voltar a ela
+func (s *KeyRotationService) Retire(ctx context.Context) (*domain.KeyRotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.expect(domain.KeyRotationSwitched)
+	if err != nil {
+		return nil, err
+	}
+	before := rotationAudit(*r)
+
+	now := time.Now()
+	r.Status = domain.KeyRotationRetired
+	r.Retired = &now
+	r.OldPrivateKey = ""
+	if r.Persisted {
+		r.NewPrivateKey = ""
+	}
+	if err := s.repo.Save(*r); err != nil {
+		return nil, fmt.Errorf("erro ao salvar rotação: %w", err)
+	}
+	if err := s.keys.Remove(r.OldFingerprint); err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, domain.AuditKeyRotationRetired, r.ID, before, rotationAudit(*r))
+	slog.InfoContext(ctx, "chave anterior aposentada", "rotation_id", r.ID, "old_fingerprint", r.OldFingerprint)
+	if !r.Persisted {
+		slog.WarnContext(ctx, "atualize a origem da chave privada com a nova chave; até lá ela é lida do registro da rotação",
+			"rotation_id", r.ID)
+	}
+	return r, nil
+}
+
+// Cancel descarta a nova chave antes da troca
+func (s *KeyRotationService) Cancel(ctx context.Context) (*domain.KeyRotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.expect(domain.KeyRotationPending)
+	if err != nil {
+		return nil, err
+	}
+	before := rotationAudit(*r)
+
+	now := time.Now()
+	r.Status = domain.KeyRotationCancelled
+	r.Cancelled = &now
+	r.NewPrivateKey = ""
+	if err := s.repo.Save(*r); err != nil {
+		return nil, fmt.Errorf("erro ao salvar rotação: %w", err)
+	}
+	s.keys.Remove(r.NewFingerprint)
+
+	s.auditor.Record(ctx, domain.AuditKeyRotationCancelled, r.ID, before, rotationAudit(*r))
+	slog.InfoContext(ctx, "rotação de chave cancelada", "rotation_id", r.ID)
+	return r, nil
+}
+
+// Latest retorna a rotação mais recente (nil se nunca houve rotação)
+func (s *KeyRotationService) Latest() (*domain.KeyRotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latest()
+}
+
+// ActiveFingerprint retorna a impressão digital da chave que assina as chamadas
+func (s *KeyRotationService) ActiveFingerprint() string {
+	return s.keys.Active()
+}
+
+// LoadedFingerprints lista as chaves carregadas no chaveiro
+func (s *KeyRotationService) LoadedFingerprints() []string {
+	return s.keys.Loaded()
+}
+
+func (s *KeyRotationService) latest() (*domain.KeyRotation, error) {
+	r, err := s.repo.Latest()
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar rotação de chave: %w", err)
+	}
+	return r, nil
+}
+
+// expect retorna a rotação mais recente se ela estiver no estado indicado
+func (s *KeyRotationService) expect(status string) (*domain.KeyRotation, error) {
+	r, err := s.latest()
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: nenhuma rotação em andamento", domain.ErrKeyRotationState)
+	}
+	if r.Status != status {
+		return nil, fmt.Errorf("%w: rotação %s está %s (esperado %s)", domain.ErrKeyRotationState, r.ID, r.Status, status)
+	}
+	return r, nil
+}
+
+// seal cifra a chave a guardar no registro quando a origem é o keystore
+func (s *KeyRotationService) seal(ctx context.Context, pemKey string) (string, error) {
+	if s.sealer == nil {
+		return pemKey, nil
+	}
+	sealed, err := s.sealer.SealPrivateKey(ctx, pemKey)
+	if err != nil {
+		return "", fmt.Errorf("erro ao cifrar a chave da rotação: %w", err)
+	}
+	return sealed, nil
+}
+
+// open devolve a chave guardada no registro em PEM
+func (s *KeyRotationService) open(ctx context.Context, stored string) (string, error) {
+	if !secrets.IsSealed(stored) {
+		return stored, nil
+	}
+	if s.sealer == nil {
+		return "", errors.New("chave cifrada pelo keystore, mas a origem configurada é outra")
+	}
+	return s.sealer.OpenPrivateKey(ctx, stored)
+}
+
+// sealStored cifra as chaves gravadas em texto puro antes de o keystore ser
+// a origem configurada
+func (s *KeyRotationService) sealStored(ctx context.Context, r *domain.KeyRotation) error {
+	if s.sealer == nil {
+		return nil
+	}
+	changed := false
+	for _, stored := range []*string{&r.NewPrivateKey, &r.OldPrivateKey} {
+		if *stored == "" || secrets.IsSealed(*stored) {
+			continue
+		}
+		sealed, err := s.seal(ctx, *stored)
+		if err != nil {
+			return err
+		}
+		*stored, changed = sealed, true
+	}
+	if !changed {
+		return nil
+	}
+	if err := s.repo.Save(*r); err != nil {
+		return fmt.Errorf("erro ao salvar rotação: %w", err)
+	}
+	slog.InfoContext(ctx, "chaves do registro da rotação cifradas com o keystore", "rotation_id", r.ID)
+	return nil
+}
+
+// rotationAudit resume a rotação para a auditoria, sem as chaves privadas
+func rotationAudit(r domain.KeyRotation) map[string]interface{} {
+	return map[string]interface{}{
+		"status":          r.Status,
+		"new_fingerprint": r.NewFingerprint,
+		"old_fingerprint": r.OldFingerprint,
+		"persisted":       r.Persisted,
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/secrets"
+)
+
+func TestKeyRotationFlow(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	oldKey, _, err := secrets.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := secrets.NewFileProvider(filepath.Join(dir, "private.pem"))
+	if err := keyFile.StorePrivateKey(ctx, oldKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// newService simula a inicialização: lê a chave da origem e retoma a rotação
+	registered := false
+	newService := func() (*KeyRotationService, *secrets.KeyRing) {
+		pemKey, err := keyFile.PrivateKey(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys, err := secrets.NewKeyRing("123", "sandbox", pemKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		repo, err := repository.NewFileKeyRotationRepository(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify := func(ctx context.Context, fingerprint string) error {
+			if !registered {
+				return domain.ErrCredentialsRejected
+			}
+			return nil
+		}
+		svc := NewKeyRotationService(repo, keys, keyFile, verify, NopAuditor)
+		if err := svc.Restore(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return svc, keys
+	}
+
+	svc, keys := newService()
+	oldFingerprint := keys.Active()
+
+	rotation, err := svc.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.PublicKey == "" || keys.Active() != oldFingerprint || len(keys.Loaded()) != 2 {
+		t.Fatalf("nova chave deveria estar carregada sem assinar: %+v", keys.Loaded())
+	}
+	if _, err := svc.Start(ctx); !errors.Is(err, domain.ErrKeyRotationState) {
+		t.Errorf("segunda rotação simultânea deveria ser recusada, erro: %v", err)
+	}
+
+	// Sem a chave pública cadastrada a troca é recusada
+	if _, err := svc.Confirm(ctx); !errors.Is(err, domain.ErrCredentialsRejected) {
+		t.Fatalf("troca deveria exigir a chave cadastrada, erro: %v", err)
+	}
+	registered = true
+	if _, err := svc.Confirm(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if keys.Active() != rotation.NewFingerprint {
+		t.Fatal("assinatura deveria usar a nova chave")
+	}
+
+	// Reinício entre a troca e a aposentadoria: origem já tem a nova chave e a
+	// anterior é recarregada do registro
+	svc, keys = newService()
+	if keys.Active() != rotation.NewFingerprint || len(keys.Loaded()) != 2 {
+		t.Fatalf("rotação não retomada: ativa %s, carregadas %v", keys.Active(), keys.Loaded())
+	}
+
+	retired, err := svc.Retire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys.Loaded()) != 1 || retired.OldPrivateKey != "" || retired.NewPrivateKey != "" {
+		t.Errorf("chave anterior deveria ter sido descartada: %v %+v", keys.Loaded(), retired)
+	}
+	if _, err := svc.Rollback(ctx); !errors.Is(err, domain.ErrKeyRotationState) {
+		t.Errorf("rollback após aposentar deveria ser recusado, erro: %v", err)
+	}
+}
+
+func TestKeyRotationSealsKeysWithKeystore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	oldKey, _, err := secrets.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase := func() (string, error) { return "senha", nil }
+	keystore := secrets.NewKeystoreProvider(filepath.Join(dir, "keystore.json"), passphrase)
+	if err := keystore.StorePrivateKey(ctx, oldKey); err != nil {
+		t.Fatal(err)
+	}
+
+	newService := func() (*KeyRotationService, *secrets.KeyRing) {
+		pemKey, err := keystore.PrivateKey(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys, err := secrets.NewKeyRing("123", "sandbox", pemKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		repo, err := repository.NewFileKeyRotationRepository(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verify := func(ctx context.Context, fingerprint string) error { return nil }
+		svc := NewKeyRotationService(repo, keys, keystore, verify, NopAuditor)
+		if err := svc.Restore(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return svc, keys
+	}
+	plaintext := func() bool {
+		content, err := os.ReadFile(filepath.Join(dir, "key_rotations.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Contains(string(content), "PRIVATE KEY")
+	}
+
+	svc, _ := newService()
+	rotation, err := svc.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext() {
+		t.Fatal("nova chave não deveria ficar em texto puro no registro")
+	}
+	if _, err := svc.Confirm(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if plaintext() {
+		t.Fatal("chave anterior não deveria ficar em texto puro no registro")
+	}
+
+	// Reinício: as chaves cifradas são recarregadas do registro
+	_, keys := newService()
+	if keys.Active() != rotation.NewFingerprint || len(keys.Loaded()) != 2 {
+		t.Fatalf("rotação não retomada: ativa %s, carregadas %v", keys.Active(), keys.Loaded())
+	}
+}
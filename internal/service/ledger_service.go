@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// LedgerService registra a movimentação financeira no razão interno
+type LedgerService struct {
+	repo domain.LedgerRepository
+}
+
+// InvoiceReconciliation compara o valor creditado de um invoice com repasses e taxas
+type InvoiceReconciliation struct {
+	InvoiceID string `json:"invoice_id"`
+	Credited  int64  `json:"credited"`
+	Fees      int64  `json:"fees"`
+	Forwarded int64  `json:"forwarded"`
+	Pending   int64  `json:"pending"`
+	InTransit int64  `json:"in_transit"`
+	Balanced  bool   `json:"balanced"`
+}
+
+// LedgerVerification é o resultado da verificação do razão
+type LedgerVerification struct {
+	Balanced           bool                    `json:"balanced"`
+	Accounts           map[string]int64        `json:"accounts"`
+	UnbalancedEntries  []string                `json:"unbalanced_entries"`
+	PendingInvoices    []InvoiceReconciliation `json:"pending_invoices"`
+	InTransitInvoices  []InvoiceReconciliation `json:"in_transit_invoices"`
+	MismatchedInvoices []InvoiceReconciliation `json:"mismatched_invoices"`
+	InvoiceCount       int                     `json:"invoice_count"`
+}
+
+// NewLedgerService cria uma nova instância do serviço
+func NewLedgerService(repo domain.LedgerRepository) *LedgerService {
+	return &LedgerService{
+		repo: repo,
+	}
+}
+
+// RecordInvoiceCredit registra o crédito de um invoice: bruto = líquido pendente + taxa
+//
+// O lançamento é idempotente: um evento reenviado não gera novo lançamento.
+func (s *LedgerService) RecordInvoiceCredit(ctx context.Context, invoiceID string, amount, fee domain.Money) error {
+	net, err := amount.Sub(fee)
+	if err != nil {
+		return fmt.Errorf("erro ao calcular valor líquido: %w", err)
+	}
+
+	entry := domain.LedgerEntry{
+		ID:        fmt.Sprintf("led-%s-credit", invoiceID),
+		Key:       "invoice_credited:" + invoiceID,
+		Kind:      domain.LedgerEntryInvoiceCredited,
+		InvoiceID: invoiceID,
+		Postings: []domain.LedgerPosting{
+			{Account: domain.LedgerAccountPending, Debit: net.Cents()},
+			{Account: domain.LedgerAccountFees, Debit: fee.Cents()},
+			{Account: domain.LedgerAccountReceivables, Credit: amount.Cents()},
+		},
+		Created: time.Now(),
+	}
+
+	return s.append(ctx, entry)
+}
+
+// RecordForwardIntent registra, antes da chamada à StarkBank, que o valor
+// pendente de um invoice está sendo repassado
+//
+// A partir daqui HasForward bloqueia novos repasses do invoice: se o processo
+// cair antes do resultado, o invoice fica em trânsito na verificação do razão
+// até ser conciliado, em vez de ser pago de novo. Cada tentativa tem a própria
+// chave, para que uma nova tentativa após RecordForwardFailed seja registrada.
+func (s *LedgerService) RecordForwardIntent(ctx context.Context, invoiceID string, amount domain.Money) error {
+	entries, err := s.repo.ListByInvoiceID(invoiceID)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar razão: %w", err)
+	}
+	attempt := 1
+	for _, e := range entries {
+		if e.Kind == domain.LedgerEntryTransferRequested {
+			attempt++
+		}
+	}
+
+	entry := domain.LedgerEntry{
+		ID:        fmt.Sprintf("led-%s-request-%d", invoiceID, attempt),
+		Key:       fmt.Sprintf("transfer_requested:%s:%d", invoiceID, attempt),
+		Kind:      domain.LedgerEntryTransferRequested,
+		InvoiceID: invoiceID,
+		Postings: []domain.LedgerPosting{
+			{Account: domain.LedgerAccountInTransit, Debit: amount.Cents()},
+			{Account: domain.LedgerAccountPending, Credit: amount.Cents()},
+		},
+		Created: time.Now(),
+	}
+
+	return s.append(ctx, entry)
+}
+
+// RecordForward concilia a intenção de repasse de um invoice com a
+// transferência criada
+func (s *LedgerService) RecordForward(ctx context.Context, invoiceID, transferID string, amount domain.Money) error {
+	entry := domain.LedgerEntry{
+		ID:         fmt.Sprintf("led-%s-forward", invoiceID),
+		Key:        "transfer_created:" + invoiceID,
+		Kind:       domain.LedgerEntryTransferCreated,
+		InvoiceID:  invoiceID,
+		TransferID: transferID,
+		Postings: []domain.LedgerPosting{
+			{Account: domain.LedgerAccountForwarded, Debit: amount.Cents()},
+			{Account: domain.LedgerAccountInTransit, Credit: amount.Cents()},
+		},
+		Created: time.Now(),
+	}
+
+	return s.append(ctx, entry)
+}
+
+// RecordForwardFailed concilia a intenção de repasse de um invoice cuja
+// transferência não foi criada: o valor volta a pendente
+func (s *LedgerService) RecordForwardFailed(ctx context.Context, invoiceID string, amount domain.Money) error {
+	entries, err := s.repo.ListByInvoiceID(invoiceID)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar razão: %w", err)
+	}
+	attempt := 0
+	for _, e := range entries {
+		if e.Kind == domain.LedgerEntryTransferRequested {
+			attempt++
+		}
+	}
+
+	entry := domain.LedgerEntry{
+		ID:        fmt.Sprintf("led-%s-failed-%d", invoiceID, attempt),
+		Key:       fmt.Sprintf("transfer_failed:%s:%d", invoiceID, attempt),
+		Kind:      domain.LedgerEntryTransferFailed,
+		InvoiceID: invoiceID,
+		Postings: []domain.LedgerPosting{
+			{Account: domain.LedgerAccountPending, Debit: amount.Cents()},
+			{Account: domain.LedgerAccountInTransit, Credit: amount.Cents()},
+		},
+		Created: time.Now(),
+	}
+
+	return s.append(ctx, entry)
+}
+
+// RecordScheduledTransfer registra uma transferência agendada ou recorrente,
+// paga com o saldo próprio da conta e não com créditos de invoices
+//
+// at é quando o valor sai da conta: a data agendada de uma transferência
+// única ou o horário da execução de uma regra recorrente.
+func (s *LedgerService) RecordScheduledTransfer(ctx context.Context, transferID string, amount domain.Money, at time.Time) error {
+	entry := domain.LedgerEntry{
+		ID:         fmt.Sprintf("led-%s-scheduled", transferID),
+		Key:        "scheduled_transfer:" + transferID,
+		Kind:       domain.LedgerEntryScheduledTransfer,
+		TransferID: transferID,
+		Postings: []domain.LedgerPosting{
+			{Account: domain.LedgerAccountScheduled, Debit: amount.Cents()},
+			{Account: domain.LedgerAccountOwnFunds, Credit: amount.Cents()},
+		},
+		Created: at,
+	}
+
+	return s.append(ctx, entry)
+}
+
+// RecordScheduledReverted estorna o lançamento de uma transferência agendada
+// que não saiu da conta (cancelada ou recusada), na mesma data dele
+func (s *LedgerService) RecordScheduledReverted(ctx context.Context, transferID string) error {
+	entries, err := s.repo.ListByTransferID(transferID)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar razão: %w", err)
+	}
+	for _, e := range entries {
+		if e.Kind != domain.LedgerEntryScheduledTransfer {
+			continue
+		}
+		var amount int64
+		for _, p := range e.Postings {
+			if p.Account == domain.LedgerAccountScheduled {
+				amount += p.Debit
+			}
+		}
+		return s.append(ctx, domain.LedgerEntry{
+			ID:         fmt.Sprintf("led-%s-scheduled-reverted", transferID),
+			Key:        "scheduled_reverted:" + transferID,
+			Kind:       domain.LedgerEntryScheduledReverted,
+			TransferID: transferID,
+			Postings: []domain.LedgerPosting{
+				{Account: domain.LedgerAccountOwnFunds, Debit: amount},
+				{Account: domain.LedgerAccountScheduled, Credit: amount},
+			},
+			Created: e.Created,
+		})
+	}
+	return nil
+}
+
+// RecordReversal registra o estorno de um invoice: o valor devolvido ao
+// pagador sai do saldo próprio da conta, sem alterar o crédito nem o repasse
+// do invoice
+func (s *LedgerService) RecordReversal(ctx context.Context, invoiceID string, amount domain.Money) error {
+	entry := domain.LedgerEntry{
+		ID:        fmt.Sprintf("led-%s-reversal", invoiceID),
+		Key:       "reversal:" + invoiceID,
+		Kind:      domain.LedgerEntryReversal,
+		InvoiceID: invoiceID,
+		Postings: []domain.LedgerPosting{
+			{Account: domain.LedgerAccountReversed, Debit: amount.Cents()},
+			{Account: domain.LedgerAccountOwnFunds, Credit: amount.Cents()},
+		},
+		Created: time.Now(),
+	}
+
+	return s.append(ctx, entry)
+}
+
+// HasForward indica se o invoice já foi repassado ou tem repasse em trânsito
+func (s *LedgerService) HasForward(invoiceID string) (bool, error) {
+	entries, err := s.repo.ListByInvoiceID(invoiceID)
+	if err != nil {
+		return false, err
+	}
+
+	var out int64
+	for _, e := range entries {
+		for _, p := range e.Postings {
+			if p.Account == domain.LedgerAccountInTransit || p.Account == domain.LedgerAccountForwarded {
+				out += p.Debit - p.Credit
+			}
+		}
+	}
+	return out > 0, nil
+}
+
+// ForwardedSince soma os repasses registrados a partir de since
+func (s *LedgerService) ForwardedSince(since time.Time) (domain.Money, error) {
+	entries, err := s.repo.List()
+	if err != nil {
+		return domain.Money{}, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		if e.Kind != domain.LedgerEntryTransferCreated || e.Created.Before(since) {
+			continue
+		}
+		for _, p := range e.Postings {
+			if p.Account == domain.LedgerAccountForwarded {
+				total += p.Debit
+			}
+		}
+	}
+	return domain.BRL(total), nil
+}
+
+// EntriesByInvoice lista os lançamentos de um invoice
+func (s *LedgerService) EntriesByInvoice(invoiceID string) ([]domain.LedgerEntry, error) {
+	return s.repo.ListByInvoiceID(invoiceID)
+}
+
+// EntriesByTransfer lista os lançamentos de uma transferência
+func (s *LedgerService) EntriesByTransfer(transferID string) ([]domain.LedgerEntry, error) {
+	return s.repo.ListByTransferID(transferID)
+}
+
+// Entries lista todos os lançamentos
+func (s *LedgerService) Entries() ([]domain.LedgerEntry, error) {
+	return s.repo.List()
+}
+
+// EntriesBetween lista os lançamentos criados em [from, to), ex: a janela de
+// um dia útil até o horário de corte
+func (s *LedgerService) EntriesBetween(from, to time.Time) ([]domain.LedgerEntry, error) {
+	entries, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	result := []domain.LedgerEntry{}
+	for _, e := range entries {
+		if !e.Created.Before(from) && e.Created.Before(to) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+// Verify comprova que todo crédito está coberto por repasses mais taxas
+//
+// Invoices com saldo pendente (ainda não repassados) ou em trânsito (repasse
+// enviado sem resultado registrado, ex: queda do processo durante a chamada à
+// StarkBank) são listados à parte e não tornam o razão desbalanceado;
+// divergências entre crédito e repasse sim. Invoices em trânsito precisam ser
+// conciliados com as transferências da StarkBank.
+func (s *LedgerService) Verify() (*LedgerVerification, error) {
+	entries, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LedgerVerification{
+		Balanced:           true,
+		Accounts:           make(map[string]int64),
+		UnbalancedEntries:  []string{},
+		PendingInvoices:    []InvoiceReconciliation{},
+		InTransitInvoices:  []InvoiceReconciliation{},
+		MismatchedInvoices: []InvoiceReconciliation{},
+	}
+
+	invoices := make(map[string]*InvoiceReconciliation)
+	for _, e := range entries {
+		if !e.Balanced() {
+			result.Balanced = false
+			result.UnbalancedEntries = append(result.UnbalancedEntries, e.ID)
+		}
+		if e.InvoiceID == "" {
+			// Transferências agendadas não pertencem a invoices
+			for _, p := range e.Postings {
+				result.Accounts[p.Account] += p.Debit - p.Credit
+			}
+			continue
+		}
+
+		rec, ok := invoices[e.InvoiceID]
+		if !ok {
+			rec = &InvoiceReconciliation{InvoiceID: e.InvoiceID}
+			invoices[e.InvoiceID] = rec
+		}
+
+		for _, p := range e.Postings {
+			result.Accounts[p.Account] += p.Debit - p.Credit
+
+			switch p.Account {
+			case domain.LedgerAccountReceivables:
+				rec.Credited += p.Credit - p.Debit
+			case domain.LedgerAccountFees:
+				rec.Fees += p.Debit - p.Credit
+			case domain.LedgerAccountForwarded:
+				rec.Forwarded += p.Debit - p.Credit
+			case domain.LedgerAccountPending:
+				rec.Pending += p.Debit - p.Credit
+			case domain.LedgerAccountInTransit:
+				rec.InTransit += p.Debit - p.Credit
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(invoices))
+	for id := range invoices {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		rec := invoices[id]
+		rec.Balanced = rec.Credited == rec.Forwarded+rec.Fees
+		switch {
+		case rec.Balanced:
+		case rec.InTransit > 0 && rec.Credited == rec.Forwarded+rec.Fees+rec.Pending+rec.InTransit:
+			result.InTransitInvoices = append(result.InTransitInvoices, *rec)
+		case rec.Pending > 0 && rec.Credited == rec.Forwarded+rec.Fees+rec.Pending:
+			result.PendingInvoices = append(result.PendingInvoices, *rec)
+		default:
+			result.Balanced = false
+			result.MismatchedInvoices = append(result.MismatchedInvoices, *rec)
+		}
+	}
+	result.InvoiceCount = len(ids)
+
+	return result, nil
+}
+
+// append grava o lançamento ignorando reenvios do mesmo evento
+func (s *LedgerService) append(ctx context.Context, entry domain.LedgerEntry) error {
+	err := s.repo.Append(entry)
+	if errors.Is(err, domain.ErrDuplicateLedgerEntry) {
+		slog.InfoContext(ctx, "lançamento já registrado no razão", "key", entry.Key)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao registrar lançamento no razão: %w", err)
+	}
+
+	slog.InfoContext(ctx, "lançamento registrado no razão", "key", entry.Key)
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+)
+
+func TestLedgerVerify(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := repository.NewFileLedgerRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao criar razão: %v", err)
+	}
+
+	svc := NewLedgerService(repo)
+	ctx := context.Background()
+
+	// Invoice creditado e repassado
+	svc.RecordInvoiceCredit(ctx, "inv-1", domain.BRL(10000), domain.BRL(50))
+	svc.RecordForwardIntent(ctx, "inv-1", domain.BRL(9950))
+	svc.RecordForward(ctx, "inv-1", "tr-1", domain.BRL(9950))
+
+	// Evento reenviado não duplica o lançamento
+	svc.RecordInvoiceCredit(ctx, "inv-1", domain.BRL(10000), domain.BRL(50))
+
+	// Invoice creditado ainda não repassado
+	svc.RecordInvoiceCredit(ctx, "inv-2", domain.BRL(5000), domain.BRL(0))
+
+	result, err := svc.Verify()
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if !result.Balanced {
+		t.Errorf("razão deveria estar balanceado: %+v", result)
+	}
+	if len(result.PendingInvoices) != 1 || result.PendingInvoices[0].InvoiceID != "inv-2" {
+		t.Errorf("inv-2 deveria estar pendente: %+v", result.PendingInvoices)
+	}
+	if result.Accounts["pending"] != 5000 {
+		t.Errorf("saldo pendente incorreto: %d (esperado 5000)", result.Accounts["pending"])
+	}
+
+	forwarded, _ := svc.HasForward("inv-1")
+	if !forwarded {
+		t.Error("inv-1 deveria ter repasse registrado")
+	}
+
+	// O razão é recarregado do disco com os mesmos lançamentos
+	repo.Close()
+	reopened, err := repository.NewFileLedgerRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao reabrir razão: %v", err)
+	}
+	defer reopened.Close()
+
+	entries, _ := reopened.List()
+	if len(entries) != 4 {
+		t.Errorf("esperado 4 lançamentos após reabrir, obtido %d", len(entries))
+	}
+}
+
+func TestLedgerForwardIntent(t *testing.T) {
+	repo, err := repository.NewFileLedgerRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("erro ao criar razão: %v", err)
+	}
+	defer repo.Close()
+
+	svc := NewLedgerService(repo)
+	ctx := context.Background()
+	svc.RecordInvoiceCredit(ctx, "inv-1", domain.BRL(1000), domain.BRL(0))
+
+	// A intenção bloqueia novos repasses antes do resultado da StarkBank
+	if err := svc.RecordForwardIntent(ctx, "inv-1", domain.BRL(1000)); err != nil {
+		t.Fatalf("erro ao registrar intenção: %v", err)
+	}
+	if forwarded, _ := svc.HasForward("inv-1"); !forwarded {
+		t.Error("invoice em trânsito deveria bloquear novo repasse")
+	}
+	result, _ := svc.Verify()
+	if !result.Balanced || len(result.InTransitInvoices) != 1 {
+		t.Errorf("inv-1 deveria estar em trânsito: %+v", result)
+	}
+
+	// Uma transferência que falhou devolve o valor a pendente e libera uma
+	// nova tentativa, registrada com outra chave
+	if err := svc.RecordForwardFailed(ctx, "inv-1", domain.BRL(1000)); err != nil {
+		t.Fatalf("erro ao registrar falha: %v", err)
+	}
+	if forwarded, _ := svc.HasForward("inv-1"); forwarded {
+		t.Error("repasse que falhou não deveria bloquear nova tentativa")
+	}
+	if err := svc.RecordForwardIntent(ctx, "inv-1", domain.BRL(1000)); err != nil {
+		t.Fatalf("erro ao registrar nova intenção: %v", err)
+	}
+	svc.RecordForward(ctx, "inv-1", "tr-1", domain.BRL(1000))
+
+	result, _ = svc.Verify()
+	if !result.Balanced || len(result.InTransitInvoices) != 0 || len(result.PendingInvoices) != 0 {
+		t.Errorf("inv-1 deveria estar repassado: %+v", result)
+	}
+	if result.Accounts[domain.LedgerAccountInTransit] != 0 || result.Accounts[domain.LedgerAccountForwarded] != 1000 {
+		t.Errorf("contas incorretas: %+v", result.Accounts)
+	}
+}
+
+func TestLedgerDiscardsPartialLastLine(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := repository.NewFileLedgerRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao criar razão: %v", err)
+	}
+	svc := NewLedgerService(repo)
+	svc.RecordInvoiceCredit(context.Background(), "inv-1", domain.BRL(1000), domain.BRL(0))
+	repo.Close()
+
+	// Queda do processo no meio de uma gravação
+	path := filepath.Join(dir, "ledger.jsonl")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("erro ao abrir razão: %v", err)
+	}
+	f.WriteString(`{"ID":"led-inv-2-credit","Key":"invoice_cre`)
+	f.Close()
+
+	reopened, err := repository.NewFileLedgerRepository(dir)
+	if err != nil {
+		t.Fatalf("linha incompleta no fim não deveria impedir a abertura: %v", err)
+	}
+	defer reopened.Close()
+
+	// O lançamento interrompido pode ser gravado de novo
+	if err := NewLedgerService(reopened).RecordInvoiceCredit(context.Background(), "inv-2", domain.BRL(500), domain.BRL(0)); err != nil {
+		t.Fatalf("erro ao gravar após descartar linha incompleta: %v", err)
+	}
+	reopened.Close()
+
+	again, err := repository.NewFileLedgerRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao reabrir razão: %v", err)
+	}
+	defer again.Close()
+	if entries, _ := again.List(); len(entries) != 2 {
+		t.Errorf("esperado 2 lançamentos, obtido %d", len(entries))
+	}
+}
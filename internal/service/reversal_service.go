@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// ReversalService trata estornos de invoices já repassados
+type ReversalService struct {
+	reversals domain.ReversalRepository
+	forwards  domain.ForwardRepository
+	holds     domain.PayerHoldRepository
+	refunds   domain.RefundRequestRepository
+	ledger    *LedgerService
+	releaser  PayerReleaser
+	auditor   domain.Auditor
+
+	mu     sync.RWMutex
+	action string
+}
+
+// ReversalReport resume a exposição causada por estornos
+type ReversalReport struct {
+	OpenCount     int                    `json:"open_count"`
+	OpenExposure  domain.Money           `json:"open_exposure"`
+	ResolvedCount int                    `json:"resolved_count"`
+	OpenRefunds   int                    `json:"open_refunds"`
+	RefundsDue    domain.Money           `json:"refunds_due"`
+	Reversals     []domain.Reversal      `json:"reversals"`
+	Refunds       []domain.RefundRequest `json:"refunds"`
+}
+
+// PayerReleaser libera os créditos bloqueados de um pagador quando o estorno
+// é resolvido
+type PayerReleaser interface {
+	ReleasePayer(ctx context.Context, taxID string) (int, error)
+}
+
+// NewReversalService cria uma nova instância do serviço; releaser libera os
+// créditos de pagadores desbloqueados (nil não libera)
+func NewReversalService(
+	reversals domain.ReversalRepository,
+	forwards domain.ForwardRepository,
+	holds domain.PayerHoldRepository,
+	refunds domain.RefundRequestRepository,
+	ledger *LedgerService,
+	releaser PayerReleaser,
+	action string,
+	auditor domain.Auditor,
+) *ReversalService {
+	return &ReversalService{
+		reversals: reversals,
+		forwards:  forwards,
+		holds:     holds,
+		refunds:   refunds,
+		ledger:    ledger,
+		releaser:  releaser,
+		action:    action,
+		auditor:   auditor,
+	}
+}
+
+// HandleReversal associa o estorno ao repasse original e executa a ação de compensação
+func (s *ReversalService) HandleReversal(ctx context.Context, event domain.WebhookEvent) (*domain.Reversal, error) {
+	existing, err := s.reversals.GetByInvoiceID(event.InvoiceID)
+	if err == nil {
+		slog.InfoContext(ctx, "estorno já registrado", "invoice_id", event.InvoiceID, "reversal_id", existing.ID)
+		return existing, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	reversal := domain.Reversal{
+		ID:         fmt.Sprintf("rev-%s-%d", event.InvoiceID, time.Now().Unix()),
+		InvoiceID:  event.InvoiceID,
+		PayerName:  event.PayerName,
+		PayerTaxID: event.PayerTaxID,
+		Amount:     event.Amount,
+		Status:     domain.ReversalStatusOpen,
+		Created:    time.Now(),
+	}
+
+	// O lançamento vem antes do registro do estorno: se falhar, o evento é
+	// reenviado e o estorno ainda não existe
+	if err := s.ledger.RecordReversal(ctx, event.InvoiceID, event.Amount); err != nil {
+		return nil, err
+	}
+
+	forward, err := s.forwards.GetByInvoiceID(event.InvoiceID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		slog.InfoContext(ctx, "invoice estornado antes de ser repassado", "invoice_id", event.InvoiceID)
+		reversal.Status = domain.ReversalStatusNoExposure
+		reversal.Note = "invoice estornado sem repasse associado"
+		return &reversal, s.save(ctx, reversal)
+	case err != nil:
+		return nil, err
+	}
+
+	reversal.TransferID = forward.TransferID
+	reversal.Exposure = forward.Forwarded
+	if reversal.PayerTaxID == "" {
+		reversal.PayerTaxID = forward.PayerTaxID
+		reversal.PayerName = forward.PayerName
+	}
+
+	slog.WarnContext(ctx, "estorno de invoice já repassado",
+		"invoice_id", reversal.InvoiceID,
+		"transfer_id", reversal.TransferID,
+		"exposure", reversal.Exposure)
+
+	if err := s.compensate(ctx, &reversal); err != nil {
+		return nil, err
+	}
+
+	return &reversal, s.save(ctx, reversal)
+}
+
+// save grava um estorno novo e o registra na trilha de auditoria
+func (s *ReversalService) save(ctx context.Context, reversal domain.Reversal) error {
+	if err := s.reversals.Save(reversal); err != nil {
+		return err
+	}
+	s.auditor.Record(ctx, domain.AuditReversalRecorded, reversal.ID, nil, reversalAuditValue(reversal))
+	return nil
+}
+
+// reversalAuditValue resume o estorno para a trilha (sem dados do pagador)
+func reversalAuditValue(r domain.Reversal) map[string]interface{} {
+	return map[string]interface{}{
+		"invoice_id":  r.InvoiceID,
+		"transfer_id": r.TransferID,
+		"status":      r.Status,
+		"action":      r.Action,
+		"exposure":    r.Exposure,
+		"note":        r.Note,
+	}
+}
+
+// refundAuditValue resume a solicitação de devolução para a trilha
+func refundAuditValue(r domain.RefundRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"reversal_id": r.ReversalID,
+		"transfer_id": r.TransferID,
+		"amount":      r.Amount,
+		"status":      r.Status,
+		"note":        r.Note,
+	}
+}
+
+// SetAction altera a compensação aplicada aos próximos estornos
+func (s *ReversalService) SetAction(action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.action = action
+}
+
+// compensate executa a ação de compensação configurada
+func (s *ReversalService) compensate(ctx context.Context, reversal *domain.Reversal) error {
+	s.mu.RLock()
+	reversal.Action = s.action
+	s.mu.RUnlock()
+
+	switch reversal.Action {
+	case domain.ReversalActionHoldPayer:
+		taxID := normalizeTaxID(reversal.PayerTaxID)
+		if taxID == "" {
+			reversal.Action = domain.ReversalActionManualCase
+			reversal.Note = "pagador sem CPF/CNPJ, aberto caso manual"
+			return nil
+		}
+		if err := s.holds.Save(domain.PayerHold{
+			TaxID:      taxID,
+			ReversalID: reversal.ID,
+			Created:    time.Now(),
+		}); err != nil {
+			return fmt.Errorf("erro ao bloquear pagador: %w", err)
+		}
+		reversal.Note = "repasses futuros do pagador bloqueados"
+	case domain.ReversalActionRefundRequest:
+		refund := domain.RefundRequest{
+			ID:         "ref-" + reversal.ID,
+			ReversalID: reversal.ID,
+			InvoiceID:  reversal.InvoiceID,
+			TransferID: reversal.TransferID,
+			Amount:     reversal.Exposure,
+			Status:     domain.RefundStatusRequested,
+			Requested:  time.Now(),
+		}
+		if err := s.refunds.Save(refund); err != nil {
+			return fmt.Errorf("erro ao registrar solicitação de devolução: %w", err)
+		}
+		s.auditor.Record(ctx, domain.AuditRefundRequested, refund.ID, nil, refundAuditValue(refund))
+		reversal.Note = fmt.Sprintf("solicitada devolução de %s referente à transferência %s (%s)",
+			reversal.Exposure, reversal.TransferID, refund.ID)
+	case domain.ReversalActionManualCase:
+		reversal.Note = "caso aberto para análise manual"
+	default:
+		return fmt.Errorf("ação de estorno desconhecida: %s", reversal.Action)
+	}
+
+	slog.InfoContext(ctx, "compensação de estorno aplicada", "action", reversal.Action, "note", reversal.Note)
+	return nil
+}
+
+// IsPayerHeld indica se os repasses de um pagador estão bloqueados
+func (s *ReversalService) IsPayerHeld(taxID string) (bool, error) {
+	taxID = normalizeTaxID(taxID)
+	if taxID == "" {
+		return false, nil
+	}
+
+	_, err := s.holds.Get(taxID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Resolve encerra um estorno, liquida a solicitação de devolução, se houver,
+// e libera o pagador, se estiver bloqueado, com os créditos que aguardavam a
+// resolução
+func (s *ReversalService) Resolve(ctx context.Context, id, note string) (*domain.Reversal, error) {
+	reversal, err := s.reversals.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if reversal.Status != domain.ReversalStatusOpen {
+		return nil, fmt.Errorf("estorno %s não está aberto (status: %s)", id, reversal.Status)
+	}
+
+	if reversal.Action == domain.ReversalActionHoldPayer {
+		if err := s.holds.Delete(normalizeTaxID(reversal.PayerTaxID)); err != nil {
+			return nil, fmt.Errorf("erro ao liberar pagador: %w", err)
+		}
+	}
+
+	now := time.Now()
+	if reversal.Action == domain.ReversalActionRefundRequest {
+		if err := s.settleRefund(ctx, reversal.ID, note, now); err != nil {
+			return nil, err
+		}
+	}
+
+	before := reversalAuditValue(*reversal)
+	reversal.Status = domain.ReversalStatusResolved
+	reversal.Resolved = &now
+	if note != "" {
+		reversal.Note = note
+	}
+
+	if err := s.reversals.Save(*reversal); err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, domain.AuditReversalResolved, id, before, reversalAuditValue(*reversal))
+	slog.InfoContext(ctx, "estorno resolvido", "reversal_id", id)
+
+	if reversal.Action == domain.ReversalActionHoldPayer && s.releaser != nil {
+		released, err := s.releaser.ReleasePayer(ctx, reversal.PayerTaxID)
+		if err != nil {
+			return reversal, fmt.Errorf("estorno resolvido, mas a liberação dos créditos do pagador falhou: %w", err)
+		}
+		slog.InfoContext(ctx, "créditos do pagador liberados", "reversal_id", id, "credits", released)
+	}
+	return reversal, nil
+}
+
+// settleRefund encerra a solicitação de devolução de um estorno
+func (s *ReversalService) settleRefund(ctx context.Context, reversalID, note string, now time.Time) error {
+	refund, err := s.refunds.GetByReversalID(reversalID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if refund.Status != domain.RefundStatusRequested {
+		return nil
+	}
+
+	before := refundAuditValue(*refund)
+	refund.Status = domain.RefundStatusSettled
+	refund.Settled = &now
+	refund.Note = note
+	if err := s.refunds.Save(*refund); err != nil {
+		return fmt.Errorf("erro ao liquidar solicitação de devolução: %w", err)
+	}
+	s.auditor.Record(ctx, domain.AuditRefundSettled, refund.ID, before, refundAuditValue(*refund))
+	return nil
+}
+
+// Report gera o relatório de exposição a estornos
+func (s *ReversalService) Report() (*ReversalReport, error) {
+	reversals, err := s.reversals.List()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReversalReport{Reversals: reversals}
+	for _, r := range reversals {
+		switch r.Status {
+		case domain.ReversalStatusOpen:
+			report.OpenCount++
+			exposure, err := report.OpenExposure.Add(r.Exposure)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao somar exposição: %w", err)
+			}
+			report.OpenExposure = exposure
+		case domain.ReversalStatusResolved:
+			report.ResolvedCount++
+		}
+	}
+
+	refunds, err := s.refunds.List()
+	if err != nil {
+		return nil, err
+	}
+	report.Refunds = refunds
+	for _, r := range refunds {
+		if r.Status != domain.RefundStatusRequested {
+			continue
+		}
+		report.OpenRefunds++
+		due, err := report.RefundsDue.Add(r.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao somar devoluções: %w", err)
+		}
+		report.RefundsDue = due
+	}
+
+	return report, nil
+}
+
+// normalizeTaxID remove a formatação de um CPF/CNPJ
+func normalizeTaxID(taxID string) string {
+	var b strings.Builder
+	for _, c := range taxID {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+)
+
+func newTestReversalService(t *testing.T, action string, releaser PayerReleaser) (*ReversalService, domain.ForwardRepository) {
+	svc, forwards, _ := newTestReversalServiceWithLedger(t, action, releaser)
+	return svc, forwards
+}
+
+func newTestReversalServiceWithLedger(t *testing.T, action string, releaser PayerReleaser) (*ReversalService, domain.ForwardRepository, *LedgerService) {
+	t.Helper()
+	dir := t.TempDir()
+
+	forwards, err := repository.NewFileForwardRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao criar repositório de repasses: %v", err)
+	}
+	reversals, err := repository.NewFileReversalRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao criar repositório de estornos: %v", err)
+	}
+	holds, err := repository.NewFilePayerHoldRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao criar repositório de bloqueios: %v", err)
+	}
+
+	refunds, err := repository.NewFileRefundRequestRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao criar repositório de devoluções: %v", err)
+	}
+
+	ledgerRepo, err := repository.NewFileLedgerRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao criar razão: %v", err)
+	}
+	t.Cleanup(func() { ledgerRepo.Close() })
+	ledger := NewLedgerService(ledgerRepo)
+
+	return NewReversalService(reversals, forwards, holds, refunds, ledger, releaser, action, NopAuditor), forwards, ledger
+}
+
+func TestHandleReversalHoldPayer(t *testing.T) {
+	svc, forwards := newTestReversalService(t, domain.ReversalActionHoldPayer, nil)
+
+	forwards.Save(domain.Forward{
+		InvoiceID:  "inv-1",
+		TransferID: "tr-1",
+		PayerTaxID: "012.345.678-90",
+		Amount:     domain.BRL(10000),
+		Fee:        domain.BRL(50),
+		Forwarded:  domain.BRL(9950),
+		Created:    time.Now(),
+	})
+
+	reversal, err := svc.HandleReversal(context.Background(), domain.WebhookEvent{
+		Subscription: "invoice",
+		EventType:    "reversed",
+		InvoiceID:    "inv-1",
+		Amount:       domain.BRL(10000),
+	})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if reversal.TransferID != "tr-1" || reversal.Exposure != domain.BRL(9950) {
+		t.Errorf("estorno não associado ao repasse: %+v", reversal)
+	}
+
+	held, _ := svc.IsPayerHeld("01234567890")
+	if !held {
+		t.Error("pagador deveria estar bloqueado")
+	}
+
+	report, _ := svc.Report()
+	if report.OpenCount != 1 || report.OpenExposure != domain.BRL(9950) {
+		t.Errorf("relatório incorreto: %+v", report)
+	}
+
+	// Evento reenviado não deve gerar um segundo estorno
+	if _, err := svc.HandleReversal(context.Background(), domain.WebhookEvent{InvoiceID: "inv-1", Amount: domain.BRL(10000)}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	report, _ = svc.Report()
+	if len(report.Reversals) != 1 {
+		t.Errorf("estorno duplicado: %d registros", len(report.Reversals))
+	}
+
+	if _, err := svc.Resolve(context.Background(), reversal.ID, ""); err != nil {
+		t.Fatalf("erro ao resolver: %v", err)
+	}
+	held, _ = svc.IsPayerHeld("01234567890")
+	if held {
+		t.Error("pagador deveria ter sido liberado")
+	}
+}
+
+func TestHandleReversalWithoutForward(t *testing.T) {
+	svc, _, ledger := newTestReversalServiceWithLedger(t, domain.ReversalActionManualCase, nil)
+
+	reversal, err := svc.HandleReversal(context.Background(), domain.WebhookEvent{InvoiceID: "inv-2", Amount: domain.BRL(5000)})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if reversal.Status != domain.ReversalStatusNoExposure || !reversal.Exposure.IsZero() {
+		t.Errorf("estorno sem repasse não deveria gerar exposição: %+v", reversal)
+	}
+
+	// O valor estornado sai do saldo mesmo sem repasse, uma única vez
+	if _, err := svc.HandleReversal(context.Background(), domain.WebhookEvent{InvoiceID: "inv-2", Amount: domain.BRL(5000)}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	entries, _ := ledger.EntriesByInvoice("inv-2")
+	if len(entries) != 1 || entries[0].Kind != domain.LedgerEntryReversal || entries[0].Postings[0].Debit != 5000 {
+		t.Errorf("esperado um lançamento de estorno de 5000: %+v", entries)
+	}
+}
+
+func TestHandleReversalRefundRequest(t *testing.T) {
+	svc, forwards := newTestReversalService(t, domain.ReversalActionRefundRequest, nil)
+
+	forwards.Save(domain.Forward{
+		InvoiceID:  "inv-3",
+		TransferID: "tr-3",
+		Amount:     domain.BRL(10000),
+		Fee:        domain.BRL(50),
+		Forwarded:  domain.BRL(9950),
+		Created:    time.Now(),
+	})
+
+	reversal, err := svc.HandleReversal(context.Background(), domain.WebhookEvent{InvoiceID: "inv-3", Amount: domain.BRL(10000)})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	report, _ := svc.Report()
+	if report.OpenRefunds != 1 || report.RefundsDue != domain.BRL(9950) || len(report.Refunds) != 1 {
+		t.Fatalf("devolução não registrada no relatório: %+v", report)
+	}
+	refund := report.Refunds[0]
+	if refund.ReversalID != reversal.ID || refund.TransferID != "tr-3" || refund.Status != domain.RefundStatusRequested {
+		t.Errorf("solicitação de devolução incorreta: %+v", refund)
+	}
+
+	if _, err := svc.Resolve(context.Background(), reversal.ID, "valor devolvido"); err != nil {
+		t.Fatalf("erro ao resolver: %v", err)
+	}
+
+	report, _ = svc.Report()
+	if report.OpenRefunds != 0 || !report.RefundsDue.IsZero() {
+		t.Errorf("devolução deveria estar liquidada: %+v", report)
+	}
+	refund = report.Refunds[0]
+	if refund.Status != domain.RefundStatusSettled || refund.Settled == nil || refund.Note != "valor devolvido" {
+		t.Errorf("devolução não liquidada: %+v", refund)
+	}
+}
@@ -1,59 +1,138 @@
 package service
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sync"
 	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// Estados do gerador de invoices
+const (
+	SchedulerStateIdle      = "idle"      // ainda não iniciado
+	SchedulerStateRunning   = "running"   // gerando lotes periodicamente
+	SchedulerStateCompleted = "completed" // duração configurada concluída
+	SchedulerStateStopped   = "stopped"   // interrompido manualmente
+	SchedulerStateDisabled  = "disabled"  // desativado na configuração
 )
 
 // SchedulerService gerencia tarefas agendadas
 type SchedulerService struct {
 	invoiceService *InvoiceService
+	policy         config.SchedulerConfig
 	stopChan       chan bool
+
+	mu     sync.Mutex
+	status SchedulerStatus
 }
 
-// NewSchedulerService cria uma nova instância do serviço
-func NewSchedulerService(invoiceService *InvoiceService) *SchedulerService {
+// SchedulerStatus descreve o estado atual do gerador de invoices
+type SchedulerStatus struct {
+	State     string     `json:"state"`
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	Runs      int        `json:"runs"`
+}
+
+// NewSchedulerService cria uma nova instância do serviço com a política de
+// geração (intervalo, duração e tamanho dos lotes)
+func NewSchedulerService(invoiceService *InvoiceService, policy config.SchedulerConfig) *SchedulerService {
 	return &SchedulerService{
 		invoiceService: invoiceService,
+		policy:         policy,
 		stopChan:       make(chan bool),
+		status:         SchedulerStatus{State: SchedulerStateIdle},
 	}
 }
 
-// StartInvoiceGeneration inicia a geração periódica de invoices
-func (s *SchedulerService) StartInvoiceGeneration() {
-	log.Println("🚀 Iniciando gerador de invoices...")
-	log.Println("📋 Configuração: 8-12 invoices a cada 3 horas durante 24 horas")
+// StartInvoiceGeneration inicia a geração periódica de invoices; ctx é a
+// base de cada lote (ex: identifica o tenant nos logs)
+func (s *SchedulerService) StartInvoiceGeneration(ctx context.Context) {
+	if !s.policy.Enabled {
+		slog.InfoContext(ctx, "gerador de invoices desativado")
+		s.setState(SchedulerStateDisabled)
+		return
+	}
+
+	slog.InfoContext(ctx, "iniciando gerador de invoices",
+		"batch", fmt.Sprintf("%d-%d", s.policy.MinBatch, s.policy.MaxBatch),
+		"interval", s.policy.Interval.String(),
+		"duration", s.policy.Duration.String())
+	s.setState(SchedulerStateRunning)
+	ctx = domain.ContextWithActor(ctx, domain.ActorScheduler)
 
 	// Gerar invoices imediatamente
-	if _, err := s.invoiceService.GenerateRandomInvoices(); err != nil {
-		log.Printf("❌ Erro ao gerar invoices iniciais: %v\n", err)
+	if _, err := s.generate(ctx); err != nil {
+		slog.ErrorContext(ctx, "erro ao gerar invoices iniciais", "error", err)
 	}
 
-	// Ticker para executar a cada 3 horas
-	ticker := time.NewTicker(3 * time.Hour)
+	ticker := time.NewTicker(s.policy.Interval)
 	defer ticker.Stop()
 
-	// Timer para parar após 24 horas
-	stopTimer := time.NewTimer(24 * time.Hour)
+	// Timer para parar ao fim da duração configurada
+	stopTimer := time.NewTimer(s.policy.Duration)
 	defer stopTimer.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			if _, err := s.invoiceService.GenerateRandomInvoices(); err != nil {
-				log.Printf("❌ Erro ao gerar invoices: %v\n", err)
+			if _, err := s.generate(ctx); err != nil {
+				slog.ErrorContext(ctx, "erro ao gerar invoices", "error", err)
 			}
 		case <-stopTimer.C:
-			log.Println("⏰ 24 horas completadas! Parando gerador de invoices...")
+			slog.InfoContext(ctx, "duração do gerador concluída, parando gerador de invoices", "duration", s.policy.Duration.String())
+			s.setState(SchedulerStateCompleted)
 			return
 		case <-s.stopChan:
-			log.Println("🛑 Gerador de invoices interrompido manualmente")
+			slog.InfoContext(ctx, "gerador de invoices interrompido manualmente")
+			s.setState(SchedulerStateStopped)
 			return
 		}
 	}
 }
 
+// Status retorna o estado atual do gerador
+func (s *SchedulerService) Status() SchedulerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
 // Stop para o scheduler
 func (s *SchedulerService) Stop() {
 	close(s.stopChan)
 }
+
+// RunOnce gera um lote fora do agendamento (ex: disparado pelo operador),
+// mesmo com o gerador desativado ou concluído
+func (s *SchedulerService) RunOnce(ctx context.Context) (int, error) {
+	return s.generate(ctx)
+}
+
+// generate cria um lote de invoices e registra o resultado no estado
+func (s *SchedulerService) generate(ctx context.Context) (int, error) {
+	count := s.policy.MinBatch + rand.Intn(s.policy.MaxBatch-s.policy.MinBatch+1)
+	created, err := s.invoiceService.GenerateRandomInvoices(ctx, count)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.status.LastRun = &now
+	s.status.Runs++
+	s.status.LastError = ""
+	if err != nil {
+		s.status.LastError = err.Error()
+	}
+	return len(created), err
+}
+
+func (s *SchedulerService) setState(state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.State = state
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/calendar"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// TransferScheduleService gerencia transferências agendadas e recorrentes
+//
+// Transferências únicas são criadas na hora, com a data agendada na
+// StarkBank, que as executa; cancelar remove a transferência na StarkBank.
+// Regras recorrentes são executadas pelo próprio serviço, apenas em dias
+// úteis, e a varredura por keep_balance nunca leva valores ainda devidos a
+// repasses de invoices. Agendamentos são criados por administradores e não
+// passam pela política de repasse; entram no razão como saídas do saldo
+// próprio. Datas e horários seguem o fuso do calendário.
+type TransferScheduleService struct {
+	repo      domain.ScheduledTransferRepository
+	transfers *TransferService
+	ledger    *LedgerService
+	reserved  []ReservedBalance
+	calendar  *calendar.Calendar
+	interval  time.Duration
+	auditor   domain.Auditor
+	stopChan  chan bool
+
+	// Execuções e cancelamentos acontecem em série
+	mu sync.Mutex
+}
+
+// ReservedBalance é implementado pelas filas de repasse: o valor delas já
+// pertence a repasses pendentes e fica fora da varredura por keep_balance
+type ReservedBalance interface {
+	Reserved() (domain.Money, error)
+}
+
+// NewTransferScheduleService cria uma nova instância do serviço; reserved são
+// as filas cujo valor a varredura por keep_balance preserva
+func NewTransferScheduleService(
+	repo domain.ScheduledTransferRepository,
+	transfers *TransferService,
+	ledger *LedgerService,
+	reserved []ReservedBalance,
+	cal *calendar.Calendar,
+	interval time.Duration,
+	auditor domain.Auditor,
+) *TransferScheduleService {
+	return &TransferScheduleService{
+		repo:      repo,
+		transfers: transfers,
+		ledger:    ledger,
+		reserved:  reserved,
+		calendar:  cal,
+		interval:  interval,
+		auditor:   auditor,
+		stopChan:  make(chan bool),
+	}
+}
+
+// Schedule registra um agendamento
+//
+// Para uma transferência única, schedule.Next é a data desejada: se não for
+// dia útil, passa ao próximo dia útil, e a transferência é criada na hora
+// com essa data. Para uma regra recorrente, Next é calculado a partir de
+// Days e At. Dados inválidos retornam domain.ErrInvalidInput.
+func (s *TransferScheduleService) Schedule(ctx context.Context, schedule domain.ScheduledTransfer) (*domain.ScheduledTransfer, error) {
+	if err := s.validate(schedule); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.calendar.Now()
+	schedule.ID = fmt.Sprintf("sch-%d", now.UnixNano())
+	schedule.Status = domain.ScheduleStatusActive
+	schedule.Created = now
+	schedule.CreatedBy = domain.ActorFromContext(ctx)
+
+	var ledgerErr error
+	switch schedule.Kind {
+	case domain.ScheduleOnce:
+		date := s.calendar.NextBusinessDay(schedule.Next.In(s.calendar.Location()))
+		schedule.Next = &date
+		transfer, err := s.transfers.CreateScheduled(ctx, schedule, schedule.Amount, &date, "sched-"+schedule.ID)
+		if err != nil {
+			return nil, err
+		}
+		schedule.TransferID = transfer.ID
+		ledgerErr = s.ledger.RecordScheduledTransfer(ctx, transfer.ID, transfer.Amount, date)
+	case domain.ScheduleRecurring:
+		next, err := s.next(schedule, now)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+		}
+		schedule.Next = &next
+	}
+
+	if err := s.repo.Save(schedule); err != nil {
+		return nil, fmt.Errorf("erro ao registrar agendamento: %w", err)
+	}
+	s.auditor.Record(ctx, domain.AuditTransferScheduled, schedule.ID, nil, map[string]interface{}{
+		"kind":         schedule.Kind,
+		"amount":       schedule.Amount,
+		"keep_balance": schedule.KeepBalance,
+		"days":         schedule.Days,
+		"at":           schedule.At,
+		"next":         schedule.Next,
+		"transfer_id":  schedule.TransferID,
+		"destination":  schedule.Destination != nil,
+	})
+	slog.InfoContext(ctx, "transferência agendada",
+		"schedule_id", schedule.ID,
+		"kind", schedule.Kind,
+		"next", schedule.Next)
+	if ledgerErr != nil {
+		return &schedule, fmt.Errorf("transferência %s agendada, mas o registro no razão falhou: %w", schedule.TransferID, ledgerErr)
+	}
+	return &schedule, nil
+}
+
+// Cancel cancela um agendamento ativo; a transferência de um agendamento
+// único é cancelada na StarkBank, o que falha se já foi processada
+func (s *TransferScheduleService) Cancel(ctx context.Context, id string) (*domain.ScheduledTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != domain.ScheduleStatusActive {
+		return nil, fmt.Errorf("%w (%s)", domain.ErrScheduleClosed, schedule.Status)
+	}
+	if schedule.Kind == domain.ScheduleOnce && schedule.TransferID != "" {
+		if _, err := s.transfers.Cancel(ctx, schedule.TransferID); err != nil {
+			return nil, fmt.Errorf("erro ao cancelar a transferência %s na StarkBank: %w", schedule.TransferID, err)
+		}
+	}
+
+	now := s.calendar.Now()
+	schedule.Status = domain.ScheduleStatusCanceled
+	schedule.Canceled = &now
+	schedule.Next = nil
+	if err := s.repo.Save(*schedule); err != nil {
+		return nil, fmt.Errorf("erro ao cancelar agendamento: %w", err)
+	}
+	s.auditor.Record(ctx, domain.AuditScheduleCanceled, schedule.ID, nil, map[string]interface{}{
+		"kind":        schedule.Kind,
+		"transfer_id": schedule.TransferID,
+	})
+	slog.InfoContext(ctx, "agendamento cancelado", "schedule_id", schedule.ID, "kind", schedule.Kind)
+	if schedule.Kind == domain.ScheduleOnce && schedule.TransferID != "" {
+		if err := s.ledger.RecordScheduledReverted(ctx, schedule.TransferID); err != nil {
+			return schedule, fmt.Errorf("agendamento cancelado, mas o estorno no razão falhou: %w", err)
+		}
+	}
+	return schedule, nil
+}
+
+// List lista os agendamentos com o status informado (vazio lista todos)
+func (s *TransferScheduleService) List(status string) ([]domain.ScheduledTransfer, error) {
+	return s.repo.List(status)
+}
+
+// Run executa as regras recorrentes vencidas e encerra os agendamentos
+// únicos cuja data já passou; retorna quantas transferências foram criadas
+func (s *TransferScheduleService) Run(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx = domain.ContextWithActor(ctx, domain.ActorSchedules)
+	schedules, err := s.repo.List(domain.ScheduleStatusActive)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar agendamentos: %w", err)
+	}
+
+	now := s.calendar.Now()
+	created := 0
+	for i := range schedules {
+		schedule := &schedules[i]
+		if schedule.Next == nil || schedule.Next.After(now) {
+			continue
+		}
+		switch schedule.Kind {
+		case domain.ScheduleOnce:
+			s.settle(ctx, schedule)
+		case domain.ScheduleRecurring:
+			if s.execute(ctx, schedule, now) {
+				created++
+			}
+		}
+		if err := s.repo.Save(*schedule); err != nil {
+			return created, fmt.Errorf("erro ao atualizar agendamento: %w", err)
+		}
+	}
+	return created, nil
+}
+
+// Start inicia a verificação periódica dos agendamentos; ctx é a base de
+// cada verificação (ex: identifica o tenant nos logs)
+func (s *TransferScheduleService) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "iniciando transferências agendadas", "interval", s.interval.String())
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Run(ctx); err != nil {
+				slog.ErrorContext(ctx, "erro ao executar transferências agendadas", "error", err)
+			}
+		case <-s.stopChan:
+			slog.InfoContext(ctx, "transferências agendadas interrompidas")
+			return
+		}
+	}
+}
+
+// Stop para a verificação periódica
+func (s *TransferScheduleService) Stop() {
+	close(s.stopChan)
+}
+
+// execute cria a transferência de uma regra recorrente vencida e calcula a
+// próxima execução; retorna true se a transferência foi criada
+//
+// O external ID inclui a data da execução: repetir a execução do dia não
+// duplica a transferência. Uma falha é repetida na próxima verificação
+// enquanto for o mesmo dia; depois, a execução é perdida.
+//
+// Com keep_balance, a varredura preserva além do mínimo os créditos que
+// aguardam repasse (fila da política, aprovações e retidos por saldo).
+func (s *TransferScheduleService) execute(ctx context.Context, schedule *domain.ScheduledTransfer, now time.Time) bool {
+	due := schedule.Next.In(now.Location())
+	amount := schedule.Amount
+	var reserved domain.Money
+	var err error
+	if schedule.KeepBalance != nil {
+		reserved, err = s.reservedTotal()
+		if err == nil {
+			amount, err = s.transfers.Excess(ctx, *schedule.KeepBalance, reserved)
+		}
+	}
+
+	var transfer *domain.Transfer
+	switch {
+	case err != nil:
+	case !amount.IsPositive():
+		schedule.LastResult = fmt.Sprintf("sem excedente acima de %s (reservado para repasses: %s)", *schedule.KeepBalance, reserved)
+	default:
+		externalID := fmt.Sprintf("sched-%s-%s", schedule.ID, due.Format("20060102"))
+		transfer, err = s.transfers.CreateScheduled(ctx, *schedule, amount, nil, externalID)
+	}
+
+	schedule.LastRun = &now
+	if err != nil {
+		schedule.LastResult = "erro: " + err.Error()
+		slog.ErrorContext(ctx, "erro na transferência recorrente", "schedule_id", schedule.ID, "error", err)
+		if sameDay(due, now) {
+			return false // repetida na próxima verificação
+		}
+	} else if transfer != nil {
+		schedule.TransferID = transfer.ID
+		schedule.LastResult = fmt.Sprintf("transferência %s de %s", transfer.ID, transfer.Amount)
+		if err := s.ledger.RecordScheduledTransfer(ctx, transfer.ID, transfer.Amount, now); err != nil {
+			schedule.LastResult += "; erro no razão: " + err.Error()
+			slog.ErrorContext(ctx, "erro ao registrar transferência recorrente no razão", "schedule_id", schedule.ID, "error", err)
+		}
+	}
+
+	// A próxima execução parte de agora: execuções perdidas (ex: processo
+	// parado) não são repetidas em sequência
+	next, nextErr := s.next(*schedule, now)
+	if nextErr != nil {
+		slog.ErrorContext(ctx, "erro ao calcular próxima execução", "schedule_id", schedule.ID, "error", nextErr)
+		schedule.Next = nil
+	} else {
+		schedule.Next = &next
+	}
+	slog.InfoContext(ctx, "transferência recorrente executada",
+		"schedule_id", schedule.ID,
+		"result", schedule.LastResult,
+		"next", schedule.Next)
+	return transfer != nil
+}
+
+// settle consulta a transferência de um agendamento único cuja data passou
+// e o encerra quando a StarkBank a processou
+func (s *TransferScheduleService) settle(ctx context.Context, schedule *domain.ScheduledTransfer) {
+	transfer, err := s.transfers.GetByID(ctx, schedule.TransferID)
+	if err != nil {
+		slog.WarnContext(ctx, "erro ao consultar transferência agendada", "schedule_id", schedule.ID, "error", err)
+		return
+	}
+	switch transfer.Status {
+	case "failed", "canceled":
+		// O valor não saiu da conta
+		if err := s.ledger.RecordScheduledReverted(ctx, transfer.ID); err != nil {
+			slog.ErrorContext(ctx, "erro ao estornar transferência agendada no razão", "schedule_id", schedule.ID, "error", err)
+			return // tentado de novo na próxima verificação
+		}
+		fallthrough
+	case "success":
+		now := s.calendar.Now()
+		schedule.Status = domain.ScheduleStatusDone
+		schedule.LastRun = &now
+		schedule.LastResult = "transferência " + transfer.Status
+		schedule.Next = nil
+	}
+}
+
+// validate confere os campos do agendamento conforme o tipo
+func (s *TransferScheduleService) validate(schedule domain.ScheduledTransfer) error {
+	if d := schedule.Destination; d != nil {
+		if d.BankCode == "" || d.BranchCode == "" || d.AccountNumber == "" || d.Name == "" || d.TaxID == "" {
+			return errors.New("destination exige bank_code, branch_code, account_number, name e tax_id")
+		}
+	}
+
+	switch schedule.Kind {
+	case domain.ScheduleOnce:
+		if schedule.Next == nil {
+			return errors.New("transferência única exige a data")
+		}
+		if schedule.Next.Before(startOfDay(s.calendar.Now())) {
+			return fmt.Errorf("data %s já passou", schedule.Next.Format("2006-01-02"))
+		}
+		if schedule.KeepBalance != nil || len(schedule.Days) > 0 || schedule.At != "" {
+			return errors.New("transferência única aceita apenas valor fixo, sem days, at ou keep_balance")
+		}
+		if !schedule.Amount.IsPositive() {
+			return errors.New("transferência única exige valor positivo")
+		}
+	case domain.ScheduleRecurring:
+		if (schedule.KeepBalance == nil) == (!schedule.Amount.IsPositive()) {
+			return errors.New("transferência recorrente exige valor fixo ou keep_balance, não ambos")
+		}
+		if schedule.KeepBalance != nil && schedule.KeepBalance.Cents() < 0 {
+			return errors.New("keep_balance não pode ser negativo")
+		}
+		if _, _, err := parseClock(schedule.At); err != nil {
+			return err
+		}
+		days, err := parseWeekdays(schedule.Days)
+		if err != nil {
+			return err
+		}
+		if !days[time.Monday] && !days[time.Tuesday] && !days[time.Wednesday] && !days[time.Thursday] && !days[time.Friday] {
+			return errors.New("days precisa incluir ao menos um dia de semana (sábados e domingos não são dias úteis)")
+		}
+	default:
+		return fmt.Errorf("tipo de agendamento inválido: %q (use once ou recurring)", schedule.Kind)
+	}
+	return nil
+}
+
+// next calcula a próxima execução da regra depois de after, no primeiro dia
+// útil entre os dias da regra
+func (s *TransferScheduleService) next(schedule domain.ScheduledTransfer, after time.Time) (time.Time, error) {
+	hour, minute, err := parseClock(schedule.At)
+	if err != nil {
+		return time.Time{}, err
+	}
+	days, err := parseWeekdays(schedule.Days)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for i := 0; i <= 366; i++ {
+		day := time.Date(after.Year(), after.Month(), after.Day()+i, hour, minute, 0, 0, after.Location())
+		if day.After(after) && days[day.Weekday()] && s.calendar.IsBusinessDay(day) {
+			return day, nil
+		}
+	}
+	return time.Time{}, errors.New("nenhum dia útil nos próximos 12 meses atende à regra")
+}
+
+// Location retorna o fuso das datas e horários dos agendamentos
+func (s *TransferScheduleService) Location() *time.Location {
+	return s.calendar.Location()
+}
+
+// reservedTotal soma os valores das filas de repasse
+func (s *TransferScheduleService) reservedTotal() (domain.Money, error) {
+	var total int64
+	for _, r := range s.reserved {
+		amount, err := r.Reserved()
+		if err != nil {
+			return domain.Money{}, err
+		}
+		total += amount.Cents()
+	}
+	return domain.BRL(total), nil
+}
+
+// weekdayNames são os nomes aceitos em days
+var weekdayNames = map[string][]time.Weekday{
+	"sun":      {time.Sunday},
+	"mon":      {time.Monday},
+	"tue":      {time.Tuesday},
+	"wed":      {time.Wednesday},
+	"thu":      {time.Thursday},
+	"fri":      {time.Friday},
+	"sat":      {time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"daily":    {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+}
+
+// parseWeekdays converte os dias da regra (mon...sun, weekdays ou daily)
+func parseWeekdays(names []string) (map[time.Weekday]bool, error) {
+	if len(names) == 0 {
+		return nil, errors.New("transferência recorrente exige days (ex: weekdays, mon, fri)")
+	}
+	days := make(map[time.Weekday]bool)
+	for _, name := range names {
+		weekdays, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("dia inválido: %q (use sun, mon, tue, wed, thu, fri, sat, weekdays ou daily)", name)
+		}
+		for _, d := range weekdays {
+			days[d] = true
+		}
+	}
+	return days, nil
+}
+
+// parseClock converte o horário HH:MM da regra
+func parseClock(at string) (int, int, error) {
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return 0, 0, fmt.Errorf("horário inválido: %q (use HH:MM)", at)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// sameDay indica se a e b caem no mesmo dia
+func sameDay(a, b time.Time) bool {
+	return startOfDay(a).Equal(startOfDay(b))
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/calendar"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/repository"
+)
+
+// stubReserved simula uma fila de repasse com valor fixo
+type stubReserved struct {
+	amount domain.Money
+}
+
+func (r stubReserved) Reserved() (domain.Money, error) { return r.amount, nil }
+
+// newTestScheduleService cria o serviço com o saldo informado e reserved
+// centavos devidos a repasses, no fuso de São Paulo
+func newTestScheduleService(t *testing.T, balance, reserved int64) (*TransferScheduleService, *recordingTransferRepo, domain.ScheduledTransferRepository) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := repository.NewFileScheduledTransferRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao abrir agendamentos: %v", err)
+	}
+	ledgerRepo, err := repository.NewFileLedgerRepository(dir)
+	if err != nil {
+		t.Fatalf("erro ao abrir razão: %v", err)
+	}
+	t.Cleanup(func() { ledgerRepo.Close() })
+	cal, err := calendar.Load("", "", "America/Sao_Paulo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfers := &recordingTransferRepo{}
+	transferService := NewTransferService(transfers, config.DestinationAccount{}, ForwardTarget{}, nil, nil, &stubBalanceProvider{amount: domain.BRL(balance)}, domain.BRL(100), NopAuditor)
+	svc := NewTransferScheduleService(repo, transferService, NewLedgerService(ledgerRepo),
+		[]ReservedBalance{stubReserved{amount: domain.BRL(reserved)}}, cal, time.Minute, NopAuditor)
+	return svc, transfers, repo
+}
+
+func TestScheduleNextSkipsWeekend(t *testing.T) {
+	svc, _, _ := newTestScheduleService(t, 0, 0)
+	rule := domain.ScheduledTransfer{Kind: domain.ScheduleRecurring, Days: []string{"weekdays"}, At: "17:00"}
+
+	friday := time.Date(2024, time.June, 7, 18, 0, 0, 0, time.Local)
+	next, err := svc.next(rule, friday)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if want := time.Date(2024, time.June, 10, 17, 0, 0, 0, time.Local); !next.Equal(want) {
+		t.Fatalf("próxima execução = %s, esperado segunda %s", next, want)
+	}
+
+	// Antes do horário, a execução é no mesmo dia
+	next, _ = svc.next(rule, friday.Add(-2*time.Hour))
+	if want := time.Date(2024, time.June, 7, 17, 0, 0, 0, time.Local); !next.Equal(want) {
+		t.Fatalf("próxima execução = %s, esperado %s", next, want)
+	}
+}
+
+func TestScheduleOnceMovesToBusinessDayAndCancels(t *testing.T) {
+	svc, transfers, _ := newTestScheduleService(t, 0, 0)
+
+	saturday := time.Now().AddDate(0, 0, 1)
+	for saturday.Weekday() != time.Saturday {
+		saturday = saturday.AddDate(0, 0, 1)
+	}
+	schedule, err := svc.Schedule(context.Background(), domain.ScheduledTransfer{
+		Kind: domain.ScheduleOnce, Amount: domain.BRL(50_000), Next: &saturday,
+	})
+	if err != nil {
+		t.Fatalf("erro ao agendar: %v", err)
+	}
+	if !schedule.Next.After(saturday) || !svc.calendar.IsBusinessDay(*schedule.Next) {
+		t.Fatalf("data = %s, esperado o próximo dia útil", schedule.Next.Format("2006-01-02"))
+	}
+	// A data futura dispensa a verificação de saldo, feita pela StarkBank
+	if len(transfers.created) != 1 || transfers.created[0].Scheduled == nil || schedule.TransferID == "" {
+		t.Fatalf("esperada uma transferência agendada na StarkBank, obtido %+v", transfers.created)
+	}
+
+	if _, err := svc.Cancel(context.Background(), schedule.ID); err != nil {
+		t.Fatalf("erro ao cancelar: %v", err)
+	}
+	entries, _ := svc.ledger.EntriesByTransfer(schedule.TransferID)
+	if len(entries) != 2 || entries[0].Kind != domain.LedgerEntryScheduledTransfer ||
+		entries[1].Kind != domain.LedgerEntryScheduledReverted || !entries[1].Created.Equal(entries[0].Created) {
+		t.Fatalf("esperados lançamento e estorno na data agendada, obtido %+v", entries)
+	}
+	if _, err := svc.Cancel(context.Background(), schedule.ID); !errors.Is(err, domain.ErrScheduleClosed) {
+		t.Fatalf("esperado ErrScheduleClosed, obtido %v", err)
+	}
+}
+
+func TestScheduleRecurringSweepsExcess(t *testing.T) {
+	svc, transfers, repo := newTestScheduleService(t, 1_500_000, 0)
+
+	keep := domain.BRL(1_000_000)
+	due := time.Now().Add(-time.Minute)
+	rule := domain.ScheduledTransfer{
+		ID: "sch-1", Kind: domain.ScheduleRecurring, Status: domain.ScheduleStatusActive,
+		KeepBalance: &keep, Days: []string{"daily"}, At: "17:00", Next: &due,
+	}
+	if err := repo.Save(rule); err != nil {
+		t.Fatalf("erro ao salvar: %v", err)
+	}
+
+	created, err := svc.Run(context.Background())
+	if err != nil || created != 1 {
+		t.Fatalf("esperada 1 transferência, obtido %d (%v)", created, err)
+	}
+	// Saldo 15.000,00 - 10.000,00 mantidos - 1,00 de taxa
+	if got := transfers.created[0].Amount.Cents(); got != 499_900 {
+		t.Fatalf("valor varrido = %d, esperado 499900", got)
+	}
+
+	entries, _ := svc.ledger.EntriesByTransfer("tr-1")
+	if len(entries) != 1 || entries[0].Kind != domain.LedgerEntryScheduledTransfer {
+		t.Fatalf("transferência varrida fora do razão: %+v", entries)
+	}
+
+	saved, _ := repo.Get("sch-1")
+	if saved.Next == nil || !saved.Next.After(time.Now()) || saved.TransferID != "tr-1" {
+		t.Fatalf("agendamento não avançou: %+v", saved)
+	}
+	if created, _ := svc.Run(context.Background()); created != 0 {
+		t.Fatalf("regra executada de novo antes da próxima data")
+	}
+}
+
+func TestScheduleKeepBalanceSparesReserved(t *testing.T) {
+	svc, transfers, repo := newTestScheduleService(t, 1_500_000, 200_000)
+
+	keep := domain.BRL(1_000_000)
+	due := time.Now().Add(-time.Minute)
+	rule := domain.ScheduledTransfer{
+		ID: "sch-1", Kind: domain.ScheduleRecurring, Status: domain.ScheduleStatusActive,
+		KeepBalance: &keep, Days: []string{"daily"}, At: "17:00", Next: &due,
+	}
+	if err := repo.Save(rule); err != nil {
+		t.Fatalf("erro ao salvar: %v", err)
+	}
+
+	if _, err := svc.Run(context.Background()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	// Saldo 15.000,00 - 10.000,00 mantidos - 2.000,00 devidos a repasses - 1,00 de taxa
+	if len(transfers.created) != 1 || transfers.created[0].Amount.Cents() != 299_900 {
+		t.Fatalf("esperado varrer 299900, obtido %+v", transfers.created)
+	}
+	// A próxima execução segue o fuso configurado
+	saved, _ := repo.Get("sch-1")
+	if next := saved.Next.In(svc.Location()); next.Hour() != 17 || next.Minute() != 0 {
+		t.Fatalf("próxima execução = %s, esperado 17:00 em %s", next, svc.Location())
+	}
+}
+
+func TestScheduleValidation(t *testing.T) {
+	svc, _, _ := newTestScheduleService(t, 0, 0)
+	keep := domain.BRL(100)
+	cases := []domain.ScheduledTransfer{
+		{Kind: domain.ScheduleRecurring, Days: []string{"sat", "sun"}, At: "17:00", Amount: domain.BRL(100)},
+		{Kind: domain.ScheduleRecurring, Days: []string{"mon"}, At: "25:00", Amount: domain.BRL(100)},
+		{Kind: domain.ScheduleRecurring, Days: []string{"mon"}, At: "17:00", Amount: domain.BRL(100), KeepBalance: &keep},
+		{Kind: domain.ScheduleOnce, Amount: domain.BRL(100)},
+	}
+	for _, c := range cases {
+		if _, err := svc.Schedule(context.Background(), c); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("agendamento %+v: esperado ErrInvalidInput, obtido %v", c, err)
+		}
+	}
+}
@@ -1,84 +1,464 @@
 package service
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/metrics"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
 )
 
 // TransferService gerencia a lógica de negócio relacionada a transferências
 type TransferService struct {
 	repo        domain.TransferRepository
 	destination config.DestinationAccount
+	target      ForwardTarget // destino dos repasses de invoices
+	pixKeys     domain.PixKeyRepository
+	brcodes     domain.BRCodePaymentRepository
+	balance     domain.BalanceProvider
+	fee         domain.Money // taxa estimada cobrada por transferência
+	auditor     domain.Auditor
+
+	brcodePaid atomic.Bool // o BR Code de uso único do destino já foi pago
 }
 
-// NewTransferService cria uma nova instância do serviço
-func NewTransferService(repo domain.TransferRepository, dest config.DestinationAccount) *TransferService {
+// NewTransferService cria uma nova instância do serviço; os repasses de
+// invoices vão para target, e as demais transferências para dest
+func NewTransferService(
+	repo domain.TransferRepository,
+	dest config.DestinationAccount,
+	target ForwardTarget,
+	pixKeys domain.PixKeyRepository,
+	brcodes domain.BRCodePaymentRepository,
+	balance domain.BalanceProvider,
+	fee domain.Money,
+	auditor domain.Auditor,
+) *TransferService {
 	return &TransferService{
 		repo:        repo,
 		destination: dest,
+		target:      target,
+		pixKeys:     pixKeys,
+		brcodes:     brcodes,
+		balance:     balance,
+		fee:         fee,
+		auditor:     auditor,
 	}
 }
 
 // CreateFromInvoicePayment cria uma transferência a partir de um pagamento de invoice
-func (s *TransferService) CreateFromInvoicePayment(invoiceID string, amount, fee int64) (*domain.Transfer, error) {
+func (s *TransferService) CreateFromInvoicePayment(ctx context.Context, invoiceID string, amount, fee domain.Money) (_ *domain.Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "TransferService.CreateFromInvoicePayment",
+		attribute.String("invoice.id", invoiceID),
+		attribute.Int64("amount.cents", amount.Cents()))
+	defer func() { tracing.End(span, err) }()
+
 	// Calcular valor líquido (valor recebido - taxas)
-	netAmount := amount - fee
+	netAmount, err := amount.Sub(fee)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular valor líquido: %w", err)
+	}
+
+	if !netAmount.IsPositive() {
+		return nil, fmt.Errorf("valor líquido inválido: %s", netAmount)
+	}
+
+	slog.InfoContext(ctx, "criando transferência",
+		"invoice_id", invoiceID,
+		"amount", netAmount,
+		"gross", amount,
+		"fee", fee)
+
+	// ExternalID determinístico: a StarkBank recusa um segundo repasse do
+	// mesmo invoice, mesmo que a primeira chamada tenha expirado sem resposta
+	externalID := "inv-" + invoiceID
+
+	return s.forward(ctx, span, netAmount, fmt.Sprintf("Transferência referente ao invoice %s", invoiceID), externalID, nil, map[string]interface{}{
+		"invoice_id":  invoiceID,
+		"gross":       amount,
+		"fee":         fee,
+		"external_id": externalID,
+	})
+}
+
+// CreateFromCredits cria uma única transferência com o valor líquido somado
+// de vários invoices (repasse agrupado)
+//
+// O external ID deriva dos invoices: repetir a varredura após uma falha (ex:
+// timeout da StarkBank) não duplica o repasse.
+func (s *TransferService) CreateFromCredits(ctx context.Context, invoiceIDs []string, net domain.Money) (_ *domain.Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "TransferService.CreateFromCredits",
+		attribute.Int("invoice.count", len(invoiceIDs)),
+		attribute.Int64("amount.cents", net.Cents()))
+	defer func() { tracing.End(span, err) }()
 
-	if netAmount <= 0 {
-		return nil, fmt.Errorf("valor líquido inválido: R$%.2f", float64(netAmount)/100)
+	if len(invoiceIDs) == 0 || !net.IsPositive() {
+		return nil, fmt.Errorf("repasse agrupado inválido: %d invoices, valor %s", len(invoiceIDs), net)
 	}
 
-	log.Printf("💸 Criando transferência de R$%.2f (bruto: R$%.2f - taxa: R$%.2f)\n",
-		float64(netAmount)/100,
-		float64(amount)/100,
-		float64(fee)/100)
+	slog.InfoContext(ctx, "criando transferência agrupada", "invoices", len(invoiceIDs), "amount", net)
+
+	externalID := sweepExternalID(invoiceIDs)
+	return s.forward(ctx, span, net, fmt.Sprintf("Repasse agrupado de %d invoices", len(invoiceIDs)), externalID, nil, map[string]interface{}{
+		"invoice_ids": invoiceIDs,
+		"external_id": externalID,
+	})
+}
+
+// sweepExternalID identifica um repasse agrupado pelo hash dos invoices,
+// independente da ordem
+func sweepExternalID(invoiceIDs []string) string {
+	sorted := append([]string(nil), invoiceIDs...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, ",")))
+	return "sweep-" + hex.EncodeToString(sum[:16])
+}
+
+// CreateFromApproval cria a transferência de uma solicitação aprovada, com os
+// aprovadores nas tags da transferência
+//
+// O external ID deriva da solicitação: repetir a criação após uma falha não
+// duplica o repasse na StarkBank.
+func (s *TransferService) CreateFromApproval(ctx context.Context, approval domain.TransferApproval) (_ *domain.Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "TransferService.CreateFromApproval",
+		attribute.String("approval.id", approval.ID),
+		attribute.Int("invoice.count", len(approval.InvoiceIDs)),
+		attribute.Int64("amount.cents", approval.Amount.Cents()))
+	defer func() { tracing.End(span, err) }()
+
+	if len(approval.InvoiceIDs) == 0 || !approval.Amount.IsPositive() {
+		return nil, fmt.Errorf("repasse aprovado inválido: %d invoices, valor %s", len(approval.InvoiceIDs), approval.Amount)
+	}
+
+	approvers := approval.Approvers()
+	slog.InfoContext(ctx, "criando transferência aprovada",
+		"approval_id", approval.ID,
+		"invoices", len(approval.InvoiceIDs),
+		"amount", approval.Amount,
+		"approved_by", approvers)
+
+	externalID := "approval-" + approval.ID
+	var tags []string
+	for _, name := range approvers {
+		tags = append(tags, "approved-by:"+strings.ToLower(name))
+	}
+	return s.forward(ctx, span, approval.Amount, fmt.Sprintf("Repasse aprovado de %d invoices", len(approval.InvoiceIDs)), externalID, tags, map[string]interface{}{
+		"invoice_ids": approval.InvoiceIDs,
+		"external_id": externalID,
+		"approval_id": approval.ID,
+		"approved_by": approvers,
+	})
+}
 
-	// Gerar ExternalID único e curto para idempotência
-	externalID := fmt.Sprintf("inv-%s-%d", invoiceID, time.Now().Unix())
+// CreateScheduled cria a transferência de um agendamento, na conta do
+// agendamento ou, sem ela, na conta de destino do tenant
+//
+// Com date, a transferência é agendada na StarkBank para a data; sem date, é
+// executada na hora (execução de uma regra recorrente).
+func (s *TransferService) CreateScheduled(ctx context.Context, schedule domain.ScheduledTransfer, amount domain.Money, date *time.Time, externalID string) (_ *domain.Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "TransferService.CreateScheduled",
+		attribute.String("schedule.id", schedule.ID),
+		attribute.Int64("amount.cents", amount.Cents()))
+	defer func() { tracing.End(span, err) }()
 
-	transfer := domain.Transfer{
-		Amount:        int(netAmount),
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("valor de transferência agendada inválido: %s", amount)
+	}
+
+	description := schedule.Description
+	if description == "" {
+		description = "Transferência agendada " + schedule.ID
+	}
+	transfer := s.toDestination(amount, description, externalID)
+	if d := schedule.Destination; d != nil {
+		transfer.BankCode = d.BankCode
+		transfer.BranchCode = d.BranchCode
+		transfer.AccountNumber = d.AccountNumber
+		transfer.Name = d.Name
+		transfer.TaxID = d.TaxID
+		transfer.AccountType = d.AccountType
+	}
+	transfer.Scheduled = date
+
+	slog.InfoContext(ctx, "criando transferência agendada",
+		"schedule_id", schedule.ID,
+		"amount", amount,
+		"scheduled", date)
+	return s.create(ctx, span, transfer, map[string]interface{}{
+		"schedule_id": schedule.ID,
+		"kind":        schedule.Kind,
+		"external_id": externalID,
+		"scheduled":   date,
+	})
+}
+
+// Excess retorna quanto do saldo pode ser transferido mantendo keep na conta,
+// além de reserved (valores já devidos a repasses), já descontada a taxa
+// estimada; zero se não houver excedente
+func (s *TransferService) Excess(ctx context.Context, keep, reserved domain.Money) (domain.Money, error) {
+	// A varredura precisa do saldo atual, não do cache
+	s.balance.Invalidate()
+	balance, err := s.balance.Get(ctx)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("erro ao verificar saldo: %w", err)
+	}
+	excess := balance.Amount.Cents() - keep.Cents() - reserved.Cents() - s.fee.Cents()
+	if excess < 0 {
+		excess = 0
+	}
+	return domain.BRL(excess), nil
+}
+
+// Cancel cancela uma transferência ainda não processada (ex: agendada)
+func (s *TransferService) Cancel(ctx context.Context, id string) (*domain.Transfer, error) {
+	transfer, err := s.repo.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.balance.Invalidate()
+	slog.InfoContext(ctx, "transferência cancelada", "transfer_id", id, "status", transfer.Status)
+	return transfer, nil
+}
+
+// toDestination monta a transferência para a conta de destino
+func (s *TransferService) toDestination(amount domain.Money, description, externalID string) domain.Transfer {
+	return domain.Transfer{
+		Amount:        amount,
 		BankCode:      s.destination.BankCode,
 		BranchCode:    s.destination.BranchCode,
 		AccountNumber: s.destination.AccountNumber,
 		Name:          s.destination.Name,
 		TaxID:         s.destination.TaxID,
 		AccountType:   s.destination.AccountType,
-		Description:   fmt.Sprintf("Transferência referente ao invoice %s", invoiceID),
+		Description:   description,
+		ExternalID:    externalID,
+	}
+}
+
+// forward repassa amount ao destino dos repasses do tenant: transferência à
+// conta de destino ou à conta de uma chave Pix, ou pagamento de um BR Code
+func (s *TransferService) forward(ctx context.Context, span trace.Span, amount domain.Money, description, externalID string, tags []string, details map[string]interface{}) (*domain.Transfer, error) {
+	details["destination"] = s.target.kind()
+	switch s.target.Kind {
+	case config.ForwardToPixKey:
+		transfer, err := s.toPixKey(ctx, amount, description, externalID)
+		if err != nil {
+			return nil, err
+		}
+		transfer.Tags = tags
+		return s.create(ctx, span, transfer, details)
+	case config.ForwardToBRCode:
+		return s.payBRCode(ctx, span, amount, description, externalID, tags, details)
+	default:
+		transfer := s.toDestination(amount, description, externalID)
+		transfer.Tags = tags
+		return s.create(ctx, span, transfer, details)
+	}
+}
+
+// toPixKey monta uma transferência Pix para a conta da chave de destino,
+// consultada no DICT; o código do banco é o ISPB, o que faz da transferência
+// um Pix. Um titular que não confere com o CPF/CNPJ configurado (a chave
+// mudou de dono) retorna domain.ErrInvalidInput.
+func (s *TransferService) toPixKey(ctx context.Context, amount domain.Money, description, externalID string) (domain.Transfer, error) {
+	key, err := s.pixKeys.Get(ctx, s.target.PixKey)
+	if err != nil {
+		return domain.Transfer{}, err
+	}
+
+	if key.TaxID != "" && !domain.MatchesMaskedTaxID(key.TaxID, s.target.TaxID) {
+		return domain.Transfer{}, fmt.Errorf("%w: titular da chave Pix no DICT (%s) não confere com o CPF/CNPJ configurado", domain.ErrInvalidInput, key.TaxID)
+	}
+	return domain.Transfer{
+		Amount:        amount,
+		BankCode:      key.ISPB,
+		BranchCode:    key.BranchCode,
+		AccountNumber: key.AccountNumber,
+		Name:          key.Name,
+		TaxID:         s.target.TaxID,
+		AccountType:   key.AccountType,
+		Description:   description,
 		ExternalID:    externalID,
+	}, nil
+}
+
+// payBRCode paga o BR Code de destino com amount
+//
+// Um código inativo (um código dinâmico já pago, por exemplo), de valor fixo
+// diferente de amount ou de uso único já pago por este processo retorna
+// domain.ErrInvalidInput e os créditos continuam pendentes. A StarkBank não
+// aceita external ID em pagamentos; ele vai nas tags.
+func (s *TransferService) payBRCode(ctx context.Context, span trace.Span, amount domain.Money, description, externalID string, tags []string, details map[string]interface{}) (*domain.Transfer, error) {
+	if s.target.SingleUse && s.brcodePaid.Load() {
+		return nil, fmt.Errorf("%w: BR Code de uso único do destino já foi pago; configure um novo código", domain.ErrInvalidInput)
+	}
+	preview, err := s.brcodes.Preview(ctx, s.target.BRCode)
+	if err != nil {
+		return nil, err
+	}
+	if preview.Status != "" && preview.Status != "active" {
+		return nil, fmt.Errorf("%w: BR Code de destino não está ativo (%s)", domain.ErrInvalidInput, preview.Status)
+	}
+	if (preview.FixedAmount() || s.target.SingleUse && preview.Amount.IsPositive()) && preview.Amount.Cents() != amount.Cents() {
+		return nil, fmt.Errorf("%w: BR Code de destino tem valor fixo de %s, repasse de %s", domain.ErrInvalidInput, preview.Amount, amount)
+	}
+	if err := s.checkBalance(ctx, amount); err != nil {
+		return nil, err
 	}
 
-	created, err := s.repo.Create([]domain.Transfer{transfer})
+	payment, err := s.brcodes.Create(ctx, domain.BRCodePayment{
+		BRCode:      s.target.BRCode,
+		TaxID:       preview.TaxID,
+		Description: description,
+		Amount:      amount,
+		Tags:        append(tags, "external-id:"+externalID),
+	})
 	if err != nil {
-		log.Printf("❌ Erro ao criar transferência: %v\n", err)
+		slog.ErrorContext(ctx, "erro ao pagar BR Code", "external_id", externalID, "error", err)
 		return nil, err
 	}
+	s.balance.Invalidate()
+	if s.target.SingleUse {
+		s.brcodePaid.Store(true)
+		slog.WarnContext(ctx, "BR Code de uso único do destino pago; os próximos repasses aguardam um novo código", "payment_id", payment.ID)
+	}
+
+	return s.recordCreated(ctx, span, &domain.Transfer{
+		ID:          payment.ID,
+		Kind:        domain.TransferKindBRCode,
+		Amount:      payment.Amount,
+		Name:        preview.Name,
+		TaxID:       preview.TaxID,
+		Description: description,
+		ExternalID:  externalID,
+		Tags:        payment.Tags,
+		Status:      payment.Status,
+		Fee:         payment.Fee,
+		Created:     payment.Created,
+	}, details), nil
+}
+
+// ResolveDestination consulta o destino dos repasses (a chave Pix no DICT ou
+// o BR Code), para conferência antes que o dinheiro saia
+func (s *TransferService) ResolveDestination(ctx context.Context) (*ForwardDestination, error) {
+	result := &ForwardDestination{Kind: s.target.kind()}
+	switch s.target.Kind {
+	case config.ForwardToPixKey:
+		key, err := s.pixKeys.Get(ctx, s.target.PixKey)
+		if err != nil {
+			return nil, err
+		}
+		result.PixKey = key
+	case config.ForwardToBRCode:
+		preview, err := s.brcodes.Preview(ctx, s.target.BRCode)
+		if err != nil {
+			return nil, err
+		}
+		result.BRCode = preview
+	default:
+		// Número da conta e CPF/CNPJ ficam de fora, como no resumo da configuração
+		d := s.destination
+		result.Account = &domain.BankAccount{BankCode: d.BankCode, BranchCode: d.BranchCode, Name: d.Name, AccountType: d.AccountType}
+	}
+	return result, nil
+}
+
+// DestinationKey identifica o destino dos repasses no limite por destino
+func (s *TransferService) DestinationKey() string {
+	switch s.target.Kind {
+	case config.ForwardToPixKey:
+		return "pix:" + s.target.PixKey
+	case config.ForwardToBRCode:
+		return "brcode:" + s.target.BRCode
+	}
+	return DestinationKey(s.destination)
+}
+
+// create verifica o saldo, envia a transferência e registra o resultado na
+// auditoria e nas métricas; details complementa o registro de auditoria
+func (s *TransferService) create(ctx context.Context, span trace.Span, transfer domain.Transfer, details map[string]interface{}) (*domain.Transfer, error) {
+	// Verificar se o saldo cobre a transferência antes de enviá-la; uma
+	// transferência agendada para outro dia é verificada pela StarkBank na data
+	if transfer.Scheduled == nil || !transfer.Scheduled.After(time.Now()) {
+		if err := s.checkBalance(ctx, transfer.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.repo.Create(ctx, []domain.Transfer{transfer})
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao criar transferência", "external_id", transfer.ExternalID, "error", err)
+		return nil, err
+	}
+
+	// O saldo mudou: a próxima verificação precisa consultar a API
+	s.balance.Invalidate()
 
 	if len(created) == 0 {
 		return nil, fmt.Errorf("nenhuma transferência criada")
 	}
 
-	result := &created[0]
-	log.Printf("✅ Transferência criada com sucesso!\n")
-	log.Printf("   ID: %s\n", result.ID)
-	log.Printf("   Valor: R$%.2f\n", float64(result.Amount)/100)
-	log.Printf("   Status: %s\n", result.Status)
-	log.Printf("   Destinatário: %s\n", result.Name)
-	log.Printf("   Invoice Origem: %s\n", invoiceID)
+	return s.recordCreated(ctx, span, &created[0], details), nil
+}
 
-	return result, nil
+// recordCreated registra uma transferência (ou pagamento) criada na
+// auditoria, nas métricas e nos logs
+func (s *TransferService) recordCreated(ctx context.Context, span trace.Span, result *domain.Transfer, details map[string]interface{}) *domain.Transfer {
+	span.SetAttributes(attribute.String("transfer.id", result.ID))
+	details["amount"] = result.Amount
+	details["status"] = result.Status
+	s.auditor.Record(ctx, domain.AuditTransferCreated, result.ID, nil, details)
+	metrics.TransfersCreated.Inc(result.Status)
+	metrics.ForwardedAmount.Add(float64(result.Amount.Cents()), result.Amount.Currency())
+	slog.InfoContext(ctx, "transferência criada",
+		"transfer_id", result.ID,
+		"amount", result.Amount,
+		"status", result.Status,
+		"account_number", result.AccountNumber,
+		"external_id", result.ExternalID,
+		"kind", result.Kind)
+
+	return result
+}
+
+// checkBalance verifica se o saldo disponível cobre o valor mais a taxa estimada
+func (s *TransferService) checkBalance(ctx context.Context, amount domain.Money) error {
+	required, err := amount.Add(s.fee)
+	if err != nil {
+		return fmt.Errorf("erro ao calcular valor necessário: %w", err)
+	}
+
+	balance, err := s.balance.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar saldo: %w", err)
+	}
+
+	if c, err := balance.Amount.Cmp(required); err != nil || c < 0 {
+		slog.WarnContext(ctx, "saldo insuficiente para transferência", "required", required, "available", balance.Amount)
+		return &domain.InsufficientBalanceError{Required: required, Available: balance.Amount}
+	}
+
+	return nil
 }
 
 // GetByID busca uma transferência por ID
-func (s *TransferService) GetByID(id string) (*domain.Transfer, error) {
-	return s.repo.GetByID(id)
+func (s *TransferService) GetByID(ctx context.Context, id string) (*domain.Transfer, error) {
+	return s.repo.GetByID(ctx, id)
 }
 
 // List lista transferências
-func (s *TransferService) List(limit int) ([]domain.Transfer, error) {
-	return s.repo.List(limit)
+func (s *TransferService) List(ctx context.Context, limit int) ([]domain.Transfer, error) {
+	return s.repo.List(ctx, limit)
 }
@@ -1,59 +1,131 @@
 package service
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/tracing"
 )
 
 // WebhookServiceImpl implementa a lógica de processamento de webhooks
 type WebhookServiceImpl struct {
-	transferService *TransferService
+	forwarding      *ForwardingService
+	reversalService *ReversalService
+	ledgerService   *LedgerService
+	holdService     *HoldQueueService
+	verifier        domain.EventVerifier
 }
 
 // NewWebhookService cria uma nova instância do serviço
-func NewWebhookService(transferService *TransferService) *WebhookServiceImpl {
+func NewWebhookService(
+	forwarding *ForwardingService,
+	reversalService *ReversalService,
+	ledgerService *LedgerService,
+	holdService *HoldQueueService,
+	verifier domain.EventVerifier,
+) *WebhookServiceImpl {
 	return &WebhookServiceImpl{
-		transferService: transferService,
+		forwarding:      forwarding,
+		reversalService: reversalService,
+		ledgerService:   ledgerService,
+		holdService:     holdService,
+		verifier:        verifier,
 	}
 }
 
 // ProcessEvent processa um evento de webhook
-func (s *WebhookServiceImpl) ProcessEvent(event domain.WebhookEvent) error {
-	log.Printf("📋 Processando evento: Tipo=%s | Status=%s\n", event.EventType, event.Status)
+func (s *WebhookServiceImpl) ProcessEvent(ctx context.Context, event domain.WebhookEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "WebhookServiceImpl.ProcessEvent",
+		attribute.String("event.id", event.EventID),
+		attribute.String("event.subscription", event.Subscription),
+		attribute.String("event.type", event.EventType),
+		attribute.String("invoice.id", event.InvoiceID))
+	defer func() { tracing.End(span, err) }()
+
+	slog.InfoContext(ctx, "processando evento", "event_type", event.EventType, "status", event.Status)
 
 	// Processar apenas invoices creditados
 	if event.Subscription != "invoice" {
-		log.Printf("⏭️  Evento ignorado: subscription=%s\n", event.Subscription)
+		slog.InfoContext(ctx, "evento ignorado", "subscription", event.Subscription)
 		return nil
 	}
 
+	// Estornos de invoices já creditados precisam ser compensados
+	if event.EventType == "reversed" {
+		slog.InfoContext(ctx, "invoice estornado detectado",
+			"invoice_id", event.InvoiceID,
+			"amount", event.Amount)
+		// Um crédito ainda na fila da política ou retido por falta de saldo
+		// não chega a ser repassado
+		if _, err := s.forwarding.Cancel(ctx, event.InvoiceID, "invoice estornado antes do repasse"); err != nil {
+			return err
+		}
+		if _, err := s.holdService.Cancel(ctx, event.InvoiceID, "invoice estornado antes da liberação"); err != nil {
+			return err
+		}
+		_, err := s.reversalService.HandleReversal(ctx, event)
+		return err
+	}
+
 	// IMPORTANTE: Processar APENAS 'credited', NÃO 'paid'
 	// O desafio pede: "Receives the webhook callback of the Invoice credit"
 	// Se processar 'paid' também, cria transferências duplicadas!
 	if event.EventType != "credited" {
-		log.Printf("⏭️  Invoice ainda não creditado: tipo=%s (aguardando 'credited')\n", event.EventType)
+		slog.InfoContext(ctx, "invoice ainda não creditado, aguardando 'credited'", "event_type", event.EventType)
 		return nil
 	}
 
-	log.Printf("💰 Invoice creditado detectado! ID: %s | Valor: R$%.2f | Taxa: R$%.2f\n",
-		event.InvoiceID,
-		float64(event.Amount)/100,
-		float64(event.Fee)/100)
-
-	// Criar transferência com o valor recebido menos as taxas
-	_, err := s.transferService.CreateFromInvoicePayment(
-		event.InvoiceID,
-		event.Amount,
-		event.Fee,
-	)
+	slog.InfoContext(ctx, "invoice creditado detectado",
+		"invoice_id", event.InvoiceID,
+		"amount", event.Amount,
+		"fee", event.Fee)
 
+	// Sem saldo suficiente, o repasse entra na fila de retenção e o webhook
+	// é confirmado: a liberação acontece quando o saldo se recuperar. O
+	// crédito de um pagador bloqueado aguarda na fila da política até a
+	// resolução do estorno
+	_, err = s.Forward(ctx, event)
+	var insufficient *domain.InsufficientBalanceError
+	if errors.As(err, &insufficient) {
+		return s.holdService.Hold(ctx, event, *insufficient)
+	}
+	if errors.Is(err, domain.ErrPayerHeld) {
+		return s.forwarding.HoldPayer(ctx, event)
+	}
 	return err
 }
 
-// ValidateSignature valida a assinatura digital de um webhook
-func (s *WebhookServiceImpl) ValidateSignature(body, signature string) bool {
-	// TODO: Implementar validação de assinatura usando Event.Parse
-	// Por enquanto, retorna true para desenvolvimento
-	return true
+// Forward registra o crédito de um invoice e o entrega à política de repasse
+//
+// Retorna a transferência criada, ou nil se o repasse não for necessário
+// (evento duplicado) ou se a política deixou o crédito pendente. Retorna
+// domain.ErrPayerHeld se o pagador tiver estorno pendente e
+// domain.ErrInsufficientBalance se o saldo não cobrir o repasse.
+func (s *WebhookServiceImpl) Forward(ctx context.Context, event domain.WebhookEvent) (*domain.Transfer, error) {
+	// Registrar o crédito no razão antes de qualquer repasse
+	if err := s.ledgerService.RecordInvoiceCredit(ctx, event.InvoiceID, event.Amount, event.Fee); err != nil {
+		return nil, err
+	}
+
+	// Pagadores com estorno pendente ficam bloqueados
+	held, err := s.reversalService.IsPayerHeld(event.PayerTaxID)
+	if err != nil {
+		return nil, err
+	}
+	if held {
+		slog.WarnContext(ctx, "repasse bloqueado: pagador possui estorno pendente", "invoice_id", event.InvoiceID)
+		return nil, domain.ErrPayerHeld
+	}
+
+	return s.forwarding.Submit(ctx, event)
+}
+
+// VerifySignature valida a assinatura digital de um webhook com as
+// credenciais do tenant
+func (s *WebhookServiceImpl) VerifySignature(ctx context.Context, body, signature string) error {
+	return s.verifier.Verify(ctx, body, signature)
 }
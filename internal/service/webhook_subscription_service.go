@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+// Ações de um plano de sincronização de webhooks
+const (
+	WebhookActionKeep   = "keep"
+	WebhookActionCreate = "create"
+	WebhookActionDelete = "delete"
+)
+
+// WebhookChange é um item do plano de sincronização
+type WebhookChange struct {
+	Action  string                     `json:"action"`
+	Webhook domain.WebhookSubscription `json:"webhook"`
+	Reason  string                     `json:"reason"`
+}
+
+// WebhookPlan lista o que a sincronização fará com os webhooks do projeto
+type WebhookPlan struct {
+	Changes []WebhookChange `json:"changes"`
+}
+
+// Pending conta as alterações (criações e remoções) do plano
+func (p WebhookPlan) Pending() int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action != WebhookActionKeep {
+			n++
+		}
+	}
+	return n
+}
+
+// WebhookSubscriptionService gerencia os webhooks cadastrados na StarkBank
+type WebhookSubscriptionService struct {
+	repo domain.WebhookSubscriptionRepository
+}
+
+// NewWebhookSubscriptionService cria uma nova instância do serviço
+func NewWebhookSubscriptionService(repo domain.WebhookSubscriptionRepository) *WebhookSubscriptionService {
+	return &WebhookSubscriptionService{repo: repo}
+}
+
+// List lista os webhooks do projeto
+func (s *WebhookSubscriptionService) List(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return s.repo.List(ctx)
+}
+
+// Create cadastra um webhook após validar a URL e os eventos
+func (s *WebhookSubscriptionService) Create(ctx context.Context, url string, subscriptions []string) (*domain.WebhookSubscription, error) {
+	if err := validateWebhook(url, subscriptions); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(ctx, url, normalizeSubscriptions(subscriptions))
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "webhook criado", "webhook_id", created.ID, "url", created.URL, "subscriptions", created.Subscriptions)
+	return created, nil
+}
+
+// Delete remove um webhook
+func (s *WebhookSubscriptionService) Delete(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "webhook removido", "webhook_id", id)
+	return nil
+}
+
+// Plan compara os webhooks cadastrados com o desejado na configuração
+//
+// O webhook desejado é mantido se já existir com a mesma URL e os mesmos
+// eventos; senão, é criado. Webhooks que recebem algum dos eventos desejados
+// em outra URL (ex: um túnel ngrok antigo) ou com outros eventos são
+// removidos, para que nenhum evento seja entregue em duplicidade. Webhooks
+// sem eventos em comum não são tocados.
+func (s *WebhookSubscriptionService) Plan(ctx context.Context, desired config.WebhookConfig) (*WebhookPlan, error) {
+	if desired.URL == "" {
+		return nil, fmt.Errorf("webhook.url (WEBHOOK_URL) não configurada")
+	}
+	if err := validateWebhook(desired.URL, desired.Subscriptions); err != nil {
+		return nil, err
+	}
+	want := normalizeSubscriptions(desired.Subscriptions)
+
+	existing, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &WebhookPlan{}
+	found := false
+	for _, w := range existing {
+		subs := normalizeSubscriptions(w.Subscriptions)
+		change := WebhookChange{Action: WebhookActionDelete, Webhook: w}
+		switch {
+		case w.URL == desired.URL && equalSubscriptions(subs, want) && !found:
+			found = true
+			change.Action = WebhookActionKeep
+			change.Reason = "igual ao configurado"
+		case w.URL == desired.URL && equalSubscriptions(subs, want):
+			change.Reason = "duplicado"
+		case w.URL == desired.URL:
+			change.Reason = "eventos diferentes do configurado (" + strings.Join(want, ",") + ")"
+		case overlaps(subs, want):
+			change.Reason = "outra URL recebe os mesmos eventos"
+		default:
+			change.Action = WebhookActionKeep
+			change.Reason = "sem eventos em comum (não gerenciado)"
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if !found {
+		plan.Changes = append(plan.Changes, WebhookChange{
+			Action:  WebhookActionCreate,
+			Webhook: domain.WebhookSubscription{URL: desired.URL, Subscriptions: want},
+			Reason:  "configurado e não cadastrado",
+		})
+	}
+	return plan, nil
+}
+
+// Apply executa o plano: cria antes de remover, para que os eventos não
+// fiquem sem destino durante a troca
+func (s *WebhookSubscriptionService) Apply(ctx context.Context, plan *WebhookPlan) error {
+	for _, c := range plan.Changes {
+		if c.Action != WebhookActionCreate {
+			continue
+		}
+		if _, err := s.Create(ctx, c.Webhook.URL, c.Webhook.Subscriptions); err != nil {
+			return err
+		}
+	}
+	for _, c := range plan.Changes {
+		if c.Action != WebhookActionDelete {
+			continue
+		}
+		if err := s.Delete(ctx, c.Webhook.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateWebhook(url string, subscriptions []string) error {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return fmt.Errorf("URL do webhook inválida: %q (use uma URL http(s) completa)", url)
+	}
+	if len(subscriptions) == 0 {
+		return fmt.Errorf("informe ao menos um evento (%s)", strings.Join(config.WebhookSubscriptions, ", "))
+	}
+	for _, sub := range subscriptions {
+		if !contains(config.WebhookSubscriptions, sub) {
+			return fmt.Errorf("evento desconhecido: %q (use %s)", sub, strings.Join(config.WebhookSubscriptions, ", "))
+		}
+	}
+	return nil
+}
+
+// normalizeSubscriptions ordena e remove duplicados para comparação
+func normalizeSubscriptions(subs []string) []string {
+	seen := make(map[string]bool, len(subs))
+	result := make([]string, 0, len(subs))
+	for _, s := range subs {
+		s = strings.TrimSpace(s)
+		if s != "" && !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func equalSubscriptions(a, b []string) bool {
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
+
+func overlaps(a, b []string) bool {
+	for _, s := range a {
+		if contains(b, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/config"
+	"github.com/jpdsbarbosa/challenge-joao-barbosa/internal/domain"
+)
+
+type fakeWebhookRepo struct {
+	webhooks []domain.WebhookSubscription
+	calls    []string
+}
+
+func (r *fakeWebhookRepo) List(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return append([]domain.WebhookSubscription(nil), r.webhooks...), nil
+}
+
+func (r *fakeWebhookRepo) Create(ctx context.Context, url string, subscriptions []string) (*domain.WebhookSubscription, error) {
+	w := domain.WebhookSubscription{ID: fmt.Sprintf("new-%d", len(r.webhooks)), URL: url, Subscriptions: subscriptions}
+	r.webhooks = append(r.webhooks, w)
+	r.calls = append(r.calls, "create "+url)
+	return &w, nil
+}
+
+func (r *fakeWebhookRepo) Delete(ctx context.Context, id string) error {
+	for i, w := range r.webhooks {
+		if w.ID == id {
+			r.webhooks = append(r.webhooks[:i], r.webhooks[i+1:]...)
+			r.calls = append(r.calls, "delete "+id)
+			return nil
+		}
+	}
+	return fmt.Errorf("webhook %s não encontrado", id)
+}
+
+func TestWebhookSyncPlan(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeWebhookRepo{webhooks: []domain.WebhookSubscription{
+		{ID: "1", URL: "https://antigo.ngrok.io/webhook", Subscriptions: []string{"invoice"}},
+		{ID: "2", URL: "https://outro.exemplo.com/hook", Subscriptions: []string{"boleto"}},
+		{ID: "3", URL: "https://api.exemplo.com/webhook", Subscriptions: []string{"invoice"}},
+	}}
+	svc := NewWebhookSubscriptionService(repo)
+	desired := config.WebhookConfig{URL: "https://api.exemplo.com/webhook", Subscriptions: []string{"transfer", "invoice"}}
+
+	plan, err := svc.Plan(ctx, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		WebhookActionDelete + " 1", // outra URL com invoice
+		WebhookActionKeep + " 2",   // sem eventos em comum
+		WebhookActionDelete + " 3", // mesma URL, eventos diferentes
+		WebhookActionCreate + " ",
+	}
+	if len(plan.Changes) != len(want) {
+		t.Fatalf("plano com %d itens, esperado %d: %+v", len(plan.Changes), len(want), plan.Changes)
+	}
+	for i, c := range plan.Changes {
+		if got := c.Action + " " + c.Webhook.ID; got != want[i] {
+			t.Errorf("item %d = %q, esperado %q", i, got, want[i])
+		}
+	}
+	if plan.Pending() != 3 {
+		t.Errorf("Pending = %d, esperado 3", plan.Pending())
+	}
+
+	if err := svc.Apply(ctx, plan); err != nil {
+		t.Fatal(err)
+	}
+	if repo.calls[0] != "create "+desired.URL {
+		t.Errorf("o webhook deveria ser criado antes das remoções: %v", repo.calls)
+	}
+
+	plan, err = svc.Plan(ctx, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Pending() != 0 {
+		t.Errorf("após aplicar, o plano deveria estar vazio: %+v", plan.Changes)
+	}
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartLinkedCarriesOrigin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	ctx, origin := Start(context.Background(), "webhook")
+	carrier := Inject(ctx)
+	origin.End()
+
+	if carrier["traceparent"] == "" {
+		t.Fatalf("traceparent não injetado: %v", carrier)
+	}
+
+	_, worker := StartLinked(context.Background(), "release", carrier)
+	worker.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("esperados 2 spans, obtidos %d", len(spans))
+	}
+	released := spans[1]
+	if released.SpanContext().TraceID() == origin.SpanContext().TraceID() {
+		t.Error("trabalho assíncrono deveria ter um trace próprio")
+	}
+	links := released.Links()
+	if len(links) != 1 || links[0].SpanContext.SpanID() != origin.SpanContext().SpanID() {
+		t.Errorf("span deveria estar ligado ao span de origem: %+v", links)
+	}
+}
@@ -6,10 +6,10 @@ echo "║           🧪 TESTE DE WEBHOOK - SIMULADO 🧪             ║"
 echo "║                                                        ║"
 echo "╚════════════════════════════════════════════════════════╝"
 echo ""
-echo "📨 Enviando webhook simulado de invoice pago..."
+echo "📨 Enviando webhook simulado (sem assinatura) de invoice pago..."
 echo ""
 
-curl -X POST http://localhost:8080/webhook \
+curl -s -o /dev/null -w "HTTP %{http_code}" -X POST http://localhost:8080/webhook \
   -H "Content-Type: application/json" \
   -d '{
     "event": {
@@ -29,6 +29,7 @@ curl -X POST http://localhost:8080/webhook \
 
 echo ""
 echo ""
-echo "✅ Webhook enviado!"
-echo "📊 Verifique os logs do servidor (make run) para ver o resultado"
+echo "✅ Webhook enviado! O esperado é HTTP 401: eventos sem a assinatura"
+echo "   Digital-Signature da StarkBank são rejeitados."
+echo "📊 Para processar um evento real use: go run ./cmd/ctl events replay <id>"
 echo ""